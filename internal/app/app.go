@@ -3,8 +3,7 @@ package app
 
 import (
 	"context"
-	"os"
-	"path/filepath"
+	"errors"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kyleking/gh-lazydispatch/internal/chain"
@@ -13,6 +12,7 @@ import (
 	"github.com/kyleking/gh-lazydispatch/internal/git"
 	"github.com/kyleking/gh-lazydispatch/internal/github"
 	"github.com/kyleking/gh-lazydispatch/internal/logs"
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
 	"github.com/kyleking/gh-lazydispatch/internal/ui/modal"
 	"github.com/kyleking/gh-lazydispatch/internal/ui/panes"
 	"github.com/kyleking/gh-lazydispatch/internal/watcher"
@@ -44,6 +44,13 @@ type Model struct {
 	history   *frecency.Store
 	repo      string
 
+	// Repo switcher: the checked out repository, the repositories offered,
+	// and the state of each repository that has been shown
+	homeRepo          string
+	repos             []string
+	repoSessions      map[string]*repoSession
+	pendingRepoSwitch bool
+
 	selectedWorkflow int
 	branch           string
 	inputs           map[string]string
@@ -53,6 +60,10 @@ type Model struct {
 	modalStack *modal.Stack
 
 	pendingInputName string
+	pendingSource    rule.ValidationRule // command source waiting for confirmation
+	approvedCommands map[string]bool     // command sources confirmed in this session
+
+	runInputs map[int64]map[string]string // inputs of runs dispatched in this session, for log reports
 
 	selectedInput          int
 	viewMode               ViewMode
@@ -67,6 +78,9 @@ type Model struct {
 
 	wfdConfig     *config.WfdConfig
 	chainExecutor *chain.ChainExecutor
+	chainJournal  *chain.Journal
+	logsDir       string
+	exportsDir    string
 
 	pendingChainName      string
 	pendingChain          *config.Chain
@@ -76,6 +90,7 @@ type Model struct {
 	// Metadata for the currently executing chain
 	executingChainName      string
 	executingChainBranch    string
+	executingChainRepo      string
 	executingChainVariables map[string]string
 
 	rightPanel panes.TabbedRightModel
@@ -87,6 +102,7 @@ type Model struct {
 
 // RunUpdateMsg is sent when a watched run is updated.
 type RunUpdateMsg struct {
+	Repo   string // repository whose watcher sent the update
 	Update watcher.RunUpdate
 }
 
@@ -95,41 +111,90 @@ type ChainUpdateMsg struct {
 	Update chain.ChainUpdate
 }
 
-// New creates a new application model.
-func New(workflows []workflow.WorkflowFile, history *frecency.Store, repo string) Model {
-	ctx := context.Background()
-	currentBranch := git.GetCurrentBranch(ctx)
+// Dirs locates the state the application keeps between sessions.
+type Dirs struct {
+	Journal string // persisted chain executions
+	Logs    string // log cache, with the cached step outputs next to it
+	Exports string // files exported from the logs viewer
+}
+
+// DefaultDirs returns the directories under the user's cache directory.
+func DefaultDirs() Dirs {
+	return Dirs{Journal: chain.JournalDir(), Logs: logs.DefaultCacheDir(), Exports: logs.DefaultExportDir()}
+}
+
+// New creates a new application model for the repository checked out in the
+// current directory, keeping its state in dirs.
+func New(workflows []workflow.WorkflowFile, history *frecency.Store, repo string, dirs Dirs) Model {
+	cfg, cfgErr := config.Load(".")
+
+	client, _ := github.NewClient(repo) // nil when repo is not owner/name
+
+	m := newModel(workflows, history, repo, git.GetCurrentBranch(context.Background()), cfg, client, dirs)
+	m.reportConfigError(cfgErr)
+
+	return m
+}
+
+// NewRemote creates an application model for a repository that is not checked
+// out, with the workflows and configuration read by LoadRemoteRepo. The model
+// keeps using the client the repository was read with and its state in dirs.
+func NewRemote(remote *RemoteRepo, history *frecency.Store, dirs Dirs) Model {
+	m := newModel(remote.Workflows, history, remote.Repo, remote.Ref, remote.Config, remote.Client, dirs)
+	m.repoSessions[remote.Repo] = &repoSession{remote: true, loaded: true, defaultBranch: remote.DefaultBranch}
+	m.reportConfigError(remote.ConfigErr)
+
+	return m
+}
 
+func newModel(
+	workflows []workflow.WorkflowFile,
+	history *frecency.Store,
+	repo, branch string,
+	cfg *config.WfdConfig,
+	ghClient *github.Client,
+	dirs Dirs,
+) Model {
 	m := Model{
 		focused:          PaneWorkflows,
 		workflows:        workflows,
 		history:          history,
 		repo:             repo,
-		branch:           currentBranch,
+		homeRepo:         repo,
+		repos:            []string{repo},
+		repoSessions:     make(map[string]*repoSession),
+		approvedCommands: make(map[string]bool),
+		runInputs:        make(map[int64]map[string]string),
+		branch:           branch,
 		inputs:           make(map[string]string),
 		modalStack:       modal.NewStack(),
 		keys:             DefaultKeyMap(),
 		selectedInput:    -1,
 		selectedWorkflow: -1,
 		rightPanel:       panes.NewTabbedRight(),
+		chainJournal:     chain.NewJournal(dirs.Journal),
+		logsDir:          dirs.Logs,
+		exportsDir:       dirs.Exports,
 	}
 
-	if ghClient, err := github.NewClient(repo); err == nil {
+	if ghClient != nil {
 		m.ghClient = ghClient
 		m.watcher = watcher.NewWatcher(ghClient)
 
 		// Initialize log manager
-		cacheDir, _ := os.UserCacheDir()
-		logCacheDir := filepath.Join(cacheDir, "lazydispatch", "logs")
-		m.logManager = logs.NewManager(ghClient, logCacheDir)
+		m.logManager = logs.NewManager(ghClient, dirs.Logs)
 		m.logManager.LoadCache()
 	}
 
-	if cfg, err := config.Load("."); err == nil && cfg != nil {
+	if cfg != nil {
 		m.wfdConfig = cfg
 		m.rightPanel.SetChains(cfg.Chains)
 	}
 
+	m.applyLogsConfig()
+
+	m.offerChainResume()
+
 	if len(workflows) > 0 {
 		m.selectedWorkflow = 0
 		m.initializeInputs(workflows[0])
@@ -145,6 +210,23 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+// Close saves the log caches of every repository opened during the session.
+func (m Model) Close() error {
+	var errs []error
+
+	if m.logManager != nil {
+		errs = append(errs, m.logManager.CloseCache())
+	}
+
+	for _, session := range m.repoSessions {
+		if session.logManager != nil {
+			errs = append(errs, session.logManager.CloseCache())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // Update implements tea.Model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.modalStack.HasActive() {
@@ -172,9 +254,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case modal.InputResultMsg:
 		return m.handleInputResult(msg)
 
-	case modal.ConfirmResultMsg:
-		return m.handleConfirmResult(msg)
-
 	case modal.FilterResultMsg:
 		return m.handleFilterResult(msg)
 
@@ -184,6 +263,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case modal.RunConfirmResultMsg:
 		return m.handleRunConfirmResult(msg)
 
+	case executionDoneMsg:
+		return m.handleExecutionDone(msg)
+
+	case repoWorkflowsMsg:
+		return m.handleRepoWorkflows(msg)
+
+	case optionsMsg:
+		return m.handleOptions(msg)
+
+	case modal.ConfirmResultMsg:
+		return m.handleSourceCommandConfirm(msg)
+
+	case branchesMsg:
+		return m.handleRemoteBranches(msg)
+
+	case latestTagMsg:
+		return m.handleLatestTag(msg)
+
 	case modal.RemapResultMsg:
 		return m.handleRemapResult(msg)
 
@@ -201,7 +298,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return m, nil
 
+	case modal.RunActionRequestMsg:
+		m.modalStack.Push(modal.NewRunActionModal(msg.Action, msg.RunID, msg.Workflow))
+		return m, nil
+
+	case modal.RunActionResultMsg:
+		return m.handleRunActionResult(msg)
+
+	case runActionDoneMsg:
+		return m.handleRunActionDone(msg)
+
 	case RunUpdateMsg:
+		if msg.Repo != m.repo {
+			// A run of a repository in the background: keep draining its watcher
+			if session, ok := m.repoSessions[msg.Repo]; ok {
+				return m, subscribeWatcher(msg.Repo, session.watcher)
+			}
+
+			return m, nil
+		}
+
 		if m.watcher != nil {
 			m.rightPanel.SetRuns(m.watcher.GetRuns())
 		}
@@ -220,6 +336,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case modal.ChainConfirmResultMsg:
 		return m.handleChainConfirmResult(msg)
 
+	case modal.ChainResumeResultMsg:
+		return m.handleChainResumeResult(msg)
+
 	case modal.ChainStatusStopMsg:
 		return m.handleChainStatusStop()
 
@@ -311,6 +430,26 @@ func (m Model) updateModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	switch msg := msg.(type) {
+	case ChainUpdateMsg:
+		// Keep following the chain while a modal is open, e.g. its own status modal
+		if status, ok := m.modalStack.Current().(*modal.ChainStatusModal); ok {
+			status.UpdateState(msg.Update.State)
+		}
+
+		return m.handleChainUpdate(msg)
+
+	case repoWorkflowsMsg:
+		return m.handleRepoWorkflows(msg)
+
+	case ChainTriageMsg:
+		if status, ok := m.modalStack.Current().(*modal.ChainStatusModal); ok && status.ChainName() == msg.ChainName {
+			status.SetTriage(msg.Failures)
+		}
+
+		return m, nil
+	}
+
 	cmd := m.modalStack.Update(msg)
 
 	// If a streaming modal was closed, stop the stream
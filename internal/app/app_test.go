@@ -1,11 +1,24 @@
 package app
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
 	"github.com/kyleking/gh-lazydispatch/internal/frecency"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
 	"github.com/kyleking/gh-lazydispatch/internal/ui/modal"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
 	"github.com/kyleking/gh-lazydispatch/internal/workflow"
 )
 
@@ -36,6 +49,15 @@ func testWorkflows() []workflow.WorkflowFile {
 	}
 }
 
+// testDirs keeps the chain journals and log cache of a test in temporary directories.
+func testDirs(t *testing.T) Dirs {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return Dirs{Journal: filepath.Join(dir, "chains"), Logs: filepath.Join(dir, "logs"), Exports: filepath.Join(dir, "exports")}
+}
+
 func testHistory() *frecency.Store {
 	store := frecency.NewStore()
 	store.Record("owner/repo", "deploy.yml", "main", map[string]string{"environment": "prod"})
@@ -49,7 +71,7 @@ func TestNew(t *testing.T) {
 	workflows := testWorkflows()
 	history := testHistory()
 
-	m := New(workflows, history, "owner/repo")
+	m := New(workflows, history, "owner/repo", testDirs(t))
 
 	if m.focused != PaneWorkflows {
 		t.Errorf("expected initial focus on PaneWorkflows, got %d", m.focused)
@@ -65,7 +87,7 @@ func TestNew(t *testing.T) {
 }
 
 func TestUpdate_Tab(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	msg := tea.KeyMsg{Type: tea.KeyTab}
 	result, _ := m.Update(msg)
@@ -91,7 +113,7 @@ func TestUpdate_Tab(t *testing.T) {
 }
 
 func TestUpdate_ShiftTab(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	msg := tea.KeyMsg{Type: tea.KeyShiftTab}
 	result, _ := m.Update(msg)
@@ -103,7 +125,7 @@ func TestUpdate_ShiftTab(t *testing.T) {
 }
 
 func TestUpdate_UpDown_Workflows(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	down := tea.KeyMsg{Type: tea.KeyDown}
 	result, _ := m.Update(down)
@@ -123,7 +145,7 @@ func TestUpdate_UpDown_Workflows(t *testing.T) {
 }
 
 func TestUpdate_Watch(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}}
 	result, _ := m.Update(msg)
@@ -142,7 +164,7 @@ func TestUpdate_Watch(t *testing.T) {
 }
 
 func TestUpdate_WindowSize(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	msg := tea.WindowSizeMsg{Width: 120, Height: 40}
 	result, _ := m.Update(msg)
@@ -158,7 +180,7 @@ func TestUpdate_WindowSize(t *testing.T) {
 }
 
 func TestView_NotEmpty(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.width = 120
 	m.height = 40
 
@@ -173,7 +195,7 @@ func TestView_NotEmpty(t *testing.T) {
 }
 
 func TestSelectedWorkflow(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	wf := m.SelectedWorkflow()
 	if wf == nil {
@@ -186,7 +208,7 @@ func TestSelectedWorkflow(t *testing.T) {
 }
 
 func TestUpdate_UpDown_History(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.focused = PaneHistory
 
 	entries := m.currentHistoryEntries()
@@ -214,7 +236,7 @@ func TestUpdate_UpDown_History(t *testing.T) {
 }
 
 func TestUpdate_UpDown_Config(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.focused = PaneConfig
 
 	down := tea.KeyMsg{Type: tea.KeyDown}
@@ -239,7 +261,7 @@ func TestUpdate_UpDown_Config(t *testing.T) {
 }
 
 func TestUpdate_Space(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.focused = PaneWorkflows
 
 	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}}
@@ -252,7 +274,7 @@ func TestUpdate_Space(t *testing.T) {
 }
 
 func TestHandleSelectResult(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.pendingInputName = "environment"
 
 	result, _ := m.handleSelectResult(modal.SelectResultMsg{Value: "production"})
@@ -268,7 +290,7 @@ func TestHandleSelectResult(t *testing.T) {
 }
 
 func TestHandleBranchResult(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	result, _ := m.handleBranchResult(modal.BranchResultMsg{Value: "feature/test"})
 	m = result.(Model)
@@ -279,7 +301,7 @@ func TestHandleBranchResult(t *testing.T) {
 }
 
 func TestHandleInputResult(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.pendingInputName = "environment"
 
 	result, _ := m.handleInputResult(modal.InputResultMsg{Value: "staging"})
@@ -294,37 +316,234 @@ func TestHandleInputResult(t *testing.T) {
 	}
 }
 
-func TestHandleConfirmResult(t *testing.T) {
-	tests := []struct {
-		name  string
-		value bool
-		want  string
-	}{
-		{"true value", true, "true"},
-		{"false value", false, "false"},
+func typedInputWorkflows() []workflow.WorkflowFile {
+	return []workflow.WorkflowFile{{
+		Name:     "Deploy",
+		Filename: "deploy.yml",
+		On: workflow.OnTrigger{
+			WorkflowDispatch: &workflow.WorkflowDispatch{
+				Inputs: map[string]workflow.WorkflowInput{
+					"debug":       {Type: "boolean", Default: "false"},
+					"replicas":    {Type: "number", Default: "3"},
+					"environment": {Type: "environment", Default: "staging"},
+				},
+			},
+		},
+	}}
+}
+
+func TestOpenInputModal_BooleanToggles(t *testing.T) {
+	m := New(typedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+
+	for _, want := range []string{"true", "false", "true"} {
+		result, _ := m.openInputModalForName("debug")
+		m = result.(Model)
+
+		if m.inputs["debug"] != want {
+			t.Errorf("debug: got %q, want %q", m.inputs["debug"], want)
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			m := New(testWorkflows(), testHistory(), "owner/repo")
-			m.pendingInputName = "debug"
+	if m.modalStack.HasActive() {
+		t.Error("toggling a boolean should not open a modal")
+	}
+}
 
-			result, _ := m.handleConfirmResult(modal.ConfirmResultMsg{Value: tt.value})
-			m = result.(Model)
+func TestOpenInputModal_Number(t *testing.T) {
+	m := New(typedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
-			if m.inputs["debug"] != tt.want {
-				t.Errorf("expected debug=%s, got %q", tt.want, m.inputs["debug"])
-			}
+	result, _ := m.openInputModalForName("replicas")
+	m = result.(Model)
 
-			if m.pendingInputName != "" {
-				t.Error("expected pendingInputName to be cleared")
-			}
-		})
+	if _, ok := m.modalStack.Current().(*modal.InputModal); !ok {
+		t.Fatalf("expected a number input modal, got %T", m.modalStack.Current())
+	}
+
+	m.modalStack.Current().Update(tea.KeyMsg{Type: tea.KeyUp})
+
+	if view := m.modalStack.Current().View(); !strings.Contains(view, "4") {
+		t.Errorf("expected up to step from the default to 4, got:\n%s", view)
+	}
+}
+
+func TestOpenInputModal_EnvironmentPicker(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/environments?per_page=100"},
+		`{"total_count":2,"environments":[{"name":"staging"},{"name":"production"}]}`, "", nil)
+
+	m := New(typedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.ghClient, _ = github.NewClientWithExecutor("owner/repo", mockExec)
+
+	result, cmd := m.openInputModalForName("environment")
+	m = result.(Model)
+
+	if cmd == nil {
+		t.Fatal("expected the environments to be listed")
+	}
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if _, ok := m.modalStack.Current().(*modal.PickerModal); !ok {
+		t.Fatalf("expected an environment picker, got %T", m.modalStack.Current())
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = result.(Model)
+
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if m.inputs["environment"] != "production" {
+		t.Errorf("environment: got %q, want production", m.inputs["environment"])
+	}
+}
+
+func TestOpenInputModal_EnvironmentFallsBackToText(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/environments?per_page=100"},
+		"", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+
+	m := New(typedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.ghClient, _ = github.NewClientWithExecutor("owner/repo", mockExec)
+
+	result, cmd := m.openInputModalForName("environment")
+	m = result.(Model)
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if _, ok := m.modalStack.Current().(*modal.InputModal); !ok {
+		t.Fatalf("expected a text input when environments cannot be listed, got %T", m.modalStack.Current())
+	}
+}
+
+func sourcedInputWorkflows() []workflow.WorkflowFile {
+	return []workflow.WorkflowFile{{
+		Name:     "Preview",
+		Filename: "preview.yml",
+		On: workflow.OnTrigger{
+			WorkflowDispatch: &workflow.WorkflowDispatch{
+				Inputs: map[string]workflow.WorkflowInput{
+					"pr": {ValidationRules: []rule.ValidationRule{{Type: rule.RuleSource, Source: rule.SourcePRs}}},
+					"stack": {ValidationRules: []rule.ValidationRule{
+						{Type: rule.RuleSource, Source: rule.SourceCommand, Pattern: "printf 'api\\nweb\\n'"},
+					}},
+				},
+			},
+		},
+	}}
+}
+
+func TestOpenInputModal_PullRequestSource(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/pulls?state=open&per_page=100"},
+		`[{"number":42,"title":"Fix login"},{"number":41,"title":"Add search"}]`, "", nil)
+
+	m := New(sourcedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.ghClient, _ = github.NewClientWithExecutor("owner/repo", mockExec)
+
+	result, cmd := m.openInputModalForName("pr")
+	m = result.(Model)
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if _, ok := m.modalStack.Current().(*modal.PickerModal); !ok {
+		t.Fatalf("expected a pull request picker, got %T", m.modalStack.Current())
+	}
+
+	for _, r := range "search" {
+		result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(Model)
+	}
+
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if m.inputs["pr"] != "41" {
+		t.Errorf("pr: got %q, want 41", m.inputs["pr"])
+	}
+}
+
+func TestOpenInputModal_CommandSource(t *testing.T) {
+	m := New(sourcedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+
+	// The command runs only once it is confirmed
+	result, cmd := m.openInputModalForName("stack")
+	m = result.(Model)
+
+	if _, ok := m.modalStack.Current().(*modal.ConfirmModal); !ok || cmd != nil {
+		t.Fatalf("expected the command to be confirmed first, got %T", m.modalStack.Current())
+	}
+
+	m.modalStack.Pop()
+
+	result, cmd = m.Update(modal.ConfirmResultMsg{Value: true})
+	m = result.(Model)
+
+	msg, ok := cmd().(optionsMsg)
+	if !ok || msg.err != nil || len(msg.options) != 2 || msg.options[1].Value != "web" {
+		t.Fatalf("expected the command's output lines, got %+v", msg)
+	}
+
+	// A confirmed command runs again without asking
+	result, cmd = m.openInputModalForName("stack")
+	m = result.(Model)
+
+	if cmd == nil || m.modalStack.HasActive() {
+		t.Fatal("expected a confirmed command to run without asking again")
+	}
+
+	// Workflows of a repository that is not checked out do not run commands
+	m.repoSessions[m.repo] = &repoSession{remote: true}
+
+	result, cmd = m.openInputModalForName("stack")
+	m = result.(Model)
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if _, ok := m.modalStack.Current().(*modal.InputModal); !ok {
+		t.Fatalf("expected a text input for a remote command source, got %T", m.modalStack.Current())
+	}
+}
+
+func TestOpenInputModal_CommandSourceDeclined(t *testing.T) {
+	m := New(sourcedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+
+	result, _ := m.openInputModalForName("stack")
+	m = result.(Model)
+	m.modalStack.Pop()
+
+	result, cmd := m.Update(modal.ConfirmResultMsg{Value: false})
+	m = result.(Model)
+
+	if cmd != nil {
+		t.Fatal("declined command was run")
+	}
+
+	if _, ok := m.modalStack.Current().(*modal.InputModal); !ok {
+		t.Fatalf("expected a text input after declining, got %T", m.modalStack.Current())
+	}
+
+	if view := m.modalStack.Current().View(); !strings.Contains(view, "source command declined") {
+		t.Errorf("expected the reason the options are missing in %q", view)
+	}
+
+	if len(m.approvedCommands) != 0 {
+		t.Error("declined command was approved")
 	}
 }
 
 func TestHandleFilterResult(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	result, _ := m.handleFilterResult(modal.FilterResultMsg{Value: "env", Cancelled: false})
 	m = result.(Model)
@@ -339,7 +558,7 @@ func TestHandleFilterResult(t *testing.T) {
 }
 
 func TestHandleFilterResult_Cancelled(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.filterText = "existing"
 
 	result, _ := m.handleFilterResult(modal.FilterResultMsg{Value: "new", Cancelled: true})
@@ -351,7 +570,7 @@ func TestHandleFilterResult_Cancelled(t *testing.T) {
 }
 
 func TestHandleResetResult(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.inputs["environment"] = "custom"
 
 	result, _ := m.handleResetResult(modal.ResetResultMsg{Confirmed: true})
@@ -363,7 +582,7 @@ func TestHandleResetResult(t *testing.T) {
 }
 
 func TestHandleResetResult_Cancelled(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 	m.inputs["environment"] = "custom"
 
 	result, _ := m.handleResetResult(modal.ResetResultMsg{Confirmed: false})
@@ -411,7 +630,7 @@ func TestBuildCLIString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := New(testWorkflows(), testHistory(), "owner/repo")
+			m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 			m.selectedWorkflow = tt.workflow
 			m.branch = tt.branch
 			m.inputs = tt.inputs
@@ -467,7 +686,7 @@ func TestHandleWorkflowKey(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := New(testWorkflows(), testHistory(), "owner/repo")
+			m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 			m.focused = PaneWorkflows
 
 			result, _ := m.handleWorkflowKey(tt.keyNum)
@@ -491,7 +710,7 @@ func TestHandleWorkflowKey(t *testing.T) {
 }
 
 func TestCurrentHistoryEntries(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	entries := m.currentHistoryEntries()
 	if len(entries) == 0 {
@@ -507,7 +726,7 @@ func TestCurrentHistoryEntries(t *testing.T) {
 }
 
 func TestGetSelectedInputName(t *testing.T) {
-	m := New(testWorkflows(), testHistory(), "owner/repo")
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
 
 	m.selectedInput = -1
 	if name := m.getSelectedInputName(); name != "" {
@@ -536,3 +755,625 @@ func contains(s, substr string) bool {
 
 	return false
 }
+
+func TestHandleRunActionResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		action modal.RunAction
+		args   []string
+		err    error
+	}{
+		{"cancel", modal.RunActionCancel, []string{"run", "cancel", "42", "--repo", "owner/repo"}, nil},
+		{"rerun", modal.RunActionRerun, []string{"run", "rerun", "42", "--repo", "owner/repo"}, nil},
+		{"rerun failed", modal.RunActionRerunFailed, []string{"run", "rerun", "42", "--failed", "--repo", "owner/repo"}, nil},
+		{"gh error", modal.RunActionRerun, []string{"run", "rerun", "42", "--repo", "owner/repo"}, errors.New("exit status 1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor()
+			mockExec.AddGHAPIRun("owner", "repo", 42, github.StatusCompleted, github.ConclusionFailure)
+			mockExec.AddCommand("gh", tt.args, "", "", tt.err)
+
+			client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+			m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
+			m.ghClient = client
+			m.watcher = watcher.NewWatcher(client)
+
+			defer m.watcher.Stop()
+
+			result, cmd := m.handleRunActionResult(modal.RunActionResultMsg{
+				Action: tt.action, RunID: 42, Workflow: "CI", Confirmed: true,
+			})
+			if cmd == nil {
+				t.Fatal("expected a command")
+			}
+
+			result, _ = result.(Model).Update(cmd())
+			m = result.(Model)
+
+			if tt.err != nil {
+				if !m.modalStack.HasActive() {
+					t.Error("expected an error modal")
+				}
+
+				return
+			}
+
+			run, ok := m.watcher.GetRun(42)
+			if !ok {
+				t.Fatal("expected run 42 to be watched")
+			}
+
+			// The mocked run still reports the failed attempt, which a re-run must not show
+			if tt.action != modal.RunActionCancel && !run.IsActive() {
+				t.Errorf("re-run shows stale attempt: status %q", run.Status)
+			}
+		})
+	}
+}
+
+func TestHandleExecutionDone(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		title string
+	}{
+		{"dispatch failed", errors.New("HTTP 422"), "Dispatch Failed"},
+		{"run not identified", fmt.Errorf("%w: no matching run", runner.ErrRunNotIdentified), "Dispatched, Run Not Identified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
+
+			result, _ := m.handleExecutionDone(executionDoneMsg{err: tt.err, workflow: "deploy.yml", watch: true})
+			m = result.(Model)
+
+			if _, ok := m.modalStack.Current().(*modal.ErrorModal); !ok {
+				t.Fatalf("expected an error modal, got %T", m.modalStack.Current())
+			}
+
+			if view := m.modalStack.Current().View(); !strings.Contains(view, tt.title) {
+				t.Errorf("expected title %q in %q", tt.title, view)
+			}
+		})
+	}
+}
+
+func TestFetchLogs_DispatchedRunInputs(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddGHAPIRun("owner", "repo", 42, github.StatusCompleted, github.ConclusionFailure)
+	mockExec.AddGHAPIJobs("owner", "repo", 42, `{"jobs": []}`)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.logManager = logs.NewManager(client, t.TempDir())
+
+	result, _ := m.handleExecutionDone(executionDoneMsg{runID: 42, workflow: "deploy.yml", inputs: map[string]string{"environment": "staging"}})
+	m = result.(Model)
+
+	msg, ok := m.fetchLogs(FetchLogsMsg{RunID: 42, Workflow: "deploy.yml"})().(LogsFetchedMsg)
+	if !ok || msg.Error != nil {
+		t.Fatalf("fetchLogs: got %+v", msg)
+	}
+
+	if len(msg.Logs.Runs) != 1 || msg.Logs.Runs[0].Inputs["environment"] != "staging" {
+		t.Errorf("Runs: got %+v, want the dispatched inputs", msg.Logs.Runs)
+	}
+}
+
+func TestUpdateModal_ChainMessages(t *testing.T) {
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.modalStack.Clear()
+
+	status := modal.NewChainStatusModal(chain.ChainState{ChainName: "release", Status: chain.ChainRunning})
+	m.modalStack.Push(status)
+
+	// Chain progress reaches the status modal instead of being swallowed by it
+	failed := chain.ChainState{ChainName: "release", Status: chain.ChainFailed, Error: errors.New("step 1 failed")}
+	result, _ := m.Update(ChainUpdateMsg{Update: chain.ChainUpdate{State: failed}})
+	m = result.(Model)
+
+	if got := status.GetDetailedError(); got != "step 1 failed" {
+		t.Errorf("status modal not updated: %q", got)
+	}
+
+	failures := []*logs.Failure{{StepName: "Test", Line: logs.LogEntry{Content: "boom"}, ExitCode: -1, Occurrences: 1}}
+
+	m.Update(ChainTriageMsg{ChainName: "other", Failures: failures})
+
+	if strings.Contains(status.GetDetailedError(), "boom") {
+		t.Error("triage of another chain should be ignored")
+	}
+
+	m.Update(ChainTriageMsg{ChainName: "release", Failures: failures})
+
+	if !strings.Contains(status.GetDetailedError(), "> boom") {
+		t.Errorf("triage not attached:\n%s", status.GetDetailedError())
+	}
+}
+
+func TestSwitchRepo(t *testing.T) {
+	deployYAML := "name: Release\non:\n  workflow_dispatch:\n    inputs:\n      version:\n        type: string\n"
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"trunk"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=trunk"},
+		`[{"name":"release.yml","type":"file"}]`, "", nil)
+	addRepoFile(mockExec, "acme/api", ".github/workflows/release.yml", "trunk", deployYAML)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/lazydispatch.yml?ref=trunk"},
+		"", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+
+	defer func(orig func(string) (*github.Client, error)) { newGitHubClient = orig }(newGitHubClient)
+
+	newGitHubClient = func(repo string) (*github.Client, error) {
+		return github.NewClientWithExecutor(repo, mockExec)
+	}
+
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.modalStack.Clear()
+	m.branch = "feature"
+
+	if _, cmd := m.openRepoModal(); cmd != nil || m.modalStack.HasActive() {
+		t.Fatal("repo switcher should be unavailable without configured repos")
+	}
+
+	m.SetRepos([]string{"acme/api", "owner/repo"})
+
+	if strings.Join(m.repos, ",") != "owner/repo,acme/api" {
+		t.Fatalf("repos = %v, want the checked out repo first", m.repos)
+	}
+
+	result, _ := m.openRepoModal()
+	m = result.(Model)
+	m.modalStack.Clear()
+
+	result, cmd := m.Update(modal.SelectResultMsg{Value: "acme/api"})
+	m = result.(Model)
+
+	if m.repo != "acme/api" || len(m.workflows) != 0 || cmd == nil {
+		t.Fatalf("expected to switch to acme/api and load its workflows, got repo %q with %d workflows", m.repo, len(m.workflows))
+	}
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if len(m.workflows) != 1 || m.workflows[0].Filename != "release.yml" {
+		t.Fatalf("workflows = %+v, want release.yml", m.workflows)
+	}
+
+	if m.branch != "trunk" {
+		t.Errorf("branch = %q, want the default branch trunk", m.branch)
+	}
+
+	if _, ok := m.inputs["version"]; !ok {
+		t.Errorf("inputs not initialized from the remote workflow: %v", m.inputs)
+	}
+
+	if got := m.buildCLIString(); !strings.Contains(got, "--repo acme/api --ref trunk") {
+		t.Errorf("command = %q, want --repo acme/api", got)
+	}
+
+	// Switching back restores the checked out repository as it was left
+	result, cmd = m.switchRepo("owner/repo")
+	m = result.(Model)
+
+	if cmd != nil {
+		t.Error("the checked out repository should not be fetched")
+	}
+
+	if len(m.workflows) != 2 || m.branch != "feature" || m.ghClient == nil || m.ghClient.Repo() != "repo" {
+		t.Errorf("home repo not restored: %d workflows, branch %q", len(m.workflows), m.branch)
+	}
+
+	if got := m.buildCLIString(); strings.Contains(got, "--repo") {
+		t.Errorf("command = %q, should not name the checked out repo", got)
+	}
+
+	// Updates of the repository in the background keep its watcher subscribed
+	if _, cmd := m.Update(RunUpdateMsg{Repo: "acme/api"}); cmd == nil {
+		t.Error("expected the background watcher to be resubscribed")
+	}
+
+	result, cmd = m.switchRepo("acme/api")
+	m = result.(Model)
+
+	if cmd != nil || len(m.workflows) != 1 || m.branch != "trunk" {
+		t.Errorf("acme/api session not kept: cmd %v, %d workflows, branch %q", cmd != nil, len(m.workflows), m.branch)
+	}
+}
+
+func TestApplyLogsConfig(t *testing.T) {
+	t.Cleanup(func() { logs.SetLevelPatterns(logs.LevelPatterns{}) })
+
+	level := func() logs.LogLevel { return logs.ParseLogOutput("FAIL: TestLogin", "test")[0].Level }
+
+	m := New(testWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	m.modalStack.Clear()
+
+	m.wfdConfig = &config.WfdConfig{Logs: config.LogsConfig{Levels: config.LogLevelPatterns{Error: []string{"^FAIL:"}}}}
+	m.applyLogsConfig()
+
+	if got := level(); got != logs.LogLevelError {
+		t.Errorf("level = %s, want the repository's error pattern applied", got)
+	}
+
+	m.wfdConfig = &config.WfdConfig{Logs: config.LogsConfig{Levels: config.LogLevelPatterns{Warning: []string{"("}}}}
+	m.applyLogsConfig()
+
+	if _, ok := m.modalStack.Current().(*modal.ErrorModal); !ok {
+		t.Errorf("expected an error modal for an invalid pattern, got %T", m.modalStack.Current())
+	}
+
+	m.modalStack.Clear()
+
+	// A repository without lazydispatch.yml drops the patterns of the previous one
+	m.wfdConfig = nil
+	m.applyLogsConfig()
+
+	if got := level(); got == logs.LogLevelError {
+		t.Error("patterns of the previous repository should be reset")
+	}
+
+	if m.modalStack.HasActive() {
+		t.Errorf("unexpected modal %T", m.modalStack.Current())
+	}
+}
+
+// addRepoFile serves a file through the mocked contents API.
+func addRepoFile(mockExec *exec.MockExecutor, repo, path, ref, content string) {
+	entry := `{"name":"` + path[strings.LastIndex(path, "/")+1:] + `","type":"file","encoding":"base64","content":"` +
+		base64.StdEncoding.EncodeToString([]byte(content)) + `"}`
+
+	mockExec.AddCommand("gh", []string{"api", "repos/" + repo + "/contents/" + path + "?ref=" + ref}, entry, "", nil)
+}
+
+func TestLoadRemoteRepo(t *testing.T) {
+	workflowYAML := `name: Deploy
+on:
+  workflow_dispatch:
+    inputs:
+      version:
+        type: string
+        # lazydispatch:validate:regex:^v[0-9]+
+        default: v1
+`
+	configYAML := "version: 1\nchains:\n  release:\n    steps:\n      - workflow: deploy.yml\n"
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"main"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=release%2F2.0"},
+		`[{"name":"deploy.yml","type":"file"},{"name":"README.md","type":"file"}]`, "", nil)
+	addRepoFile(mockExec, "acme/api", ".github/workflows/deploy.yml", "release%2F2.0", workflowYAML)
+	addRepoFile(mockExec, "acme/api", ".github/lazydispatch.yml", "release%2F2.0", configYAML)
+
+	client, _ := github.NewClientWithExecutor("acme/api", mockExec)
+
+	remote, err := LoadRemoteRepo(client, "release/2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if remote.Repo != "acme/api" || remote.Ref != "release/2.0" || remote.DefaultBranch != "main" {
+		t.Errorf("remote = %+v", remote)
+	}
+
+	if len(remote.Workflows) != 1 || remote.Workflows[0].Filename != "deploy.yml" {
+		t.Fatalf("workflows = %+v, want deploy.yml", remote.Workflows)
+	}
+
+	if rules := remote.Workflows[0].GetInputs()["version"].ValidationRules; len(rules) != 1 {
+		t.Errorf("validation comments not parsed: %+v", rules)
+	}
+
+	if !remote.Config.HasChains() {
+		t.Error("lazydispatch.yml not read from the repository")
+	}
+
+	m := NewRemote(remote, testHistory(), testDirs(t))
+	m.modalStack.Clear()
+
+	if m.branch != "release/2.0" || !m.isRemoteRepo() || !m.wfdConfig.HasChains() {
+		t.Errorf("remote model: branch %q, remote %v", m.branch, m.isRemoteRepo())
+	}
+
+	if got := m.buildCLIString(); !strings.Contains(got, "--repo acme/api --ref release/2.0") {
+		t.Errorf("command = %q", got)
+	}
+}
+
+func TestSelectBranch_RemoteRereadsWorkflows(t *testing.T) {
+	mainYAML := "on:\n  workflow_dispatch:\n    inputs:\n      version:\n        type: string\n"
+	nextYAML := "on:\n  workflow_dispatch:\n    inputs:\n      version:\n        type: string\n      dry_run:\n        type: boolean\n"
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"main"}`, "", nil)
+
+	for ref, content := range map[string]string{"main": mainYAML, "next": nextYAML} {
+		mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=" + ref},
+			`[{"name":"deploy.yml","type":"file"}]`, "", nil)
+		addRepoFile(mockExec, "acme/api", ".github/workflows/deploy.yml", ref, content)
+		mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/lazydispatch.yml?ref=" + ref},
+			"", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+	}
+
+	client, _ := github.NewClientWithExecutor("acme/api", mockExec)
+
+	remote, err := LoadRemoteRepo(client, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := NewRemote(remote, testHistory(), testDirs(t))
+	m.modalStack.Clear()
+
+	if _, ok := m.inputs["dry_run"]; ok {
+		t.Fatal("main has no dry_run input")
+	}
+
+	result, cmd := m.Update(modal.BranchResultMsg{Value: "next"})
+	m = result.(Model)
+
+	if cmd == nil {
+		t.Fatal("expected the workflows to be read at the selected branch")
+	}
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	if _, ok := m.inputs["dry_run"]; !ok || m.branch != "next" {
+		t.Errorf("inputs of next not shown: branch %q, inputs %v", m.branch, m.inputs)
+	}
+
+	// A read superseded by another branch selection is dropped
+	stale := repoWorkflowsMsg{repo: "acme/api", remote: remote}
+
+	result, _ = m.Update(stale)
+	m = result.(Model)
+
+	if _, ok := m.inputs["dry_run"]; !ok {
+		t.Error("stale read of main replaced the workflows of next")
+	}
+}
+
+func TestOpenBranchModal_RemoteListsBranchesAsync(t *testing.T) {
+	tests := []struct {
+		name       string
+		branches   string
+		err        error
+		wantModals []string
+	}{
+		{name: "listed", branches: `[{"name":"main"},{"name":"next"}]`, wantModals: []string{"branches"}},
+		{name: "error", err: errors.New("exit status 1"), wantModals: []string{"branches", "error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor()
+			mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"main"}`, "", nil)
+			mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=main"}, `[]`, "", nil)
+			mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/lazydispatch.yml?ref=main"},
+				"", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+			mockExec.AddCommand("gh", []string{"api", "repos/acme/api/branches?per_page=100"}, tt.branches, "", tt.err)
+
+			client, _ := github.NewClientWithExecutor("acme/api", mockExec)
+
+			remote, err := LoadRemoteRepo(client, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			m := NewRemote(remote, testHistory(), testDirs(t))
+			m.modalStack.Clear()
+
+			result, cmd := m.openBranchModal()
+			m = result.(Model)
+
+			if cmd == nil || m.modalStack.HasActive() {
+				t.Fatal("expected the branches to be listed by a command before the modal opens")
+			}
+
+			result, _ = m.Update(cmd())
+			m = result.(Model)
+
+			var got []string
+
+			for m.modalStack.HasActive() {
+				switch m.modalStack.Pop().(type) {
+				case *modal.ErrorModal:
+					got = append([]string{"error"}, got...)
+				case *modal.SimpleBranchModal:
+					got = append([]string{"branches"}, got...)
+				}
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.wantModals, ",") {
+				t.Errorf("modals = %v, want %v", got, tt.wantModals)
+			}
+		})
+	}
+}
+
+func TestLoadRemoteRepo_ConfigReadError(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"main"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=main"}, `[]`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/lazydispatch.yml?ref=main"},
+		"", "gh: Server Error (HTTP 500)", errors.New("exit status 1"))
+
+	client, _ := github.NewClientWithExecutor("acme/api", mockExec)
+
+	if _, err := LoadRemoteRepo(client, ""); err == nil || !strings.Contains(err.Error(), "lazydispatch.yml") {
+		t.Errorf("LoadRemoteRepo: got %v, want an error reading lazydispatch.yml", err)
+	}
+}
+
+func TestNewRemote_ReportsInvalidConfig(t *testing.T) {
+	configYAML := "version: 2\nlogs:\n  levels:\n    error: ['(']\nchains:\n  release:\n    steps:\n      - workflow: deploy.yml\n"
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"main"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=main"}, `[]`, "", nil)
+	addRepoFile(mockExec, "acme/api", ".github/lazydispatch.yml", "main", configYAML)
+
+	client, _ := github.NewClientWithExecutor("acme/api", mockExec)
+
+	remote, err := LoadRemoteRepo(client, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if remote.Config != nil || remote.ConfigErr == nil || !strings.Contains(remote.ConfigErr.Error(), "logs") {
+		t.Fatalf("Config = %v, ConfigErr = %v, want the logs section rejected", remote.Config, remote.ConfigErr)
+	}
+
+	m := NewRemote(remote, testHistory(), testDirs(t))
+
+	errModal, ok := m.modalStack.Current().(*modal.ErrorModal)
+	if !ok {
+		t.Fatalf("expected an error modal, got %T", m.modalStack.Current())
+	}
+
+	if view := errModal.View(); !strings.Contains(view, "lazydispatch.yml") {
+		t.Errorf("expected the config file to be named in %q", view)
+	}
+}
+
+func TestNewRemote_OffersChainResume(t *testing.T) {
+	configYAML := "version: 2\nchains:\n  release:\n    steps:\n      - workflow: deploy.yml\n"
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api"}, `{"full_name":"acme/api","default_branch":"main"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/acme/api/contents/.github/workflows?ref=main"}, `[]`, "", nil)
+	addRepoFile(mockExec, "acme/api", ".github/lazydispatch.yml", "main", configYAML)
+
+	client, _ := github.NewClientWithExecutor("acme/api", mockExec)
+
+	remote, err := LoadRemoteRepo(client, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dirs := testDirs(t)
+
+	if m := NewRemote(remote, testHistory(), dirs); m.modalStack.HasActive() {
+		t.Fatalf("resume offered without a saved execution: %T", m.modalStack.Current())
+	}
+
+	entry := chain.JournalEntry{ID: "release-1", Repo: "acme/api", ChainName: "release", Status: chain.ChainRunning}
+	if err := chain.NewJournal(dirs.Journal).Save(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewRemote(remote, testHistory(), dirs)
+
+	if _, ok := m.modalStack.Current().(*modal.ChainResumeModal); !ok {
+		t.Errorf("expected the saved execution to be offered, got %T", m.modalStack.Current())
+	}
+}
+
+func TestValidateAllInputs_Number(t *testing.T) {
+	m := New(typedInputWorkflows(), testHistory(), "owner/repo", testDirs(t))
+	wf := m.workflows[0]
+
+	m.inputs["replicas"] = "three"
+	if errs := m.validateAllInputs(wf, rule.Context{}); len(errs["replicas"]) != 1 {
+		t.Errorf("expected replicas to be rejected, got %v", errs)
+	}
+
+	m.inputs["replicas"] = "2.5"
+	if errs := m.validateAllInputs(wf, rule.Context{}); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateAllInputs_CrossInput(t *testing.T) {
+	wf := workflow.WorkflowFile{
+		Name:     "Deploy",
+		Filename: "deploy.yml",
+		On: workflow.OnTrigger{
+			WorkflowDispatch: &workflow.WorkflowDispatch{
+				Inputs: map[string]workflow.WorkflowInput{
+					"environment": {Default: "staging"},
+					"approver": {ValidationRules: []rule.ValidationRule{
+						{Type: rule.RuleRequired, When: &rule.Condition{Input: "environment", Value: "prod"}},
+					}},
+					"notify":  {Type: "boolean", ValidationRules: []rule.ValidationRule{{Type: rule.RuleRequires, Pattern: "channel"}}},
+					"channel": {},
+				},
+			},
+		},
+	}
+
+	m := New([]workflow.WorkflowFile{wf}, testHistory(), "owner/repo", testDirs(t))
+	m.inputs["notify"] = "false"
+
+	if errs := m.validateAllInputs(wf, rule.Context{}); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	m.inputs["environment"] = "prod"
+	m.inputs["notify"] = "true"
+
+	errs := m.validateAllInputs(wf, rule.Context{})
+	if len(errs["approver"]) != 1 || len(errs["notify"]) != 1 || len(errs) != 2 {
+		t.Errorf("expected approver and notify errors, got %v", errs)
+	}
+}
+
+func TestExecuteWorkflow_SemverLatest(t *testing.T) {
+	wf := workflow.WorkflowFile{
+		Name:     "Release",
+		Filename: "release.yml",
+		On: workflow.OnTrigger{
+			WorkflowDispatch: &workflow.WorkflowDispatch{
+				Inputs: map[string]workflow.WorkflowInput{
+					"version": {ValidationRules: []rule.ValidationRule{{Type: rule.RuleSemver, Pattern: ">latest"}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		tags    string
+		err     error
+		valid   bool
+	}{
+		{name: "newer than latest", version: "v1.10.1", tags: "[{\"name\":\"v1.9.0\"}]\n[{\"name\":\"v1.10.0\"}]", valid: true},
+		{name: "not newer", version: "v1.9.1", tags: "[{\"name\":\"v1.9.0\"}]\n[{\"name\":\"v1.10.0\"}]"},
+		{name: "tags unavailable", version: "v9.0.0", err: errors.New("exit status 1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor()
+			mockExec.AddCommand("gh", []string{"api", "--paginate", "repos/acme/api/tags?per_page=100"}, tt.tags, "", tt.err)
+
+			m := New([]workflow.WorkflowFile{wf}, testHistory(), "acme/api", testDirs(t))
+			m.ghClient, _ = github.NewClientWithExecutor("acme/api", mockExec)
+			m.repoSessions["acme/api"] = &repoSession{remote: true}
+			m.inputs["version"] = tt.version
+
+			// The tags are listed off the update loop
+			result, cmd := m.executeWorkflow()
+			m = result.(Model)
+
+			if cmd == nil || m.modalStack.HasActive() {
+				t.Fatal("expected the latest tag to be looked up first")
+			}
+
+			result, _ = m.Update(cmd())
+			m = result.(Model)
+
+			_, confirmed := m.modalStack.Current().(*modal.RunConfirmModal)
+			if confirmed != tt.valid {
+				t.Errorf("confirmed = %v, want %v (modal %T)", confirmed, tt.valid, m.modalStack.Current())
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/atotto/clipboard"
@@ -20,6 +22,7 @@ import (
 	"github.com/kyleking/gh-lazydispatch/internal/ui/modal"
 	"github.com/kyleking/gh-lazydispatch/internal/ui/panes"
 	"github.com/kyleking/gh-lazydispatch/internal/validation"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
 	"github.com/kyleking/gh-lazydispatch/internal/workflow"
 )
 
@@ -84,6 +87,9 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Branch):
 		return m.openBranchModal()
 
+	case key.Matches(msg, m.keys.Repo):
+		return m.openRepoModal()
+
 	case key.Matches(msg, m.keys.Filter):
 		if m.focused == PaneConfig {
 			return m.openFilterModal()
@@ -147,6 +153,15 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		return m, nil
 
+	case key.Matches(msg, m.keys.CancelRun):
+		return m.openRunActionModal(modal.RunActionCancel)
+
+	case key.Matches(msg, m.keys.RerunRun):
+		return m.openRunActionModal(modal.RunActionRerun)
+
+	case key.Matches(msg, m.keys.RerunFailed):
+		return m.openRunActionModal(modal.RunActionRerunFailed)
+
 	case key.Matches(msg, m.keys.LiveView):
 		return m.openLiveViewModal()
 
@@ -372,15 +387,21 @@ func (m Model) handleChainConfirmResult(msg modal.ChainConfirmResultMsg) (tea.Mo
 	m.pendingChainCommands = commands
 
 	executor := chain.NewExecutor(m.ghClient, m.watcher, chainName, chainDef)
-	m.chainExecutor = executor
+	executor.SetJournal(m.chainJournal, m.repo)
+	executor.SetRepo(m.dispatchRepo())
+	m.configureChainExecutor(executor)
 
 	if err := executor.Start(variables, branch); err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Chain Failed to Start", err.Error()))
 		return m, nil
 	}
 
+	m.chainExecutor = executor
+
 	// Store executing chain metadata for history update on completion
 	m.executingChainName = chainName
 	m.executingChainBranch = branch
+	m.executingChainRepo = m.repo
 	m.executingChainVariables = variables
 
 	m.history.RecordChain(m.repo, chainName, branch, variables, nil)
@@ -396,32 +417,91 @@ func (m Model) handleChainConfirmResult(msg modal.ChainConfirmResultMsg) (tea.Mo
 	return m, m.chainSubscription()
 }
 
-func (m Model) buildChainCommands(chainDef *config.Chain, variables map[string]string, branch string) []string {
-	commands := make([]string, len(chainDef.Steps))
+// offerChainResume asks to reattach to the most recent chain execution that was
+// interrupted when lazydispatch last exited.
+func (m *Model) offerChainResume() {
+	if m.ghClient == nil || m.wfdConfig == nil || m.chainJournal == nil {
+		return
+	}
 
-	ctx := &chain.InterpolationContext{
-		Var:   variables,
-		Steps: make(map[int]*chain.StepResult),
+	entries, err := m.chainJournal.Active(m.repo)
+	if err != nil {
+		return
 	}
 
-	for i, step := range chainDef.Steps {
-		inputs, _ := chain.InterpolateInputs(step.Inputs, ctx)
+	for _, entry := range entries {
+		if _, ok := m.wfdConfig.GetChain(entry.ChainName); ok {
+			m.modalStack.Push(modal.NewChainResumeModal(entry))
+			return
+		}
+	}
+}
+
+func (m Model) handleChainResumeResult(msg modal.ChainResumeResultMsg) (tea.Model, tea.Cmd) {
+	entry := msg.Entry
 
+	if msg.Discard {
+		if err := m.chainJournal.Remove(entry.ID); err != nil {
+			m.modalStack.Push(modal.NewErrorModal("Discard Failed", err.Error()))
+		}
+
+		return m, nil
+	}
+
+	if !msg.Resume || m.ghClient == nil || m.watcher == nil || m.chainExecutor != nil {
+		return m, nil
+	}
+
+	chainDef, ok := m.wfdConfig.GetChain(entry.ChainName)
+	if !ok {
+		return m, nil
+	}
+
+	executor := chain.NewExecutorFromJournal(m.ghClient, m.watcher, m.chainJournal, entry, chainDef)
+	executor.SetRepo(m.dispatchRepo())
+	m.configureChainExecutor(executor)
+
+	if err := executor.Start(entry.Variables, entry.Branch); err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Chain Failed to Start", err.Error()))
+		return m, nil
+	}
+
+	m.chainExecutor = executor
+
+	m.executingChainName = entry.ChainName
+	m.executingChainBranch = entry.Branch
+	m.executingChainRepo = m.repo
+	m.executingChainVariables = entry.Variables
+
+	commands := m.buildChainCommands(chainDef, entry.Variables, entry.Branch)
+	m.modalStack.Push(modal.NewChainStatusModalWithCommands(executor.State(), commands, entry.Branch))
+
+	return m, m.chainSubscription()
+}
+
+// configureChainExecutor lets chain steps read outputs that earlier runs uploaded as an
+// artifact and pass the configured correlation input to the workflows declaring it.
+func (m Model) configureChainExecutor(executor *chain.ChainExecutor) {
+	executor.SetCorrelationInputs(m.wfdConfig.CorrelationInputs(m.workflows))
+
+	if m.ghClient != nil {
+		executor.SetOutputSource(chain.NewOutputFetcher(m.ghClient, m.logsDir))
+	}
+}
+
+func (m Model) buildChainCommands(chainDef *config.Chain, variables map[string]string, branch string) []string {
+	previews := chain.PreviewInputs(chainDef, variables)
+	commands := make([]string, len(chainDef.Steps))
+
+	for i, step := range chainDef.Steps {
 		cfg := runner.RunConfig{
+			Repo:     m.dispatchRepo(),
 			Workflow: step.Workflow,
 			Branch:   branch,
-			Inputs:   inputs,
+			Inputs:   previews[i].Inputs,
 		}
 		args := runner.BuildArgs(cfg)
 		commands[i] = runner.FormatCommand(args)
-
-		ctx.Steps[i] = &chain.StepResult{
-			Workflow: step.Workflow,
-			Inputs:   inputs,
-		}
-		if i > 0 {
-			ctx.Previous = ctx.Steps[i-1]
-		}
 	}
 
 	return commands
@@ -443,44 +523,58 @@ func (m Model) executeWorkflow() (tea.Model, tea.Cmd) {
 
 	wf := m.workflows[m.selectedWorkflow]
 
-	validationErrors := m.validateAllInputs(wf)
+	if validation.UsesLatestTag(wf) {
+		return m, m.fetchLatestTag(wf.Filename)
+	}
+
+	return m.confirmWorkflow(wf, rule.Context{})
+}
+
+// confirmWorkflow validates the inputs and asks to confirm the dispatch.
+func (m Model) confirmWorkflow(wf workflow.WorkflowFile, ctx rule.Context) (tea.Model, tea.Cmd) {
+	validationErrors := m.validateAllInputs(wf, ctx)
 	if len(validationErrors) > 0 {
 		m.modalStack.Push(modal.NewValidationErrorModal(validationErrors))
 		return m, nil
 	}
 
-	cfg := runner.RunConfig{
-		Workflow: wf.Filename,
-		Branch:   m.branch,
-		Inputs:   m.inputs,
-		Watch:    m.watchRun,
-	}
-
-	m.modalStack.Push(modal.NewRunConfirmModal(cfg))
+	m.modalStack.Push(modal.NewRunConfirmModal(m.runConfig(wf)))
 
 	return m, nil
 }
 
-func (m Model) validateAllInputs(wf workflow.WorkflowFile) map[string][]string {
-	errs := make(map[string][]string)
-
-	inputs := wf.GetInputs()
-	for name, input := range inputs {
-		if rules := input.ValidationRules; len(rules) > 0 {
-			if validationErrs := rule.ValidateValue(m.inputs[name], rules); len(validationErrs) > 0 {
-				errs[name] = validationErrs
-			}
-		}
+// runConfig describes the dispatch of wf with the current branch and inputs.
+func (m Model) runConfig(wf workflow.WorkflowFile) runner.RunConfig {
+	return runner.RunConfig{
+		Repo:             m.dispatchRepo(),
+		Workflow:         wf.Filename,
+		Branch:           m.branch,
+		Inputs:           m.inputs,
+		Watch:            m.watchRun,
+		CorrelationInput: m.wfdConfig.CorrelationInputFor(wf),
+		InputTypes:       wf.InputTypes(),
 	}
+}
 
-	return errs
+// validateAllInputs checks the inputs like the CLI does. ctx holds the latest tag
+// when a rule compares against it.
+func (m Model) validateAllInputs(wf workflow.WorkflowFile, ctx rule.Context) map[string][]string {
+	return validation.ValidateInputs(wf, m.inputs, ctx)
 }
 
 type executionDoneMsg struct {
-	err error
+	err      error
+	runID    int64
+	workflow string
+	inputs   map[string]string
+	watch    bool
 }
 
 func (m Model) openBranchModal() (tea.Model, tea.Cmd) {
+	if m.isRemoteRepo() {
+		return m, fetchRemoteBranches(m.repo, m.ghClient)
+	}
+
 	ctx := context.Background()
 
 	branches, err := git.FetchBranches(ctx)
@@ -488,17 +582,20 @@ func (m Model) openBranchModal() (tea.Model, tea.Cmd) {
 		branches = []string{"main", "master", "develop"}
 	}
 
+	m.pushBranchModal(branches, git.GetDefaultBranch(ctx))
+
+	return m, nil
+}
+
+// pushBranchModal offers branches, along with the selected branch if it is not among them.
+func (m *Model) pushBranchModal(branches []string, defaultBranch string) {
 	if m.branch != "" && !_contains(branches, m.branch) {
 		branches = append(branches, m.branch)
 	}
 
-	defaultBranch := git.GetDefaultBranch(ctx)
-
 	branchModal := modal.NewSimpleBranchModal("Select Branch", branches, m.branch, defaultBranch)
 	branchModal.SetSize(m.width, m.height)
 	m.modalStack.Push(branchModal)
-
-	return m, nil
 }
 
 func (m Model) openLiveViewModal() (tea.Model, tea.Cmd) {
@@ -543,16 +640,24 @@ func (m Model) openInputModalForName(name string) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	m.pendingInputName = name
 	currentVal := m.inputs[name]
 
-	switch input.InputType() {
-	case "boolean":
-		current := currentVal == "true"
-		defaultVal := input.Default == "true"
-		m.modalStack.Push(modal.NewConfirmModal(name, input.Description, current, defaultVal))
-	case "choice":
+	if input.InputType() == "boolean" {
+		// Booleans toggle in place rather than opening a modal
+		m.inputs[name] = strconv.FormatBool(currentVal != "true")
+		return m, nil
+	}
+
+	m.pendingInputName = name
+	m.pendingRepoSwitch = false
+
+	source, hasSource := inputSource(input)
+
+	switch {
+	case input.InputType() == "choice":
 		m.modalStack.Push(modal.NewSelectModal(name, input.Options, currentVal, input.Default))
+	case hasSource:
+		return m.listSourceOptions(name, source)
 	default:
 		m.modalStack.Push(modal.NewInputModal(name, input.Description, input.Default, input.InputType(), currentVal, input.Options, input.ValidationRules))
 	}
@@ -628,6 +733,11 @@ func (m Model) openRemapModal() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleSelectResult(msg modal.SelectResultMsg) (tea.Model, tea.Cmd) {
+	if m.pendingRepoSwitch {
+		m.pendingRepoSwitch = false
+		return m.switchRepo(msg.Value)
+	}
+
 	if m.pendingInputName != "" {
 		m.inputs[m.pendingInputName] = msg.Value
 		m.pendingInputName = ""
@@ -637,8 +747,7 @@ func (m Model) handleSelectResult(msg modal.SelectResultMsg) (tea.Model, tea.Cmd
 }
 
 func (m Model) handleBranchResult(msg modal.BranchResultMsg) (tea.Model, tea.Cmd) {
-	m.branch = msg.Value
-	return m, nil
+	return m.selectBranch(msg.Value)
 }
 
 func (m Model) handleInputResult(msg modal.InputResultMsg) (tea.Model, tea.Cmd) {
@@ -650,20 +759,6 @@ func (m Model) handleInputResult(msg modal.InputResultMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
-func (m Model) handleConfirmResult(msg modal.ConfirmResultMsg) (tea.Model, tea.Cmd) {
-	if m.pendingInputName != "" {
-		if msg.Value {
-			m.inputs[m.pendingInputName] = "true"
-		} else {
-			m.inputs[m.pendingInputName] = "false"
-		}
-
-		m.pendingInputName = ""
-	}
-
-	return m, nil
-}
-
 func (m Model) handleFilterResult(msg modal.FilterResultMsg) (tea.Model, tea.Cmd) {
 	if !msg.Cancelled {
 		m.filterText = msg.Value
@@ -729,25 +824,53 @@ func (m Model) handleChainUpdate(msg ChainUpdateMsg) (tea.Model, tea.Cmd) {
 
 	state := msg.Update.State
 	if state.Status == chain.ChainCompleted || state.Status == chain.ChainFailed {
+		var cmd tea.Cmd
+		if state.Status == chain.ChainFailed {
+			cmd = m.triageChain(state, m.executingChainBranch)
+		}
+
 		// Convert chain step results to frecency step results for history
 		stepResults := convertToFrecencyStepResults(state.StepResults)
 
 		// Update history with step results
-		m.history.RecordChain(m.repo, m.executingChainName, m.executingChainBranch, m.executingChainVariables, stepResults)
+		m.history.RecordChain(m.executingChainRepo, m.executingChainName, m.executingChainBranch, m.executingChainVariables, stepResults)
 		m.history.Save()
 
 		// Clear executing chain metadata
 		m.executingChainName = ""
 		m.executingChainBranch = ""
+		m.executingChainRepo = ""
 		m.executingChainVariables = nil
 		m.chainExecutor = nil
 
-		return m, nil
+		return m, cmd
 	}
 
 	return m, m.chainSubscription()
 }
 
+// triageChain fetches the logs of a failed chain and picks out the error lines
+// most likely to explain the failure.
+func (m Model) triageChain(state chain.ChainState, branch string) tea.Cmd {
+	if m.logManager == nil {
+		return nil
+	}
+
+	manager := m.logManager
+
+	return func() tea.Msg {
+		runLogs, err := manager.GetLogsForChain(state, branch)
+		if err != nil {
+			return nil
+		}
+
+		return ChainTriageMsg{
+			ChainName: state.ChainName,
+			Failures:  logs.Triage(runLogs, logs.DefaultTriageContext),
+		}
+	}
+}
+
 // convertToFrecencyStepResults converts chain.StepResult to frecency.ChainStepResult
 func convertToFrecencyStepResults(stepResults map[int]*chain.StepResult) []frecency.ChainStepResult {
 	if len(stepResults) == 0 {
@@ -785,14 +908,7 @@ func (m Model) handleValidationErrorResult(msg modal.ValidationErrorResultMsg) (
 			return m, nil
 		}
 
-		wf := m.workflows[m.selectedWorkflow]
-		cfg := runner.RunConfig{
-			Workflow: wf.Filename,
-			Branch:   m.branch,
-			Inputs:   m.inputs,
-			Watch:    m.watchRun,
-		}
-		m.modalStack.Push(modal.NewRunConfirmModal(cfg))
+		m.modalStack.Push(modal.NewRunConfirmModal(m.runConfig(m.workflows[m.selectedWorkflow])))
 	}
 
 	return m, nil
@@ -802,11 +918,129 @@ func (m Model) doExecuteWorkflow(cfg runner.RunConfig) (tea.Model, tea.Cmd) {
 	m.history.Record(m.repo, cfg.Workflow, cfg.Branch, cfg.Inputs)
 	m.history.Save()
 
+	// Dispatch natively when possible so gh output never lands on the alt-screen
+	if m.ghClient != nil && m.ghClient.HasRESTClient() && cfg.Branch != "" {
+		client := m.ghClient
+
+		return m, func() tea.Msg {
+			runID, err := runner.ExecuteAndGetRunID(cfg, client)
+			return executionDoneMsg{err: err, runID: runID, workflow: cfg.Workflow, inputs: cfg.Inputs, watch: cfg.Watch}
+		}
+	}
+
 	return m, tea.ExecProcess(exec.Command("gh", runner.BuildArgs(cfg)...), func(err error) tea.Msg {
 		return executionDoneMsg{err: err}
 	})
 }
 
+func (m Model) handleExecutionDone(msg executionDoneMsg) (tea.Model, tea.Cmd) {
+	if errors.Is(msg.err, runner.ErrRunNotIdentified) {
+		m.modalStack.Push(modal.NewErrorModal("Dispatched, Run Not Identified",
+			"The workflow was dispatched, but its run could not be identified to watch it.\n"+msg.err.Error()))
+
+		return m, nil
+	}
+
+	if msg.err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Dispatch Failed", msg.err.Error()))
+		return m, nil
+	}
+
+	if msg.runID != 0 {
+		m.runInputs[msg.runID] = msg.inputs
+	}
+
+	if msg.watch && msg.runID != 0 && m.watcher != nil {
+		m.watcher.Watch(msg.runID, msg.workflow)
+		m.rightPanel.SetRuns(m.watcher.GetRuns())
+
+		return m, m.watcherSubscription()
+	}
+
+	return m, nil
+}
+
+// openRunActionModal confirms an action on the run selected in the Live tab.
+// Only active runs can be cancelled and only completed runs re-run.
+func (m Model) openRunActionModal(action modal.RunAction) (tea.Model, tea.Cmd) {
+	if m.focused != PaneHistory || m.rightPanel.ActiveTab() != panes.TabLive || m.ghClient == nil {
+		return m, nil
+	}
+
+	run, ok := m.rightPanel.SelectedRun()
+	if !ok || run.IsActive() != (action == modal.RunActionCancel) {
+		return m, nil
+	}
+
+	m.modalStack.Push(modal.NewRunActionModal(action, run.RunID, run.Workflow))
+
+	return m, nil
+}
+
+type runActionDoneMsg struct {
+	action   modal.RunAction
+	runID    int64
+	workflow string
+	attempt  int
+	err      error
+}
+
+func (m Model) handleRunActionResult(msg modal.RunActionResultMsg) (tea.Model, tea.Cmd) {
+	if !msg.Confirmed || m.ghClient == nil {
+		return m, nil
+	}
+
+	client := m.ghClient
+
+	return m, func() tea.Msg {
+		done := runActionDoneMsg{action: msg.Action, runID: msg.RunID, workflow: msg.Workflow}
+
+		if msg.Action == modal.RunActionCancel {
+			done.err = client.CancelRun(msg.RunID)
+			return done
+		}
+
+		// A re-run reuses the run ID, so the new attempt is told apart by its number
+		run, err := client.GetWorkflowRun(msg.RunID)
+		if err != nil {
+			done.err = err
+			return done
+		}
+
+		done.attempt = run.RunAttempt + 1
+
+		if msg.Action == modal.RunActionRerunFailed {
+			done.err = client.RerunFailedJobs(msg.RunID)
+		} else {
+			done.err = client.RerunRun(msg.RunID)
+		}
+
+		return done
+	}
+}
+
+func (m Model) handleRunActionDone(msg runActionDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Run Action Failed",
+			fmt.Sprintf("%s #%d: %v", msg.action.Label(), msg.runID, msg.err)))
+		return m, nil
+	}
+
+	if m.watcher == nil {
+		return m, nil
+	}
+
+	if msg.action == modal.RunActionCancel {
+		m.watcher.Watch(msg.runID, msg.workflow)
+	} else {
+		m.watcher.WatchAttempt(msg.runID, msg.workflow, msg.attempt)
+	}
+
+	m.rightPanel.SetRuns(m.watcher.GetRuns())
+
+	return m, m.watcherSubscription()
+}
+
 func (m *Model) applyFilter() {
 	m.filteredInputs = ui.ApplyFuzzyFilter(m.filterText, m.inputOrder)
 	m.selectedInput = -1
@@ -851,6 +1085,10 @@ func (m Model) buildCLIString() string {
 	wf := m.workflows[m.selectedWorkflow]
 
 	args := []string{"workflow", "run", wf.Filename}
+	if repo := m.dispatchRepo(); repo != "" {
+		args = append(args, "--repo", repo)
+	}
+
 	if m.branch != "" {
 		args = append(args, "--ref", m.branch)
 	}
@@ -870,9 +1108,14 @@ func (m Model) watcherSubscription() tea.Cmd {
 		return nil
 	}
 
+	return subscribeWatcher(m.repo, m.watcher)
+}
+
+// subscribeWatcher waits for the next update of a repository's watcher.
+func subscribeWatcher(repo string, w *watcher.RunWatcher) tea.Cmd {
 	return func() tea.Msg {
-		update := <-m.watcher.Updates()
-		return RunUpdateMsg{Update: update}
+		update := <-w.Updates()
+		return RunUpdateMsg{Repo: repo, Update: update}
 	}
 }
 
@@ -888,6 +1131,11 @@ func (m Model) chainSubscription() tea.Cmd {
 }
 
 func (m Model) fetchLogs(msg FetchLogsMsg) tea.Cmd {
+	// Runs dispatched in this session report the inputs they were dispatched with
+	if msg.RunID != 0 && msg.Inputs == nil {
+		msg.Inputs = m.runInputs[msg.RunID]
+	}
+
 	return func() tea.Msg {
 		if m.logManager == nil {
 			return LogsFetchedMsg{Error: errors.New("log manager not initialized")}
@@ -909,7 +1157,7 @@ func (m Model) fetchLogs(msg FetchLogsMsg) tea.Cmd {
 				workflow = runLogs.Steps[0].Workflow
 			}
 		} else if msg.RunID != 0 {
-			runLogs, err = m.logManager.GetLogsForRun(msg.RunID, msg.Workflow)
+			runLogs, err = m.logManager.GetLogsForRun(msg.RunID, msg.Workflow, msg.Inputs)
 			runID = msg.RunID
 			workflow = msg.Workflow
 		} else {
@@ -927,13 +1175,23 @@ func (m Model) fetchLogs(msg FetchLogsMsg) tea.Cmd {
 }
 
 func (m Model) showLogsViewer(runLogs *logs.RunLogs, errorsOnly bool, runID int64, workflow string) Model {
-	var logsModal modal.Context
+	var viewer *modal.LogsViewerModal
 	if errorsOnly {
-		logsModal = modal.NewLogsViewerModalWithError(runLogs, m.width, m.height)
+		viewer = modal.NewLogsViewerModalWithError(runLogs, m.width, m.height)
 	} else {
-		logsModal = modal.NewLogsViewerModal(runLogs, m.width, m.height)
+		viewer = modal.NewLogsViewerModal(runLogs, m.width, m.height)
 	}
 
+	if m.wfdConfig != nil {
+		viewer.SetFilterPresets(logFilterPresets(m.wfdConfig.Logs.Filters))
+	}
+
+	if m.exportsDir != "" {
+		viewer.SetExportDir(m.exportsDir)
+	}
+
+	var logsModal modal.Context = viewer
+
 	// Check if this is an active run and enable streaming
 	if runID != 0 && m.ghClient != nil {
 		run, err := m.ghClient.GetWorkflowRun(runID)
@@ -950,6 +1208,73 @@ func (m Model) showLogsViewer(runLogs *logs.RunLogs, errorsOnly bool, runID int6
 	return m
 }
 
+// logFilterPresets converts the filter presets of lazydispatch.yml for the log viewer.
+func logFilterPresets(filters []config.LogFilterPreset) []logs.FilterPreset {
+	presets := make([]logs.FilterPreset, 0, len(filters))
+
+	for _, f := range filters {
+		level := logs.FilterAll
+
+		switch f.Level {
+		case config.LogLevelErrors:
+			level = logs.FilterErrors
+		case config.LogLevelWarnings:
+			level = logs.FilterWarnings
+		}
+
+		presets = append(presets, logs.FilterPreset{
+			Name: f.Name,
+			Config: logs.FilterConfig{
+				Level:         level,
+				SearchTerm:    f.Search,
+				CaseSensitive: f.CaseSensitive,
+				Regex:         f.Regex,
+				StepIndex:     -1,
+				StepName:      f.Step,
+			},
+		})
+	}
+
+	return presets
+}
+
+// applyLogsConfig applies the level patterns and cache size of the current
+// repository's lazydispatch.yml, resetting them when it has none. The patterns
+// are process-wide, so this runs again on each repository switch.
+func (m *Model) applyLogsConfig() {
+	var cfg config.LogsConfig
+	if m.wfdConfig != nil {
+		cfg = m.wfdConfig.Logs
+	}
+
+	err := logs.SetLevelPatterns(logs.LevelPatterns{
+		Error:   cfg.Levels.Error,
+		Warning: cfg.Levels.Warning,
+		Debug:   cfg.Levels.Debug,
+	})
+
+	if m.logManager != nil {
+		maxMB := cfg.CacheMaxMB
+		if maxMB <= 0 {
+			maxMB = logs.DefaultCacheMaxSizeMB
+		}
+
+		err = errors.Join(err, m.logManager.SetCacheMaxSizeMB(maxMB))
+	}
+
+	if err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Invalid Logs Configuration", err.Error()))
+	}
+}
+
+// reportConfigError explains why lazydispatch.yml was rejected, since its chains
+// and logs settings are missing until it is fixed.
+func (m *Model) reportConfigError(err error) {
+	if err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Invalid "+config.ConfigFilename, err.Error()))
+	}
+}
+
 func (m *Model) startLogStream(runID int64, workflow string) tea.Cmd {
 	// Stop any existing streamer
 	if m.logStreamer != nil {
@@ -968,8 +1293,14 @@ func (m Model) logStreamSubscription() tea.Cmd {
 		return nil
 	}
 
+	updates := m.logStreamer.Updates()
+
 	return func() tea.Msg {
-		update := <-m.logStreamer.Updates()
+		update, ok := <-updates
+		if !ok {
+			return nil
+		}
+
 		return LogStreamUpdateMsg{Update: update}
 	}
 }
@@ -4,27 +4,31 @@ import "github.com/charmbracelet/bubbles/key"
 
 // KeyMap defines all keyboard shortcuts for the application.
 type KeyMap struct {
-	Branch   key.Binding
-	Chain    key.Binding
-	Clear    key.Binding
-	ClearAll key.Binding
-	Copy     key.Binding
-	Down     key.Binding
-	Edit     key.Binding
-	Enter    key.Binding
-	Escape   key.Binding
-	Filter   key.Binding
-	Help     key.Binding
-	LiveView key.Binding
-	Quit     key.Binding
-	Reset    key.Binding
-	ShiftTab key.Binding
-	Space    key.Binding
-	Tab      key.Binding
-	TabNext  key.Binding
-	TabPrev  key.Binding
-	Up       key.Binding
-	Watch    key.Binding
+	Branch      key.Binding
+	CancelRun   key.Binding
+	Chain       key.Binding
+	Clear       key.Binding
+	ClearAll    key.Binding
+	Copy        key.Binding
+	Down        key.Binding
+	Edit        key.Binding
+	Enter       key.Binding
+	Escape      key.Binding
+	Filter      key.Binding
+	Help        key.Binding
+	LiveView    key.Binding
+	Quit        key.Binding
+	RerunFailed key.Binding
+	RerunRun    key.Binding
+	Repo        key.Binding
+	Reset       key.Binding
+	ShiftTab    key.Binding
+	Space       key.Binding
+	Tab         key.Binding
+	TabNext     key.Binding
+	TabPrev     key.Binding
+	Up          key.Binding
+	Watch       key.Binding
 
 	Input0 key.Binding
 	Input1 key.Binding
@@ -64,27 +68,31 @@ func makeNumberedBinding(num int, prefix string) key.Binding {
 // DefaultKeyMap returns the default keyboard shortcuts.
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
-		Branch:   key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "branch")),
-		Chain:    key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "run chain")),
-		Clear:    key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "clear run")),
-		ClearAll: key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "clear all")),
-		Copy:     key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy to clipboard")),
-		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
-		Edit:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
-		Enter:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select/run")),
-		Escape:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
-		Filter:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
-		Help:     key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
-		LiveView: key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "live view")),
-		Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
-		Reset:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reset inputs")),
-		ShiftTab: key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev pane")),
-		Space:    key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
-		Tab:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next pane")),
-		TabNext:  key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "next tab")),
-		TabPrev:  key.NewBinding(key.WithKeys("h", "left"), key.WithHelp("h", "prev tab")),
-		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
-		Watch:    key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "watch")),
+		Branch:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "branch")),
+		CancelRun:   key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "cancel run")),
+		Chain:       key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "run chain")),
+		Clear:       key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "clear run")),
+		ClearAll:    key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "clear all")),
+		Copy:        key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy to clipboard")),
+		Down:        key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		Edit:        key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+		Enter:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select/run")),
+		Escape:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		Filter:      key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		Help:        key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		LiveView:    key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "live view")),
+		Quit:        key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+		RerunFailed: key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "rerun failed jobs")),
+		RerunRun:    key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "rerun all jobs")),
+		Repo:        key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "switch repo")),
+		Reset:       key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reset inputs")),
+		ShiftTab:    key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev pane")),
+		Space:       key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
+		Tab:         key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next pane")),
+		TabNext:     key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "next tab")),
+		TabPrev:     key.NewBinding(key.WithKeys("h", "left"), key.WithHelp("h", "prev tab")),
+		Up:          key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Watch:       key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "watch")),
 
 		Input0: makeNumberedBinding(0, "input"),
 		Input1: makeNumberedBinding(1, "input"),
@@ -136,6 +144,7 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Tab, k.ShiftTab, k.Up, k.Down},
 		{k.TabNext, k.TabPrev, k.Clear, k.ClearAll},
+		{k.CancelRun, k.RerunRun, k.RerunFailed},
 		{k.Enter, k.Edit, k.Escape, k.Branch},
 		{k.Watch, k.Filter, k.Copy, k.Reset},
 		{k.Input1, k.Input2, k.Input3, k.Input0},
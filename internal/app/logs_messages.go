@@ -10,6 +10,7 @@ type FetchLogsMsg struct {
 	ChainState *chain.ChainState
 	RunID      int64
 	Workflow   string
+	Inputs     map[string]string // dispatched inputs of RunID, when known
 	Branch     string
 	ErrorsOnly bool
 }
@@ -23,6 +24,12 @@ type LogsFetchedMsg struct {
 	Error      error
 }
 
+// ChainTriageMsg carries the failures found in the logs of a failed chain.
+type ChainTriageMsg struct {
+	ChainName string
+	Failures  []*logs.Failure
+}
+
 // ShowLogsViewerMsg opens the logs viewer modal.
 type ShowLogsViewerMsg struct {
 	Logs       *logs.RunLogs
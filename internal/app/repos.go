@@ -0,0 +1,378 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
+	"github.com/kyleking/gh-lazydispatch/internal/ui/modal"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+// newGitHubClient creates the client of a repository opened through the repo switcher.
+// Tests replace it to inject a mock executor.
+var newGitHubClient = github.NewClient
+
+// repoSession holds the state of a repository while another one is shown.
+// Each repository keeps its own client and watcher, so runs watched in one
+// repository keep updating while another is shown.
+type repoSession struct {
+	workflows  []workflow.WorkflowFile
+	branch     string
+	client     *github.Client
+	watcher    *watcher.RunWatcher
+	logManager *logs.Manager
+	wfdConfig  *config.WfdConfig
+
+	// Repositories opened through the repo switcher are not checked out:
+	// their workflows and branches come from the API
+	remote        bool
+	loaded        bool // workflows have been discovered
+	defaultBranch string
+}
+
+// RemoteRepo is a repository read through the GitHub contents API instead of a local checkout.
+type RemoteRepo struct {
+	Repo          string // owner/name
+	Ref           string // branch or tag the workflows were read at
+	DefaultBranch string
+	Workflows     []workflow.WorkflowFile
+	Config        *config.WfdConfig // nil when the repository has no valid lazydispatch.yml
+	ConfigErr     error             // why lazydispatch.yml was rejected, when it is invalid
+	Client        *github.Client    // the client the repository was read with
+}
+
+// LoadRemoteRepo reads a repository's dispatchable workflows and lazydispatch.yml
+// at ref. An empty ref reads the default branch. As with a local checkout, a
+// missing or invalid lazydispatch.yml leaves Config nil, with ConfigErr set when
+// it is invalid; failing to read it is an error.
+func LoadRemoteRepo(client *github.Client, ref string) (*RemoteRepo, error) {
+	info, err := client.GetRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	if ref == "" {
+		ref = info.DefaultBranch
+	}
+
+	workflows, err := workflow.DiscoverFrom(client, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := &RemoteRepo{
+		Repo:          client.Owner() + "/" + client.Repo(),
+		Ref:           ref,
+		DefaultBranch: info.DefaultBranch,
+		Workflows:     workflows,
+		Client:        client,
+	}
+
+	data, err := client.ReadFile(config.ConfigFilename, ref)
+
+	switch {
+	case errors.Is(err, github.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", config.ConfigFilename, err)
+	default:
+		remote.Config, remote.ConfigErr = config.Parse(data)
+	}
+
+	return remote, nil
+}
+
+// repoWorkflowsMsg carries the workflows and configuration read for a repository that is not checked out.
+type repoWorkflowsMsg struct {
+	repo   string
+	remote *RemoteRepo
+	err    error
+}
+
+// SetRepos sets the repositories (owner/name) offered by the repo switcher.
+// The repository the session started in is always offered first.
+func (m *Model) SetRepos(repos []string) {
+	m.repos = []string{m.homeRepo}
+
+	for _, repo := range repos {
+		if !slices.Contains(m.repos, repo) {
+			m.repos = append(m.repos, repo)
+		}
+	}
+}
+
+// isMultiRepo reports whether the repo switcher has more than one repository to offer.
+func (m Model) isMultiRepo() bool {
+	return len(m.repos) > 1
+}
+
+func (m Model) openRepoModal() (tea.Model, tea.Cmd) {
+	if !m.isMultiRepo() {
+		return m, nil
+	}
+
+	m.pendingInputName = ""
+	m.pendingRepoSwitch = true
+	m.modalStack.Push(modal.NewSelectModal("Select Repository", m.repos, m.repo, m.homeRepo))
+
+	return m, nil
+}
+
+// switchRepo shows another repository, creating its session on first use.
+func (m Model) switchRepo(repo string) (tea.Model, tea.Cmd) {
+	if repo == m.repo {
+		if m.isRemoteRepo() && !m.repoSessions[repo].loaded {
+			// Loading failed before: try again
+			return m, fetchRepoWorkflows(repo, m.ghClient, m.branch)
+		}
+
+		return m, nil
+	}
+
+	session, ok := m.repoSessions[repo]
+	if !ok {
+		client, err := newGitHubClient(repo)
+		if err != nil {
+			m.modalStack.Push(modal.NewErrorModal("Repository Unavailable", err.Error()))
+			return m, nil
+		}
+
+		session = &repoSession{
+			client:  client,
+			watcher: watcher.NewWatcher(client),
+			remote:  true,
+		}
+
+		if m.logManager != nil {
+			session.logManager = m.logManager.ForClient(client)
+		} else {
+			session.logManager = logs.NewManager(client, m.logsDir)
+			session.logManager.LoadCache()
+		}
+
+		m.repoSessions[repo] = session
+	}
+
+	m.repoSessions[m.repo] = m.currentSession()
+	m.restoreSession(repo, session)
+
+	if !session.loaded {
+		return m, fetchRepoWorkflows(repo, session.client, session.branch)
+	}
+
+	return m, nil
+}
+
+// currentSession captures the state of the repository being shown.
+func (m Model) currentSession() *repoSession {
+	session := &repoSession{
+		workflows:  m.workflows,
+		branch:     m.branch,
+		client:     m.ghClient,
+		watcher:    m.watcher,
+		logManager: m.logManager,
+		wfdConfig:  m.wfdConfig,
+		loaded:     true,
+	}
+
+	if prev, ok := m.repoSessions[m.repo]; ok {
+		session.remote = prev.remote
+		session.loaded = prev.loaded
+		session.defaultBranch = prev.defaultBranch
+	}
+
+	return session
+}
+
+// isRemoteRepo reports whether the repository being shown is not the checked out one.
+func (m Model) isRemoteRepo() bool {
+	session, ok := m.repoSessions[m.repo]
+	return ok && session.remote
+}
+
+// dispatchRepo returns the repository to pass to gh with --repo, empty for the checked out one.
+func (m Model) dispatchRepo() string {
+	if m.isRemoteRepo() {
+		return m.repo
+	}
+
+	return ""
+}
+
+// restoreSession shows a repository's workflows, runs and chains.
+func (m *Model) restoreSession(repo string, session *repoSession) {
+	m.stopLogStream()
+
+	m.repo = repo
+	m.workflows = session.workflows
+	m.branch = session.branch
+	m.ghClient = session.client
+	m.watcher = session.watcher
+	m.logManager = session.logManager
+	m.wfdConfig = session.wfdConfig
+
+	var chains map[string]config.Chain
+	if m.wfdConfig != nil {
+		chains = m.wfdConfig.Chains
+	}
+
+	m.rightPanel.SetChains(chains)
+	m.applyLogsConfig()
+
+	if m.watcher != nil {
+		m.rightPanel.SetRuns(m.watcher.GetRuns())
+	} else {
+		m.rightPanel.SetRuns(nil)
+	}
+
+	m.previewingHistoryEntry = nil
+	m.selectWorkflows()
+}
+
+// selectWorkflows selects the first workflow, or clears the inputs when there is none.
+func (m *Model) selectWorkflows() {
+	if len(m.workflows) > 0 {
+		m.selectedWorkflow = 0
+		m.initializeInputs(m.workflows[0])
+
+		return
+	}
+
+	m.selectedWorkflow = -1
+	m.inputs = make(map[string]string)
+	m.inputOrder = nil
+	m.filteredInputs = nil
+	m.selectedInput = -1
+	m.viewMode = WorkflowListMode
+	m.syncHistoryEntries()
+}
+
+// fetchRepoWorkflows reads a repository's workflows and configuration at ref,
+// or at its default branch when ref is empty.
+func fetchRepoWorkflows(repo string, client *github.Client, ref string) tea.Cmd {
+	return func() tea.Msg {
+		remote, err := LoadRemoteRepo(client, ref)
+		return repoWorkflowsMsg{repo: repo, remote: remote, err: err}
+	}
+}
+
+func (m Model) handleRepoWorkflows(msg repoWorkflowsMsg) (tea.Model, tea.Cmd) {
+	session, ok := m.repoSessions[msg.repo]
+	if !ok {
+		return m, nil
+	}
+
+	if msg.repo != m.repo {
+		// Read after switching away: keep it for when the repository is shown again
+		if msg.err == nil && (session.branch == "" || session.branch == msg.remote.Ref) {
+			session.workflows = msg.remote.Workflows
+			session.wfdConfig = msg.remote.Config
+			session.branch = msg.remote.Ref
+			session.defaultBranch = msg.remote.DefaultBranch
+			session.loaded = true
+		}
+
+		return m, nil
+	}
+
+	if msg.err != nil {
+		m.modalStack.Push(modal.NewErrorModal("Failed to Load Workflows", msg.repo+": "+msg.err.Error()))
+		return m, nil
+	}
+
+	if m.branch != "" && m.branch != msg.remote.Ref {
+		return m, nil // superseded by another branch being selected
+	}
+
+	var selected string
+	if wf := m.SelectedWorkflow(); wf != nil {
+		selected = wf.Filename
+	}
+
+	m.workflows = msg.remote.Workflows
+	m.wfdConfig = msg.remote.Config
+	m.branch = msg.remote.Ref
+
+	session.loaded = true
+	session.defaultBranch = msg.remote.DefaultBranch
+
+	var chains map[string]config.Chain
+	if m.wfdConfig != nil {
+		chains = m.wfdConfig.Chains
+	}
+
+	m.rightPanel.SetChains(chains)
+	m.applyLogsConfig()
+	m.selectWorkflows()
+	m.reportConfigError(msg.remote.ConfigErr)
+
+	// Keep the workflow selected before reading another branch
+	for i, wf := range m.workflows {
+		if wf.Filename == selected {
+			m.selectedWorkflow = i
+			m.initializeInputs(wf)
+		}
+	}
+
+	return m, nil
+}
+
+// selectBranch sets the branch to dispatch on. The workflows of a repository
+// that is not checked out are read again at that branch, so the inputs shown
+// match the branch being targeted.
+func (m Model) selectBranch(branch string) (tea.Model, tea.Cmd) {
+	if !m.isRemoteRepo() || branch == m.branch || m.ghClient == nil {
+		m.branch = branch
+		return m, nil
+	}
+
+	m.branch = branch
+	m.repoSessions[m.repo].loaded = false
+
+	return m, fetchRepoWorkflows(m.repo, m.ghClient, branch)
+}
+
+// branchesMsg carries the branches listed for a repository that is not checked out.
+type branchesMsg struct {
+	repo     string
+	branches []string
+	err      error
+}
+
+// fetchRemoteBranches lists the branches of a repository that is not checked out
+// through the API, without blocking the UI.
+func fetchRemoteBranches(repo string, client *github.Client) tea.Cmd {
+	return func() tea.Msg {
+		branches, err := client.ListBranches()
+		return branchesMsg{repo: repo, branches: branches, err: err}
+	}
+}
+
+// handleRemoteBranches opens the branch modal with the listed branches. When they
+// cannot be listed, the error is shown over a modal offering the default branch.
+func (m Model) handleRemoteBranches(msg branchesMsg) (tea.Model, tea.Cmd) {
+	if msg.repo != m.repo {
+		return m, nil
+	}
+
+	defaultBranch := m.repoSessions[m.repo].defaultBranch
+
+	if msg.err != nil {
+		if defaultBranch != "" {
+			m.pushBranchModal([]string{defaultBranch}, defaultBranch)
+		}
+
+		m.modalStack.Push(modal.NewErrorModal("Failed to List Branches", msg.repo+": "+msg.err.Error()))
+
+		return m, nil
+	}
+
+	m.pushBranchModal(msg.branches, defaultBranch)
+
+	return m, nil
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/git"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
+	"github.com/kyleking/gh-lazydispatch/internal/ui/modal"
+	"github.com/kyleking/gh-lazydispatch/internal/validation"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+// sourceCommandTimeout bounds a lazydispatch:source:command: command.
+const sourceCommandTimeout = 10 * time.Second
+
+// optionsMsg carries the options listed from an input's source.
+type optionsMsg struct {
+	repo    string
+	input   string
+	options []modal.PickerOption
+	err     error
+}
+
+// inputSource returns where an input's options are listed from: its
+// lazydispatch:source: annotation, or the repository's environments for an
+// environment input without one.
+func inputSource(input workflow.WorkflowInput) (rule.ValidationRule, bool) {
+	if source, ok := rule.FindSource(input.ValidationRules); ok {
+		return source, true
+	}
+
+	if input.InputType() == "environment" {
+		return rule.ValidationRule{Type: rule.RuleSource, Source: rule.SourceEnvironments}, true
+	}
+
+	return rule.ValidationRule{}, false
+}
+
+// listSourceOptions lists an input's options. A command source asks for confirmation
+// the first time it runs in a session, since the workflow decides what it runs.
+func (m Model) listSourceOptions(input string, source rule.ValidationRule) (tea.Model, tea.Cmd) {
+	if source.Source == rule.SourceCommand && !m.isRemoteRepo() && !m.approvedCommands[source.Pattern] {
+		m.pendingSource = source
+		m.modalStack.Push(modal.NewConfirmModal("Run Source Command?",
+			fmt.Sprintf("The options of %s are listed by running:\n%s", input, source.Pattern), false, false))
+
+		return m, nil
+	}
+
+	return m, m.fetchOptions(input, source)
+}
+
+// handleSourceCommandConfirm runs a confirmed command source. When it is declined,
+// the input is edited as text instead.
+func (m Model) handleSourceCommandConfirm(msg modal.ConfirmResultMsg) (tea.Model, tea.Cmd) {
+	source := m.pendingSource
+	m.pendingSource = rule.ValidationRule{}
+
+	if source.Source != rule.SourceCommand || m.pendingInputName == "" {
+		return m, nil
+	}
+
+	if !msg.Value {
+		return m.handleOptions(optionsMsg{repo: m.repo, input: m.pendingInputName, err: errors.New("source command declined")})
+	}
+
+	m.approvedCommands[source.Pattern] = true
+
+	return m, m.fetchOptions(m.pendingInputName, source)
+}
+
+// fetchOptions lists an input's options when it is edited, so the picker shows
+// them as they are now. Tags and branches come from git for the checked out
+// repository and from the API otherwise.
+func (m Model) fetchOptions(input string, source rule.ValidationRule) tea.Cmd {
+	repo := m.repo
+	client := m.ghClient
+	remote := m.isRemoteRepo()
+
+	return func() tea.Msg {
+		options, err := listOptions(source, client, remote)
+		return optionsMsg{repo: repo, input: input, options: options, err: err}
+	}
+}
+
+func listOptions(source rule.ValidationRule, client *github.Client, remote bool) ([]modal.PickerOption, error) {
+	ctx := context.Background()
+
+	switch {
+	case source.Source == rule.SourceCommand:
+		// Workflows of a repository that is not checked out are not trusted to run commands
+		if remote {
+			return nil, errors.New("command sources only run for the checked out repository")
+		}
+
+		return runSourceCommand(ctx, source.Pattern)
+	case source.Source == rule.SourceTags && !remote:
+		tags, err := git.FetchTags(ctx)
+		return valueOptions(tags), err
+	case source.Source == rule.SourceBranches && !remote:
+		branches, err := git.FetchBranches(ctx)
+		return valueOptions(branches), err
+	case client == nil:
+		return nil, fmt.Errorf("listing %s requires a GitHub client", source.Source)
+	}
+
+	switch source.Source {
+	case rule.SourceTags:
+		tags, err := client.ListTags()
+		return valueOptions(tags), err
+	case rule.SourceBranches:
+		branches, err := client.ListBranches()
+		return valueOptions(branches), err
+	case rule.SourceEnvironments:
+		environments, err := client.ListEnvironments()
+		return valueOptions(environments), err
+	case rule.SourceReleases:
+		releases, err := client.ListReleases()
+		if err != nil {
+			return nil, err
+		}
+
+		options := make([]modal.PickerOption, 0, len(releases))
+
+		for _, release := range releases {
+			if release.Draft {
+				continue
+			}
+
+			description := release.Name
+			if release.Prerelease {
+				description = strings.TrimSpace(description + " (pre-release)")
+			}
+
+			options = append(options, modal.PickerOption{Value: release.TagName, Description: description})
+		}
+
+		return options, nil
+	case rule.SourcePRs:
+		pulls, err := client.ListOpenPullRequests()
+		if err != nil {
+			return nil, err
+		}
+
+		options := make([]modal.PickerOption, len(pulls))
+		for i, pull := range pulls {
+			options[i] = modal.PickerOption{Value: strconv.Itoa(pull.Number), Description: pull.Title}
+		}
+
+		return options, nil
+	}
+
+	return nil, fmt.Errorf("unknown source %q", source.Source)
+}
+
+// runSourceCommand runs a shell command and offers each line of its output.
+func runSourceCommand(ctx context.Context, command string) ([]modal.PickerOption, error) {
+	ctx, cancel := context.WithTimeout(ctx, sourceCommandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("source command failed: %w", err)
+	}
+
+	var values []string
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if value := strings.TrimSpace(line); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return valueOptions(values), nil
+}
+
+func valueOptions(values []string) []modal.PickerOption {
+	options := make([]modal.PickerOption, len(values))
+	for i, value := range values {
+		options[i] = modal.PickerOption{Value: value}
+	}
+
+	return options
+}
+
+// latestTagMsg carries the latest tag looked up to validate a dispatch of workflow.
+type latestTagMsg struct {
+	repo     string
+	workflow string
+	tag      string
+	err      error
+}
+
+// fetchLatestTag looks up the repository's latest tag for semver:>latest rules, from git
+// for the checked out repository and from the API otherwise.
+func (m Model) fetchLatestTag(workflow string) tea.Cmd {
+	repo := m.repo
+	client := m.ghClient
+	remote := m.isRemoteRepo()
+
+	return func() tea.Msg {
+		var lister validation.TagLister
+
+		if remote {
+			if client == nil {
+				return latestTagMsg{repo: repo, workflow: workflow, err: errors.New("listing tags requires a GitHub client")}
+			}
+
+			lister = client
+		}
+
+		tag, err := validation.LatestTag(context.Background(), lister)
+
+		return latestTagMsg{repo: repo, workflow: workflow, tag: tag, err: err}
+	}
+}
+
+// handleLatestTag validates the dispatch the latest tag was looked up for, unless
+// another repository or workflow has been selected since.
+func (m Model) handleLatestTag(msg latestTagMsg) (tea.Model, tea.Cmd) {
+	if msg.repo != m.repo || m.selectedWorkflow < 0 || m.selectedWorkflow >= len(m.workflows) {
+		return m, nil
+	}
+
+	wf := m.workflows[m.selectedWorkflow]
+	if wf.Filename != msg.workflow {
+		return m, nil
+	}
+
+	return m.confirmWorkflow(wf, rule.Context{LatestTag: msg.tag, LatestTagErr: msg.err})
+}
+
+// handleOptions opens the picker of an input's options. When they cannot be
+// listed, or there are none, the input is edited as text instead, with the
+// reason the options are missing shown below its description.
+func (m Model) handleOptions(msg optionsMsg) (tea.Model, tea.Cmd) {
+	if msg.repo != m.repo || msg.input != m.pendingInputName || m.selectedWorkflow < 0 || m.selectedWorkflow >= len(m.workflows) {
+		return m, nil
+	}
+
+	input, ok := m.workflows[m.selectedWorkflow].GetInputs()[msg.input]
+	if !ok {
+		m.pendingInputName = ""
+		return m, nil
+	}
+
+	currentVal := m.inputs[msg.input]
+
+	if msg.err != nil || len(msg.options) == 0 {
+		description := input.Description
+		if msg.err != nil {
+			description = strings.TrimSpace(description + "\nOptions unavailable: " + msg.err.Error())
+		}
+
+		m.modalStack.Push(modal.NewInputModal(msg.input, description, input.Default, input.InputType(), currentVal, nil, input.ValidationRules))
+
+		return m, nil
+	}
+
+	picker := modal.NewPickerModal(msg.input, msg.options, currentVal, input.Default)
+	picker.SetSize(m.width, m.height)
+	m.modalStack.Push(picker)
+
+	return m, nil
+}
@@ -59,6 +59,10 @@ func (m Model) View() string {
 func (m Model) viewTopStatusBar() string {
 	var parts []string
 
+	if m.isMultiRepo() {
+		parts = append(parts, "Repo: "+m.repo)
+	}
+
 	if m.wfdConfig != nil && len(m.wfdConfig.Chains) > 0 {
 		parts = append(parts, fmt.Sprintf("Chains(%d)", len(m.wfdConfig.Chains)))
 	}
@@ -117,6 +121,10 @@ func (m Model) viewFooterBar() string {
 		hints = append(hints, "[Enter] run", "[1-0] edit", "[/] filter", "[b] branch")
 	}
 
+	if m.isMultiRepo() {
+		hints = append(hints, "[p] repo")
+	}
+
 	hints = append(hints, "[?] help", "[q] quit")
 
 	return ui.HelpStyle.Render(" " + strings.Join(hints, "  "))
@@ -238,6 +246,10 @@ func (m Model) viewWorkflowPane(width, height int) string {
 		content += ui.TableDefaultStyle.Render("  " + allLine)
 	}
 
+	if session, ok := m.repoSessions[m.repo]; ok && session.remote && !session.loaded {
+		content += "\n" + ui.TableDimmedStyle.Render("  Loading workflows...")
+	}
+
 	if len(m.workflows) > 0 {
 		content += "\n"
 	}
@@ -454,6 +466,13 @@ func (m Model) renderTableRows(height int) string {
 		valueDisplay := ui.FormatEmptyValue(val)
 		isSpecialValue := val == ""
 
+		if input.InputType() == "boolean" {
+			valueDisplay = "[ ] " + val
+			if val == "true" {
+				valueDisplay = "[x] " + val
+			}
+		}
+
 		defaultDisplay := ui.FormatEmptyValue(input.Default)
 
 		isSelected := i == m.selectedInput
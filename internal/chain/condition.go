@@ -0,0 +1,430 @@
+package chain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a parsed `if:` expression of a chain step.
+//
+// The language supports:
+//   - references: var.key, previous.conclusion, previous.status, previous.inputs.key,
+//     previous.outputs.key, and the same fields on steps.N (N is 0-indexed)
+//   - string literals in single or double quotes, and true/false
+//   - comparisons with == and !=
+//   - && and || (&& binds tighter), ! for negation, and parentheses
+//
+// A reference used on its own is true unless it is empty or "false".
+// References to steps that have not run resolve to the empty string.
+type Condition struct {
+	source string
+	root   condNode
+	steps  []int // indices of the steps.N references
+}
+
+// ParseCondition parses an `if:` expression.
+func ParseCondition(source string) (*Condition, error) {
+	tokens, err := tokenizeCondition(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &condParser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+
+	return &Condition{source: source, root: root, steps: p.steps}, nil
+}
+
+// String returns the expression as written.
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Eval evaluates the condition against the chain context.
+func (c *Condition) Eval(ctx *InterpolationContext) bool {
+	if ctx == nil {
+		ctx = &InterpolationContext{}
+	}
+
+	return truthy(c.root.eval(ctx))
+}
+
+// EvaluateCondition parses and evaluates an `if:` expression.
+// An empty expression is always true.
+func EvaluateCondition(source string, ctx *InterpolationContext) (bool, error) {
+	if strings.TrimSpace(source) == "" {
+		return true, nil
+	}
+
+	cond, err := ParseCondition(source)
+	if err != nil {
+		return false, err
+	}
+
+	return cond.Eval(ctx), nil
+}
+
+func truthy(value string) bool {
+	return value != "" && value != "false"
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+
+	return "false"
+}
+
+type condNode interface {
+	eval(ctx *InterpolationContext) string
+}
+
+type literalNode struct{ value string }
+
+func (n literalNode) eval(*InterpolationContext) string { return n.value }
+
+type refNode struct{ path []string }
+
+func (n refNode) eval(ctx *InterpolationContext) string {
+	switch n.path[0] {
+	case "var":
+		return ctx.Var[strings.Join(n.path[1:], ".")]
+	case "previous":
+		return stepField(ctx.Previous, n.path[1:])
+	case "steps":
+		idx, _ := strconv.Atoi(n.path[1])
+		return stepField(ctx.Steps[idx], n.path[2:])
+	}
+
+	return ""
+}
+
+func stepField(result *StepResult, path []string) string {
+	if result == nil {
+		return ""
+	}
+
+	switch path[0] {
+	case "conclusion":
+		return result.Conclusion
+	case "status":
+		return string(result.Status)
+	case "inputs":
+		return result.Inputs[strings.Join(path[1:], ".")]
+	case "outputs":
+		return result.Outputs[strings.Join(path[1:], ".")]
+	}
+
+	return ""
+}
+
+type compareNode struct {
+	left, right condNode
+	equal       bool
+}
+
+func (n compareNode) eval(ctx *InterpolationContext) string {
+	return boolString((n.left.eval(ctx) == n.right.eval(ctx)) == n.equal)
+}
+
+type logicNode struct {
+	left, right condNode
+	and         bool
+}
+
+func (n logicNode) eval(ctx *InterpolationContext) string {
+	left := truthy(n.left.eval(ctx))
+	if n.and {
+		return boolString(left && truthy(n.right.eval(ctx)))
+	}
+
+	return boolString(left || truthy(n.right.eval(ctx)))
+}
+
+type notNode struct{ operand condNode }
+
+func (n notNode) eval(ctx *InterpolationContext) string {
+	return boolString(!truthy(n.operand.eval(ctx)))
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokEq
+	tokNeq
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenizeCondition(source string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+
+			tokens = append(tokens, token{kind: tokString, text: string(runes[i+1 : end]), pos: i})
+			i = end + 1
+		case r == '(' || r == ')':
+			kind := tokLParen
+			if r == ')' {
+				kind = tokRParen
+			}
+
+			tokens = append(tokens, token{kind: kind, text: string(r), pos: i})
+			i++
+		case strings.HasPrefix(string(runes[i:]), "=="):
+			tokens = append(tokens, token{kind: tokEq, text: "==", pos: i})
+			i += 2
+		case strings.HasPrefix(string(runes[i:]), "!="):
+			tokens = append(tokens, token{kind: tokNeq, text: "!=", pos: i})
+			i += 2
+		case strings.HasPrefix(string(runes[i:]), "&&"):
+			tokens = append(tokens, token{kind: tokAnd, text: "&&", pos: i})
+			i += 2
+		case strings.HasPrefix(string(runes[i:]), "||"):
+			tokens = append(tokens, token{kind: tokOr, text: "||", pos: i})
+			i += 2
+		case r == '!':
+			tokens = append(tokens, token{kind: tokNot, text: "!", pos: i})
+			i++
+		case isIdentRune(r):
+			end := i
+			for end < len(runes) && isIdentRune(runes[end]) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[i:end]), pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", r, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+type condParser struct {
+	tokens []token
+	pos    int
+	steps  []int
+}
+
+func (p *condParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *condParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *condParser) parseOr() (condNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *condParser) parseAnd() (condNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAnd {
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicNode{left: left, right: right, and: true}
+	}
+
+	return left, nil
+}
+
+func (p *condParser) parseUnary() (condNode, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{operand: operand}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		p.next()
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at offset %d, got %q", tok.pos, tok.text)
+		}
+
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *condParser) parseComparison() (condNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.peek().kind {
+	case tokEq, tokNeq:
+		op := p.next()
+
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return compareNode{left: left, right: right, equal: op.kind == tokEq}, nil
+	}
+
+	return left, nil
+}
+
+func (p *condParser) parseOperand() (condNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokString:
+		return literalNode{value: tok.text}, nil
+	case tokIdent:
+		if tok.text == "true" || tok.text == "false" {
+			return literalNode{value: tok.text}, nil
+		}
+
+		path := strings.Split(tok.text, ".")
+		if err := validateReference(path); err != nil {
+			return nil, fmt.Errorf("%s at offset %d", err, tok.pos)
+		}
+
+		if path[0] == "steps" {
+			idx, _ := strconv.Atoi(path[1])
+			p.steps = append(p.steps, idx)
+		}
+
+		return refNode{path: path}, nil
+	}
+
+	return nil, fmt.Errorf("expected a value at offset %d, got %q", tok.pos, tok.text)
+}
+
+func validateReference(path []string) error {
+	ref := strings.Join(path, ".")
+
+	switch path[0] {
+	case "var":
+		if len(path) < 2 {
+			return fmt.Errorf("incomplete reference %q", ref)
+		}
+
+		return nil
+	case "previous":
+		return validateStepField(ref, path[1:])
+	case "steps":
+		if len(path) < 2 {
+			return fmt.Errorf("incomplete reference %q", ref)
+		}
+
+		var idx int
+		if !parseStepIndex(path[1], &idx) {
+			return fmt.Errorf("invalid step index in %q", ref)
+		}
+
+		return validateStepField(ref, path[2:])
+	}
+
+	return fmt.Errorf("unknown reference %q", ref)
+}
+
+func validateStepField(ref string, path []string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("incomplete reference %q", ref)
+	}
+
+	switch path[0] {
+	case "conclusion", "status":
+		if len(path) == 1 {
+			return nil
+		}
+	case "inputs", "outputs":
+		if len(path) >= 2 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown step field in %q", ref)
+}
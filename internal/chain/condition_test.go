@@ -0,0 +1,189 @@
+package chain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	chainerr "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	ctx := &chain.InterpolationContext{
+		Var: map[string]string{"environment": "prod", "dry_run": "false", "notify": "true"},
+		Previous: &chain.StepResult{
+			Status:     chain.StepCompleted,
+			Conclusion: "success",
+		},
+		Steps: map[int]*chain.StepResult{
+			0: {Conclusion: "failure", Inputs: map[string]string{"region": "us-east-1"}, Outputs: map[string]string{"changed": "true"}},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"var.environment == 'prod' && previous.conclusion == 'success'", true},
+		{`var.environment == "staging"`, false},
+		{"var.environment != 'staging'", true},
+		{"steps.0.conclusion == 'failure' || var.environment == 'dev'", true},
+		{"steps.0.inputs.region == 'us-east-1'", true},
+		{"steps.0.outputs.changed", true},
+		{"steps.3.conclusion == 'success'", false},
+		{"steps.3.conclusion == ''", true},
+		{"previous.status == 'completed'", true},
+		{"var.notify", true},
+		{"var.dry_run", false},
+		{"!var.dry_run", true},
+		{"var.missing", false},
+		{"!(var.environment == 'prod' || var.notify)", false},
+		{"var.environment == 'dev' || var.notify && previous.conclusion == 'success'", true},
+		{"true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := chain.EvaluateCondition(tt.expr, ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"var.environment == 'prod", "unterminated string"},
+		{"env.FOO == 'x'", `unknown reference "env.FOO"`},
+		{"steps.x.conclusion == 'success'", "invalid step index"},
+		{"previous.outputs == 'x'", "unknown step field"},
+		{"var.a ==", "expected a value"},
+		{"(var.a == 'b'", "expected )"},
+		{"var.a == 'b' 'c'", "unexpected"},
+		{"var.a = 'b'", "unexpected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := chain.ParseCondition(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error: got %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChainExecutor_ConditionalSteps(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+
+	defer runner.SetExecutor(nil)
+
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{
+			{Workflow: "build.yml", WaitFor: config.WaitNone},
+			{Workflow: "deploy-prod.yml", WaitFor: config.WaitNone, If: "var.environment == 'prod'"},
+			{Workflow: "deploy-staging.yml", WaitFor: config.WaitNone, If: "var.environment == 'staging'"},
+		},
+	}
+
+	executor := chain.NewExecutor(testutil.NewMockGitHubClient(), testutil.NewMockRunWatcher(), "release", chainDef)
+	if err := executor.Start(map[string]string{"environment": "staging"}, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainCompleted {
+		t.Fatalf("Status: got %v, want %v", state.Status, chain.ChainCompleted)
+	}
+
+	want := []chain.StepStatus{chain.StepCompleted, chain.StepSkipped, chain.StepCompleted}
+	for i, status := range want {
+		if state.StepStatuses[i] != status {
+			t.Errorf("StepStatuses[%d]: got %v, want %v", i, state.StepStatuses[i], status)
+		}
+	}
+
+	if reason := state.StepResults[1].SkipReason; !strings.Contains(reason, "var.environment == 'prod'") {
+		t.Errorf("SkipReason: got %q", reason)
+	}
+
+	for _, cmd := range mockExec.ExecutedCommands {
+		if strings.Contains(strings.Join(cmd.Args, " "), "deploy-prod.yml") {
+			t.Error("skipped step was dispatched")
+		}
+	}
+}
+
+func TestChainExecutor_InvalidCondition(t *testing.T) {
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{{Workflow: "deploy.yml", If: "var.environment === 'prod'"}},
+	}
+
+	executor := chain.NewExecutor(testutil.NewMockGitHubClient(), testutil.NewMockRunWatcher(), "release", chainDef)
+
+	err := executor.Start(nil, "main")
+
+	var condErr *chainerr.ConditionError
+	if !errors.As(err, &condErr) || condErr.StepIndex != 0 {
+		t.Fatalf("Start: got %v, want *ConditionError for step 0", err)
+	}
+
+	if executor.State().Status != chain.ChainPending {
+		t.Errorf("chain started despite invalid condition")
+	}
+}
+
+func TestValidateConditions_StepReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		cond    string
+		wantErr bool
+	}{
+		{name: "ancestor", cond: "steps.0.conclusion == 'success'"},
+		{name: "parallel step", cond: "steps.1.conclusion == 'success'", wantErr: true},
+		{name: "out of range", cond: "steps.99.outputs.tag", wantErr: true},
+		{name: "itself", cond: "steps.2.status", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chainDef := &config.Chain{
+				Steps: []config.ChainStep{
+					{ID: "build", Workflow: "build.yml", Needs: []string{}},
+					{ID: "lint", Workflow: "lint.yml", Needs: []string{}},
+					{Workflow: "deploy.yml", Needs: []string{"build"}, If: tt.cond},
+				},
+			}
+
+			err := chain.ValidateConditions(chainDef)
+
+			var condErr *chainerr.ConditionError
+			if tt.wantErr != errors.As(err, &condErr) {
+				t.Fatalf("ValidateConditions: got %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && condErr.StepIndex != 2 {
+				t.Errorf("StepIndex = %d, want 2", condErr.StepIndex)
+			}
+		})
+	}
+}
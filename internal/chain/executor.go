@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,24 +40,96 @@ const (
 	StepSkipped   StepStatus = "skipped"
 )
 
+// isFinished returns true once a step will not change status again.
+func (s StepStatus) isFinished() bool {
+	return s == StepCompleted || s == StepFailed || s == StepSkipped
+}
+
+// StepNode describes a step's place in the chain's dependency graph.
+type StepNode struct {
+	Name     string
+	Workflow string
+	Needs    []int
+}
+
 // StepResult represents the result of a completed step.
 type StepResult struct {
 	Workflow   string
 	Inputs     map[string]string
 	RunID      int64
 	RunURL     string
+	HeadSHA    string
+	Outputs    map[string]string // published by the run; see OutputFetcher
 	Status     StepStatus
 	Conclusion string
+	SkipReason string // set when the step's if: expression was false
+	// Attempts lists every finished attempt when the step waits for its run, oldest first.
+	// RunID, RunURL, and Conclusion describe the last one.
+	Attempts []StepAttempt
+}
+
+// StepAttempt records one attempt of a step. Retries in rerun_failed mode reuse the run ID.
+type StepAttempt struct {
+	RunID      int64  `json:"run_id"`
+	RunURL     string `json:"run_url,omitempty"`
+	Conclusion string `json:"conclusion"` // "timed_out" when the step's timeout elapsed
+}
+
+// errStepTimeout is returned while waiting for a run that exceeds the step's timeout.
+var errStepTimeout = errors.New("timed out waiting for run")
+
+// pollInterval is how often waitForRun checks on a run.
+var pollInterval = watcher.PollInterval
+
+// SetPollInterval overrides how often chain steps poll their runs.
+// Intended for tests; pass watcher.PollInterval to restore.
+func SetPollInterval(d time.Duration) {
+	pollInterval = d
 }
 
 // ChainState represents the current state of a chain execution.
+// Several steps may be running at once; CurrentStep is the one started most recently.
 type ChainState struct {
 	ChainName    string
 	CurrentStep  int
 	StepResults  map[int]*StepResult
 	StepStatuses []StepStatus
+	Steps        []StepNode // indexed like StepStatuses; nil for states rebuilt from history
 	Status       ChainStatus
 	Error        error
+
+	dispatchedAt map[int]time.Time // when each step's latest dispatch started, journaled for resuming
+}
+
+// IsSequential returns true if every step needs exactly the step before it.
+func (s ChainState) IsSequential() bool {
+	for i, step := range s.Steps {
+		if (i == 0 && len(step.Needs) != 0) || (i > 0 && (len(step.Needs) != 1 || step.Needs[0] != i-1)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clone copies the state so snapshots can be read while the executor keeps mutating it.
+func (s *ChainState) clone() ChainState {
+	c := *s
+
+	c.StepStatuses = append([]StepStatus(nil), s.StepStatuses...)
+	c.dispatchedAt = maps.Clone(s.dispatchedAt)
+
+	c.StepResults = make(map[int]*StepResult, len(s.StepResults))
+	for i, result := range s.StepResults {
+		if result == nil {
+			continue
+		}
+
+		r := *result
+		c.StepResults[i] = &r
+	}
+
+	return c
 }
 
 // ChainUpdate is sent when the chain state changes.
@@ -71,10 +146,26 @@ type ChainExecutor struct {
 	state     *ChainState
 	variables map[string]string // chain-level variables
 	branch    string
+	repo      string // passed to gh with --repo; empty for the current directory's repository
 	updates   chan ChainUpdate
 	mu        sync.RWMutex
 	stopCh    chan struct{}
 	stopOnce  sync.Once
+	stopped   bool
+
+	abortCh    chan struct{} // closed when a failing step aborts the chain
+	abortOnce  sync.Once
+	dispatchMu sync.Mutex // serializes dispatches so concurrent runs of one workflow correlate unambiguously
+
+	journal      *Journal
+	journalEntry JournalEntry
+	saveMu       sync.Mutex          // orders snapshots and journal writes so an older state never overwrites a newer one
+	attached     map[int]*StepResult // runs dispatched before a restart, keyed by step index
+	unconfirmed  map[int]JournalStep // steps dispatched before a restart whose runs were not identified yet
+
+	outputs OutputSource
+
+	correlationInputs map[string]string // workflow file -> input receiving a correlation ID
 }
 
 // NewExecutor creates a new chain executor.
@@ -94,17 +185,32 @@ func NewExecutor(client GitHubClient, w RunWatcher, chainName string, chain *con
 			CurrentStep:  0,
 			StepResults:  make(map[int]*StepResult),
 			StepStatuses: stepStatuses,
+			Steps:        stepNodes(chain),
 			Status:       ChainPending,
 		},
 		updates: make(chan ChainUpdate, 10),
 		stopCh:  make(chan struct{}),
+		abortCh: make(chan struct{}),
 	}
 }
 
+func stepNodes(chain *config.Chain) []StepNode {
+	deps := chain.Dependencies()
+	nodes := make([]StepNode, len(chain.Steps))
+
+	for i, step := range chain.Steps {
+		nodes[i] = StepNode{Name: step.Name(), Workflow: step.Workflow, Needs: deps[i]}
+	}
+
+	return nodes
+}
+
 // PreviousStepResult contains the result of a previously completed step.
 type PreviousStepResult struct {
 	Workflow   string
+	Inputs     map[string]string
 	RunID      int64
+	RunURL     string
 	Status     string
 	Conclusion string
 }
@@ -136,7 +242,9 @@ func NewExecutorFromHistory(
 			stepStatuses[i] = status
 			stepResults[i] = &StepResult{
 				Workflow:   prev.Workflow,
+				Inputs:     prev.Inputs,
 				RunID:      prev.RunID,
+				RunURL:     prev.RunURL,
 				Status:     status,
 				Conclusion: prev.Conclusion,
 			}
@@ -155,19 +263,112 @@ func NewExecutorFromHistory(
 			CurrentStep:  resumeFromStep,
 			StepResults:  stepResults,
 			StepStatuses: stepStatuses,
+			Steps:        stepNodes(chain),
 			Status:       ChainPending,
 		},
 		updates: make(chan ChainUpdate, 10),
 		stopCh:  make(chan struct{}),
+		abortCh: make(chan struct{}),
 	}
 }
 
+// NewExecutorFromJournal creates a chain executor that reattaches to an execution persisted in j.
+// Finished steps are restored, and steps whose runs were already dispatched are waited on
+// instead of being dispatched again. A step whose dispatch started but whose run was not
+// identified before lazydispatch exited is looked up first, and only dispatched again when
+// no run of it exists. Start it with entry.Variables and entry.Branch.
+func NewExecutorFromJournal(client GitHubClient, w RunWatcher, j *Journal, entry JournalEntry, chain *config.Chain) *ChainExecutor {
+	e := NewExecutor(client, w, entry.ChainName, chain)
+	e.journal = j
+	e.journalEntry = entry
+	e.state.CurrentStep = entry.CurrentStep
+	e.attached = make(map[int]*StepResult)
+	e.unconfirmed = make(map[int]JournalStep)
+
+	for i, step := range entry.Steps {
+		if i >= len(chain.Steps) {
+			break
+		}
+
+		result := &StepResult{
+			Workflow:   step.Workflow,
+			Inputs:     step.Inputs,
+			RunID:      step.RunID,
+			RunURL:     step.RunURL,
+			HeadSHA:    step.HeadSHA,
+			Outputs:    step.Outputs,
+			Attempts:   step.Attempts,
+			Status:     step.Status,
+			Conclusion: step.Conclusion,
+			SkipReason: step.SkipReason,
+		}
+
+		switch {
+		case step.Status.isFinished():
+			e.state.StepStatuses[i] = step.Status
+			e.state.StepResults[i] = result
+		case step.RunID != 0:
+			e.attached[i] = result
+		case !step.DispatchedAt.IsZero():
+			e.unconfirmed[i] = step
+		}
+	}
+
+	return e
+}
+
+// SetJournal persists the execution to j after every step transition, so it can be
+// reattached with NewExecutorFromJournal if lazydispatch exits mid-chain.
+// Must be called before Start.
+func (e *ChainExecutor) SetJournal(j *Journal, repo string) {
+	startedAt := time.Now()
+
+	e.journal = j
+	e.journalEntry = JournalEntry{
+		ID:        newJournalID(e.chainName, startedAt),
+		Repo:      repo,
+		ChainName: e.chainName,
+		StartedAt: startedAt,
+	}
+}
+
+// SetRepo names the repository (owner/name) steps are dispatched in when they go
+// through gh, for chains of a repository other than the current directory's.
+// Must be called before Start.
+func (e *ChainExecutor) SetRepo(repo string) {
+	e.repo = repo
+}
+
+// SetOutputSource fetches the outputs of each step's run once it completes, exposing them
+// to later steps as steps.N.outputs.key. Must be called before Start.
+func (e *ChainExecutor) SetOutputSource(src OutputSource) {
+	e.outputs = src
+}
+
+// SetCorrelationInputs names, per workflow file, the input that receives a generated
+// correlation ID when a step dispatches it. Must be called before Start.
+func (e *ChainExecutor) SetCorrelationInputs(inputs map[string]string) {
+	e.correlationInputs = inputs
+}
+
+// JournalID returns the ID of the persisted execution, or empty if not journaled.
+func (e *ChainExecutor) JournalID() string {
+	return e.journalEntry.ID
+}
+
 // Start begins executing the chain with the given variables.
+// Returns *errors.ConditionError without running anything if a step's if: expression is invalid.
 func (e *ChainExecutor) Start(variables map[string]string, branch string) error {
+	if err := ValidateConditions(e.chain); err != nil {
+		return err
+	}
+
 	e.mu.Lock()
 	e.variables = variables
 	e.branch = branch
 	e.state.Status = ChainRunning
+	e.journalEntry.Variables = variables
+	e.journalEntry.Branch = branch
 	e.mu.Unlock()
 
 	go e.runChain()
@@ -175,12 +376,12 @@ func (e *ChainExecutor) Start(variables map[string]string, branch string) error
 	return nil
 }
 
-// State returns the current chain state.
+// State returns a snapshot of the current chain state.
 func (e *ChainExecutor) State() ChainState {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	return *e.state
+	return e.state.clone()
 }
 
 // Updates returns the channel for receiving chain updates.
@@ -192,88 +393,325 @@ func (e *ChainExecutor) Updates() <-chan ChainUpdate {
 // Safe to call multiple times.
 func (e *ChainExecutor) Stop() {
 	e.stopOnce.Do(func() {
+		e.mu.Lock()
+		e.stopped = true
+		e.mu.Unlock()
+
 		close(e.stopCh)
 	})
 }
 
+// ValidateConditions parses every step's if: expression and checks that its steps.N
+// references name steps that finish before the step starts.
+func ValidateConditions(chain *config.Chain) error {
+	for i, step := range chain.Steps {
+		if strings.TrimSpace(step.If) == "" {
+			continue
+		}
+
+		cond, err := ParseCondition(step.If)
+		if err == nil {
+			ancestors := chain.Ancestors(i)
+			for _, idx := range cond.steps {
+				if err = checkStepReference(ancestors, idx); err != nil {
+					break
+				}
+			}
+		}
+
+		if err != nil {
+			return &chainerr.ConditionError{StepIndex: i, Workflow: step.Workflow, Expression: step.If, Cause: err}
+		}
+	}
+
+	return nil
+}
+
+// stepOutcome is reported by a step goroutine when the step finishes.
+type stepOutcome struct {
+	idx    int
+	result *StepResult
+	err    error
+}
+
+// runChain schedules every step whose dependencies have finished, running up to
+// max_parallel steps at once, until all steps finish or the chain fails.
 func (e *ChainExecutor) runChain() {
 	defer close(e.updates)
+	defer e.finishJournal()
 
-	for i, step := range e.chain.Steps {
-		select {
-		case <-e.stopCh:
-			return
-		default:
+	steps := e.chain.Steps
+	deps := e.chain.Dependencies()
+
+	limit := e.chain.MaxParallel
+	if limit <= 0 {
+		limit = len(steps)
+	}
+
+	started := make([]bool, len(steps))
+	finished := make([]bool, len(steps))
+
+	for i, status := range e.State().StepStatuses {
+		if status.isFinished() {
+			started[i], finished[i] = true, true // restored from history
 		}
+	}
 
-		e.mu.Lock()
-		e.state.CurrentStep = i
-		e.state.StepStatuses[i] = StepRunning
-		e.mu.Unlock()
-		e.sendUpdate()
+	outcomes := make(chan stepOutcome)
+	running := 0
 
-		result, err := e.runStep(i, step)
-		if err != nil {
-			e.handleStepError(i, step, err)
+	for {
+		for i := range steps {
+			if running >= limit || e.isHalted() {
+				break
+			}
 
-			if e.state.Status == ChainFailed {
-				return
+			if started[i] || !allFinished(deps[i], finished) {
+				continue
 			}
 
-			continue
+			started[i] = true
+			running++
+
+			e.mu.Lock()
+			e.state.CurrentStep = i
+			e.state.StepStatuses[i] = StepRunning
+			e.mu.Unlock()
+			e.sendUpdate()
+
+			go func(idx int) {
+				result, err := e.runStep(idx, steps[idx])
+				outcomes <- stepOutcome{idx: idx, result: result, err: err}
+			}(i)
+		}
+
+		if running == 0 {
+			break
 		}
 
+		outcome := <-outcomes
+		running--
+		finished[outcome.idx] = true
+
+		e.finishStep(outcome, steps[outcome.idx])
+	}
+
+	e.mu.Lock()
+	complete := e.state.Status == ChainRunning && !slices.Contains(finished, false)
+	if complete {
+		e.state.Status = ChainCompleted
+	}
+	e.mu.Unlock()
+
+	if complete {
+		e.sendUpdate()
+	}
+}
+
+// finishStep records a step's outcome and applies its failure handling.
+func (e *ChainExecutor) finishStep(outcome stepOutcome, step config.ChainStep) {
+	idx := outcome.idx
+
+	if e.isAborted() && (outcome.err != nil || outcome.result == nil) {
+		// Waiting was cancelled because another step aborted the chain.
 		e.mu.Lock()
-		e.state.StepResults[i] = result
-		e.state.StepStatuses[i] = result.Status
+		e.state.StepStatuses[idx] = StepSkipped
 		e.mu.Unlock()
 		e.sendUpdate()
 
-		if result.Status == StepFailed {
-			if !e.handleStepFailure(i, step) {
-				return
-			}
-		}
+		return
+	}
+
+	if outcome.err != nil {
+		e.handleStepError(idx, step, outcome.err)
+		e.abortIfFailed()
+		e.sendUpdate()
+
+		return
 	}
 
 	e.mu.Lock()
-	e.state.Status = ChainCompleted
+	e.state.StepResults[idx] = outcome.result
+	e.state.StepStatuses[idx] = outcome.result.Status
 	e.mu.Unlock()
+
+	if outcome.result.Status == StepFailed && !e.handleStepFailure(idx, step) {
+		e.abortIfFailed()
+	}
+
 	e.sendUpdate()
 }
 
+// abortIfFailed cancels the remaining steps once the chain has failed.
+func (e *ChainExecutor) abortIfFailed() {
+	e.mu.RLock()
+	failed := e.state.Status == ChainFailed
+	e.mu.RUnlock()
+
+	if failed {
+		e.abortOnce.Do(func() {
+			close(e.abortCh)
+		})
+	}
+}
+
+func (e *ChainExecutor) isAborted() bool {
+	select {
+	case <-e.abortCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// isHalted returns true once no further steps should be started.
+func (e *ChainExecutor) isHalted() bool {
+	select {
+	case <-e.stopCh:
+		return true
+	default:
+		return e.isAborted()
+	}
+}
+
+func allFinished(indices []int, finished []bool) bool {
+	for _, i := range indices {
+		if !finished[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (e *ChainExecutor) runStep(idx int, step config.ChainStep) (*StepResult, error) {
+	if attached, ok := e.attached[idx]; ok {
+		return e.awaitAttempts(idx, step, *attached)
+	}
+
+	if unconfirmed, ok := e.unconfirmed[idx]; ok {
+		found, err := e.findDispatchedRun(idx, step, unconfirmed)
+		if err != nil {
+			return nil, err
+		}
+
+		if found != nil {
+			return e.awaitAttempts(idx, step, *found)
+		}
+	}
+
+	state := e.State()
+
 	ctx := &InterpolationContext{
-		Var:   e.variables,
-		Steps: e.state.StepResults,
+		Var:       e.variables,
+		Steps:     state.StepResults,
+		Ancestors: e.chain.Ancestors(idx),
 	}
-	if idx > 0 {
-		ctx.Previous = e.state.StepResults[idx-1]
+	if prev := e.chain.PreviousStep(idx); prev >= 0 {
+		ctx.Previous = state.StepResults[prev]
+	}
+
+	shouldRun, err := EvaluateCondition(step.If, ctx)
+	if err != nil {
+		return nil, &chainerr.ConditionError{StepIndex: idx, Workflow: step.Workflow, Expression: step.If, Cause: err}
+	}
+
+	if !shouldRun {
+		return &StepResult{
+			Workflow:   step.Workflow,
+			Status:     StepSkipped,
+			SkipReason: fmt.Sprintf("if: %s was false", step.If),
+		}, nil
 	}
 
 	inputs, err := InterpolateInputs(step.Inputs, ctx)
 	if err != nil {
-		return nil, &chainerr.InterpolationError{
-			Field: "inputs",
-			Value: fmt.Sprintf("%v", step.Inputs),
-			Cause: err,
+		var interpErr *chainerr.InterpolationError
+		if errors.As(err, &interpErr) {
+			interpErr.Field = fmt.Sprintf("steps.%d.%s", idx, interpErr.Field)
+		}
+
+		return nil, err
+	}
+
+	dispatched, err := e.dispatchStep(idx, step, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.awaitAttempts(idx, step, dispatched)
+}
+
+// findDispatchedRun looks for the run of a step whose dispatch started before a restart but
+// was not identified. It returns nil when the step has no run, so it can be dispatched; a
+// lookup that cannot tell is an error rather than risking a second run.
+func (e *ChainExecutor) findDispatchedRun(idx int, step config.ChainStep, journaled JournalStep) (*StepResult, error) {
+	dispatch := runner.Dispatch{
+		Workflow:     step.Workflow,
+		Branch:       e.branch,
+		DispatchedAt: journaled.DispatchedAt,
+	}
+
+	if actor, err := e.client.CurrentUser(); err == nil {
+		dispatch.Actor = actor
+	}
+
+	runID, err := runner.CorrelateRun(e.client, dispatch, runner.DefaultCorrelationOptions)
+
+	var notFound *chainerr.RunNotFoundError
+
+	switch {
+	case errors.As(err, &notFound):
+		return nil, nil
+	case err != nil:
+		return nil, &chainerr.StepExecutionError{
+			StepIndex: idx,
+			Workflow:  step.Workflow,
+			Cause:     fmt.Errorf("could not tell whether the step was dispatched before lazydispatch exited: %w", err),
 		}
 	}
 
+	found := &StepResult{Workflow: step.Workflow, Inputs: journaled.Inputs, RunID: runID}
+
+	if run, _ := e.client.GetWorkflowRun(runID); run != nil {
+		found.RunURL = run.HTMLURL
+		found.HeadSHA = run.HeadSHA
+	}
+
+	return found, nil
+}
+
+// dispatchStep dispatches the step's workflow and identifies the resulting run. The dispatch
+// time is journaled first, so a restart before the run is identified can look for it.
+func (e *ChainExecutor) dispatchStep(idx int, step config.ChainStep, inputs map[string]string) (StepResult, error) {
+	e.mu.Lock()
+	if e.state.dispatchedAt == nil {
+		e.state.dispatchedAt = make(map[int]time.Time)
+	}
+
+	e.state.dispatchedAt[idx] = time.Now()
+	e.mu.Unlock()
+	e.sendUpdate()
+
 	cfg := runner.RunConfig{
-		Workflow: step.Workflow,
-		Branch:   e.branch,
-		Inputs:   inputs,
+		Repo:             e.repo,
+		Workflow:         step.Workflow,
+		Branch:           e.branch,
+		Inputs:           inputs,
+		CorrelationInput: e.correlationInputs[step.Workflow],
 	}
 
+	e.dispatchMu.Lock()
 	runID, err := runner.ExecuteAndGetRunID(cfg, e.client)
+	e.dispatchMu.Unlock()
+
 	if err != nil {
 		suggestion := ""
 		if e.branch != "" {
 			suggestion = fmt.Sprintf("Verify workflow %q exists and supports workflow_dispatch on branch %q", step.Workflow, e.branch)
 		}
 
-		return nil, &chainerr.StepDispatchError{
+		return StepResult{}, &chainerr.StepDispatchError{
 			Workflow:   step.Workflow,
 			Branch:     e.branch,
 			Cause:      err,
@@ -281,80 +719,227 @@ func (e *ChainExecutor) runStep(idx int, step config.ChainStep) (*StepResult, er
 		}
 	}
 
-	e.watcher.Watch(runID, step.Workflow)
+	dispatched := StepResult{
+		Workflow: step.Workflow,
+		Inputs:   inputs,
+		RunID:    runID,
+	}
+
+	if run, _ := e.client.GetWorkflowRun(runID); run != nil {
+		dispatched.RunURL = run.HTMLURL
+		dispatched.HeadSHA = run.HeadSHA
+	}
 
-	run, _ := e.client.GetWorkflowRun(runID)
-	runURL := ""
+	return dispatched, nil
+}
 
-	if run != nil {
-		runURL = run.HTMLURL
+// awaitAttempts waits for the dispatched run and retries it according to the step's retry
+// policy. Every finished attempt is recorded in the result's Attempts.
+func (e *ChainExecutor) awaitAttempts(idx int, step config.ChainStep, dispatched StepResult) (*StepResult, error) {
+	attempts := dispatched.Attempts
+	minRunAttempt := 0
+
+	for {
+		dispatched.Attempts = attempts
+
+		result, err := e.awaitStep(idx, step, dispatched, minRunAttempt)
+
+		conclusion := ""
+
+		switch {
+		case errors.Is(err, errStepTimeout):
+			conclusion = config.ConclusionTimedOut
+		case err != nil:
+			return nil, err
+		default:
+			conclusion = result.Conclusion
+		}
+
+		attempts = append(attempts, StepAttempt{
+			RunID:      dispatched.RunID,
+			RunURL:     dispatched.RunURL,
+			Conclusion: conclusion,
+		})
+
+		if !step.Retry.ShouldRetry(conclusion, len(attempts)) {
+			if err != nil {
+				return nil, err
+			}
+
+			result.Attempts = attempts
+
+			return result, nil
+		}
+
+		// A timed-out run is still in progress; cancel it so it does not run alongside the retry.
+		if canceller, ok := e.client.(RunCanceller); ok && conclusion == config.ConclusionTimedOut {
+			if err := canceller.CancelRun(dispatched.RunID); err != nil {
+				log.Printf("warning: failed to cancel timed-out run %d of step %d: %v", dispatched.RunID, idx, err)
+			}
+		}
+
+		if err := e.sleep(step.Retry.Delay(len(attempts))); err != nil {
+			return nil, err
+		}
+
+		// A run that outlived the step's timeout may still be in progress and cannot be re-run.
+		rerunner, canRerun := e.client.(FailedJobRerunner)
+		if canRerun && step.Retry.Mode == config.RetryRerunFailed && conclusion != config.ConclusionTimedOut {
+			// The rerun is a new attempt of the same run; wait for that attempt, not the old conclusion.
+			if run, _ := e.client.GetWorkflowRun(dispatched.RunID); run != nil {
+				minRunAttempt = run.RunAttempt + 1
+			}
+
+			if err := rerunner.RerunFailedJobs(dispatched.RunID); err != nil {
+				return nil, &chainerr.StepExecutionError{
+					StepIndex: idx,
+					Workflow:  step.Workflow,
+					RunID:     dispatched.RunID,
+					RunURL:    dispatched.RunURL,
+					Cause:     fmt.Errorf("failed to rerun failed jobs: %w", err),
+				}
+			}
+
+			continue
+		}
+
+		minRunAttempt = 0
+
+		dispatched, err = e.dispatchStep(idx, step, dispatched.Inputs)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d unless the chain is stopped or aborted first.
+func (e *ChainExecutor) sleep(d time.Duration) error {
+	if d <= 0 {
+		return nil
 	}
 
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-e.stopCh:
+		return errors.New("chain execution stopped")
+	case <-e.abortCh:
+		return errors.New("chain execution aborted")
+	case <-timer.C:
+		return nil
+	}
+}
+
+// awaitStep waits for a dispatched run according to the step's wait condition.
+// Completed runs older than minRunAttempt are treated as still pending.
+func (e *ChainExecutor) awaitStep(idx int, step config.ChainStep, dispatched StepResult, minRunAttempt int) (*StepResult, error) {
+	e.watcher.Watch(dispatched.RunID, step.Workflow)
+
+	// Record the run before waiting so a journaled execution can reattach to it.
+	waiting := dispatched
+	waiting.Status = StepWaiting
+
 	e.mu.Lock()
 	e.state.StepStatuses[idx] = StepWaiting
+	e.state.StepResults[idx] = &waiting
 	e.mu.Unlock()
 	e.sendUpdate()
 
+	result := dispatched
+	result.Status = StepCompleted
+
 	if step.WaitFor == config.WaitNone {
-		return &StepResult{
-			Workflow: step.Workflow,
-			Inputs:   inputs,
-			RunID:    runID,
-			RunURL:   runURL,
-			Status:   StepCompleted,
-		}, nil
+		return &result, nil
 	}
 
-	conclusion, waitRunURL, err := e.waitForRun(runID, step.WaitFor)
-	if waitRunURL != "" {
-		runURL = waitRunURL
+	run, err := e.waitForRun(dispatched.RunID, time.Duration(step.Timeout), minRunAttempt)
+	if run != nil {
+		if run.HTMLURL != "" {
+			result.RunURL = run.HTMLURL
+		}
+
+		if run.HeadSHA != "" {
+			result.HeadSHA = run.HeadSHA
+		}
 	}
 
 	if err != nil {
 		return nil, &chainerr.StepExecutionError{
 			StepIndex: idx,
 			Workflow:  step.Workflow,
-			RunID:     runID,
-			RunURL:    runURL,
+			RunID:     dispatched.RunID,
+			RunURL:    result.RunURL,
 			Cause:     err,
 		}
 	}
 
-	status := StepCompleted
-	if conclusion != github.ConclusionSuccess && step.WaitFor == config.WaitSuccess {
-		status = StepFailed
+	result.Conclusion = run.Conclusion
+
+	// Failed attempts are re-run or fail the chain, so only successful ones publish outputs
+	if run.Conclusion == github.ConclusionSuccess {
+		result.Outputs = e.fetchOutputs(step, run)
+	}
+
+	if run.Conclusion != github.ConclusionSuccess && step.WaitFor == config.WaitSuccess {
+		result.Status = StepFailed
+	}
+
+	return &result, nil
+}
+
+// fetchOutputs returns the run's outputs, or nil if they are unavailable. A missing output
+// only matters to steps that reference it, which then fail interpolation.
+func (e *ChainExecutor) fetchOutputs(step config.ChainStep, run *github.WorkflowRun) map[string]string {
+	if e.outputs == nil {
+		return nil
+	}
+
+	outputs, err := e.outputs.Fetch(run)
+	if err != nil {
+		log.Printf("warning: failed to fetch outputs of %s run %d: %v", step.Workflow, run.ID, err)
 	}
 
-	return &StepResult{
-		Workflow:   step.Workflow,
-		Inputs:     inputs,
-		RunID:      runID,
-		RunURL:     runURL,
-		Status:     status,
-		Conclusion: conclusion,
-	}, nil
+	return outputs
 }
 
-func (e *ChainExecutor) waitForRun(runID int64, waitFor config.WaitCondition) (conclusion, runURL string, err error) {
-	ticker := time.NewTicker(watcher.PollInterval)
+// waitForRun polls until the run completes, failing with errStepTimeout once timeout
+// elapses (zero waits indefinitely). The last run polled is returned even on error.
+func (e *ChainExecutor) waitForRun(runID int64, timeout time.Duration, minRunAttempt int) (*github.WorkflowRun, error) {
+	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
+	var deadline <-chan time.Time
+
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		deadline = timer.C
+	}
+
+	var last *github.WorkflowRun
+
 	for {
 		select {
 		case <-e.stopCh:
-			return "", "", errors.New("chain execution stopped")
+			return last, errors.New("chain execution stopped")
+		case <-e.abortCh:
+			return last, errors.New("chain execution aborted")
+		case <-deadline:
+			return last, fmt.Errorf("%w after %s", errStepTimeout, timeout)
 		case <-ticker.C:
 			run, pollErr := e.client.GetWorkflowRun(runID)
 			if pollErr != nil {
-				return "", "", &chainerr.RunWaitError{
+				return last, &chainerr.RunWaitError{
 					RunID: runID,
 					Cause: pollErr,
 				}
 			}
 
-			runURL = run.HTMLURL
-			if run.Status == github.StatusCompleted {
-				return run.Conclusion, runURL, nil
+			last = run
+			if run.Status == github.StatusCompleted && run.RunAttempt >= minRunAttempt {
+				return run, nil
 			}
 		}
 	}
@@ -392,10 +977,15 @@ func (e *ChainExecutor) handleStepFailure(idx int, step config.ChainStep) bool {
 }
 
 func (e *ChainExecutor) sendUpdate() {
+	e.saveMu.Lock()
+
 	e.mu.RLock()
-	state := *e.state
+	state := e.state.clone()
 	e.mu.RUnlock()
 
+	e.saveJournal(state)
+	e.saveMu.Unlock()
+
 	select {
 	case <-e.stopCh:
 		return
@@ -404,3 +994,37 @@ func (e *ChainExecutor) sendUpdate() {
 		log.Printf("warning: chain update channel full, update dropped for step %d", state.CurrentStep)
 	}
 }
+
+func (e *ChainExecutor) saveJournal(state ChainState) {
+	if e.journal == nil {
+		return
+	}
+
+	if err := e.journal.Save(journalEntry(state, e.chain, e.journalEntry)); err != nil {
+		log.Printf("warning: failed to persist chain %s: %v", e.chainName, err)
+	}
+}
+
+// finishJournal removes the persisted execution once the chain has finished or was stopped
+// deliberately. An execution interrupted by lazydispatch exiting stays active for reattaching.
+func (e *ChainExecutor) finishJournal() {
+	if e.journal == nil {
+		return
+	}
+
+	state := e.State()
+
+	e.mu.RLock()
+	stopped := e.stopped
+	e.mu.RUnlock()
+
+	if stopped || state.Status == ChainCompleted || state.Status == ChainFailed {
+		if err := e.journal.Remove(e.journalEntry.ID); err != nil {
+			log.Printf("warning: failed to remove persisted chain %s: %v", e.chainName, err)
+		}
+
+		return
+	}
+
+	e.saveJournal(state)
+}
@@ -1,14 +1,21 @@
 package chain_test
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/kyleking/gh-lazydispatch/internal/chain"
 	"github.com/kyleking/gh-lazydispatch/internal/config"
 	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
 	"github.com/kyleking/gh-lazydispatch/internal/runner"
 	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
 )
 
 func TestNewExecutor(t *testing.T) {
@@ -112,3 +119,413 @@ func TestNewExecutorFromHistory(t *testing.T) {
 		t.Errorf("StepStatuses[1]: got %v, want %v", state.StepStatuses[1], chain.StepPending)
 	}
 }
+
+// concurrencyClient records how many steps look up their run at the same time.
+type concurrencyClient struct {
+	*testutil.MockGitHubClient
+
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	events   []string
+}
+
+func (c *concurrencyClient) GetWorkflowRun(runID int64) (*github.WorkflowRun, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.maxSeen = max(c.maxSeen, c.inFlight)
+	c.events = append(c.events, fmt.Sprintf("start:%d", runID))
+	c.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.events = append(c.events, fmt.Sprintf("end:%d", runID))
+	c.mu.Unlock()
+
+	return c.MockGitHubClient.GetWorkflowRun(runID)
+}
+
+func TestChainExecutor_ParallelSteps(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+
+	defer runner.SetExecutor(nil)
+
+	client := &concurrencyClient{MockGitHubClient: testutil.NewMockGitHubClient()}
+	client.LatestByWorkflow["build.yml"] = 1
+	client.LatestByWorkflow["deploy-us.yml"] = 2
+	client.LatestByWorkflow["deploy-eu.yml"] = 3
+	client.LatestByWorkflow["deploy-ap.yml"] = 4
+	client.LatestByWorkflow["smoke.yml"] = 5
+
+	chainDef := &config.Chain{
+		MaxParallel: 2,
+		Steps: []config.ChainStep{
+			{ID: "build", Workflow: "build.yml", WaitFor: config.WaitNone},
+			{ID: "us", Workflow: "deploy-us.yml", WaitFor: config.WaitNone, Needs: []string{"build"}},
+			{ID: "eu", Workflow: "deploy-eu.yml", WaitFor: config.WaitNone, Needs: []string{"build"}},
+			{ID: "ap", Workflow: "deploy-ap.yml", WaitFor: config.WaitNone, Needs: []string{"build"}},
+			{ID: "smoke", Workflow: "smoke.yml", WaitFor: config.WaitNone, Needs: []string{"us", "eu", "ap"}},
+		},
+	}
+
+	executor := chain.NewExecutor(client, testutil.NewMockRunWatcher(), "fan-out", chainDef)
+	if err := executor.Start(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainCompleted {
+		t.Fatalf("Status: got %v, want %v", state.Status, chain.ChainCompleted)
+	}
+
+	if len(state.Steps) != 5 || state.Steps[4].Name != "smoke" || len(state.Steps[4].Needs) != 3 {
+		t.Errorf("Steps: got %+v", state.Steps)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.maxSeen > 2 {
+		t.Errorf("max concurrent steps: got %d, want at most 2", client.maxSeen)
+	}
+
+	position := make(map[string]int, len(client.events))
+	for i, event := range client.events {
+		position[event] = i
+	}
+
+	for _, deploy := range []string{"2", "3", "4"} {
+		if position["end:"+deploy] > position["start:5"] || position["end:1"] > position["start:"+deploy] {
+			t.Errorf("steps ran out of dependency order: %v", client.events)
+			break
+		}
+	}
+}
+
+func TestChainExecutor_ParallelAbort(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	mockExec.AddGHWorkflowRunError("deploy-eu.yml", "main", "HTTP 404", errors.New("exit status 1"))
+	runner.SetExecutor(mockExec)
+
+	defer runner.SetExecutor(nil)
+
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{
+			{ID: "us", Workflow: "deploy-us.yml", WaitFor: config.WaitNone, Needs: []string{}},
+			{ID: "eu", Workflow: "deploy-eu.yml", WaitFor: config.WaitNone, Needs: []string{}, OnFailure: config.FailureAbort},
+			{ID: "smoke", Workflow: "smoke.yml", WaitFor: config.WaitNone, Needs: []string{"us", "eu"}},
+		},
+	}
+
+	executor := chain.NewExecutor(testutil.NewMockGitHubClient(), testutil.NewMockRunWatcher(), "fan-out", chainDef)
+	if err := executor.Start(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainFailed {
+		t.Fatalf("Status: got %v, want %v", state.Status, chain.ChainFailed)
+	}
+
+	if state.StepStatuses[1] != chain.StepFailed || state.StepStatuses[2] != chain.StepPending {
+		t.Errorf("StepStatuses: got %v", state.StepStatuses)
+	}
+}
+
+// retryClient hands out a new run ID for every dispatch and reports runs from Runs,
+// which default to in progress.
+type retryClient struct {
+	*testutil.MockGitHubClient
+
+	mu         sync.Mutex
+	dispatches int
+	reruns     int
+	cancelled  []int64
+}
+
+func (c *retryClient) ListWorkflowRuns(filter github.RunFilter) ([]github.WorkflowRun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.dispatches++
+
+	return []github.WorkflowRun{{
+		ID:         int64(c.dispatches),
+		Event:      github.EventWorkflowDispatch,
+		HeadBranch: filter.Branch,
+		CreatedAt:  time.Now(),
+	}}, nil
+}
+
+func (c *retryClient) GetWorkflowRun(runID int64) (*github.WorkflowRun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if run, ok := c.Runs[runID]; ok {
+		copied := *run
+		return &copied, nil
+	}
+
+	return &github.WorkflowRun{ID: runID, Status: github.StatusInProgress}, nil
+}
+
+func (c *retryClient) RerunFailedJobs(runID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reruns++
+
+	// The API keeps returning the finished attempt for a while before the rerun shows up.
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		run := c.Runs[runID]
+		run.RunAttempt++
+		run.Conclusion = github.ConclusionSuccess
+	}()
+
+	return nil
+}
+
+func (c *retryClient) CancelRun(runID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cancelled = append(c.cancelled, runID)
+
+	return nil
+}
+
+// fetchRecorder is an OutputSource that publishes each attempt's number as an output.
+type fetchRecorder struct {
+	mu      sync.Mutex
+	fetched []github.WorkflowRun
+}
+
+func (f *fetchRecorder) Fetch(run *github.WorkflowRun) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fetched = append(f.fetched, *run)
+
+	return map[string]string{"attempt": strconv.Itoa(run.RunAttempt)}, nil
+}
+
+func runRetryChain(t *testing.T, client *retryClient, step config.ChainStep, configure ...func(*chain.ChainExecutor)) chain.ChainState {
+	t.Helper()
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+	chain.SetPollInterval(5 * time.Millisecond)
+
+	defer runner.SetExecutor(nil)
+	defer chain.SetPollInterval(watcher.PollInterval)
+
+	chainDef := &config.Chain{Steps: []config.ChainStep{step}}
+
+	executor := chain.NewExecutor(client, testutil.NewMockRunWatcher(), "retry", chainDef)
+	for _, fn := range configure {
+		fn(executor)
+	}
+
+	if err := executor.Start(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	return executor.State()
+}
+
+func TestChainExecutor_RetryDispatch(t *testing.T) {
+	client := &retryClient{MockGitHubClient: testutil.NewMockGitHubClient()}
+	client.WithRun(&github.WorkflowRun{ID: 1, Status: github.StatusCompleted, Conclusion: github.ConclusionFailure})
+	client.WithRun(&github.WorkflowRun{ID: 2, Status: github.StatusCompleted, Conclusion: github.ConclusionCancelled})
+	client.WithRun(&github.WorkflowRun{ID: 3, Status: github.StatusCompleted, Conclusion: github.ConclusionSuccess})
+
+	state := runRetryChain(t, client, config.ChainStep{
+		Workflow:  "flaky.yml",
+		WaitFor:   config.WaitSuccess,
+		OnFailure: config.FailureAbort,
+		Retry: &config.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     config.Duration(time.Millisecond),
+			On:          []string{config.ConclusionFailure, config.ConclusionCancelled},
+			Mode:        config.RetryDispatch,
+		},
+	})
+
+	if state.Status != chain.ChainCompleted {
+		t.Fatalf("Status: got %v, want %v (error: %v)", state.Status, chain.ChainCompleted, state.Error)
+	}
+
+	result := state.StepResults[0]
+	if result.RunID != 3 || result.Conclusion != github.ConclusionSuccess {
+		t.Errorf("result: got run %d conclusion %q, want run 3 success", result.RunID, result.Conclusion)
+	}
+
+	want := []chain.StepAttempt{
+		{RunID: 1, Conclusion: github.ConclusionFailure},
+		{RunID: 2, Conclusion: github.ConclusionCancelled},
+		{RunID: 3, Conclusion: github.ConclusionSuccess},
+	}
+	if len(result.Attempts) != len(want) {
+		t.Fatalf("Attempts: got %+v, want %+v", result.Attempts, want)
+	}
+
+	for i, attempt := range want {
+		if result.Attempts[i] != attempt {
+			t.Errorf("Attempts[%d]: got %+v, want %+v", i, result.Attempts[i], attempt)
+		}
+	}
+}
+
+func TestChainExecutor_RetryExhausted(t *testing.T) {
+	client := &retryClient{MockGitHubClient: testutil.NewMockGitHubClient()}
+	client.WithRun(&github.WorkflowRun{ID: 1, Status: github.StatusCompleted, Conclusion: github.ConclusionFailure})
+	client.WithRun(&github.WorkflowRun{ID: 2, Status: github.StatusCompleted, Conclusion: github.ConclusionFailure})
+
+	state := runRetryChain(t, client, config.ChainStep{
+		Workflow:  "broken.yml",
+		WaitFor:   config.WaitSuccess,
+		OnFailure: config.FailureAbort,
+		Retry:     &config.RetryPolicy{MaxAttempts: 2, On: []string{config.ConclusionFailure}, Mode: config.RetryDispatch},
+	})
+
+	if state.Status != chain.ChainFailed || state.StepStatuses[0] != chain.StepFailed {
+		t.Fatalf("got chain %v step %v, want failed", state.Status, state.StepStatuses[0])
+	}
+
+	if got := len(state.StepResults[0].Attempts); got != 2 || client.dispatches != 2 {
+		t.Errorf("got %d attempts and %d dispatches, want 2", got, client.dispatches)
+	}
+}
+
+func TestChainExecutor_RetryRerunFailed(t *testing.T) {
+	client := &retryClient{MockGitHubClient: testutil.NewMockGitHubClient()}
+	client.WithRun(&github.WorkflowRun{ID: 1, Status: github.StatusCompleted, Conclusion: github.ConclusionFailure, RunAttempt: 1})
+
+	outputs := &fetchRecorder{}
+
+	state := runRetryChain(t, client, config.ChainStep{
+		Workflow:  "e2e.yml",
+		WaitFor:   config.WaitSuccess,
+		OnFailure: config.FailureAbort,
+		Retry:     &config.RetryPolicy{MaxAttempts: 2, On: []string{config.ConclusionFailure}, Mode: config.RetryRerunFailed},
+	}, func(e *chain.ChainExecutor) { e.SetOutputSource(outputs) })
+
+	if state.Status != chain.ChainCompleted {
+		t.Fatalf("Status: got %v, want %v (error: %v)", state.Status, chain.ChainCompleted, state.Error)
+	}
+
+	if client.dispatches != 1 || client.reruns != 1 {
+		t.Errorf("got %d dispatches and %d reruns, want 1 each", client.dispatches, client.reruns)
+	}
+
+	attempts := state.StepResults[0].Attempts
+	if len(attempts) != 2 || attempts[0].RunID != 1 || attempts[1].RunID != 1 || attempts[1].Conclusion != github.ConclusionSuccess {
+		t.Errorf("Attempts: got %+v", attempts)
+	}
+
+	// Outputs are only fetched for the attempt that succeeded
+	if len(outputs.fetched) != 1 || outputs.fetched[0].RunAttempt != 2 || state.StepResults[0].Outputs["attempt"] != "2" {
+		t.Errorf("fetched outputs of %+v, got outputs %v", outputs.fetched, state.StepResults[0].Outputs)
+	}
+}
+
+func TestChainExecutor_StepTimeout(t *testing.T) {
+	client := &retryClient{MockGitHubClient: testutil.NewMockGitHubClient()}
+
+	state := runRetryChain(t, client, config.ChainStep{
+		Workflow:  "stuck.yml",
+		WaitFor:   config.WaitSuccess,
+		OnFailure: config.FailureAbort,
+		Timeout:   config.Duration(30 * time.Millisecond),
+		Retry:     &config.RetryPolicy{MaxAttempts: 2, On: []string{config.ConclusionTimedOut}, Mode: config.RetryDispatch},
+	})
+
+	if state.Status != chain.ChainFailed {
+		t.Fatalf("Status: got %v, want %v", state.Status, chain.ChainFailed)
+	}
+
+	if state.Error == nil || !strings.Contains(state.Error.Error(), "timed out") {
+		t.Errorf("Error: got %v, want timeout", state.Error)
+	}
+
+	if client.dispatches != 2 {
+		t.Errorf("dispatches: got %d, want 2", client.dispatches)
+	}
+
+	// Only the retried run is cancelled; the last attempt is left to the user.
+	if len(client.cancelled) != 1 || client.cancelled[0] != 1 {
+		t.Errorf("cancelled runs: got %v, want [1]", client.cancelled)
+	}
+}
+
+func TestChainExecutor_ReplaysCassette(t *testing.T) {
+	cassette := exec.UseCassette(t, "../../testdata/cassettes/chain_release_deploy.yaml")
+
+	client, err := github.NewClientWithExecutor("acme/app", cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Dispatches are replayed from the recorded REST requests
+	client.WithRESTClient(cassette)
+
+	chain.SetPollInterval(5 * time.Millisecond)
+	defer chain.SetPollInterval(watcher.PollInterval)
+
+	chainDef := &config.Chain{Steps: []config.ChainStep{
+		{
+			Workflow:  "release.yml",
+			Inputs:    map[string]string{"version": "{{ var.version }}", "prerelease": "true"},
+			WaitFor:   config.WaitSuccess,
+			OnFailure: config.FailureAbort,
+		},
+		{
+			Workflow:  "deploy.yml",
+			Inputs:    map[string]string{"environment": "staging", "version": "{{ var.version }}"},
+			WaitFor:   config.WaitSuccess,
+			OnFailure: config.FailureAbort,
+		},
+	}}
+
+	executor := chain.NewExecutor(client, testutil.NewMockRunWatcher(), "ship", chainDef)
+	if err := executor.Start(map[string]string{"version": "v2.0.0-rc.1"}, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainFailed {
+		t.Fatalf("Status: got %v, want %v (error: %v)", state.Status, chain.ChainFailed, state.Error)
+	}
+
+	release, deploy := state.StepResults[0], state.StepResults[1]
+	if release.RunID != 5101 || release.Conclusion != github.ConclusionSuccess {
+		t.Errorf("release: got run %d conclusion %q, want run 5101 success", release.RunID, release.Conclusion)
+	}
+
+	if release.RunURL != "https://github.com/acme/app/actions/runs/5101" {
+		t.Errorf("release RunURL: got %q", release.RunURL)
+	}
+
+	if deploy.RunID != 5102 || deploy.Conclusion != github.ConclusionFailure {
+		t.Errorf("deploy: got run %d conclusion %q, want run 5102 failure", deploy.RunID, deploy.Conclusion)
+	}
+}
@@ -44,6 +44,10 @@ func ExportAsBash(chainName string, chain *config.Chain, variables map[string]st
 		step := chain.Steps[i]
 		sb.WriteString(fmt.Sprintf("# Step %d: %s\n", i+1, step.Workflow))
 
+		if len(step.Needs) > 0 {
+			sb.WriteString(fmt.Sprintf("# (original: needs %s)\n", strings.Join(step.Needs, ", ")))
+		}
+
 		switch step.WaitFor {
 		case config.WaitSuccess:
 			sb.WriteString("# (original: wait for success)\n")
@@ -61,31 +65,17 @@ func ExportAsBash(chainName string, chain *config.Chain, variables map[string]st
 }
 
 func resolveChainCommands(chain *config.Chain, variables map[string]string, branch string) []string {
+	previews := PreviewInputs(chain, variables)
 	commands := make([]string, len(chain.Steps))
 
-	ctx := &InterpolationContext{
-		Var:   variables,
-		Steps: make(map[int]*StepResult),
-	}
-
 	for i, step := range chain.Steps {
-		inputs, _ := InterpolateInputs(step.Inputs, ctx)
-
 		cfg := runner.RunConfig{
 			Workflow: step.Workflow,
 			Branch:   branch,
-			Inputs:   inputs,
+			Inputs:   previews[i].Inputs,
 		}
 		args := runner.BuildArgs(cfg)
 		commands[i] = runner.FormatCommand(args)
-
-		ctx.Steps[i] = &StepResult{
-			Workflow: step.Workflow,
-			Inputs:   inputs,
-		}
-		if i > 0 {
-			ctx.Previous = ctx.Steps[i-1]
-		}
 	}
 
 	return commands
@@ -9,7 +9,8 @@ import (
 type GitHubClient interface {
 	GetWorkflowRun(runID int64) (*github.WorkflowRun, error)
 	GetWorkflowRunJobs(runID int64) ([]github.Job, error)
-	GetLatestRun(workflowName string) (*github.WorkflowRun, error)
+	ListWorkflowRuns(filter github.RunFilter) ([]github.WorkflowRun, error)
+	CurrentUser() (string, error)
 	Owner() string
 	Repo() string
 }
@@ -20,3 +21,20 @@ type RunWatcher interface {
 	Unwatch(runID int64)
 	Updates() <-chan watcher.RunUpdate
 }
+
+// OutputSource fetches the outputs a completed run attempt published for later steps.
+type OutputSource interface {
+	Fetch(run *github.WorkflowRun) (map[string]string, error)
+}
+
+// FailedJobRerunner is implemented by clients that can re-run a run's failed jobs in place,
+// used by steps with retry mode rerun_failed.
+type FailedJobRerunner interface {
+	RerunFailedJobs(runID int64) error
+}
+
+// RunCanceller is implemented by clients that can cancel a run, used to stop a timed-out
+// run before its step is retried with a new dispatch.
+type RunCanceller interface {
+	CancelRun(runID int64) error
+}
@@ -0,0 +1,213 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+)
+
+// JournalDir returns the directory holding persisted chain executions.
+func JournalDir() string {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "lazydispatch", "chains")
+	}
+
+	home, _ := os.UserHomeDir()
+
+	return filepath.Join(home, ".cache", "lazydispatch", "chains")
+}
+
+// JournalStep is the persisted state of a single chain step.
+type JournalStep struct {
+	Workflow   string            `json:"workflow"`
+	Inputs     map[string]string `json:"inputs,omitempty"`
+	RunID      int64             `json:"run_id,omitempty"`
+	RunURL     string            `json:"run_url,omitempty"`
+	HeadSHA    string            `json:"head_sha,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	Attempts   []StepAttempt     `json:"attempts,omitempty"`
+	Status     StepStatus        `json:"status"`
+	Conclusion string            `json:"conclusion,omitempty"`
+	SkipReason string            `json:"skip_reason,omitempty"`
+	// DispatchedAt is when the step's latest dispatch started, used to look for its run
+	// when lazydispatch exited before the run was identified.
+	DispatchedAt time.Time `json:"dispatched_at,omitzero"`
+}
+
+// JournalEntry is the persisted state of a chain execution, written after every step transition
+// so the execution can be reattached after lazydispatch exits.
+type JournalEntry struct {
+	ID          string            `json:"id"`
+	Repo        string            `json:"repo"`
+	ChainName   string            `json:"chain_name"`
+	Branch      string            `json:"branch"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Status      ChainStatus       `json:"status"`
+	CurrentStep int               `json:"current_step"`
+	Steps       []JournalStep     `json:"steps"`
+	Error       string            `json:"error,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// IsActive returns true if the chain had not finished when the entry was written.
+func (e JournalEntry) IsActive() bool {
+	return e.Status == ChainPending || e.Status == ChainRunning
+}
+
+// Journal persists chain executions as one JSON file per execution.
+type Journal struct {
+	dir string
+}
+
+// NewJournal creates a journal stored in dir.
+func NewJournal(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Save writes the entry, replacing any previous version. Each write goes through its own
+// temporary file so concurrent saves never rename a file another save already moved.
+func (j *Journal) Save(entry JournalEntry) error {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(j.dir, entry.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), j.path(entry.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+// Remove deletes the entry with the given ID. Missing entries are not an error.
+func (j *Journal) Remove(id string) error {
+	if err := os.Remove(j.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Active returns the unfinished executions for repo, most recently updated first.
+// Unreadable entries are skipped.
+func (j *Journal) Active(repo string) ([]JournalEntry, error) {
+	files, err := filepath.Glob(filepath.Join(j.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []JournalEntry
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+
+		if entry.Repo == repo && entry.IsActive() {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].UpdatedAt.After(entries[b].UpdatedAt)
+	})
+
+	return entries, nil
+}
+
+func (j *Journal) path(id string) string {
+	return filepath.Join(j.dir, id+".json")
+}
+
+// newJournalID builds a filesystem-safe, unique ID for a chain execution.
+func newJournalID(chainName string, startedAt time.Time) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+
+		return '_'
+	}, chainName)
+
+	return fmt.Sprintf("%s-%d", safe, startedAt.UnixNano())
+}
+
+// journalEntry converts a state snapshot into its persisted form.
+func journalEntry(state ChainState, chainDef *config.Chain, base JournalEntry) JournalEntry {
+	steps := len(chainDef.Steps)
+
+	entry := base
+	entry.Status = state.Status
+	entry.CurrentStep = state.CurrentStep
+	entry.Steps = make([]JournalStep, steps)
+	entry.UpdatedAt = time.Now()
+
+	if state.Error != nil {
+		entry.Error = state.Error.Error()
+	}
+
+	for i := range steps {
+		entry.Steps[i].Workflow = chainDef.Steps[i].Workflow
+
+		if i < len(state.StepStatuses) {
+			entry.Steps[i].Status = state.StepStatuses[i]
+		}
+
+		entry.Steps[i].DispatchedAt = state.dispatchedAt[i]
+
+		if result, ok := state.StepResults[i]; ok && result != nil {
+			entry.Steps[i].Workflow = result.Workflow
+			entry.Steps[i].Inputs = result.Inputs
+			entry.Steps[i].RunID = result.RunID
+			entry.Steps[i].RunURL = result.RunURL
+			entry.Steps[i].HeadSHA = result.HeadSHA
+			entry.Steps[i].Outputs = result.Outputs
+			entry.Steps[i].Attempts = result.Attempts
+			entry.Steps[i].Conclusion = result.Conclusion
+			entry.Steps[i].SkipReason = result.SkipReason
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,359 @@
+package chain_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
+)
+
+func TestJournal_SaveActiveRemove(t *testing.T) {
+	j := chain.NewJournal(t.TempDir())
+	now := time.Now()
+
+	entries := []chain.JournalEntry{
+		{ID: "older", Repo: "owner/repo", ChainName: "a", Status: chain.ChainRunning, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "newer", Repo: "owner/repo", ChainName: "b", Status: chain.ChainRunning, UpdatedAt: now},
+		{ID: "done", Repo: "owner/repo", ChainName: "c", Status: chain.ChainCompleted, UpdatedAt: now},
+		{ID: "other", Repo: "owner/other", ChainName: "d", Status: chain.ChainRunning, UpdatedAt: now},
+	}
+
+	for _, entry := range entries {
+		if err := j.Save(entry); err != nil {
+			t.Fatalf("Save(%s): %v", entry.ID, err)
+		}
+	}
+
+	active, err := j.Active("owner/repo")
+	if err != nil {
+		t.Fatalf("Active: %v", err)
+	}
+
+	if len(active) != 2 || active[0].ID != "newer" || active[1].ID != "older" {
+		t.Fatalf("Active: got %+v, want [newer older]", active)
+	}
+
+	if err := j.Remove("newer"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	if err := j.Remove("newer"); err != nil {
+		t.Errorf("Remove of missing entry: %v", err)
+	}
+
+	active, _ = j.Active("owner/repo")
+	if len(active) != 1 || active[0].ID != "older" {
+		t.Errorf("Active after remove: got %+v", active)
+	}
+}
+
+func TestJournal_SkipsCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := chain.NewJournal(dir).Active("owner/repo")
+	if err != nil || len(active) != 0 {
+		t.Errorf("Active: got %v, %v", active, err)
+	}
+}
+
+func TestChainExecutor_JournalRemovedOnCompletion(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+
+	defer runner.SetExecutor(nil)
+
+	dir := t.TempDir()
+	j := chain.NewJournal(dir)
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{{Workflow: "build.yml", WaitFor: config.WaitNone}},
+	}
+
+	executor := chain.NewExecutor(testutil.NewMockGitHubClient(), testutil.NewMockRunWatcher(), "release", chainDef)
+	executor.SetJournal(j, "owner/repo")
+
+	if !strings.HasPrefix(executor.JournalID(), "release-") {
+		t.Errorf("JournalID: got %q", executor.JournalID())
+	}
+
+	if err := executor.Start(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("journal not removed after completion: %v", files)
+	}
+}
+
+func TestNewExecutorFromJournal_Reattaches(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+
+	defer runner.SetExecutor(nil)
+
+	client := testutil.NewMockGitHubClient()
+	client.LatestByWorkflow["notify.yml"] = 303
+
+	j := chain.NewJournal(t.TempDir())
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{
+			{Workflow: "build.yml", WaitFor: config.WaitNone},
+			{Workflow: "deploy.yml", WaitFor: config.WaitNone},
+			{Workflow: "notify.yml", WaitFor: config.WaitNone},
+		},
+	}
+
+	entry := chain.JournalEntry{
+		ID:          "release-1",
+		Repo:        "owner/repo",
+		ChainName:   "release",
+		Branch:      "main",
+		Status:      chain.ChainRunning,
+		CurrentStep: 1,
+		Steps: []chain.JournalStep{
+			{Workflow: "build.yml", RunID: 101, Status: chain.StepCompleted},
+			{Workflow: "deploy.yml", RunID: 202, Status: chain.StepWaiting},
+			{Workflow: "notify.yml", Status: chain.StepPending},
+		},
+	}
+
+	executor := chain.NewExecutorFromJournal(client, testutil.NewMockRunWatcher(), j, entry, chainDef)
+	if executor.JournalID() != "release-1" {
+		t.Errorf("JournalID: got %q, want release-1", executor.JournalID())
+	}
+
+	if err := executor.Start(entry.Variables, entry.Branch); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainCompleted {
+		t.Fatalf("Status: got %v, want %v", state.Status, chain.ChainCompleted)
+	}
+
+	wantRunIDs := []int64{101, 202, 303}
+	for i, want := range wantRunIDs {
+		if got := state.StepResults[i].RunID; got != want {
+			t.Errorf("StepResults[%d].RunID: got %d, want %d", i, got, want)
+		}
+	}
+
+	for _, cmd := range mockExec.ExecutedCommands {
+		args := strings.Join(cmd.Args, " ")
+		if strings.Contains(args, "build.yml") || strings.Contains(args, "deploy.yml") {
+			t.Errorf("restored step was dispatched again: %s %s", cmd.Name, args)
+		}
+	}
+}
+
+func TestNewExecutorFromJournal_FindsUnidentifiedDispatch(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+
+	defer runner.SetExecutor(nil)
+
+	client := testutil.NewMockGitHubClient()
+	client.LatestByWorkflow["deploy.yml"] = 202
+
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{{Workflow: "deploy.yml", WaitFor: config.WaitNone}},
+	}
+
+	// lazydispatch exited after dispatching deploy.yml but before identifying its run
+	entry := chain.JournalEntry{
+		ID:        "release-1",
+		Repo:      "owner/repo",
+		ChainName: "release",
+		Branch:    "main",
+		Status:    chain.ChainRunning,
+		Steps: []chain.JournalStep{
+			{Workflow: "deploy.yml", Status: chain.StepRunning, DispatchedAt: time.Now().Add(-time.Minute)},
+		},
+	}
+
+	executor := chain.NewExecutorFromJournal(client, testutil.NewMockRunWatcher(), chain.NewJournal(t.TempDir()), entry, chainDef)
+	if err := executor.Start(entry.Variables, entry.Branch); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainCompleted || state.StepResults[0].RunID != 202 {
+		t.Fatalf("state: got %v with run %d, want completed with run 202", state.Status, state.StepResults[0].RunID)
+	}
+
+	for _, cmd := range mockExec.ExecutedCommands {
+		if strings.Contains(strings.Join(cmd.Args, " "), "deploy.yml") {
+			t.Errorf("step with a dispatched run was dispatched again: %s %v", cmd.Name, cmd.Args)
+		}
+	}
+}
+
+func TestChainExecutor_JournalsDispatchTime(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+	chain.SetPollInterval(5 * time.Millisecond)
+
+	defer runner.SetExecutor(nil)
+	defer chain.SetPollInterval(watcher.PollInterval)
+
+	client := testutil.NewMockGitHubClient()
+	client.LatestByWorkflow["deploy.yml"] = 1
+
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{{Workflow: "deploy.yml", WaitFor: config.WaitSuccess}},
+	}
+
+	j := chain.NewJournal(t.TempDir())
+	executor := chain.NewExecutor(client, testutil.NewMockRunWatcher(), "release", chainDef)
+	executor.SetJournal(j, "owner/repo")
+
+	before := time.Now()
+
+	if err := executor.Start(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	var dispatchedAt time.Time
+
+	for deadline := time.Now().Add(5 * time.Second); dispatchedAt.IsZero() && time.Now().Before(deadline); {
+		if active, _ := j.Active("owner/repo"); len(active) == 1 {
+			dispatchedAt = active[0].Steps[0].DispatchedAt
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	executor.Stop()
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	if dispatchedAt.Before(before) {
+		t.Errorf("DispatchedAt: got %v, want the time of the dispatch", dispatchedAt)
+	}
+}
+
+func TestJournal_ConcurrentSaves(t *testing.T) {
+	j := chain.NewJournal(t.TempDir())
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 100)
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs <- j.Save(chain.JournalEntry{ID: "release-1", Repo: "owner/repo", Status: chain.ChainRunning, CurrentStep: i})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Save: %v", err)
+		}
+	}
+
+	if active, _ := j.Active("owner/repo"); len(active) != 1 {
+		t.Errorf("Active: got %d entries, want 1", len(active))
+	}
+}
+
+func TestChainExecutor_JournalParallelSteps(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+	chain.SetPollInterval(5 * time.Millisecond)
+
+	defer runner.SetExecutor(nil)
+	defer chain.SetPollInterval(watcher.PollInterval)
+
+	var logged bytes.Buffer
+
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	client := testutil.NewMockGitHubClient()
+	client.LatestByWorkflow["deploy-us.yml"] = 1
+	client.LatestByWorkflow["deploy-eu.yml"] = 2
+	client.LatestByWorkflow["deploy-ap.yml"] = 3
+
+	// The runs stay queued, so the steps keep waiting until the chain is stopped.
+	chainDef := &config.Chain{
+		MaxParallel: 3,
+		Steps: []config.ChainStep{
+			{ID: "us", Workflow: "deploy-us.yml", WaitFor: config.WaitSuccess, Needs: []string{}},
+			{ID: "eu", Workflow: "deploy-eu.yml", WaitFor: config.WaitSuccess, Needs: []string{}},
+			{ID: "ap", Workflow: "deploy-ap.yml", WaitFor: config.WaitSuccess, Needs: []string{}},
+		},
+	}
+
+	j := chain.NewJournal(t.TempDir())
+	executor := chain.NewExecutor(client, testutil.NewMockRunWatcher(), "fan-out", chainDef)
+	executor.SetJournal(j, "owner/repo")
+
+	if err := executor.Start(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := func() bool {
+		active, _ := j.Active("owner/repo")
+		if len(active) != 1 {
+			return false
+		}
+
+		for _, step := range active[0].Steps {
+			if step.RunID == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	ok := recorded()
+	for !ok && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+
+		ok = recorded()
+	}
+
+	executor.Stop()
+	testutil.DrainChainUpdates(t, executor.Updates(), 5*time.Second)
+
+	if !ok {
+		t.Error("journal is missing the run of a dispatched step")
+	}
+
+	if strings.Contains(logged.String(), "failed to persist") {
+		t.Errorf("journal save failed: %s", logged.String())
+	}
+}
@@ -0,0 +1,173 @@
+package chain
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+// OutputsArtifact is the name of the artifact a workflow uploads to pass outputs to later steps.
+const OutputsArtifact = "lazydispatch-outputs"
+
+// OutputsFile is the file within OutputsArtifact holding a JSON object of output names to values.
+const OutputsFile = "lazydispatch-outputs.json"
+
+// ArtifactClient defines the GitHub API operations needed to fetch step outputs.
+type ArtifactClient interface {
+	ListRunArtifacts(runID int64) ([]github.Artifact, error)
+	DownloadArtifact(artifactID int64) ([]byte, error)
+	Owner() string
+	Repo() string
+}
+
+// OutputFetcher reads the outputs of completed runs from their OutputsArtifact.
+// Results are cached on disk per run attempt, since a completed attempt's artifacts no
+// longer change; a re-run attempt of the same run may publish different ones.
+type OutputFetcher struct {
+	client   ArtifactClient
+	cacheDir string
+}
+
+// NewOutputFetcher creates an output fetcher caching under logCacheDir/outputs.
+func NewOutputFetcher(client ArtifactClient, logCacheDir string) *OutputFetcher {
+	return &OutputFetcher{
+		client:   client,
+		cacheDir: filepath.Join(logCacheDir, "outputs"),
+	}
+}
+
+// Fetch returns the outputs published by a completed run attempt.
+// A run that did not upload OutputsArtifact has no outputs and no error. That absence is
+// only cached for successful attempts, whose artifacts are all uploaded.
+func (f *OutputFetcher) Fetch(run *github.WorkflowRun) (map[string]string, error) {
+	cachePath := filepath.Join(f.cacheDir, fmt.Sprintf("%s_%s_%d_%d.json", f.client.Owner(), f.client.Repo(), run.ID, run.RunAttempt))
+
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var outputs map[string]string
+		if err := json.Unmarshal(data, &outputs); err == nil {
+			return outputs, nil
+		}
+	}
+
+	outputs, found, err := f.download(run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found && run.Conclusion != github.ConclusionSuccess {
+		return outputs, nil
+	}
+
+	if err := writeOutputsCache(cachePath, outputs); err != nil {
+		return outputs, fmt.Errorf("failed to cache outputs: %w", err)
+	}
+
+	return outputs, nil
+}
+
+// download reads the run's OutputsArtifact, reporting whether it has one.
+func (f *OutputFetcher) download(runID int64) (map[string]string, bool, error) {
+	artifacts, err := f.client.ListRunArtifacts(runID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+
+	for _, artifact := range artifacts {
+		if artifact.Name != OutputsArtifact || artifact.Expired {
+			continue
+		}
+
+		archive, err := f.client.DownloadArtifact(artifact.ID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to download %s: %w", OutputsArtifact, err)
+		}
+
+		outputs, err := parseOutputsArchive(archive)
+
+		return outputs, true, err
+	}
+
+	return map[string]string{}, false, nil
+}
+
+func parseOutputsArchive(archive []byte) (map[string]string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", OutputsArtifact, err)
+	}
+
+	for _, file := range reader.File {
+		if path.Base(file.Name) != OutputsFile {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", OutputsFile, err)
+		}
+
+		data, err := io.ReadAll(rc)
+		rc.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", OutputsFile, err)
+		}
+
+		return ParseOutputs(data)
+	}
+
+	return nil, fmt.Errorf("%s does not contain %s", OutputsArtifact, OutputsFile)
+}
+
+// ParseOutputs decodes a JSON object of outputs. String values are used as-is, null values
+// are dropped, and other values keep their JSON encoding, so `{"count": 3}` yields "3".
+func ParseOutputs(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object: %w", OutputsFile, err)
+	}
+
+	outputs := make(map[string]string, len(raw))
+
+	for key, value := range raw {
+		text := strings.TrimSpace(string(value))
+		if text == "null" {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			text = s
+		}
+
+		outputs[key] = text
+	}
+
+	return outputs, nil
+}
+
+func writeOutputsCache(cachePath string, outputs map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return err
+	}
+
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, cachePath)
+}
@@ -0,0 +1,155 @@
+package chain_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+type artifactClient struct {
+	artifacts map[int64][]github.Artifact
+	archives  map[int64][]byte
+	downloads int
+}
+
+func (c *artifactClient) ListRunArtifacts(runID int64) ([]github.Artifact, error) {
+	return c.artifacts[runID], nil
+}
+
+func (c *artifactClient) DownloadArtifact(artifactID int64) ([]byte, error) {
+	c.downloads++
+	return c.archives[artifactID], nil
+}
+
+func (c *artifactClient) Owner() string { return "owner" }
+func (c *artifactClient) Repo() string  { return "repo" }
+
+func outputsArchive(t *testing.T, name, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	f, err := w.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func succeededRun(id int64, attempt int) *github.WorkflowRun {
+	return &github.WorkflowRun{ID: id, RunAttempt: attempt, Status: github.StatusCompleted, Conclusion: github.ConclusionSuccess}
+}
+
+func TestOutputFetcher_Fetch(t *testing.T) {
+	client := &artifactClient{
+		artifacts: map[int64][]github.Artifact{
+			100: {
+				{ID: 1, Name: "coverage"},
+				{ID: 2, Name: chain.OutputsArtifact},
+			},
+		},
+		archives: map[int64][]byte{
+			2: outputsArchive(t, chain.OutputsFile, `{"image_tag": "app:1.2.3", "build": 42, "signed": true, "notes": null}`),
+		},
+	}
+
+	fetcher := chain.NewOutputFetcher(client, t.TempDir())
+
+	for range 2 {
+		outputs, err := fetcher.Fetch(succeededRun(100, 1))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := map[string]string{"image_tag": "app:1.2.3", "build": "42", "signed": "true"}
+		if len(outputs) != len(want) {
+			t.Errorf("outputs: got %v, want %v", outputs, want)
+		}
+
+		for k, v := range want {
+			if outputs[k] != v {
+				t.Errorf("outputs[%q]: got %q, want %q", k, outputs[k], v)
+			}
+		}
+	}
+
+	if client.downloads != 1 {
+		t.Errorf("downloads: got %d, want 1 (second fetch should hit the cache)", client.downloads)
+	}
+}
+
+func TestOutputFetcher_NoArtifact(t *testing.T) {
+	client := &artifactClient{
+		artifacts: map[int64][]github.Artifact{
+			100: {{ID: 2, Name: chain.OutputsArtifact, Expired: true}},
+		},
+	}
+
+	outputs, err := chain.NewOutputFetcher(client, t.TempDir()).Fetch(succeededRun(100, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(outputs) != 0 {
+		t.Errorf("outputs: got %v, want none", outputs)
+	}
+}
+
+func TestOutputFetcher_InvalidArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{"not a zip", []byte("not a zip")},
+		{"missing file", outputsArchive(t, "other.json", "{}")},
+		{"not an object", outputsArchive(t, chain.OutputsFile, `["a"]`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &artifactClient{
+				artifacts: map[int64][]github.Artifact{100: {{ID: 2, Name: chain.OutputsArtifact}}},
+				archives:  map[int64][]byte{2: tt.archive},
+			}
+
+			if _, err := chain.NewOutputFetcher(client, t.TempDir()).Fetch(succeededRun(100, 1)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestOutputFetcher_RerunAttempt(t *testing.T) {
+	client := &artifactClient{artifacts: map[int64][]github.Artifact{}, archives: map[int64][]byte{}}
+	fetcher := chain.NewOutputFetcher(client, t.TempDir())
+
+	// The first attempt failed before uploading its outputs
+	failed := &github.WorkflowRun{ID: 100, RunAttempt: 1, Status: github.StatusCompleted, Conclusion: github.ConclusionFailure}
+	if outputs, err := fetcher.Fetch(failed); err != nil || len(outputs) != 0 {
+		t.Fatalf("failed attempt: got %v, %v", outputs, err)
+	}
+
+	client.artifacts[100] = []github.Artifact{{ID: 2, Name: chain.OutputsArtifact}}
+	client.archives[2] = outputsArchive(t, chain.OutputsFile, `{"image_tag": "app:1.2.3"}`)
+
+	for _, run := range []*github.WorkflowRun{failed, succeededRun(100, 2)} {
+		outputs, err := fetcher.Fetch(run)
+		if err != nil || outputs["image_tag"] != "app:1.2.3" {
+			t.Errorf("attempt %d: got %v, %v; want the uploaded outputs", run.RunAttempt, outputs, err)
+		}
+	}
+}
@@ -1,8 +1,16 @@
 package chain
 
 import (
+	"fmt"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	chainerr "github.com/kyleking/gh-lazydispatch/internal/errors"
 )
 
 // InterpolationContext provides values for template interpolation.
@@ -10,6 +18,17 @@ type InterpolationContext struct {
 	Var      map[string]string // chain-level variables (replaces Trigger)
 	Previous *StepResult
 	Steps    map[int]*StepResult
+	// Ancestors holds the steps that steps.N may reference, those finishing before the
+	// step being resolved starts; nil allows any step.
+	Ancestors map[int]bool
+
+	// Env resolves env.* references; nil reads the process environment.
+	Env func(key string) (string, bool)
+	// Now is the time used by `now`; zero means time.Now().
+	Now time.Time
+	// Preview renders run metadata of steps that have not been dispatched yet as
+	// placeholders instead of failing, for showing commands before a chain starts.
+	Preview bool
 }
 
 var templatePattern = regexp.MustCompile(`\{\{\s*([^}]+)\s*\}\}`)
@@ -17,54 +36,318 @@ var templatePattern = regexp.MustCompile(`\{\{\s*([^}]+)\s*\}\}`)
 // Interpolate replaces template expressions in a string.
 // Supported expressions:
 //   - {{ var.key }} - Value from chain-level variables
+//   - {{ env.KEY }} - Value from the environment
 //   - {{ previous.inputs.key }} - Value from previous step's inputs
 //   - {{ steps.N.inputs.key }} - Value from step N's inputs (0-indexed)
+//   - {{ steps.N.outputs.key }} - Output published by step N's run (also on previous)
+//   - {{ steps.N.run_id }}, .run_url, .conclusion, .head_sha - Run metadata of step N (also on previous)
+//   - {{ now }} - The current time
+//
+// Values can be piped through filters: default "x", upper, lower, trim,
+// replace "old" "new", and date "layout" (Go time layout, e.g. "2006-01-02").
+// References that cannot be resolved are an error unless a default filter supplies a value.
 func Interpolate(template string, ctx *InterpolationContext) (string, error) {
 	if ctx == nil {
 		return template, nil
 	}
 
+	var firstErr error
+
 	result := templatePattern.ReplaceAllStringFunc(template, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+
 		expr := strings.TrimSpace(match[2 : len(match)-2])
-		parts := strings.Split(expr, ".")
 
-		if len(parts) < 2 {
+		value, err := evalTemplateExpr(expr, ctx)
+		if err != nil {
+			firstErr = err
 			return match
 		}
 
-		switch parts[0] {
-		case "var":
-			if len(parts) >= 2 && ctx.Var != nil {
-				key := strings.Join(parts[1:], ".")
-				if val, ok := ctx.Var[key]; ok {
-					return val
-				}
+		return value
+	})
+
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	return result, nil
+}
+
+// evalTemplateExpr evaluates `ref | filter arg... | filter ...`.
+func evalTemplateExpr(expr string, ctx *InterpolationContext) (string, error) {
+	stages, err := splitPipeline(expr)
+	if err != nil {
+		return "", err
+	}
+
+	ref := stages[0]
+	if len(ref) != 1 {
+		return "", fmt.Errorf("expected a single reference before the first |, got %q", strings.Join(ref, " "))
+	}
+
+	value, ok, err := resolveReference(ref[0], ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, stage := range stages[1:] {
+		value, ok, err = applyFilter(stage[0], stage[1:], value, ok, ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !ok {
+		return "", fmt.Errorf("unresolved reference %q", ref[0])
+	}
+
+	return value, nil
+}
+
+// splitPipeline tokenizes an expression into pipeline stages of words, unquoting string arguments.
+func splitPipeline(expr string) ([][]string, error) {
+	var (
+		stages [][]string
+		words  []string
+	)
+
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == ' ' || r == '\t':
+			i++
+		case r == '|':
+			if len(words) == 0 {
+				return nil, fmt.Errorf("empty stage in %q", expr)
 			}
-		case "previous":
-			if ctx.Previous != nil && len(parts) >= 3 && parts[1] == "inputs" {
-				key := strings.Join(parts[2:], ".")
-				if val, ok := ctx.Previous.Inputs[key]; ok {
-					return val
-				}
+
+			stages = append(stages, words)
+			words = nil
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
 			}
-		case "steps":
-			if ctx.Steps != nil && len(parts) >= 4 && parts[2] == "inputs" {
-				var stepNum int
-				if parseStepIndex(parts[1], &stepNum) {
-					if step, ok := ctx.Steps[stepNum]; ok {
-						key := strings.Join(parts[3:], ".")
-						if val, ok := step.Inputs[key]; ok {
-							return val
-						}
-					}
-				}
+
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string in %q", expr)
 			}
+
+			words = append(words, string(runes[i+1:end]))
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && runes[end] != ' ' && runes[end] != '\t' && runes[end] != '|' {
+				end++
+			}
+
+			words = append(words, string(runes[i:end]))
+			i = end
 		}
+	}
 
-		return match
-	})
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty stage in %q", expr)
+	}
 
-	return result, nil
+	return append(stages, words), nil
+}
+
+// resolveReference looks up a dotted reference. ok is false when a well-formed
+// reference has no value; err is set for references that can never resolve.
+func resolveReference(ref string, ctx *InterpolationContext) (value string, ok bool, err error) {
+	parts := strings.Split(ref, ".")
+
+	switch parts[0] {
+	case "now":
+		if len(parts) != 1 {
+			break
+		}
+
+		return ctx.now().Format(time.RFC3339), true, nil
+	case "var":
+		if len(parts) < 2 {
+			break
+		}
+
+		value, ok = ctx.Var[strings.Join(parts[1:], ".")]
+
+		return value, ok, nil
+	case "env":
+		if len(parts) != 2 {
+			break
+		}
+
+		lookup := ctx.Env
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+
+		value, ok = lookup(parts[1])
+
+		return value, ok, nil
+	case "previous":
+		if len(parts) < 2 {
+			break
+		}
+
+		return ctx.stepField("previous", ctx.Previous, parts[1:])
+	case "steps":
+		if len(parts) < 3 {
+			break
+		}
+
+		var idx int
+		if !parseStepIndex(parts[1], &idx) {
+			return "", false, fmt.Errorf("invalid step index in %q", ref)
+		}
+
+		if ctx.Ancestors != nil {
+			if err := checkStepReference(ctx.Ancestors, idx); err != nil {
+				return "", false, fmt.Errorf("%q: %w", ref, err)
+			}
+		}
+
+		return ctx.stepField("steps."+parts[1], ctx.Steps[idx], parts[2:])
+	}
+
+	return "", false, fmt.Errorf("unknown reference %q", ref)
+}
+
+func (ctx *InterpolationContext) stepField(prefix string, result *StepResult, path []string) (string, bool, error) {
+	ref := prefix + "." + strings.Join(path, ".")
+
+	field := path[0]
+	if field == "inputs" || field == "outputs" {
+		if len(path) < 2 {
+			return "", false, fmt.Errorf("incomplete reference %q", ref)
+		}
+
+		var values map[string]string
+
+		switch {
+		case result == nil:
+			return ctx.placeholder(ref)
+		case field == "inputs":
+			values = result.Inputs
+		case ctx.Preview && result.RunID == 0:
+			return ctx.placeholder(ref)
+		default:
+			values = result.Outputs
+		}
+
+		value, ok := values[strings.Join(path[1:], ".")]
+
+		return value, ok, nil
+	}
+
+	switch {
+	case len(path) != 1:
+		return "", false, fmt.Errorf("unknown step field in %q", ref)
+	case field != "run_id" && field != "run_url" && field != "conclusion" && field != "head_sha":
+		return "", false, fmt.Errorf("unknown step field in %q", ref)
+	case result == nil || (ctx.Preview && result.RunID == 0):
+		return ctx.placeholder(ref)
+	}
+
+	switch field {
+	case "run_id":
+		if result.RunID == 0 {
+			return "", false, nil
+		}
+
+		return strconv.FormatInt(result.RunID, 10), true, nil
+	case "run_url":
+		return result.RunURL, result.RunURL != "", nil
+	case "conclusion":
+		return result.Conclusion, result.Conclusion != "", nil
+	case "head_sha":
+		return result.HeadSHA, result.HeadSHA != "", nil
+	}
+
+	return "", false, nil
+}
+
+// placeholder stands in for step values that only exist once the chain runs.
+func (ctx *InterpolationContext) placeholder(ref string) (string, bool, error) {
+	if ctx.Preview {
+		return "<" + ref + ">", true, nil
+	}
+
+	return "", false, nil
+}
+
+func (ctx *InterpolationContext) now() time.Time {
+	if ctx.Now.IsZero() {
+		return time.Now()
+	}
+
+	return ctx.Now
+}
+
+// filterArgs is the number of arguments each filter takes; -1 means zero or one.
+var filterArgs = map[string]int{"default": 1, "upper": 0, "lower": 0, "trim": 0, "replace": 2, "date": -1}
+
+func applyFilter(name string, args []string, value string, ok bool, ctx *InterpolationContext) (string, bool, error) {
+	n, known := filterArgs[name]
+	if !known {
+		return "", false, fmt.Errorf("unknown filter %q", name)
+	}
+
+	if (n >= 0 && len(args) != n) || (n < 0 && len(args) > 1) {
+		return "", false, fmt.Errorf("filter %q takes %d argument(s), got %d", name, max(n, 1), len(args))
+	}
+
+	switch name {
+	case "default":
+		if !ok || value == "" {
+			return args[0], true, nil
+		}
+	case "upper":
+		value = strings.ToUpper(value)
+	case "lower":
+		value = strings.ToLower(value)
+	case "trim":
+		value = strings.TrimSpace(value)
+	case "replace":
+		value = strings.ReplaceAll(value, args[0], args[1])
+	case "date":
+		if !ok {
+			break
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", false, fmt.Errorf("date: %q is not an RFC 3339 time", value)
+		}
+
+		layout := time.RFC3339
+		if len(args) == 1 {
+			layout = args[0]
+		}
+
+		value = t.In(ctx.now().Location()).Format(layout)
+	}
+
+	return value, ok, nil
+}
+
+// checkStepReference rejects a steps.N reference to a step that does not exist or
+// that the referencing step does not need, directly or indirectly: its result would
+// depend on scheduling, if it exists at all.
+func checkStepReference(ancestors map[int]bool, idx int) error {
+	if !ancestors[idx] {
+		return fmt.Errorf("step %d is not among the steps this step needs", idx)
+	}
+
+	return nil
 }
 
 func parseStepIndex(s string, n *int) bool {
@@ -88,13 +371,21 @@ func parseStepIndex(s string, n *int) bool {
 }
 
 // InterpolateInputs interpolates all values in an input map.
+// Returns *errors.InterpolationError naming the first input (in key order) that failed.
 func InterpolateInputs(inputs map[string]string, ctx *InterpolationContext) (map[string]string, error) {
 	result := make(map[string]string, len(inputs))
 
-	for key, value := range inputs {
-		interpolated, err := Interpolate(value, ctx)
+	keys := make([]string, 0, len(inputs))
+	for key := range inputs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		interpolated, err := Interpolate(inputs[key], ctx)
 		if err != nil {
-			return nil, err
+			return nil, &chainerr.InterpolationError{Field: "inputs." + key, Value: inputs[key], Cause: err}
 		}
 
 		result[key] = interpolated
@@ -102,3 +393,56 @@ func InterpolateInputs(inputs map[string]string, ctx *InterpolationContext) (map
 
 	return result, nil
 }
+
+// StepPreview is a step's inputs as they would be dispatched, resolved before the chain runs.
+type StepPreview struct {
+	Inputs map[string]string
+	Err    error // *errors.InterpolationError when an input cannot be resolved
+}
+
+// PreviewInputs resolves every step's inputs without dispatching anything. Run metadata of
+// earlier steps is rendered as placeholders; unresolvable references are reported per step.
+func PreviewInputs(chainDef *config.Chain, variables map[string]string) []StepPreview {
+	previews := make([]StepPreview, len(chainDef.Steps))
+
+	ctx := &InterpolationContext{
+		Var:     variables,
+		Steps:   make(map[int]*StepResult),
+		Preview: true,
+	}
+
+	for i, step := range chainDef.Steps {
+		ctx.Ancestors = chainDef.Ancestors(i)
+
+		ctx.Previous = nil
+		if prev := chainDef.PreviousStep(i); prev >= 0 {
+			ctx.Previous = ctx.Steps[prev]
+		}
+
+		inputs, err := InterpolateInputs(step.Inputs, ctx)
+		if err != nil {
+			previews[i].Err = fmt.Errorf("step %d (%s): %w", i+1, step.Workflow, err)
+			inputs = step.Inputs
+		}
+
+		previews[i].Inputs = inputs
+
+		ctx.Steps[i] = &StepResult{
+			Workflow: step.Workflow,
+			Inputs:   inputs,
+		}
+	}
+
+	return previews
+}
+
+// PreviewError returns the first interpolation error in the previews, if any.
+func PreviewError(previews []StepPreview) error {
+	for _, p := range previews {
+		if p.Err != nil {
+			return p.Err
+		}
+	}
+
+	return nil
+}
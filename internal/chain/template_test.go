@@ -1,9 +1,14 @@
 package chain_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	chainerr "github.com/kyleking/gh-lazydispatch/internal/errors"
 )
 
 func TestInterpolate_VarInputs(t *testing.T) {
@@ -22,7 +27,6 @@ func TestInterpolate_VarInputs(t *testing.T) {
 		{"simple key", "{{ var.version }}", "1.0.0"},
 		{"with spaces", "{{  var.env  }}", "production"},
 		{"in text", "Deploy version {{ var.version }} to {{ var.env }}", "Deploy version 1.0.0 to production"},
-		{"missing key", "{{ var.missing }}", "{{ var.missing }}"},
 	}
 
 	for _, tt := range tests {
@@ -57,7 +61,6 @@ func TestInterpolate_PreviousStep(t *testing.T) {
 	}{
 		{"simple key", "{{ previous.inputs.version }}", "2.0.0"},
 		{"another key", "{{ previous.inputs.tag }}", "v2.0.0"},
-		{"missing key", "{{ previous.inputs.missing }}", "{{ previous.inputs.missing }}"},
 	}
 
 	for _, tt := range tests {
@@ -95,8 +98,6 @@ func TestInterpolate_StepsByIndex(t *testing.T) {
 	}{
 		{"step 0", "{{ steps.0.inputs.key }}", "value0"},
 		{"step 1", "{{ steps.1.inputs.key }}", "value1"},
-		{"missing step", "{{ steps.99.inputs.key }}", "{{ steps.99.inputs.key }}"},
-		{"missing key", "{{ steps.0.inputs.missing }}", "{{ steps.0.inputs.missing }}"},
 	}
 
 	for _, tt := range tests {
@@ -115,18 +116,137 @@ func TestInterpolate_StepsByIndex(t *testing.T) {
 
 func TestInterpolate_MissingKey(t *testing.T) {
 	ctx := &chain.InterpolationContext{
-		Var: map[string]string{"key": "value"},
+		Var:      map[string]string{"key": "value"},
+		Previous: &chain.StepResult{Inputs: map[string]string{}},
+		Steps:    map[int]*chain.StepResult{0: {Inputs: map[string]string{}}},
 	}
 
-	template := "{{ var.missing }}"
+	for _, template := range []string{
+		"{{ var.missing }}",
+		"{{ previous.inputs.missing }}",
+		"{{ steps.0.inputs.missing }}",
+		"{{ steps.99.inputs.key }}",
+		"{{ steps.0.run_id }}",
+		"prefix {{ var.key }} {{ var.missing }}",
+	} {
+		t.Run(template, func(t *testing.T) {
+			_, err := chain.Interpolate(template, ctx)
+			if err == nil || !strings.Contains(err.Error(), "unresolved reference") {
+				t.Errorf("error: got %v, want unresolved reference", err)
+			}
+		})
+	}
+}
 
-	result, err := chain.Interpolate(template, ctx)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestInterpolate_RunMetadata(t *testing.T) {
+	build := &chain.StepResult{
+		Workflow:   "build.yml",
+		RunID:      4242,
+		RunURL:     "https://github.com/o/r/actions/runs/4242",
+		HeadSHA:    "abc123",
+		Conclusion: "success",
+		Outputs:    map[string]string{"image_tag": "app:1.2.3"},
+	}
+	ctx := &chain.InterpolationContext{
+		Previous: build,
+		Steps:    map[int]*chain.StepResult{0: build},
 	}
 
-	if result != template {
-		t.Errorf("expected original template for missing key, got %q", result)
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{{ steps.0.run_id }}", "4242"},
+		{"{{ steps.0.run_url }}", "https://github.com/o/r/actions/runs/4242"},
+		{"{{ steps.0.conclusion }}", "success"},
+		{"{{ steps.0.head_sha }}", "abc123"},
+		{"{{ previous.run_id }}-{{ previous.head_sha }}", "4242-abc123"},
+		{"{{ steps.0.outputs.image_tag }}", "app:1.2.3"},
+		{"{{ previous.outputs.image_tag }}", "app:1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			result, err := chain.Interpolate(tt.template, ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("got %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpolate_EnvAndFilters(t *testing.T) {
+	env := map[string]string{"USER": "octocat", "EMPTY": ""}
+	ctx := &chain.InterpolationContext{
+		Var: map[string]string{"env": "Production", "name": "  my service  "},
+		Env: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		Now: time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"env", "{{ env.USER }}", "octocat"},
+		{"default on missing", `{{ env.TEAM | default "platform" }}`, "platform"},
+		{"default on empty", `{{ env.EMPTY | default 'none' }}`, "none"},
+		{"default on set value", `{{ env.USER | default "x" }}`, "octocat"},
+		{"upper", "{{ var.env | upper }}", "PRODUCTION"},
+		{"lower", "{{ var.env | lower }}", "production"},
+		{"trim", "[{{ var.name | trim }}]", "[my service]"},
+		{"replace", `{{ var.name | trim | replace " " "-" }}`, "my-service"},
+		{"now", "{{ now }}", "2026-03-14T15:09:26Z"},
+		{"date", `release-{{ now | date "2006-01-02" }}`, "release-2026-03-14"},
+		{"chained default", `{{ var.missing | default "Dev" | lower }}`, "dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := chain.Interpolate(tt.template, ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("got %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpolate_Errors(t *testing.T) {
+	ctx := &chain.InterpolationContext{Var: map[string]string{"v": "1.0.0"}}
+
+	tests := []struct {
+		template string
+		wantErr  string
+	}{
+		{"{{ var.v | shout }}", `unknown filter "shout"`},
+		{"{{ var.v | default }}", `filter "default" takes 1 argument(s)`},
+		{`{{ var.v | replace "a" }}`, `filter "replace" takes 2 argument(s)`},
+		{`{{ var.v | date }}`, "not an RFC 3339 time"},
+		{"{{ secrets.TOKEN }}", `unknown reference "secrets.TOKEN"`},
+		{"{{ steps.x.inputs.a }}", "invalid step index"},
+		{"{{ steps.0.artifacts }}", "unknown step field"},
+		{`{{ var.v | default "x }}`, "unterminated string"},
+		{"{{ var.v || upper }}", "empty stage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			_, err := chain.Interpolate(tt.template, ctx)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error: got %v, want containing %q", err, tt.wantErr)
+			}
+		})
 	}
 }
 
@@ -174,3 +294,116 @@ func TestInterpolateInputs(t *testing.T) {
 		}
 	}
 }
+
+func TestInterpolateInputs_Error(t *testing.T) {
+	inputs := map[string]string{
+		"a": "{{ var.ok }}",
+		"b": "{{ var.missing }}",
+	}
+
+	_, err := chain.InterpolateInputs(inputs, &chain.InterpolationContext{Var: map[string]string{"ok": "1"}})
+
+	var interpErr *chainerr.InterpolationError
+	if !errors.As(err, &interpErr) {
+		t.Fatalf("got %v, want *InterpolationError", err)
+	}
+
+	if interpErr.Field != "inputs.b" || interpErr.Value != "{{ var.missing }}" {
+		t.Errorf("got field %q value %q", interpErr.Field, interpErr.Value)
+	}
+}
+
+func TestPreviewInputs(t *testing.T) {
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{
+			{Workflow: "build.yml", Inputs: map[string]string{"version": "{{ var.version }}"}},
+			{Workflow: "deploy.yml", Inputs: map[string]string{
+				"build_run": "{{ previous.run_id }}",
+				"version":   "{{ previous.inputs.version }}",
+				"image":     "{{ previous.outputs.image_tag }}",
+			}},
+			{Workflow: "notify.yml", Inputs: map[string]string{"channel": "{{ var.channel }}"}},
+		},
+	}
+
+	previews := chain.PreviewInputs(chainDef, map[string]string{"version": "1.2.3"})
+
+	if got := previews[1].Inputs["build_run"]; got != "<previous.run_id>" {
+		t.Errorf("build_run: got %q, want placeholder", got)
+	}
+
+	if got := previews[1].Inputs["image"]; got != "<previous.outputs.image_tag>" {
+		t.Errorf("image: got %q, want placeholder", got)
+	}
+
+	if got := previews[1].Inputs["version"]; got != "1.2.3" {
+		t.Errorf("version: got %q, want 1.2.3", got)
+	}
+
+	if previews[0].Err != nil || previews[1].Err != nil {
+		t.Errorf("unexpected errors: %v, %v", previews[0].Err, previews[1].Err)
+	}
+
+	err := chain.PreviewError(previews)
+	if err == nil || !strings.Contains(err.Error(), "step 3 (notify.yml)") || !strings.Contains(err.Error(), "var.channel") {
+		t.Errorf("PreviewError: got %v", err)
+	}
+}
+
+func TestPreviewInputs_StepReferences(t *testing.T) {
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{
+			{ID: "build", Workflow: "build.yml", Needs: []string{}},
+			{ID: "lint", Workflow: "lint.yml", Needs: []string{}},
+			{ID: "deploy", Workflow: "deploy.yml", Needs: []string{"build"}, Inputs: map[string]string{
+				"build_run": "{{ steps.0.run_id }}",
+			}},
+			{Workflow: "notify.yml", Needs: []string{"deploy"}, Inputs: map[string]string{
+				"build_run": "{{ steps.0.run_id }}",
+			}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantErr string
+	}{
+		{name: "ancestor", ref: "{{ steps.0.outputs.tag }}"},
+		{name: "indirect ancestor", ref: "{{ steps.2.run_id }}"},
+		{name: "parallel step", ref: "{{ steps.1.run_id }}", wantErr: "step 1 is not among the steps this step needs"},
+		{name: "later step", ref: "{{ steps.4.run_id }}", wantErr: "step 4 is not among"},
+		{name: "out of range", ref: "{{ steps.99.outputs.tag }}", wantErr: "step 99 is not among"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := *chainDef
+			def.Steps = append([]config.ChainStep(nil), chainDef.Steps...)
+			def.Steps = append(def.Steps, config.ChainStep{
+				Workflow: "report.yml",
+				Needs:    []string{"deploy"},
+				Inputs:   map[string]string{"value": tt.ref},
+			})
+
+			previews := chain.PreviewInputs(&def, nil)
+
+			if previews[3].Err != nil {
+				t.Errorf("indirect ancestor reference: %v", previews[3].Err)
+			}
+
+			err := previews[4].Err
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error: got %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
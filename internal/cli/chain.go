@@ -0,0 +1,429 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/frecency"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+// ChainOptions holds the parsed flags of the chain subcommand.
+type ChainOptions struct {
+	Chain     string
+	Branch    string
+	Variables map[string]string
+	JSON      bool
+}
+
+// ChainEvent is one NDJSON line emitted per chain update with --json.
+type ChainEvent struct {
+	Chain       string            `json:"chain"`
+	Status      chain.ChainStatus `json:"status"`
+	CurrentStep int               `json:"current_step"`
+	Steps       []StepEvent       `json:"steps"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// StepEvent describes a single step within a ChainEvent.
+type StepEvent struct {
+	Index      int               `json:"index"`
+	ID         string            `json:"id,omitempty"`
+	Workflow   string            `json:"workflow"`
+	Status     chain.StepStatus  `json:"status"`
+	RunID      int64             `json:"run_id,omitempty"`
+	RunURL     string            `json:"run_url,omitempty"`
+	Conclusion string            `json:"conclusion,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	// Attempt is the current attempt of a retried step; zero until the step is retried.
+	Attempt  int                 `json:"attempt,omitempty"`
+	Attempts []chain.StepAttempt `json:"attempts,omitempty"`
+}
+
+const chainUsage = `Usage:
+  lazydispatch chain <name> [flags]
+
+Run a chain from .github/lazydispatch.yml to completion without the TUI.
+
+Flags:
+`
+
+func newChainFlagSet(opts *ChainOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("chain", flag.ContinueOnError)
+	fs.StringVar(&opts.Branch, "branch", "", "Branch to run every step on")
+	fs.Var(keyValueFlag(opts.Variables), "var", "Chain variable as key=value (repeatable)")
+	fs.BoolVar(&opts.JSON, "json", false, "Stream chain updates as NDJSON to stdout")
+
+	return fs
+}
+
+// ParseChainArgs parses the arguments of the chain subcommand.
+func ParseChainArgs(args []string) (ChainOptions, error) {
+	opts := ChainOptions{Variables: make(map[string]string)}
+
+	fs := newChainFlagSet(&opts)
+	fs.SetOutput(io.Discard)
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return opts, err
+	}
+
+	if len(positional) != 1 {
+		return opts, errors.New("expected exactly one chain name")
+	}
+
+	opts.Chain = positional[0]
+
+	return opts, nil
+}
+
+// ChainCommand implements `lazydispatch chain` and returns the process exit code.
+func ChainCommand(args []string, stdout, stderr io.Writer, newEnv func() (Env, error)) int {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		fmt.Fprint(stdout, chainUsage+chainFlagHelp())
+		return ExitSuccess
+	}
+
+	opts, err := ParseChainArgs(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n%s%s", err, chainUsage, chainFlagHelp())
+		return ExitUsage
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return ExitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return RunChain(ctx, opts, env)
+}
+
+// RunChain executes the chain described by opts until it completes, fails, or ctx is cancelled.
+func RunChain(ctx context.Context, opts ChainOptions, env Env) int {
+	runner.SetOutput(env.Stderr)
+	defer runner.SetOutput(nil)
+
+	cfg, err := config.Load(env.RepoRoot)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return ExitFailure
+	}
+
+	chainDef, ok := cfg.GetChain(opts.Chain)
+	if !ok {
+		fmt.Fprintf(env.Stderr, "Error: no chain %q in %s (available: %s)\n",
+			opts.Chain, config.ConfigFilename, strings.Join(cfg.ChainNames(), ", "))
+
+		return ExitUsage
+	}
+
+	variables, validationErrs := ResolveChainVariables(chainDef, opts.Variables)
+	if len(validationErrs) > 0 {
+		printValidationErrors(env, validationErrs)
+		return ExitUsage
+	}
+
+	// Catch template errors before any step is dispatched rather than halfway through the chain.
+	if err := chain.PreviewError(chain.PreviewInputs(chainDef, variables)); err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return ExitUsage
+	}
+
+	workflows, err := workflow.Discover(env.RepoRoot)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: discovering workflows: %v\n", err)
+		return ExitFailure
+	}
+
+	executor := chain.NewExecutor(env.Client, discardWatcher{}, opts.Chain, chainDef)
+	executor.SetCorrelationInputs(cfg.CorrelationInputs(workflows))
+	if artifacts, ok := env.Client.(chain.ArtifactClient); ok {
+		executor.SetOutputSource(chain.NewOutputFetcher(artifacts, logs.DefaultCacheDir()))
+	}
+	if err := executor.Start(variables, opts.Branch); err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return ExitFailure
+	}
+
+	progress := newChainProgress(env, chainDef, opts.JSON)
+	updates := executor.Updates()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			executor.Stop()
+			fmt.Fprintln(env.Stderr, "Interrupted, stopping chain")
+
+			break loop
+		case update, ok := <-updates:
+			if !ok {
+				break loop
+			}
+
+			progress.report(update.State)
+		}
+	}
+
+	final := executor.State()
+	progress.report(final)
+
+	if env.History != nil {
+		env.History.RecordChain(env.Repo, opts.Chain, opts.Branch, variables, historyStepResults(final))
+		env.saveHistory()
+	}
+
+	switch final.Status {
+	case chain.ChainCompleted:
+		return ExitSuccess
+	case chain.ChainFailed:
+		if final.Error != nil {
+			fmt.Fprintf(env.Stderr, "Error: %v\n", final.Error)
+		}
+
+		return ExitFailure
+	default:
+		return ExitFailure
+	}
+}
+
+// ResolveChainVariables applies defaults and validates the given values against the chain's
+// variable definitions, mirroring the TUI's variable modal.
+func ResolveChainVariables(chainDef *config.Chain, values map[string]string) (map[string]string, map[string][]string) {
+	resolved := make(map[string]string, len(chainDef.Variables))
+	errs := make(map[string][]string)
+	defined := make(map[string]bool, len(chainDef.Variables))
+
+	for _, v := range chainDef.Variables {
+		defined[v.Name] = true
+		resolved[v.Name] = v.Default
+	}
+
+	for name, value := range values {
+		if !defined[name] {
+			errs[name] = append(errs[name], "unknown variable")
+			continue
+		}
+
+		resolved[name] = value
+	}
+
+	for _, v := range chainDef.Variables {
+		value := resolved[v.Name]
+
+		if v.Required && strings.TrimSpace(value) == "" {
+			errs[v.Name] = append(errs[v.Name], "value is required")
+		}
+
+		if v.Type == "choice" && value != "" && len(v.Options) > 0 && !slices.Contains(v.Options, value) {
+			errs[v.Name] = append(errs[v.Name], "must be one of: "+strings.Join(v.Options, ", "))
+		}
+	}
+
+	return resolved, errs
+}
+
+// chainProgress renders chain updates as NDJSON or as one line per step transition.
+type chainProgress struct {
+	env         Env
+	def         *config.Chain
+	json        bool
+	lastStatus  map[int]chain.StepStatus
+	lastAttempt map[int]int
+	lastChain   chain.ChainStatus
+}
+
+func newChainProgress(env Env, def *config.Chain, jsonOutput bool) *chainProgress {
+	return &chainProgress{
+		env:         env,
+		def:         def,
+		json:        jsonOutput,
+		lastStatus:  make(map[int]chain.StepStatus),
+		lastAttempt: make(map[int]int),
+	}
+}
+
+func (p *chainProgress) report(state chain.ChainState) {
+	event := NewChainEvent(state, p.def)
+
+	if p.json {
+		if p.changed(event) {
+			writeJSON(p.env, event)
+		}
+
+		p.remember(event)
+
+		return
+	}
+
+	for _, step := range event.Steps {
+		if p.lastStatus[step.Index] == step.Status && p.lastAttempt[step.Index] == step.Attempt {
+			continue
+		}
+
+		line := fmt.Sprintf("[%d/%d] %s: %s", step.Index+1, len(event.Steps), step.Workflow, step.Status)
+		if step.Conclusion != "" {
+			line += " (" + step.Conclusion + ")"
+		}
+
+		if step.Reason != "" {
+			line += " (" + step.Reason + ")"
+		}
+
+		if step.Attempt > 0 {
+			line += fmt.Sprintf(" (attempt %d)", step.Attempt)
+		}
+
+		if step.RunURL != "" {
+			line += " " + step.RunURL
+		} else if step.RunID != 0 {
+			line += fmt.Sprintf(" run %d", step.RunID)
+		}
+
+		fmt.Fprintln(p.env.Stdout, line)
+	}
+
+	if event.Status != p.lastChain && (event.Status == chain.ChainCompleted || event.Status == chain.ChainFailed) {
+		fmt.Fprintf(p.env.Stdout, "Chain %s %s\n", event.Chain, event.Status)
+	}
+
+	p.remember(event)
+}
+
+func (p *chainProgress) changed(event ChainEvent) bool {
+	if event.Status != p.lastChain {
+		return true
+	}
+
+	for _, step := range event.Steps {
+		if p.lastStatus[step.Index] != step.Status || p.lastAttempt[step.Index] != step.Attempt {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *chainProgress) remember(event ChainEvent) {
+	p.lastChain = event.Status
+	for _, step := range event.Steps {
+		p.lastStatus[step.Index] = step.Status
+		p.lastAttempt[step.Index] = step.Attempt
+	}
+}
+
+// NewChainEvent converts a chain state into its machine-readable form.
+// def supplies workflow names for steps that have not produced a result yet.
+func NewChainEvent(state chain.ChainState, def *config.Chain) ChainEvent {
+	event := ChainEvent{
+		Chain:       state.ChainName,
+		Status:      state.Status,
+		CurrentStep: state.CurrentStep,
+		Steps:       make([]StepEvent, len(state.StepStatuses)),
+	}
+
+	if state.Error != nil {
+		event.Error = state.Error.Error()
+	}
+
+	for i, status := range state.StepStatuses {
+		step := StepEvent{Index: i, Status: status}
+		if def != nil && i < len(def.Steps) {
+			step.ID = def.Steps[i].ID
+			step.Workflow = def.Steps[i].Workflow
+		}
+
+		if result, ok := state.StepResults[i]; ok && result != nil {
+			step.Workflow = result.Workflow
+			step.RunID = result.RunID
+			step.RunURL = result.RunURL
+			step.Conclusion = result.Conclusion
+			step.Reason = result.SkipReason
+			step.Outputs = result.Outputs
+			step.Attempts = result.Attempts
+			step.Attempt = currentAttempt(result, status)
+		}
+
+		event.Steps[i] = step
+	}
+
+	return event
+}
+
+// currentAttempt numbers the attempt a step is on, or returns zero if it has not been retried.
+func currentAttempt(result *chain.StepResult, status chain.StepStatus) int {
+	attempt := len(result.Attempts)
+	if status == chain.StepRunning || status == chain.StepWaiting {
+		attempt++
+	}
+
+	if attempt < 2 {
+		return 0
+	}
+
+	return attempt
+}
+
+func historyStepResults(state chain.ChainState) []frecency.ChainStepResult {
+	indices := make([]int, 0, len(state.StepResults))
+	for i := range state.StepResults {
+		indices = append(indices, i)
+	}
+
+	if len(indices) == 0 {
+		return nil
+	}
+
+	sort.Ints(indices)
+
+	results := make([]frecency.ChainStepResult, indices[len(indices)-1]+1)
+	for _, i := range indices {
+		if r := state.StepResults[i]; r != nil {
+			results[i] = frecency.ChainStepResult{
+				Workflow:   r.Workflow,
+				RunID:      r.RunID,
+				Status:     string(r.Status),
+				Conclusion: r.Conclusion,
+			}
+		}
+	}
+
+	return results
+}
+
+func chainFlagHelp() string {
+	var b strings.Builder
+
+	fs := newChainFlagSet(&ChainOptions{Variables: make(map[string]string)})
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+
+	return b.String()
+}
+
+// discardWatcher satisfies chain.RunWatcher without polling; the chain executor
+// waits on runs itself and the CLI has no live runs view to feed.
+type discardWatcher struct{}
+
+func (discardWatcher) Watch(int64, string)               {}
+func (discardWatcher) Unwatch(int64)                     {}
+func (discardWatcher) Updates() <-chan watcher.RunUpdate { return nil }
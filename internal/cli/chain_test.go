@@ -0,0 +1,199 @@
+package cli_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/cli"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+)
+
+const chainConfig = `version: 1
+chains:
+  release:
+    variables:
+      - name: environment
+        type: choice
+        options: [staging, prod]
+        default: staging
+      - name: version
+        required: true
+    steps:
+      - workflow: build.yml
+        wait_for: none
+        inputs:
+          version: "{{ var.version }}"
+      - workflow: deploy.yml
+        wait_for: none
+        inputs:
+          environment: "{{ var.environment }}"
+`
+
+func writeChainConfig(t *testing.T, root string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(root, config.ConfigFilename), []byte(chainConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseChainArgs(t *testing.T) {
+	opts, err := cli.ParseChainArgs([]string{"release", "--branch", "main", "--var", "version=1.0", "--json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.Chain != "release" || opts.Branch != "main" || opts.Variables["version"] != "1.0" || !opts.JSON {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	if _, err := cli.ParseChainArgs([]string{"--branch", "main"}); err == nil {
+		t.Error("expected error without chain name")
+	}
+}
+
+func TestResolveChainVariables(t *testing.T) {
+	chainDef := &config.Chain{
+		Variables: []config.ChainVariable{
+			{Name: "environment", Type: "choice", Options: []string{"staging", "prod"}, Default: "staging"},
+			{Name: "version", Type: "string", Required: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]string
+		wantErrs []string
+		wantEnv  string
+	}{
+		{name: "defaults applied", values: map[string]string{"version": "1"}, wantEnv: "staging"},
+		{name: "override", values: map[string]string{"version": "1", "environment": "prod"}, wantEnv: "prod"},
+		{name: "missing required", values: nil, wantErrs: []string{"version"}},
+		{name: "invalid choice", values: map[string]string{"version": "1", "environment": "dev"}, wantErrs: []string{"environment"}},
+		{name: "unknown variable", values: map[string]string{"version": "1", "region": "eu"}, wantErrs: []string{"region"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, errs := cli.ResolveChainVariables(chainDef, tt.values)
+
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("errors = %v, want keys %v", errs, tt.wantErrs)
+			}
+
+			for _, name := range tt.wantErrs {
+				if _, ok := errs[name]; !ok {
+					t.Errorf("missing error for %s: %v", name, errs)
+				}
+			}
+
+			if tt.wantEnv != "" && resolved["environment"] != tt.wantEnv {
+				t.Errorf("environment = %q, want %q", resolved["environment"], tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestRunChain_NDJSON(t *testing.T) {
+	client := testutil.NewMockGitHubClient()
+	client.LatestByWorkflow["build.yml"] = 101
+	client.LatestByWorkflow["deploy.yml"] = 102
+
+	env, stdout, stderr := newTestEnv(t, client)
+	writeChainConfig(t, env.RepoRoot)
+
+	code := cli.RunChain(context.Background(), cli.ChainOptions{
+		Chain:     "release",
+		Branch:    "main",
+		Variables: map[string]string{"version": "1.2.3"},
+		JSON:      true,
+	}, env)
+	if code != cli.ExitSuccess {
+		t.Fatalf("exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	var events []cli.ChainEvent
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var event cli.ChainEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) < 2 {
+		t.Fatalf("expected several events, got %d", len(events))
+	}
+
+	last := events[len(events)-1]
+	if last.Status != chain.ChainCompleted {
+		t.Errorf("final status = %s, want %s", last.Status, chain.ChainCompleted)
+	}
+
+	if last.Steps[0].RunID != 101 || last.Steps[1].RunID != 102 {
+		t.Errorf("unexpected run IDs: %+v", last.Steps)
+	}
+
+	history := env.History.TopForRepo("owner/repo", "", 0)
+	if len(history) != 1 || len(history[0].StepResults) != 2 {
+		t.Errorf("chain not recorded in history: %+v", history)
+	}
+}
+
+func TestRunChain_Failure(t *testing.T) {
+	client := testutil.NewMockGitHubClient()
+
+	env, stdout, stderr := newTestEnv(t, client)
+	writeChainConfig(t, env.RepoRoot)
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddGHWorkflowRunError("build.yml", "main", "HTTP 404", errors.New("exit status 1"))
+	runner.SetExecutor(mockExec)
+
+	code := cli.RunChain(context.Background(), cli.ChainOptions{
+		Chain:     "release",
+		Branch:    "main",
+		Variables: map[string]string{"version": "1.2.3"},
+	}, env)
+	if code != cli.ExitFailure {
+		t.Errorf("exit code = %d, want %d", code, cli.ExitFailure)
+	}
+
+	if !strings.Contains(stdout.String(), "Chain release failed") {
+		t.Errorf("stdout missing failure line: %s", stdout.String())
+	}
+
+	if !strings.Contains(stderr.String(), "build.yml") {
+		t.Errorf("stderr missing dispatch error: %s", stderr.String())
+	}
+}
+
+func TestRunChain_Validation(t *testing.T) {
+	env, _, stderr := newTestEnv(t, testutil.NewMockGitHubClient())
+	writeChainConfig(t, env.RepoRoot)
+
+	if code := cli.RunChain(context.Background(), cli.ChainOptions{Chain: "release"}, env); code != cli.ExitUsage {
+		t.Errorf("exit code = %d, want %d", code, cli.ExitUsage)
+	}
+
+	if !strings.Contains(stderr.String(), "version: value is required") {
+		t.Errorf("stderr missing validation error: %s", stderr.String())
+	}
+
+	if code := cli.RunChain(context.Background(), cli.ChainOptions{Chain: "missing"}, env); code != cli.ExitUsage {
+		t.Errorf("unknown chain exit code = %d, want %d", code, cli.ExitUsage)
+	}
+}
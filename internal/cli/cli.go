@@ -0,0 +1,110 @@
+// Package cli implements the non-interactive lazydispatch subcommands for scripts and CI.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kyleking/gh-lazydispatch/internal/frecency"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+)
+
+// Exit codes returned by the subcommands.
+const (
+	ExitSuccess      = 0 // dispatched (and, when watching, concluded successfully)
+	ExitFailure      = 1 // dispatch, API, or run failure
+	ExitUsage        = 2 // invalid flags or input validation errors
+	ExitUnidentified = 3 // dispatched, but the run could not be identified to report or watch it
+)
+
+// GitHubClient defines the GitHub API operations needed by the subcommands.
+type GitHubClient interface {
+	runner.GitHubClient
+	GetWorkflowRun(runID int64) (*github.WorkflowRun, error)
+	GetWorkflowRunJobs(runID int64) ([]github.Job, error)
+	Owner() string
+	Repo() string
+}
+
+// Env holds the dependencies shared by the subcommands.
+type Env struct {
+	Stdout      io.Writer
+	Stderr      io.Writer
+	RepoRoot    string
+	Repo        string
+	Client      GitHubClient
+	History     *frecency.Store
+	HistoryPath string
+}
+
+// NewEnv builds an Env for the current directory and repository.
+func NewEnv() (Env, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Env{}, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	repo, err := runner.DetectRepo()
+	if err != nil {
+		return Env{}, err
+	}
+
+	client, err := github.NewClient(repo)
+	if err != nil {
+		return Env{}, err
+	}
+
+	history, err := frecency.Load()
+	if err != nil {
+		history = frecency.NewStore()
+	}
+
+	return Env{
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+		RepoRoot:    cwd,
+		Repo:        repo,
+		Client:      client,
+		History:     history,
+		HistoryPath: frecency.CachePath(),
+	}, nil
+}
+
+func (e Env) saveHistory() {
+	if e.History == nil || e.HistoryPath == "" {
+		return
+	}
+
+	if err := e.History.SaveTo(e.HistoryPath); err != nil {
+		fmt.Fprintf(e.Stderr, "Warning: could not save history: %v\n", err)
+	}
+}
+
+// keyValueFlag collects repeated key=value flags.
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+
+	f[key] = val
+
+	return nil
+}
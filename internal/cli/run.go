@@ -0,0 +1,406 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/frecency"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/validation"
+	"github.com/kyleking/gh-lazydispatch/internal/watcher"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+// statusDispatched is reported for a dispatch whose run could not be identified.
+const statusDispatched = "dispatched"
+
+// RunOptions holds the parsed flags of the run subcommand.
+type RunOptions struct {
+	Workflow    string
+	Ref         string
+	Inputs      map[string]string
+	FromHistory int
+	Watch       bool
+	JSON        bool
+}
+
+// RunResult is the machine-readable summary printed with --json.
+type RunResult struct {
+	Workflow   string              `json:"workflow"`
+	Ref        string              `json:"ref,omitempty"`
+	Inputs     map[string]string   `json:"inputs"`
+	RunID      int64               `json:"run_id,omitempty"`
+	RunURL     string              `json:"run_url,omitempty"`
+	Status     string              `json:"status,omitempty"`
+	Conclusion string              `json:"conclusion,omitempty"`
+	Errors     map[string][]string `json:"errors,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+const runUsage = `Usage:
+  lazydispatch run <workflow> [flags]
+
+Dispatch a workflow without the TUI. <workflow> is a file name (deploy.yml),
+a file name without extension (deploy), or the workflow's name.
+
+Flags:
+`
+
+// ParseRunArgs parses the arguments of the run subcommand.
+func ParseRunArgs(args []string) (RunOptions, error) {
+	opts := RunOptions{Inputs: make(map[string]string)}
+
+	fs := newRunFlagSet(&opts)
+	fs.SetOutput(io.Discard)
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return opts, err
+	}
+
+	if len(positional) != 1 {
+		return opts, errors.New("expected exactly one workflow argument")
+	}
+
+	if opts.FromHistory < 0 {
+		return opts, errors.New("--from-history must be positive")
+	}
+
+	opts.Workflow = positional[0]
+
+	return opts, nil
+}
+
+// RunCommand implements `lazydispatch run` and returns the process exit code.
+// The environment is only built once the arguments parse, so --help works outside a repository.
+func RunCommand(args []string, stdout, stderr io.Writer, newEnv func() (Env, error)) int {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		fmt.Fprint(stdout, runUsage+runFlagHelp())
+		return ExitSuccess
+	}
+
+	opts, err := ParseRunArgs(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n%s%s", err, runUsage, runFlagHelp())
+		return ExitUsage
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return ExitFailure
+	}
+
+	return Run(opts, env)
+}
+
+// Run dispatches the workflow described by opts.
+func Run(opts RunOptions, env Env) int {
+	runner.SetOutput(env.Stderr)
+	defer runner.SetOutput(nil)
+
+	result := RunResult{Workflow: opts.Workflow}
+
+	fail := func(code int, err error) int {
+		result.Error = err.Error()
+		if opts.JSON {
+			writeJSON(env, result)
+		}
+
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+
+		return code
+	}
+
+	workflows, err := workflow.Discover(env.RepoRoot)
+	if err != nil {
+		return fail(ExitFailure, fmt.Errorf("discovering workflows: %w", err))
+	}
+
+	wf, err := findWorkflow(workflows, opts.Workflow)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	result.Workflow = wf.Filename
+
+	ref, inputs, err := resolveInputs(wf, opts, env)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	result.Ref = ref
+	result.Inputs = inputs
+
+	var ctx rule.Context
+	if validation.UsesLatestTag(wf) {
+		ctx.LatestTag, ctx.LatestTagErr = validation.LatestTag(context.Background(), nil)
+	}
+
+	if validationErrs := validation.ValidateInputs(wf, inputs, ctx); len(validationErrs) > 0 {
+		result.Errors = validationErrs
+		result.Error = "input validation failed"
+
+		if opts.JSON {
+			writeJSON(env, result)
+		}
+
+		printValidationErrors(env, validationErrs)
+
+		return ExitUsage
+	}
+
+	wfdConfig, err := config.Load(env.RepoRoot)
+	if err != nil {
+		return fail(ExitFailure, err)
+	}
+
+	cfg := runner.RunConfig{
+		Workflow:         wf.Filename,
+		Branch:           ref,
+		Inputs:           inputs,
+		CorrelationInput: wfdConfig.CorrelationInputFor(wf),
+		InputTypes:       wf.InputTypes(),
+	}
+
+	runID, err := runner.ExecuteAndGetRunID(cfg, env.Client)
+
+	dispatched := err == nil || errors.Is(err, runner.ErrRunNotIdentified)
+	if !dispatched {
+		return fail(ExitFailure, err)
+	}
+
+	if env.History != nil {
+		env.History.Record(env.Repo, wf.Filename, ref, inputs)
+		env.saveHistory()
+	}
+
+	if err != nil {
+		result.Status = statusDispatched
+		return fail(ExitUnidentified, err)
+	}
+
+	result.RunID = runID
+
+	if run, err := env.Client.GetWorkflowRun(runID); err == nil && run != nil {
+		result.RunURL = run.HTMLURL
+		result.Status = run.Status
+		result.Conclusion = run.Conclusion
+	}
+
+	if !opts.JSON {
+		fmt.Fprintf(env.Stdout, "Dispatched %s (run %d)\n", wf.Filename, runID)
+
+		if result.RunURL != "" {
+			fmt.Fprintln(env.Stdout, result.RunURL)
+		}
+	}
+
+	if !opts.Watch {
+		if opts.JSON {
+			writeJSON(env, result)
+		}
+
+		return ExitSuccess
+	}
+
+	final := watchRun(env, runID, wf.Filename, !opts.JSON)
+	if final.HTMLURL != "" {
+		result.RunURL = final.HTMLURL
+	}
+
+	result.Status = final.Status
+	result.Conclusion = final.Conclusion
+
+	if final.LastError != nil {
+		return fail(ExitFailure, final.LastError)
+	}
+
+	if opts.JSON {
+		writeJSON(env, result)
+	} else {
+		fmt.Fprintf(env.Stdout, "Run %d %s\n", runID, conclusionLabel(final))
+	}
+
+	if !final.IsSuccess() {
+		return ExitFailure
+	}
+
+	return ExitSuccess
+}
+
+func findWorkflow(workflows []workflow.WorkflowFile, name string) (workflow.WorkflowFile, error) {
+	for _, wf := range workflows {
+		base := strings.TrimSuffix(wf.Filename, filepath.Ext(wf.Filename))
+		if wf.Filename == name || base == name || wf.Name == name {
+			return wf, nil
+		}
+	}
+
+	names := make([]string, len(workflows))
+	for i, wf := range workflows {
+		names[i] = wf.Filename
+	}
+
+	return workflow.WorkflowFile{}, fmt.Errorf("no dispatchable workflow %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// resolveInputs layers workflow defaults, the selected history entry, and explicit flags.
+func resolveInputs(wf workflow.WorkflowFile, opts RunOptions, env Env) (string, map[string]string, error) {
+	ref := ""
+	inputs := make(map[string]string)
+
+	for name, def := range wf.GetInputs() {
+		inputs[name] = def.Default
+	}
+
+	if opts.FromHistory > 0 {
+		if env.History == nil {
+			return "", nil, errors.New("no history available")
+		}
+
+		entries := env.History.TopForRepo(env.Repo, wf.Filename, 0)
+		if opts.FromHistory > len(entries) {
+			return "", nil, fmt.Errorf("history has %d entries for %s, cannot use entry %d", len(entries), wf.Filename, opts.FromHistory)
+		}
+
+		entry := entries[opts.FromHistory-1]
+		if entry.Type != frecency.EntryTypeWorkflow {
+			return "", nil, fmt.Errorf("history entry %d is not a workflow run", opts.FromHistory)
+		}
+
+		ref = entry.Branch
+		for k, v := range entry.Inputs {
+			inputs[k] = v
+		}
+	}
+
+	if opts.Ref != "" {
+		ref = opts.Ref
+	}
+
+	for k, v := range opts.Inputs {
+		inputs[k] = v
+	}
+
+	return ref, inputs, nil
+}
+
+// watchRun blocks until the run completes, optionally printing status transitions.
+func watchRun(env Env, runID int64, workflowName string, verbose bool) watcher.WatchedRun {
+	w := watcher.NewWatcher(env.Client)
+	defer w.Stop()
+
+	w.Watch(runID, workflowName)
+
+	lastStatus := ""
+
+	for update := range w.Updates() {
+		if update.RunID != runID {
+			continue
+		}
+
+		if update.Error != nil {
+			return watcher.WatchedRun{RunID: runID, LastError: update.Error}
+		}
+
+		if verbose && update.Run.Status != lastStatus {
+			fmt.Fprintf(env.Stdout, "  %s\n", update.Run.Status)
+			lastStatus = update.Run.Status
+		}
+
+		if !update.Run.IsActive() {
+			return update.Run
+		}
+	}
+
+	return watcher.WatchedRun{RunID: runID, LastError: errors.New("watcher stopped")}
+}
+
+func conclusionLabel(run watcher.WatchedRun) string {
+	if run.Conclusion != "" {
+		return run.Conclusion
+	}
+
+	return run.Status
+}
+
+func printValidationErrors(env Env, errs map[string][]string) {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(env.Stderr, "Error: input validation failed")
+
+	for _, name := range names {
+		for _, msg := range errs[name] {
+			fmt.Fprintf(env.Stderr, "  %s: %s\n", name, msg)
+		}
+	}
+}
+
+func writeJSON(env Env, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: encoding JSON: %v\n", err)
+		return
+	}
+
+	fmt.Fprintln(env.Stdout, string(data))
+}
+
+func newRunFlagSet(opts *RunOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.StringVar(&opts.Ref, "ref", "", "Branch or tag to run the workflow on")
+	fs.Var(keyValueFlag(opts.Inputs), "input", "Workflow input as key=value (repeatable)")
+	fs.IntVar(&opts.FromHistory, "from-history", 0, "Start from the Nth most frecent history entry for the workflow")
+	fs.BoolVar(&opts.Watch, "watch", false, "Wait for the run to complete")
+	fs.BoolVar(&opts.JSON, "json", false, "Print a JSON summary to stdout")
+
+	return fs
+}
+
+func runFlagHelp() string {
+	var b strings.Builder
+
+	fs := newRunFlagSet(&RunOptions{Inputs: make(map[string]string)})
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+
+	return b.String()
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// Ensure the real client satisfies the interface.
+var _ GitHubClient = (*github.Client)(nil)
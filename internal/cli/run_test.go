@@ -0,0 +1,304 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kyleking/gh-lazydispatch/internal/cli"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/frecency"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+)
+
+const deployWorkflow = `name: Deploy
+on:
+  workflow_dispatch:
+    inputs:
+      environment:
+        type: choice
+        options: [staging, prod]
+        default: staging
+      version:
+        type: string
+        # lazydispatch:validate:regex:^v[0-9]+
+        default: v1
+      notes:
+        # lazydispatch:validate:when:version=v0:required
+        type: string
+`
+
+func setupRepo(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "deploy.yml"), []byte(deployWorkflow), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func newTestEnv(t *testing.T, client *testutil.MockGitHubClient) (cli.Env, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.DefaultResult = &exec.CommandResult{}
+	runner.SetExecutor(mockExec)
+	t.Cleanup(func() { runner.SetExecutor(nil) })
+
+	var stdout, stderr bytes.Buffer
+
+	return cli.Env{
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+		RepoRoot: setupRepo(t),
+		Repo:     "owner/repo",
+		Client:   client,
+		History:  frecency.NewStore(),
+	}, &stdout, &stderr
+}
+
+func TestParseRunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantErr     bool
+		wantOptions cli.RunOptions
+	}{
+		{
+			name: "flags after workflow",
+			args: []string{"deploy.yml", "--ref", "main", "--input", "a=1", "--input", "b=x=y", "--watch", "--json"},
+			wantOptions: cli.RunOptions{
+				Workflow: "deploy.yml", Ref: "main", Inputs: map[string]string{"a": "1", "b": "x=y"}, Watch: true, JSON: true,
+			},
+		},
+		{
+			name:        "flags before workflow",
+			args:        []string{"--from-history", "2", "deploy"},
+			wantOptions: cli.RunOptions{Workflow: "deploy", FromHistory: 2, Inputs: map[string]string{}},
+		},
+		{name: "missing workflow", args: []string{"--ref", "main"}, wantErr: true},
+		{name: "two workflows", args: []string{"a.yml", "b.yml"}, wantErr: true},
+		{name: "malformed input", args: []string{"deploy.yml", "--input", "novalue"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := cli.ParseRunArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if opts.Workflow != tt.wantOptions.Workflow || opts.Ref != tt.wantOptions.Ref ||
+				opts.FromHistory != tt.wantOptions.FromHistory || opts.Watch != tt.wantOptions.Watch ||
+				opts.JSON != tt.wantOptions.JSON {
+				t.Errorf("options = %+v, want %+v", opts, tt.wantOptions)
+			}
+
+			for k, v := range tt.wantOptions.Inputs {
+				if opts.Inputs[k] != v {
+					t.Errorf("input %s = %q, want %q", k, opts.Inputs[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       cli.RunOptions
+		conclusion string
+		wantCode   int
+		wantStderr string
+	}{
+		{
+			name:     "dispatch without watch",
+			opts:     cli.RunOptions{Workflow: "deploy.yml", Ref: "main"},
+			wantCode: cli.ExitSuccess,
+		},
+		{
+			name:       "watch success",
+			opts:       cli.RunOptions{Workflow: "deploy", Ref: "main", Watch: true},
+			conclusion: github.ConclusionSuccess,
+			wantCode:   cli.ExitSuccess,
+		},
+		{
+			name:       "watch failure",
+			opts:       cli.RunOptions{Workflow: "Deploy", Ref: "main", Watch: true},
+			conclusion: github.ConclusionFailure,
+			wantCode:   cli.ExitFailure,
+		},
+		{
+			name:       "unknown workflow",
+			opts:       cli.RunOptions{Workflow: "missing.yml"},
+			wantCode:   cli.ExitUsage,
+			wantStderr: "no dispatchable workflow",
+		},
+		{
+			name:       "invalid choice",
+			opts:       cli.RunOptions{Workflow: "deploy.yml", Inputs: map[string]string{"environment": "dev"}},
+			wantCode:   cli.ExitUsage,
+			wantStderr: "environment: must be one of",
+		},
+		{
+			name:       "validation rule",
+			opts:       cli.RunOptions{Workflow: "deploy.yml", Inputs: map[string]string{"version": "1.0"}},
+			wantCode:   cli.ExitUsage,
+			wantStderr: "version: must match pattern",
+		},
+		{
+			name:       "conditional rule",
+			opts:       cli.RunOptions{Workflow: "deploy.yml", Inputs: map[string]string{"version": "v0"}},
+			wantCode:   cli.ExitUsage,
+			wantStderr: "notes: value is required when version=v0",
+		},
+		{
+			name:       "unknown input",
+			opts:       cli.RunOptions{Workflow: "deploy.yml", Inputs: map[string]string{"bogus": "1"}},
+			wantCode:   cli.ExitUsage,
+			wantStderr: "bogus: unknown input",
+		},
+		{
+			name:       "history entry out of range",
+			opts:       cli.RunOptions{Workflow: "deploy.yml", FromHistory: 3},
+			wantCode:   cli.ExitUsage,
+			wantStderr: "cannot use entry 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewMockGitHubClient()
+			client.LatestID = 77
+			client.WithRun(&github.WorkflowRun{
+				ID:         77,
+				Status:     github.StatusCompleted,
+				Conclusion: tt.conclusion,
+				HTMLURL:    "https://github.com/owner/repo/actions/runs/77",
+			})
+
+			env, stdout, stderr := newTestEnv(t, client)
+
+			code := cli.Run(tt.opts, env)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+
+			if tt.wantStderr != "" && !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr %q missing %q", stderr.String(), tt.wantStderr)
+			}
+
+			if tt.wantCode == cli.ExitSuccess && !strings.Contains(stdout.String(), "run 77") {
+				t.Errorf("stdout %q missing run ID", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_JSONFromHistory(t *testing.T) {
+	client := testutil.NewMockGitHubClient()
+	client.LatestID = 88
+	client.WithRun(&github.WorkflowRun{ID: 88, Status: github.StatusCompleted, Conclusion: github.ConclusionSuccess})
+
+	env, stdout, _ := newTestEnv(t, client)
+	env.History.Record("owner/repo", "deploy.yml", "release", map[string]string{"environment": "prod", "version": "v2"})
+
+	code := cli.Run(cli.RunOptions{
+		Workflow:    "deploy.yml",
+		FromHistory: 1,
+		Inputs:      map[string]string{"version": "v3"},
+		Watch:       true,
+		JSON:        true,
+	}, env)
+	if code != cli.ExitSuccess {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	var result cli.RunResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("stdout is not a single JSON document: %v\n%s", err, stdout.String())
+	}
+
+	if result.Ref != "release" || result.Inputs["environment"] != "prod" || result.Inputs["version"] != "v3" {
+		t.Errorf("history and flags not layered: %+v", result)
+	}
+
+	if result.RunID != 88 || result.Conclusion != github.ConclusionSuccess {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestRunCommand_Help(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	newEnv := func() (cli.Env, error) {
+		t.Fatal("environment should not be built for --help")
+		return cli.Env{}, nil
+	}
+
+	if code := cli.RunCommand([]string{"--help"}, &stdout, &stderr, newEnv); code != cli.ExitSuccess {
+		t.Errorf("exit code = %d", code)
+	}
+
+	if !strings.Contains(stdout.String(), "lazydispatch run <workflow>") {
+		t.Errorf("help missing usage: %s", stdout.String())
+	}
+
+	if code := cli.RunCommand(nil, &stdout, &stderr, newEnv); code != cli.ExitUsage {
+		t.Errorf("exit code without workflow = %d, want %d", code, cli.ExitUsage)
+	}
+}
+
+// unlistedRunsClient never lists the dispatched run, so it cannot be identified.
+type unlistedRunsClient struct {
+	*testutil.MockGitHubClient
+}
+
+func (c *unlistedRunsClient) ListWorkflowRuns(github.RunFilter) ([]github.WorkflowRun, error) {
+	return nil, nil
+}
+
+func TestRun_RunNotIdentified(t *testing.T) {
+	defaults := runner.DefaultCorrelationOptions
+	runner.DefaultCorrelationOptions = runner.CorrelationOptions{MaxAttempts: 1}
+
+	defer func() { runner.DefaultCorrelationOptions = defaults }()
+
+	env, stdout, _ := newTestEnv(t, testutil.NewMockGitHubClient())
+	env.Client = &unlistedRunsClient{MockGitHubClient: testutil.NewMockGitHubClient()}
+
+	code := cli.Run(cli.RunOptions{Workflow: "deploy.yml", Ref: "main", Watch: true, JSON: true}, env)
+	if code != cli.ExitUnidentified {
+		t.Fatalf("exit code = %d, want %d", code, cli.ExitUnidentified)
+	}
+
+	var result cli.RunResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("stdout is not a single JSON document: %v\n%s", err, stdout.String())
+	}
+
+	if result.Status != "dispatched" || result.RunID != 0 || result.Error == "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if entries := env.History.TopForRepo("owner/repo", "deploy.yml", 10); len(entries) != 1 {
+		t.Errorf("history entries = %d, want the dispatch recorded", len(entries))
+	}
+}
@@ -2,11 +2,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"time"
 
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,6 +22,91 @@ const ConfigFilename = ".github/lazydispatch.yml"
 type WfdConfig struct {
 	Version int              `yaml:"version"`
 	Chains  map[string]Chain `yaml:"chains"`
+	Logs    LogsConfig       `yaml:"logs"`
+	// CorrelationInput names a workflow_dispatch input that receives a generated ID on
+	// each dispatch, so workflows echoing it in their run-name are identified exactly.
+	CorrelationInput string `yaml:"correlation_input"`
+}
+
+// LogsConfig customizes how the log viewer classifies and filters log lines.
+type LogsConfig struct {
+	Levels     LogLevelPatterns  `yaml:"levels"`
+	Filters    []LogFilterPreset `yaml:"filters"`
+	CacheMaxMB int               `yaml:"cache_max_mb"` // 0 keeps the default size cap
+}
+
+// LogLevelPatterns lists regular expressions that mark unmarked log lines as
+// errors, warnings, or debug output, in addition to the built-in wording rules.
+type LogLevelPatterns struct {
+	Error   []string `yaml:"error"`
+	Warning []string `yaml:"warning"`
+	Debug   []string `yaml:"debug"`
+}
+
+// LogFilterPreset is a named log filter the log viewer cycles through with `f`.
+type LogFilterPreset struct {
+	Name          string `yaml:"name"`
+	Level         string `yaml:"level"` // "all", "errors", or "warnings"; defaults to all
+	Search        string `yaml:"search"`
+	Regex         bool   `yaml:"regex"`
+	CaseSensitive bool   `yaml:"case_sensitive"`
+	Step          string `yaml:"step"` // step name or workflow file; empty for all steps
+}
+
+// Log filter preset levels.
+const (
+	LogLevelAll      = "all"
+	LogLevelErrors   = "errors"
+	LogLevelWarnings = "warnings"
+)
+
+// validate checks the level patterns and filter presets.
+func (c *LogsConfig) validate() error {
+	for _, set := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"error", c.Levels.Error},
+		{"warning", c.Levels.Warning},
+		{"debug", c.Levels.Debug},
+	} {
+		for _, pattern := range set.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("levels.%s: invalid pattern %q: %w", set.name, pattern, err)
+			}
+		}
+	}
+
+	names := make(map[string]bool, len(c.Filters))
+
+	for i, preset := range c.Filters {
+		if preset.Name == "" {
+			return fmt.Errorf("filters[%d]: name is required", i)
+		}
+
+		if names[preset.Name] {
+			return fmt.Errorf("filters[%d]: duplicate name %q", i, preset.Name)
+		}
+
+		names[preset.Name] = true
+
+		if preset.Level != LogLevelAll && preset.Level != LogLevelErrors && preset.Level != LogLevelWarnings {
+			return fmt.Errorf("filter %q: unknown level %q (expected %s, %s, or %s)",
+				preset.Name, preset.Level, LogLevelAll, LogLevelErrors, LogLevelWarnings)
+		}
+
+		if preset.Regex {
+			if _, err := regexp.Compile(preset.Search); err != nil {
+				return fmt.Errorf("filter %q: invalid search pattern %q: %w", preset.Name, preset.Search, err)
+			}
+		}
+	}
+
+	if c.CacheMaxMB < 0 {
+		return errors.New("cache_max_mb must not be negative")
+	}
+
+	return nil
 }
 
 // ChainVariable represents a variable that can be set when running a chain.
@@ -34,14 +124,282 @@ type Chain struct {
 	Description string          `yaml:"description"`
 	Variables   []ChainVariable `yaml:"variables"`
 	Steps       []ChainStep     `yaml:"steps"`
+	MaxParallel int             `yaml:"max_parallel"` // 0 runs every ready step at once
 }
 
 // ChainStep represents a single step in a workflow chain.
 type ChainStep struct {
+	ID        string            `yaml:"id"`
 	Workflow  string            `yaml:"workflow"`
 	WaitFor   WaitCondition     `yaml:"wait_for"`
 	Inputs    map[string]string `yaml:"inputs"`
 	OnFailure FailureAction     `yaml:"on_failure"`
+	// Needs lists the IDs of steps that must finish first. When omitted, the step
+	// needs the step before it; an explicit empty list lets it start immediately.
+	Needs []string `yaml:"needs"`
+	// If is an expression evaluated before dispatch; the step is skipped when it is false.
+	If string `yaml:"if"`
+	// Timeout bounds how long each attempt waits for its run; zero waits indefinitely.
+	Timeout Duration     `yaml:"timeout"`
+	Retry   *RetryPolicy `yaml:"retry"`
+}
+
+// RetryPolicy re-runs a step whose run concludes with one of the On conclusions.
+type RetryPolicy struct {
+	MaxAttempts int       `yaml:"max_attempts"` // including the first attempt
+	Backoff     Duration  `yaml:"backoff"`      // delay before the first retry, doubled for each further retry
+	On          []string  `yaml:"on"`           // conclusions to retry; defaults to [failure]
+	Mode        RetryMode `yaml:"mode"`
+}
+
+// RetryMode specifies how a step is retried.
+type RetryMode string
+
+const (
+	// RetryDispatch dispatches the workflow again, creating a new run.
+	RetryDispatch RetryMode = "dispatch"
+	// RetryRerunFailed re-runs only the failed jobs of the existing run (gh run rerun --failed).
+	RetryRerunFailed RetryMode = "rerun_failed"
+)
+
+// Retry conclusions accepted in RetryPolicy.On. ConclusionTimedOut also covers a step timeout.
+const (
+	ConclusionFailure   = "failure"
+	ConclusionCancelled = "cancelled"
+	ConclusionTimedOut  = "timed_out"
+)
+
+// ShouldRetry returns true if an attempt with the given conclusion may be retried,
+// given how many attempts have been made so far.
+func (p *RetryPolicy) ShouldRetry(conclusion string, attempts int) bool {
+	if p == nil || attempts >= p.MaxAttempts {
+		return false
+	}
+
+	return slices.Contains(p.On, conclusion)
+}
+
+// Delay returns the backoff before the given retry (1 for the first retry).
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	delay := time.Duration(p.Backoff)
+	for range retry - 1 {
+		delay *= 2
+	}
+
+	return delay
+}
+
+// Duration is a time.Duration written in YAML as a Go duration string, e.g. "30m" or "1h30m".
+type Duration time.Duration
+
+// UnmarshalYAML parses a duration string.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// validateSteps checks per-step timeout and retry settings.
+func (c *Chain) validateSteps() error {
+	for i, step := range c.Steps {
+		if step.Timeout < 0 {
+			return fmt.Errorf("step %d (%s): timeout must not be negative", i+1, step.Name())
+		}
+
+		if step.Timeout > 0 && step.WaitFor == WaitNone {
+			return fmt.Errorf("step %d (%s): timeout requires wait_for success or completion", i+1, step.Name())
+		}
+
+		retry := step.Retry
+		if retry == nil {
+			continue
+		}
+
+		if step.WaitFor == WaitNone {
+			return fmt.Errorf("step %d (%s): retry requires wait_for success or completion", i+1, step.Name())
+		}
+
+		if retry.MaxAttempts < 1 {
+			return fmt.Errorf("step %d (%s): retry.max_attempts must be at least 1", i+1, step.Name())
+		}
+
+		if retry.Backoff < 0 {
+			return fmt.Errorf("step %d (%s): retry.backoff must not be negative", i+1, step.Name())
+		}
+
+		if retry.Mode != RetryDispatch && retry.Mode != RetryRerunFailed {
+			return fmt.Errorf("step %d (%s): unknown retry.mode %q (expected %s or %s)",
+				i+1, step.Name(), retry.Mode, RetryDispatch, RetryRerunFailed)
+		}
+
+		for _, on := range retry.On {
+			if on != ConclusionFailure && on != ConclusionCancelled && on != ConclusionTimedOut {
+				return fmt.Errorf("step %d (%s): unknown retry.on conclusion %q (expected %s, %s, or %s)",
+					i+1, step.Name(), on, ConclusionFailure, ConclusionCancelled, ConclusionTimedOut)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Name returns the step ID, or the workflow when the step has no ID.
+func (s ChainStep) Name() string {
+	if s.ID != "" {
+		return s.ID
+	}
+
+	return s.Workflow
+}
+
+// Dependencies returns, for each step, the indices of the steps it needs.
+// Unknown IDs are ignored; Load rejects them.
+func (c *Chain) Dependencies() [][]int {
+	ids := make(map[string]int, len(c.Steps))
+	for i, step := range c.Steps {
+		if step.ID != "" {
+			ids[step.ID] = i
+		}
+	}
+
+	deps := make([][]int, len(c.Steps))
+
+	for i, step := range c.Steps {
+		if step.Needs == nil {
+			if i > 0 {
+				deps[i] = []int{i - 1}
+			}
+
+			continue
+		}
+
+		for _, id := range step.Needs {
+			if j, ok := ids[id]; ok {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	return deps
+}
+
+// Ancestors returns the indices of the steps that finish before step i starts:
+// those it needs, directly or through the steps they need.
+func (c *Chain) Ancestors(i int) map[int]bool {
+	deps := c.Dependencies()
+	ancestors := make(map[int]bool)
+
+	if i < 0 || i >= len(deps) {
+		return ancestors
+	}
+
+	queue := slices.Clone(deps[i])
+	for len(queue) > 0 {
+		j := queue[0]
+		queue = queue[1:]
+
+		if ancestors[j] {
+			continue
+		}
+
+		ancestors[j] = true
+		queue = append(queue, deps[j]...)
+	}
+
+	return ancestors
+}
+
+// PreviousStep returns the index of the step whose result templates see as
+// `previous`: the last step it needs, or -1 if it needs none.
+func (c *Chain) PreviousStep(i int) int {
+	deps := c.Dependencies()
+	if i < 0 || i >= len(deps) || len(deps[i]) == 0 {
+		return -1
+	}
+
+	return deps[i][len(deps[i])-1]
+}
+
+// validateGraph checks step IDs and needs, and rejects dependency cycles.
+func (c *Chain) validateGraph() error {
+	ids := make(map[string]bool, len(c.Steps))
+
+	for _, step := range c.Steps {
+		if step.ID == "" {
+			continue
+		}
+
+		if ids[step.ID] {
+			return fmt.Errorf("duplicate step id %q", step.ID)
+		}
+
+		ids[step.ID] = true
+	}
+
+	for i, step := range c.Steps {
+		for _, id := range step.Needs {
+			if !ids[id] {
+				return fmt.Errorf("step %d (%s) needs unknown step %q", i+1, step.Name(), id)
+			}
+
+			if id == step.ID {
+				return fmt.Errorf("step %d (%s) needs itself", i+1, step.Name())
+			}
+		}
+	}
+
+	if c.MaxParallel < 0 {
+		return errors.New("max_parallel must not be negative")
+	}
+
+	// Kahn's algorithm: any step left unvisited is part of a cycle.
+	deps := c.Dependencies()
+	remaining := make([]int, len(deps))
+	dependents := make([][]int, len(deps))
+
+	var ready []int
+
+	for i, needs := range deps {
+		remaining[i] = len(needs)
+		for _, j := range needs {
+			dependents[j] = append(dependents[j], i)
+		}
+
+		if len(needs) == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	visited := 0
+
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		visited++
+
+		for _, d := range dependents[i] {
+			remaining[d]--
+			if remaining[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	if visited != len(deps) {
+		return errors.New("step dependencies form a cycle")
+	}
+
+	return nil
 }
 
 // WaitCondition specifies when to proceed to the next step.
@@ -79,6 +437,12 @@ func LoadFrom(path string) (*WfdConfig, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return Parse(data)
+}
+
+// Parse parses and validates the content of a configuration file, e.g. one
+// fetched from a repository that is not checked out.
+func Parse(data []byte) (*WfdConfig, error) {
 	var config WfdConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -97,6 +461,16 @@ func LoadFrom(path string) (*WfdConfig, error) {
 			if chain.Steps[i].OnFailure == "" {
 				chain.Steps[i].OnFailure = FailureAbort
 			}
+
+			if retry := chain.Steps[i].Retry; retry != nil {
+				if retry.Mode == "" {
+					retry.Mode = RetryDispatch
+				}
+
+				if len(retry.On) == 0 {
+					retry.On = []string{ConclusionFailure}
+				}
+			}
 		}
 
 		for i := range chain.Variables {
@@ -105,9 +479,27 @@ func LoadFrom(path string) (*WfdConfig, error) {
 			}
 		}
 
+		if err := chain.validateGraph(); err != nil {
+			return nil, fmt.Errorf("chain %q: %w", name, err)
+		}
+
+		if err := chain.validateSteps(); err != nil {
+			return nil, fmt.Errorf("chain %q: %w", name, err)
+		}
+
 		config.Chains[name] = chain
 	}
 
+	for i := range config.Logs.Filters {
+		if config.Logs.Filters[i].Level == "" {
+			config.Logs.Filters[i].Level = LogLevelAll
+		}
+	}
+
+	if err := config.Logs.validate(); err != nil {
+		return nil, fmt.Errorf("logs: %w", err)
+	}
+
 	return &config, nil
 }
 
@@ -141,6 +533,34 @@ func (c *WfdConfig) ChainNames() []string {
 	return names
 }
 
+// CorrelationInputFor returns the correlation input for a workflow that declares it
+// and "" otherwise, since GitHub rejects dispatches with undeclared inputs.
+func (c *WfdConfig) CorrelationInputFor(wf workflow.WorkflowFile) string {
+	if c == nil || c.CorrelationInput == "" {
+		return ""
+	}
+
+	if _, ok := wf.GetInputs()[c.CorrelationInput]; !ok {
+		return ""
+	}
+
+	return c.CorrelationInput
+}
+
+// CorrelationInputs maps the file of each workflow declaring the correlation input
+// to the input's name, for chains dispatching several workflows.
+func (c *WfdConfig) CorrelationInputs(workflows []workflow.WorkflowFile) map[string]string {
+	inputs := make(map[string]string)
+
+	for _, wf := range workflows {
+		if input := c.CorrelationInputFor(wf); input != "" {
+			inputs[wf.Filename] = input
+		}
+	}
+
+	return inputs
+}
+
 // HasChains returns true if any chains are defined.
 func (c *WfdConfig) HasChains() bool {
 	return c != nil && len(c.Chains) > 0
@@ -1,11 +1,15 @@
 package config_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
 )
 
 func TestLoad_ValidConfig(t *testing.T) {
@@ -406,3 +410,374 @@ chains:
 		t.Errorf("default type: got %q, want %q", v.Type, "string")
 	}
 }
+
+func TestLoad_StepGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   string
+		wantErr string
+	}{
+		{
+			name: "fan out and in",
+			steps: `
+      - id: build
+        workflow: build.yml
+      - id: deploy-us
+        workflow: deploy.yml
+        needs: [build]
+      - id: deploy-eu
+        workflow: deploy.yml
+        needs: [build]
+      - workflow: smoke.yml
+        needs: [deploy-us, deploy-eu]
+`,
+		},
+		{
+			name: "unknown need",
+			steps: `
+      - id: build
+        workflow: build.yml
+      - workflow: deploy.yml
+        needs: [test]
+`,
+			wantErr: `needs unknown step "test"`,
+		},
+		{
+			name: "duplicate id",
+			steps: `
+      - id: build
+        workflow: build.yml
+      - id: build
+        workflow: deploy.yml
+`,
+			wantErr: `duplicate step id "build"`,
+		},
+		{
+			name: "cycle",
+			steps: `
+      - id: a
+        workflow: a.yml
+        needs: [b]
+      - id: b
+        workflow: b.yml
+        needs: [a]
+`,
+			wantErr: "cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			content := "version: 1\nchains:\n  release:\n    max_parallel: 2\n    steps:" + tt.steps
+			if err := os.WriteFile(filepath.Join(dir, config.ConfigFilename), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := config.Load(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error: got %v, want containing %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			chain, _ := cfg.GetChain("release")
+			if chain.MaxParallel != 2 {
+				t.Errorf("MaxParallel: got %d, want 2", chain.MaxParallel)
+			}
+		})
+	}
+}
+
+func TestChain_Dependencies(t *testing.T) {
+	chain := &config.Chain{
+		Steps: []config.ChainStep{
+			{ID: "build", Workflow: "build.yml"},
+			{ID: "lint", Workflow: "lint.yml", Needs: []string{}},
+			{ID: "deploy", Workflow: "deploy.yml", Needs: []string{"build", "lint"}},
+			{Workflow: "notify.yml"},
+		},
+	}
+
+	want := [][]int{nil, nil, {0, 1}, {2}}
+	got := chain.Dependencies()
+
+	for i := range want {
+		if fmt.Sprint(got[i]) != fmt.Sprint(want[i]) {
+			t.Errorf("Dependencies()[%d]: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if chain.PreviousStep(2) != 1 || chain.PreviousStep(1) != -1 {
+		t.Errorf("PreviousStep: got %d and %d, want 1 and -1", chain.PreviousStep(2), chain.PreviousStep(1))
+	}
+}
+
+func TestLoad_TimeoutAndRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			steps: `
+      - workflow: e2e.yml
+        timeout: 45m
+        retry:
+          max_attempts: 3
+          backoff: 30s
+          on: [failure, cancelled]
+          mode: rerun_failed
+      - workflow: deploy.yml
+        retry:
+          max_attempts: 2
+`,
+		},
+		{name: "bad duration", steps: "\n      - workflow: a.yml\n        timeout: soon\n", wantErr: `invalid duration "soon"`},
+		{name: "zero attempts", steps: "\n      - workflow: a.yml\n        retry: {max_attempts: 0}\n", wantErr: "max_attempts must be at least 1"},
+		{name: "unknown conclusion", steps: "\n      - workflow: a.yml\n        retry: {max_attempts: 2, on: [skipped]}\n", wantErr: `unknown retry.on conclusion "skipped"`},
+		{name: "unknown mode", steps: "\n      - workflow: a.yml\n        retry: {max_attempts: 2, mode: again}\n", wantErr: `unknown retry.mode "again"`},
+		{name: "retry without wait", steps: "\n      - workflow: a.yml\n        wait_for: none\n        retry: {max_attempts: 2}\n", wantErr: "retry requires wait_for"},
+		{name: "timeout without wait", steps: "\n      - workflow: a.yml\n        wait_for: none\n        timeout: 5m\n", wantErr: "timeout requires wait_for"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			content := "version: 1\nchains:\n  release:\n    steps:" + tt.steps
+			if err := os.WriteFile(filepath.Join(dir, config.ConfigFilename), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := config.Load(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error: got %v, want containing %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			chain, _ := cfg.GetChain("release")
+
+			e2e := chain.Steps[0]
+			if time.Duration(e2e.Timeout) != 45*time.Minute || e2e.Retry.Mode != config.RetryRerunFailed {
+				t.Errorf("step 0: got timeout %v mode %q", time.Duration(e2e.Timeout), e2e.Retry.Mode)
+			}
+
+			deploy := chain.Steps[1].Retry
+			if deploy.Mode != config.RetryDispatch || len(deploy.On) != 1 || deploy.On[0] != config.ConclusionFailure {
+				t.Errorf("step 1 defaults: got mode %q on %v", deploy.Mode, deploy.On)
+			}
+		})
+	}
+}
+
+func TestLoad_LogsConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		logs    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			logs: `
+  cache_max_mb: 50
+  levels:
+    error: ["^FAIL:", "^panic:"]
+    debug: ["^🔍"]
+  filters:
+    - name: failed tests
+      search: "^--- FAIL"
+      regex: true
+      step: Run tests
+    - name: deploy errors
+      level: errors
+      step: deploy.yml
+`,
+		},
+		{name: "bad level pattern", logs: "\n  levels:\n    warning: [\"(\"]\n", wantErr: `levels.warning: invalid pattern "("`},
+		{name: "unnamed filter", logs: "\n  filters:\n    - search: x\n", wantErr: "filters[0]: name is required"},
+		{name: "duplicate filter", logs: "\n  filters:\n    - name: a\n    - name: a\n", wantErr: `duplicate name "a"`},
+		{name: "unknown level", logs: "\n  filters:\n    - name: a\n      level: fatal\n", wantErr: `unknown level "fatal"`},
+		{name: "bad search regex", logs: "\n  filters:\n    - name: a\n      search: \"[\"\n      regex: true\n", wantErr: "invalid search pattern"},
+		{name: "negative cache size", logs: "\n  cache_max_mb: -1\n", wantErr: "cache_max_mb must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			content := "version: 1\nlogs:" + tt.logs
+			if err := os.WriteFile(filepath.Join(dir, config.ConfigFilename), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := config.Load(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error: got %v, want containing %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			logs := cfg.Logs
+			if logs.CacheMaxMB != 50 || len(logs.Levels.Error) != 2 || len(logs.Levels.Debug) != 1 || len(logs.Filters) != 2 {
+				t.Fatalf("unexpected logs config %+v", logs)
+			}
+
+			if f := logs.Filters[0]; f.Level != config.LogLevelAll || !f.Regex || f.Step != "Run tests" {
+				t.Errorf("filter 0: got %+v", f)
+			}
+
+			if f := logs.Filters[1]; f.Level != config.LogLevelErrors || f.Step != "deploy.yml" {
+				t.Errorf("filter 1: got %+v", f)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &config.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     config.Duration(10 * time.Second),
+		On:          []string{config.ConclusionFailure},
+	}
+
+	if !policy.ShouldRetry("failure", 1) || !policy.ShouldRetry("failure", 2) {
+		t.Error("expected retries while attempts remain")
+	}
+
+	if policy.ShouldRetry("failure", 3) || policy.ShouldRetry("cancelled", 1) {
+		t.Error("unexpected retry")
+	}
+
+	var none *config.RetryPolicy
+	if none.ShouldRetry("failure", 1) {
+		t.Error("nil policy should never retry")
+	}
+
+	if policy.Delay(1) != 10*time.Second || policy.Delay(3) != 40*time.Second {
+		t.Errorf("Delay: got %v, %v", policy.Delay(1), policy.Delay(3))
+	}
+}
+
+func TestLoadUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+
+	cfg, err := config.LoadUserConfigFrom(path)
+	if err != nil || cfg != nil {
+		t.Fatalf("missing file: got %+v, %v; want nil, nil", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte("repos:\n  - acme/api\n  - acme/web\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err = config.LoadUserConfigFrom(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(cfg.Repos, ",") != "acme/api,acme/web" {
+		t.Errorf("Repos = %v", cfg.Repos)
+	}
+
+	for _, bad := range []string{"repos: [api]", "repos: [acme/api/x]", "repos: [acme/api, acme/api]"} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		if _, err := config.LoadUserConfigFrom(path); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestUserConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	if got := config.UserConfigPath(); got != filepath.Join("/tmp/xdg", "lazydispatch", "config.yml") {
+		t.Errorf("UserConfigPath() = %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	cfg, err := config.Parse([]byte("version: 2\nchains:\n  release:\n    steps:\n      - workflow: build.yml\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chain, ok := cfg.GetChain("release")
+	if !ok || chain.Steps[0].WaitFor != config.WaitSuccess {
+		t.Errorf("defaults not applied: %+v", chain)
+	}
+
+	if _, err := config.Parse([]byte("version: 3\n")); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+}
+
+func TestCorrelationInputs(t *testing.T) {
+	cfg, err := config.Parse([]byte("version: 2\ncorrelation_input: correlation_id\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	declaring := workflow.WorkflowFile{
+		Filename: "ci.yml",
+		On: workflow.OnTrigger{WorkflowDispatch: &workflow.WorkflowDispatch{
+			Inputs: map[string]workflow.WorkflowInput{"correlation_id": {Type: "string"}},
+		}},
+	}
+	other := workflow.WorkflowFile{
+		Filename: "deploy.yml",
+		On:       workflow.OnTrigger{WorkflowDispatch: &workflow.WorkflowDispatch{}},
+	}
+
+	if got := cfg.CorrelationInputFor(declaring); got != "correlation_id" {
+		t.Errorf("CorrelationInputFor(ci.yml) = %q, want correlation_id", got)
+	}
+
+	if got := cfg.CorrelationInputFor(other); got != "" {
+		t.Errorf("CorrelationInputFor(deploy.yml) = %q, want empty for a workflow without the input", got)
+	}
+
+	inputs := cfg.CorrelationInputs([]workflow.WorkflowFile{declaring, other})
+	if len(inputs) != 1 || inputs["ci.yml"] != "correlation_id" {
+		t.Errorf("CorrelationInputs = %v", inputs)
+	}
+
+	var missing *config.WfdConfig
+	if got := missing.CorrelationInputFor(declaring); got != "" {
+		t.Errorf("CorrelationInputFor without a config = %q, want empty", got)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfig is the per-user lazydispatch configuration, shared by every repository.
+type UserConfig struct {
+	// Repos lists the repositories (owner/name) the repo switcher offers.
+	Repos []string `yaml:"repos"`
+}
+
+// UserConfigPath returns the path to the user configuration file.
+func UserConfigPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "lazydispatch", "config.yml")
+	}
+
+	home, _ := os.UserHomeDir()
+
+	return filepath.Join(home, ".config", "lazydispatch", "config.yml")
+}
+
+// LoadUserConfig loads the user configuration from the default location.
+func LoadUserConfig() (*UserConfig, error) {
+	return LoadUserConfigFrom(UserConfigPath())
+}
+
+// LoadUserConfigFrom loads the user configuration from a specific path.
+// A missing file yields nil and no error.
+func LoadUserConfigFrom(path string) (*UserConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read user config: %w", err)
+	}
+
+	var cfg UserConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse user config: %w", err)
+	}
+
+	seen := make(map[string]bool, len(cfg.Repos))
+
+	for i, repo := range cfg.Repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("repos[%d]: invalid repository %q (expected owner/name)", i, repo)
+		}
+
+		if seen[repo] {
+			return nil, fmt.Errorf("repos[%d]: duplicate repository %q", i, repo)
+		}
+
+		seen[repo] = true
+	}
+
+	return &cfg, nil
+}
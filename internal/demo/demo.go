@@ -1,4 +1,5 @@
-// Package demo provides demo mode functionality for branch selection workflows.
+// Package demo provides demo mode: Server, an in-process fake of GitHub that
+// dispatched runs play out on, and canned mock executor responses.
 package demo
 
 import (
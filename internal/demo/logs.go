@@ -0,0 +1,176 @@
+package demo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+// logLine is a line of a step's log and when the step writes it.
+type logLine struct {
+	at   time.Time
+	text string
+}
+
+// stepLog returns the lines a step has written by now. They are spread
+// evenly over the step's planned duration; a failed step ends with an
+// error, and a cancelled one stops writing when it is cancelled.
+func (r *run) stepLog(step stepPlan, now time.Time) []logLine {
+	if step.startedAt.IsZero() || now.Before(step.startedAt) {
+		return nil
+	}
+
+	failed := step.conclusion != github.ConclusionSuccess && step.conclusion != github.ConclusionCancelled
+
+	texts := append([]string{"##[group]Run " + strings.TrimPrefix(step.name, "Run ")}, r.stepOutput(step.name, failed)...)
+	texts = append(texts, "##[endgroup]")
+
+	interval := step.plannedEnd.Sub(step.startedAt) / time.Duration(len(texts)+1)
+
+	var lines []logLine
+
+	for i, text := range texts {
+		at := step.startedAt.Add(interval * time.Duration(i))
+		if at.After(now) || at.After(step.completedAt) {
+			break
+		}
+
+		lines = append(lines, logLine{at: at, text: text})
+	}
+
+	var final string
+
+	switch {
+	case failed:
+		final = "##[error]Process completed with exit code 1."
+	case step.conclusion == github.ConclusionCancelled:
+		final = "##[error]The operation was canceled."
+	}
+
+	if final != "" && !now.Before(step.completedAt) {
+		lines = append(lines, logLine{at: step.completedAt, text: final})
+	}
+
+	return lines
+}
+
+// stepOutput returns the output of a step, made up from its name like the
+// steps of the demo repository's workflows.
+func (r *run) stepOutput(name string, failed bool) []string {
+	version := r.inputs["version"]
+	if version == "" {
+		version = "v1.2.3"
+	}
+
+	lower := strings.ToLower(name)
+
+	switch {
+	case lower == "set up job":
+		return []string{
+			"Current runner version: '2.321.0'",
+			"Operating System",
+			"  Ubuntu 24.04.1 LTS",
+			"Runner Image: ubuntu-24.04",
+			"GITHUB_TOKEN Permissions",
+			"  Contents: read",
+			"Secret source: Actions",
+		}
+	case lower == "complete job":
+		return []string{"Cleaning up orphan processes"}
+	case strings.Contains(lower, "checkout"):
+		return []string{
+			"Syncing repository: " + Repository,
+			"Getting Git version info",
+			"Determining the checkout info",
+			"/usr/bin/git -c protocol.version=2 fetch --no-tags --prune --depth=1 origin " + r.branch,
+			"Checking out the ref",
+			"HEAD is now at 3f9c2d1 Merge pull request #128 from demo-org/feature/login",
+		}
+	case strings.Contains(lower, "lint"):
+		return []string{
+			"golangci-lint run --timeout 5m",
+			"level=info msg=\"[runner] linters took 4.2s with stages: goanalysis_metalinter: 3.9s\"",
+			"##[warning]internal/api/handler.go:57:2: ineffectual assignment to err (ineffassign)",
+			"1 issues:",
+			"* ineffassign: 1",
+		}
+	case strings.Contains(lower, "test"):
+		return testOutput(failed)
+	case strings.Contains(lower, "build"):
+		return []string{
+			"go build -trimpath -ldflags \"-s -w -X main.version=" + version + "\" ./...",
+			"  • building binaries",
+			"    • building                                       binary=dist/demo_linux_amd64/demo",
+			"    • building                                       binary=dist/demo_darwin_arm64/demo",
+			"  • build succeeded after 12s",
+		}
+	case strings.Contains(lower, "publish"):
+		return []string{
+			"  • publishing",
+			"    • creating or updating release                 tag=" + version,
+			"    • uploading to release                         file=dist/demo_linux_amd64.tar.gz",
+			"    • uploading to release                         file=dist/demo_darwin_arm64.tar.gz",
+			"  • release succeeded after 9s",
+		}
+	case strings.Contains(lower, "upload"):
+		return []string{
+			"With the provided path, there will be 1 file uploaded",
+			"Artifact name is valid!",
+			"Uploaded bytes 64",
+			"Artifact lazydispatch-outputs has been successfully uploaded!",
+		}
+	case strings.Contains(lower, "deploy"):
+		environment := r.inputs["environment"]
+
+		lines := []string{"Deploying " + version + " to " + environment}
+		if r.inputs["dry_run"] == "true" {
+			return append(lines, "Dry run: 3 resources would change", "Plan: 0 to add, 3 to change, 0 to destroy.")
+		}
+
+		return append(lines,
+			"Rolling out deployment/demo-api (3 replicas)",
+			"Waiting for deployment \"demo-api\" rollout to finish: 1 of 3 updated replicas are available...",
+			"Waiting for deployment \"demo-api\" rollout to finish: 2 of 3 updated replicas are available...",
+			"deployment \"demo-api\" successfully rolled out",
+		)
+	case strings.Contains(lower, "smoke"):
+		host := fmt.Sprintf("https://%s.demo.example", r.inputs["environment"])
+
+		return []string{
+			"GET " + host + "/healthz -> 200 (41ms)",
+			"GET " + host + "/api/v1/status -> 200 (87ms)",
+			"Smoke tests passed",
+		}
+	}
+
+	return []string{"Running " + name, "Done"}
+}
+
+func testOutput(failed bool) []string {
+	lines := []string{
+		"=== RUN   TestLogin",
+		"--- PASS: TestLogin (0.41s)",
+		"=== RUN   TestCheckout",
+	}
+
+	if failed {
+		return append(lines,
+			"    checkout_test.go:88: expected status 200, got 502",
+			"--- FAIL: TestCheckout (2.31s)",
+			"=== RUN   TestSearch",
+			"--- PASS: TestSearch (0.18s)",
+			"FAIL",
+			"1 failed, 2 passed",
+		)
+	}
+
+	return append(lines,
+		"--- PASS: TestCheckout (1.02s)",
+		"=== RUN   TestSearch",
+		"--- PASS: TestSearch (0.18s)",
+		"PASS",
+		"3 passed",
+	)
+}
@@ -0,0 +1,465 @@
+package demo
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+// run is a dispatched workflow run of the demo server.
+type run struct {
+	id        int64
+	workflow  string // filename
+	name      string
+	title     string
+	branch    string
+	inputs    map[string]string
+	actor     string
+	htmlURL   string
+	createdAt time.Time
+	behavior  Behavior
+	attempts  []*attempt
+}
+
+// attempt is one attempt of a run; re-running a run adds an attempt.
+type attempt struct {
+	number      int
+	queuedAt    time.Time
+	conclusion  string // how the attempt ends unless it is cancelled first
+	cancelledAt time.Time
+	jobIDs      []int64
+}
+
+// stepPlan is when a step of an attempt runs and how it ends.
+type stepPlan struct {
+	job         int
+	number      int // within the job, from 1
+	name        string
+	startedAt   time.Time // zero for steps that never start
+	plannedEnd  time.Time // when the step ends unless cancelled; its log is spread up to then
+	completedAt time.Time
+	conclusion  string
+}
+
+// runState is the state of a run's latest attempt at some point in time.
+type runState struct {
+	status      string
+	conclusion  string // set once completed
+	completedAt time.Time
+	steps       []stepPlan
+}
+
+func (r *run) current() *attempt {
+	return r.attempts[len(r.attempts)-1]
+}
+
+// plan lays out the steps of an attempt: each takes an equal share of the
+// behavior's duration, one after another. In an attempt that does not
+// succeed, the steps after the failing one are skipped.
+func (r *run) plan(a *attempt) []stepPlan {
+	jobs := r.behavior.jobs()
+
+	total := 0
+	for _, job := range jobs {
+		total += len(job.Steps)
+	}
+
+	startedAt := a.queuedAt.Add(r.behavior.QueueDelay)
+	stepDuration := r.behavior.Duration / time.Duration(max(total, 1))
+	failing := a.conclusion != github.ConclusionSuccess
+
+	failAt := total - 1
+
+	for i, name := range flattenSteps(jobs) {
+		if name == r.behavior.FailStep {
+			failAt = i
+		}
+	}
+
+	steps := make([]stepPlan, 0, total)
+
+	for j, job := range jobs {
+		for n, name := range job.Steps {
+			i := len(steps)
+
+			step := stepPlan{
+				job:        j,
+				number:     n + 1,
+				name:       name,
+				startedAt:  startedAt.Add(stepDuration * time.Duration(i)),
+				conclusion: github.ConclusionSuccess,
+			}
+			step.plannedEnd = step.startedAt.Add(stepDuration)
+			step.completedAt = step.plannedEnd
+
+			switch {
+			case failing && i == failAt:
+				step.conclusion = a.conclusion
+			case failing && i > failAt:
+				step.startedAt = time.Time{}
+				step.plannedEnd = time.Time{}
+				step.completedAt = steps[failAt].completedAt
+				step.conclusion = github.ConclusionSkipped
+			}
+
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+// cancel ends the steps still running at cancelledAt as cancelled and skips
+// the ones that have not started.
+func cancel(steps []stepPlan, cancelledAt time.Time) {
+	for i := range steps {
+		step := &steps[i]
+
+		switch {
+		case !step.completedAt.After(cancelledAt):
+			// Finished before the cancellation
+		case !step.startedAt.IsZero() && step.startedAt.Before(cancelledAt):
+			step.completedAt = cancelledAt
+			step.conclusion = github.ConclusionCancelled
+		default:
+			step.startedAt = time.Time{}
+			step.completedAt = cancelledAt
+			step.conclusion = github.ConclusionSkipped
+		}
+	}
+}
+
+func flattenSteps(jobs []JobSpec) []string {
+	var names []string
+	for _, job := range jobs {
+		names = append(names, job.Steps...)
+	}
+
+	return names
+}
+
+// state returns the state of the run's latest attempt at now. Cancelling
+// only takes effect on an attempt that has not finished by then.
+func (r *run) state(now time.Time) runState {
+	a := r.current()
+	startedAt := a.queuedAt.Add(r.behavior.QueueDelay)
+
+	state := runState{steps: r.plan(a), conclusion: a.conclusion, completedAt: startedAt}
+
+	for _, step := range state.steps {
+		if step.completedAt.After(state.completedAt) {
+			state.completedAt = step.completedAt
+		}
+	}
+
+	if !a.cancelledAt.IsZero() && a.cancelledAt.Before(state.completedAt) {
+		cancel(state.steps, a.cancelledAt)
+		state.conclusion = github.ConclusionCancelled
+		state.completedAt = a.cancelledAt
+	}
+
+	switch {
+	case !now.Before(state.completedAt):
+		state.status = github.StatusCompleted
+	case now.Before(startedAt):
+		state.status = github.StatusQueued
+		state.conclusion = ""
+	default:
+		state.status = github.StatusInProgress
+		state.conclusion = ""
+	}
+
+	return state
+}
+
+// stepStatus returns the status and, once completed, the conclusion of a step at now.
+func stepStatus(step stepPlan, now time.Time) (string, string) {
+	switch {
+	case !now.Before(step.completedAt):
+		return github.StatusCompleted, step.conclusion
+	case !step.startedAt.IsZero() && !now.Before(step.startedAt):
+		return github.StatusInProgress, ""
+	default:
+		return github.StatusQueued, ""
+	}
+}
+
+// workflowRun returns the run as the API shows it at now.
+func (r *run) workflowRun(now time.Time) github.WorkflowRun {
+	state := r.state(now)
+
+	updatedAt := now
+	if state.status == github.StatusCompleted {
+		updatedAt = state.completedAt
+	}
+
+	sha := sha1.Sum([]byte(r.branch))
+
+	return github.WorkflowRun{
+		ID:         r.id,
+		Name:       r.name,
+		Status:     state.status,
+		Conclusion: state.conclusion,
+		CreatedAt:  r.createdAt,
+		UpdatedAt:  updatedAt,
+		HTMLURL:    r.htmlURL,
+		HeadBranch: r.branch,
+		HeadSHA:    hex.EncodeToString(sha[:]),
+		RunAttempt: r.current().number,
+		Event:      github.EventWorkflowDispatch,
+		Title:      r.title,
+		Actor:      github.Actor{Login: r.actor},
+	}
+}
+
+// jobs returns the jobs of the run's latest attempt as the API shows them at now.
+func (r *run) jobs(now time.Time) []github.Job {
+	a := r.current()
+	state := r.state(now)
+	specs := r.behavior.jobs()
+
+	jobs := make([]github.Job, len(specs))
+
+	for j, spec := range specs {
+		job := github.Job{ID: a.jobIDs[j], Name: spec.Name, Status: github.StatusQueued}
+
+		var completedAt time.Time
+
+		conclusion := github.ConclusionSkipped
+
+		for _, step := range state.steps {
+			if step.job != j {
+				continue
+			}
+
+			status, stepConclusion := stepStatus(step, now)
+			job.Steps = append(job.Steps, github.Step{
+				Name:       step.name,
+				Status:     status,
+				Conclusion: stepConclusion,
+				Number:     step.number,
+			})
+
+			if !step.startedAt.IsZero() && job.StartedAt.IsZero() {
+				job.StartedAt = step.startedAt
+			}
+
+			if step.completedAt.After(completedAt) {
+				completedAt = step.completedAt
+			}
+
+			switch step.conclusion {
+			case github.ConclusionSkipped:
+			case github.ConclusionSuccess:
+				if conclusion == github.ConclusionSkipped {
+					conclusion = github.ConclusionSuccess
+				}
+			default:
+				conclusion = step.conclusion
+			}
+		}
+
+		if conclusion == github.ConclusionSkipped && state.conclusion == github.ConclusionCancelled {
+			conclusion = github.ConclusionCancelled
+		}
+
+		switch {
+		case !now.Before(completedAt):
+			job.Status = github.StatusCompleted
+			job.Conclusion = conclusion
+		case !job.StartedAt.IsZero() && !now.Before(job.StartedAt):
+			job.Status = github.StatusInProgress
+		}
+
+		jobs[j] = job
+	}
+
+	return jobs
+}
+
+// viewLog renders the log written so far of a job, or of every job when
+// jobID is 0, like gh run view --log: one "job<TAB>step<TAB>timestamp line"
+// row per line.
+func (r *run) viewLog(now time.Time, jobID int64) (string, string, error) {
+	a := r.current()
+	state := r.state(now)
+	specs := r.behavior.jobs()
+
+	var b strings.Builder
+
+	found := false
+
+	for j, spec := range specs {
+		if jobID != 0 && a.jobIDs[j] != jobID {
+			continue
+		}
+
+		found = true
+
+		for _, step := range state.steps {
+			if step.job != j {
+				continue
+			}
+
+			for _, line := range r.stepLog(step, now) {
+				fmt.Fprintf(&b, "%s\t%s\t%s %s\n", spec.Name, step.name, formatTimestamp(line.at), line.text)
+			}
+		}
+	}
+
+	if !found {
+		return notFound()
+	}
+
+	return b.String(), "", nil
+}
+
+// jobLog renders the log written so far of the job with jobID in the run's current
+// attempt, as the job logs endpoint serves it: one "timestamp line" row per line.
+func (r *run) jobLog(now time.Time, jobID int64) (string, bool) {
+	a := r.current()
+	state := r.state(now)
+
+	j := slices.Index(a.jobIDs, jobID)
+	if j < 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+
+	for _, step := range state.steps {
+		if step.job != j {
+			continue
+		}
+
+		for _, line := range r.stepLog(step, now) {
+			fmt.Fprintf(&b, "%s %s\n", formatTimestamp(line.at), line.text)
+		}
+	}
+
+	return b.String(), true
+}
+
+// logArchive builds the run log archive of a completed run, laid out like
+// GitHub's: a `<job>/<n>_<step>.txt` file per step and a `<n>_<job>.txt`
+// file per job.
+func (r *run) logArchive(now time.Time) ([]byte, bool) {
+	state := r.state(now)
+	if state.status != github.StatusCompleted {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for j, spec := range r.behavior.jobs() {
+		var combined strings.Builder
+
+		for _, step := range state.steps {
+			if step.job != j {
+				continue
+			}
+
+			var stepLog strings.Builder
+			for _, line := range r.stepLog(step, now) {
+				fmt.Fprintf(&stepLog, "%s %s\n", formatTimestamp(line.at), line.text)
+			}
+
+			combined.WriteString(stepLog.String())
+
+			name := fmt.Sprintf("%s/%d_%s.txt", spec.Name, step.number, archiveFileName.Replace(step.name))
+			if err := writeZipFile(zw, name, stepLog.String()); err != nil {
+				return nil, false
+			}
+		}
+
+		if err := writeZipFile(zw, fmt.Sprintf("%d_%s.txt", j+1, spec.Name), combined.String()); err != nil {
+			return nil, false
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// archiveFileName drops characters GitHub leaves out of archive file names.
+var archiveFileName = strings.NewReplacer("/", "", "\\", "", ":", "", "*", "", "?", "", "\"", "", "<", "", ">", "", "|", "")
+
+func writeZipFile(zw *zip.Writer, name, content string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte(content))
+
+	return err
+}
+
+// outputs returns the outputs published by the run, once it has succeeded.
+func (r *run) outputs(now time.Time) (map[string]string, bool) {
+	state := r.state(now)
+	if len(r.behavior.Outputs) == 0 || state.conclusion != github.ConclusionSuccess {
+		return nil, false
+	}
+
+	outputs := make(map[string]string, len(r.behavior.Outputs))
+	for name, value := range r.behavior.Outputs {
+		outputs[name] = renderInputs(value, r.inputs)
+	}
+
+	return outputs, true
+}
+
+// artifacts lists the run's artifacts: the outputs artifact, identified by the run's ID.
+func (r *run) artifacts(now time.Time) []github.Artifact {
+	archive, ok := r.outputsArchive(now)
+	if !ok {
+		return []github.Artifact{}
+	}
+
+	return []github.Artifact{{ID: r.id, Name: chain.OutputsArtifact, SizeInBytes: int64(len(archive))}}
+}
+
+func (r *run) outputsArchive(now time.Time) ([]byte, bool) {
+	outputs, ok := r.outputs(now)
+	if !ok {
+		return nil, false
+	}
+
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	if err := writeZipFile(zw, chain.OutputsFile, string(data)); err != nil {
+		return nil, false
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// formatTimestamp formats a time like the timestamps of GitHub Actions logs.
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.0000000Z")
+}
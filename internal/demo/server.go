@@ -0,0 +1,738 @@
+package demo
+
+import (
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Repository is the repository served by Server.
+const Repository = "demo-org/demo-repo"
+
+// repoFiles holds the demo repository: repo/workflows is served as
+// .github/workflows and repo/lazydispatch.yml as .github/lazydispatch.yml.
+//
+//go:embed repo
+var repoFiles embed.FS
+
+// errCommandFailed is returned for gh commands that fail, like gh's exit status.
+var errCommandFailed = errors.New("exit status 1")
+
+// Behavior configures how the runs of a workflow play out.
+type Behavior struct {
+	QueueDelay time.Duration // time a run spends queued
+	Duration   time.Duration // time a run spends in progress, split evenly between its steps
+	// Conclusions lists the conclusion of each attempt, the last one repeating.
+	// Runs succeed when it is empty.
+	Conclusions []string
+	FailStep    string    // step that fails in attempts that do not succeed; the last step by default
+	Jobs        []JobSpec // jobs run one after another; DefaultJobs when empty
+	// Outputs are published as the lazydispatch-outputs artifact of successful runs.
+	// Values may reference inputs as ${{ inputs.<name> }}.
+	Outputs map[string]string
+}
+
+// JobSpec names a job and its steps.
+type JobSpec struct {
+	Name  string
+	Steps []string
+}
+
+// DefaultJobs are the jobs of workflows whose Behavior does not list any.
+var DefaultJobs = []JobSpec{
+	{Name: "build", Steps: []string{"Set up job", "Run actions/checkout@v4", "Build", "Complete job"}},
+}
+
+func (b Behavior) jobs() []JobSpec {
+	if len(b.Jobs) == 0 {
+		return DefaultJobs
+	}
+
+	return b.Jobs
+}
+
+// conclusion returns the conclusion of an attempt, numbered from 1.
+func (b Behavior) conclusion(attempt int) string {
+	if len(b.Conclusions) == 0 {
+		return github.ConclusionSuccess
+	}
+
+	return b.Conclusions[min(attempt, len(b.Conclusions))-1]
+}
+
+// demoWorkflow is a workflow file of the demo repository.
+type demoWorkflow struct {
+	workflow.WorkflowFile
+	runName string
+}
+
+// Server is an in-process fake of GitHub and the gh CLI serving the demo
+// repository. Dispatched runs play out as configured by their workflow's
+// Behavior: queued, then in progress one step at a time while their logs are
+// written, then completed. Their state is derived from the clock whenever it
+// is read, so runs progress without anything running in the background.
+//
+// Server implements exec.CommandExecutor and github.RESTClient; the client
+// returned by Client uses it in place of gh and the REST API.
+type Server struct {
+	owner         string
+	repo          string
+	actor         string
+	defaultBranch string
+	branches      []string
+	environments  []string
+	releases      []github.Release
+	files         map[string][]byte // repository path to content
+	workflows     map[string]demoWorkflow
+
+	mu              sync.Mutex
+	now             func() time.Time
+	behaviors       map[string]Behavior
+	defaultBehavior Behavior
+	runs            map[int64]*run
+	nextRunID       int64
+	nextJobID       int64
+}
+
+// NewServer creates a server for the demo repository, whose runs take tens of
+// seconds and whose end-to-end tests fail on their first attempt.
+func NewServer() *Server {
+	owner, repo, _ := strings.Cut(Repository, "/")
+
+	s := &Server{
+		owner:         owner,
+		repo:          repo,
+		actor:         "demo-user",
+		defaultBranch: "main",
+		branches:      []string{"main", "develop", "release/v1.2"},
+		environments:  []string{"staging", "production"},
+		releases: []github.Release{
+			{TagName: "v1.2.3", Name: "v1.2.3"},
+			{TagName: "v1.2.2", Name: "v1.2.2"},
+			{TagName: "v1.2.0", Name: "v1.2.0"},
+			{TagName: "v1.1.0", Name: "v1.1.0"},
+		},
+		files:     make(map[string][]byte),
+		workflows: make(map[string]demoWorkflow),
+		now:       time.Now,
+		behaviors: make(map[string]Behavior),
+		runs:      make(map[int64]*run),
+		nextRunID: 4201,
+		nextJobID: 9001,
+		defaultBehavior: Behavior{
+			QueueDelay: 3 * time.Second,
+			Duration:   15 * time.Second,
+		},
+	}
+
+	s.loadRepo()
+
+	s.behaviors["ci.yml"] = Behavior{
+		QueueDelay: 4 * time.Second,
+		Duration:   24 * time.Second,
+		Jobs: []JobSpec{
+			{Name: "lint", Steps: []string{"Set up job", "Run actions/checkout@v4", "Run golangci-lint", "Complete job"}},
+			{Name: "test", Steps: []string{"Set up job", "Run actions/checkout@v4", "Run go test ./...", "Complete job"}},
+		},
+	}
+	s.behaviors["deploy.yml"] = Behavior{
+		QueueDelay: 3 * time.Second,
+		Duration:   20 * time.Second,
+		Jobs: []JobSpec{
+			{Name: "deploy", Steps: []string{"Set up job", "Run actions/checkout@v4", "Deploy", "Smoke test", "Complete job"}},
+		},
+	}
+	s.behaviors["release.yml"] = Behavior{
+		QueueDelay: 3 * time.Second,
+		Duration:   18 * time.Second,
+		Jobs: []JobSpec{
+			{Name: "build", Steps: []string{"Set up job", "Run actions/checkout@v4", "Build binaries", "Complete job"}},
+			{Name: "publish", Steps: []string{"Set up job", "Publish release", "Upload outputs", "Complete job"}},
+		},
+		Outputs: map[string]string{"version": "${{ inputs.version }}"},
+	}
+	s.behaviors["e2e.yml"] = Behavior{
+		QueueDelay:  3 * time.Second,
+		Duration:    15 * time.Second,
+		Conclusions: []string{github.ConclusionFailure, github.ConclusionSuccess},
+		FailStep:    "Run Playwright tests",
+		Jobs: []JobSpec{
+			{Name: "e2e", Steps: []string{"Set up job", "Run actions/checkout@v4", "Run Playwright tests", "Complete job"}},
+		},
+	}
+
+	return s
+}
+
+// loadRepo reads the embedded demo repository.
+func (s *Server) loadRepo() {
+	_ = fs.WalkDir(repoFiles, "repo", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		data, err := repoFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		repoPath := path.Join(".github", strings.TrimPrefix(name, "repo/"))
+		s.files[repoPath] = data
+
+		if path.Dir(repoPath) != workflow.WorkflowDir {
+			return nil
+		}
+
+		wf, err := workflow.Parse(data)
+		if err != nil {
+			return nil
+		}
+
+		var meta struct {
+			RunName string `yaml:"run-name"`
+		}
+
+		_ = yaml.Unmarshal(data, &meta)
+
+		wf.Filename = path.Base(repoPath)
+		s.workflows[wf.Filename] = demoWorkflow{WorkflowFile: wf, runName: meta.RunName}
+
+		return nil
+	})
+}
+
+// Client returns a GitHub client for the demo repository that is served by s.
+func (s *Server) Client() *github.Client {
+	client, _ := github.NewClientWithExecutor(s.owner+"/"+s.repo, s) // Repository is always owner/name
+
+	return client.WithRESTClient(s)
+}
+
+// SetBehavior configures how runs of a workflow, by filename, play out.
+// It applies to runs dispatched afterwards.
+func (s *Server) SetBehavior(workflow string, b Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.behaviors[workflow] = b
+}
+
+// SetDefaultBehavior configures runs of workflows without a Behavior of their own.
+func (s *Server) SetDefaultBehavior(b Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.defaultBehavior = b
+}
+
+// SetClock replaces the clock runs progress by, e.g. with one tests advance by hand.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = now
+}
+
+func (s *Server) behavior(workflow string) Behavior {
+	if b, ok := s.behaviors[workflow]; ok {
+		return b
+	}
+
+	return s.defaultBehavior
+}
+
+// Execute implements exec.CommandExecutor for the gh commands lazydispatch runs.
+func (s *Server) Execute(name string, args ...string) (string, string, error) {
+	if name != "gh" || len(args) == 0 {
+		return "", name + ": command not available in demo mode", errCommandFailed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case args[0] == "--version":
+		return "gh version 2.63.0 (demo)\n", "", nil
+	case args[0] == "auth" && len(args) > 1 && args[1] == "status":
+		return "", "Logged in to github.com account " + s.actor + " (demo)\n", nil
+	case args[0] == "api" && len(args) == 2:
+		return s.api(args[1])
+	case args[0] == "api" && len(args) == 5 && args[1] == "--include" && args[2] == "-H":
+		return s.rangedAPI(args[3], args[4])
+	case args[0] == "run" && len(args) > 2:
+		return s.runCommand(args[1], args[2:])
+	case args[0] == "workflow" && len(args) > 2 && args[1] == "run":
+		return s.workflowRun(args[2:])
+	}
+
+	return "", "unknown command: gh " + strings.Join(args, " "), errCommandFailed
+}
+
+// Post implements github.RESTClient for workflow dispatches.
+func (s *Server) Post(apiPath string, body io.Reader, _ interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(apiPath, "/")
+	if len(parts) != 7 || !s.isRepo(parts[:3]) || parts[3] != "actions" || parts[4] != "workflows" || parts[6] != "dispatches" {
+		return &api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found"}
+	}
+
+	var req struct {
+		Ref    string         `json:"ref"`
+		Inputs map[string]any `json:"inputs"`
+	}
+
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return &api.HTTPError{StatusCode: http.StatusBadRequest, Message: "Problems parsing JSON"}
+	}
+
+	inputs := make(map[string]string, len(req.Inputs))
+	for k, v := range req.Inputs {
+		inputs[k] = fmt.Sprint(v)
+	}
+
+	workflowName, _ := url.PathUnescape(parts[5])
+
+	if status, message := s.dispatch(workflowName, req.Ref, inputs); status != 0 {
+		return &api.HTTPError{StatusCode: status, Message: message}
+	}
+
+	return nil
+}
+
+// dispatch creates a run as GitHub does for a workflow_dispatch event. A
+// rejected dispatch returns the HTTP status and message GitHub responds with.
+func (s *Server) dispatch(workflowName, ref string, inputs map[string]string) (int, string) {
+	wf, ok := s.workflows[workflowName]
+	if !ok || !wf.IsDispatchable() {
+		return http.StatusNotFound, "Not Found"
+	}
+
+	if !slices.Contains(s.branches, ref) {
+		return http.StatusUnprocessableEntity, "No ref found for: " + ref
+	}
+
+	declared := wf.GetInputs()
+
+	var unexpected []string
+
+	for name := range inputs {
+		if _, ok := declared[name]; !ok {
+			unexpected = append(unexpected, name)
+		}
+	}
+
+	if len(unexpected) > 0 {
+		sort.Strings(unexpected)
+		return http.StatusUnprocessableEntity, fmt.Sprintf("Unexpected inputs provided: %q", unexpected)
+	}
+
+	values := make(map[string]string, len(declared))
+
+	for name, input := range declared {
+		value, ok := inputs[name]
+		if !ok {
+			value = input.Default
+		}
+
+		if input.Required && value == "" {
+			return http.StatusUnprocessableEntity, fmt.Sprintf("Required input '%s' not provided", name)
+		}
+
+		values[name] = value
+	}
+
+	now := s.now()
+	behavior := s.behavior(workflowName)
+
+	title := wf.Name
+	if wf.runName != "" {
+		title = strings.TrimSpace(renderInputs(wf.runName, values))
+	}
+
+	r := &run{
+		id:        s.nextRunID,
+		workflow:  workflowName,
+		name:      wf.Name,
+		title:     title,
+		branch:    ref,
+		inputs:    values,
+		actor:     s.actor,
+		htmlURL:   fmt.Sprintf("https://github.com/%s/%s/actions/runs/%d", s.owner, s.repo, s.nextRunID),
+		createdAt: now,
+		behavior:  behavior,
+	}
+
+	s.nextRunID++
+	s.runs[r.id] = r
+	s.startAttempt(r, now)
+
+	return 0, ""
+}
+
+// startAttempt queues a new attempt of r, with new job IDs as on GitHub.
+func (s *Server) startAttempt(r *run, now time.Time) {
+	a := &attempt{
+		number:     len(r.attempts) + 1,
+		queuedAt:   now,
+		conclusion: r.behavior.conclusion(len(r.attempts) + 1),
+	}
+
+	for range r.behavior.jobs() {
+		a.jobIDs = append(a.jobIDs, s.nextJobID)
+		s.nextJobID++
+	}
+
+	r.attempts = append(r.attempts, a)
+}
+
+var inputReference = regexp.MustCompile(`\$\{\{\s*inputs\.([A-Za-z0-9_-]+)\s*\}\}`)
+
+// renderInputs replaces ${{ inputs.<name> }} references with input values.
+func renderInputs(template string, inputs map[string]string) string {
+	return inputReference.ReplaceAllStringFunc(template, func(ref string) string {
+		return inputs[inputReference.FindStringSubmatch(ref)[1]]
+	})
+}
+
+// workflowRun handles gh workflow run <workflow> [--repo R] [--ref REF] [-f k=v]...
+func (s *Server) workflowRun(args []string) (string, string, error) {
+	workflowName := args[0]
+	ref := s.defaultBranch
+	inputs := make(map[string]string)
+
+	for i := 1; i < len(args)-1; i += 2 {
+		switch args[i] {
+		case "--repo", "-R":
+			if args[i+1] != s.owner+"/"+s.repo {
+				return "", "HTTP 404: Not Found", errCommandFailed
+			}
+		case "--ref", "-r":
+			ref = args[i+1]
+		case "-f", "--raw-field", "-F", "--field":
+			k, v, _ := strings.Cut(args[i+1], "=")
+			inputs[k] = v
+		}
+	}
+
+	if status, message := s.dispatch(workflowName, ref, inputs); status != 0 {
+		return "", fmt.Sprintf("could not create workflow dispatch event: HTTP %d: %s", status, message), errCommandFailed
+	}
+
+	return fmt.Sprintf("Created workflow_dispatch event for %s at %s\n", workflowName, ref), "", nil
+}
+
+// runCommand handles gh run view, cancel and rerun.
+func (s *Server) runCommand(command string, args []string) (string, string, error) {
+	runID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return "", "invalid run ID: " + args[0], errCommandFailed
+	}
+
+	r, ok := s.runs[runID]
+	if !ok {
+		return notFound()
+	}
+
+	now := s.now()
+
+	switch command {
+	case "view":
+		if !slices.Contains(args, "--log") {
+			break
+		}
+
+		if i := slices.Index(args, "--job"); i >= 0 && i+1 < len(args) {
+			jobID, _ := strconv.ParseInt(args[i+1], 10, 64)
+			return r.viewLog(now, jobID)
+		}
+
+		return r.viewLog(now, 0)
+	case "cancel":
+		if r.state(now).status == github.StatusCompleted {
+			return "", "Cannot cancel a workflow run that is completed.", errCommandFailed
+		}
+
+		r.current().cancelledAt = now
+
+		return "", "", nil
+	case "rerun":
+		if r.state(now).status != github.StatusCompleted {
+			return "", fmt.Sprintf("run %d cannot be rerun; it is still in progress", runID), errCommandFailed
+		}
+
+		// --failed re-runs every job: the demo's jobs depend on each other
+		s.startAttempt(r, now)
+
+		return "", "", nil
+	}
+
+	return "", "unknown command: gh run " + command, errCommandFailed
+}
+
+// api handles gh api <path>.
+func (s *Server) api(apiPath string) (string, string, error) {
+	if apiPath == "user" {
+		return jsonResponse(github.Actor{Login: s.actor})
+	}
+
+	u, err := url.Parse(apiPath)
+	if err != nil {
+		return notFound()
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || !s.isRepo(parts[:3]) {
+		return notFound()
+	}
+
+	rest := parts[3:]
+	query := u.Query()
+
+	switch {
+	case len(rest) == 0:
+		return jsonResponse(github.Repository{FullName: s.owner + "/" + s.repo, DefaultBranch: s.defaultBranch})
+	case len(rest) == 1 && rest[0] == "branches":
+		branches := make([]github.Branch, len(s.branches))
+		for i, name := range s.branches {
+			branches[i] = github.Branch{Name: name}
+		}
+
+		return jsonResponse(branches)
+	case len(rest) == 1 && rest[0] == "environments":
+		environments := make([]github.Environment, len(s.environments))
+		for i, name := range s.environments {
+			environments[i] = github.Environment{Name: name}
+		}
+
+		return jsonResponse(github.EnvironmentsResponse{TotalCount: len(environments), Environments: environments})
+	case len(rest) == 1 && rest[0] == "releases":
+		return jsonResponse(s.releases)
+	case rest[0] == "contents":
+		return s.contents(path.Join(rest[1:]...), query.Get("ref"))
+	case rest[0] == "actions" && len(rest) > 1:
+		return s.actions(rest[1:], query)
+	}
+
+	return notFound()
+}
+
+// rangedAPI handles gh api --include -H "Range: bytes=<offset>-" <path>, which reads
+// a job's log from an offset on.
+func (s *Server) rangedAPI(header, apiPath string) (string, string, error) {
+	parts := strings.Split(strings.Trim(apiPath, "/"), "/")
+	if len(parts) != 7 || !s.isRepo(parts[:3]) || parts[3] != "actions" || parts[4] != "jobs" || parts[6] != "logs" {
+		return notFound()
+	}
+
+	jobID, _ := strconv.ParseInt(parts[5], 10, 64)
+
+	offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, "Range: bytes="), "-"))
+	if err != nil {
+		return "", "invalid range: " + header, errCommandFailed
+	}
+
+	now := s.now()
+
+	for _, r := range s.runs {
+		log, ok := r.jobLog(now, jobID)
+		if !ok {
+			continue
+		}
+
+		if offset >= len(log) {
+			return "HTTP/1.1 416 Range Not Satisfiable\n\n", "gh: HTTP 416", errCommandFailed
+		}
+
+		return fmt.Sprintf("HTTP/1.1 206 Partial Content\nContent-Range: bytes %d-%d/%d\n\n%s",
+			offset, len(log)-1, len(log), log[offset:]), "", nil
+	}
+
+	return notFound()
+}
+
+func (s *Server) isRepo(parts []string) bool {
+	return parts[0] == "repos" && parts[1] == s.owner && parts[2] == s.repo
+}
+
+// contents serves the contents API: a file, or the entries of a directory.
+func (s *Server) contents(filePath, ref string) (string, string, error) {
+	if ref != "" && !slices.Contains(s.branches, ref) {
+		return notFound()
+	}
+
+	if data, ok := s.files[filePath]; ok {
+		return jsonResponse(github.ContentEntry{
+			Name:     path.Base(filePath),
+			Path:     filePath,
+			Type:     "file",
+			Content:  base64.StdEncoding.EncodeToString(data),
+			Encoding: "base64",
+		})
+	}
+
+	seen := make(map[string]bool)
+
+	var entries []github.ContentEntry
+
+	for name := range s.files {
+		rel, ok := strings.CutPrefix(name, filePath+"/")
+		if filePath == "" {
+			rel, ok = name, true
+		}
+
+		if !ok {
+			continue
+		}
+
+		child, _, isDir := strings.Cut(rel, "/")
+		if seen[child] {
+			continue
+		}
+
+		seen[child] = true
+
+		entry := github.ContentEntry{Name: child, Path: path.Join(filePath, child), Type: "file"}
+		if isDir {
+			entry.Type = "dir"
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return notFound()
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return jsonResponse(entries)
+}
+
+// actions serves the Actions API below repos/{owner}/{repo}/actions.
+func (s *Server) actions(parts []string, query url.Values) (string, string, error) {
+	now := s.now()
+
+	switch {
+	case len(parts) == 1 && parts[0] == "runs":
+		return s.listRuns(now, query.Get("workflow"), query)
+	case len(parts) == 3 && parts[0] == "workflows" && parts[2] == "runs":
+		workflowName, _ := url.PathUnescape(parts[1])
+		if _, ok := s.workflows[workflowName]; !ok {
+			return notFound()
+		}
+
+		return s.listRuns(now, workflowName, query)
+	case len(parts) == 3 && parts[0] == "artifacts" && parts[2] == "zip":
+		runID, _ := strconv.ParseInt(parts[1], 10, 64)
+		if r, ok := s.runs[runID]; ok {
+			if archive, ok := r.outputsArchive(now); ok {
+				return string(archive), "", nil
+			}
+		}
+
+		return notFound()
+	case len(parts) < 2 || parts[0] != "runs":
+		return notFound()
+	}
+
+	runID, _ := strconv.ParseInt(parts[1], 10, 64)
+
+	r, ok := s.runs[runID]
+	if !ok {
+		return notFound()
+	}
+
+	switch {
+	case len(parts) == 2:
+		return jsonResponse(r.workflowRun(now))
+	case parts[2] == "jobs":
+		jobs := r.jobs(now)
+		return jsonResponse(github.JobsResponse{TotalCount: len(jobs), Jobs: jobs})
+	case parts[2] == "logs":
+		archive, ok := r.logArchive(now)
+		if !ok {
+			return notFound()
+		}
+
+		return string(archive), "", nil
+	case parts[2] == "artifacts":
+		artifacts := r.artifacts(now)
+		return jsonResponse(github.ArtifactsResponse{TotalCount: len(artifacts), Artifacts: artifacts})
+	}
+
+	return notFound()
+}
+
+// listRuns lists runs newest first, filtered like the API filters them.
+func (s *Server) listRuns(now time.Time, workflowName string, query url.Values) (string, string, error) {
+	var createdAfter time.Time
+	if created, ok := strings.CutPrefix(query.Get("created"), ">="); ok {
+		createdAfter, _ = time.Parse(time.RFC3339, created)
+	}
+
+	ids := make([]int64, 0, len(s.runs))
+	for id := range s.runs {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+	slices.Reverse(ids)
+
+	var runs []github.WorkflowRun
+
+	for _, id := range ids {
+		r := s.runs[id]
+
+		switch {
+		case workflowName != "" && r.workflow != workflowName && r.name != workflowName,
+			query.Get("event") != "" && query.Get("event") != github.EventWorkflowDispatch,
+			query.Get("branch") != "" && query.Get("branch") != r.branch,
+			query.Get("actor") != "" && query.Get("actor") != s.actor,
+			r.createdAt.Before(createdAfter):
+			continue
+		}
+
+		runs = append(runs, r.workflowRun(now))
+	}
+
+	if perPage, err := strconv.Atoi(query.Get("per_page")); err == nil && perPage < len(runs) {
+		runs = runs[:perPage]
+	}
+
+	return jsonResponse(github.RunsResponse{TotalCount: len(runs), WorkflowRuns: runs})
+}
+
+func jsonResponse(v any) (string, string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err.Error(), errCommandFailed
+	}
+
+	return string(data), "", nil
+}
+
+// notFound fails like gh api does for a 404 response.
+func notFound() (string, string, error) {
+	return "", "gh: Not Found (HTTP 404)", errCommandFailed
+}
@@ -0,0 +1,384 @@
+package demo_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/demo"
+	apperrors "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
+	"github.com/kyleking/gh-lazydispatch/internal/runner"
+	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+// fakeClock is advanced by hand.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+func newServer(t *testing.T) (*demo.Server, *github.Client, *fakeClock) {
+	t.Helper()
+
+	server := demo.NewServer()
+	clock := newFakeClock()
+	server.SetClock(clock.Now)
+
+	return server, server.Client(), clock
+}
+
+func dispatch(t *testing.T, client *github.Client, cfg runner.RunConfig) *github.WorkflowRun {
+	t.Helper()
+
+	if cfg.Branch == "" {
+		cfg.Branch = "main"
+	}
+
+	cfg.Correlation = runner.DefaultCorrelationOptions
+	cfg.Correlation.MaxAttempts = 1
+
+	runID, err := runner.ExecuteAndGetRunID(cfg, client)
+	if err != nil {
+		t.Fatalf("dispatch %s: %v", cfg.Workflow, err)
+	}
+
+	run, err := client.GetWorkflowRun(runID)
+	if err != nil {
+		t.Fatalf("GetWorkflowRun: %v", err)
+	}
+
+	return run
+}
+
+func TestServer_Repository(t *testing.T) {
+	_, client, _ := newServer(t)
+
+	info, err := client.GetRepository()
+	if err != nil || info.FullName != demo.Repository || info.DefaultBranch != "main" {
+		t.Fatalf("GetRepository = %+v, %v", info, err)
+	}
+
+	workflows, err := workflow.DiscoverFrom(client, "release/v1.2")
+	if err != nil {
+		t.Fatalf("DiscoverFrom: %v", err)
+	}
+
+	var names []string
+	for _, wf := range workflows {
+		names = append(names, wf.Filename)
+	}
+
+	if got := strings.Join(names, ","); got != "ci.yml,deploy.yml,e2e.yml,release.yml" {
+		t.Errorf("workflows = %s", got)
+	}
+
+	data, err := client.ReadFile(config.ConfigFilename, "main")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	cfg, err := config.Parse(data)
+	if err != nil {
+		t.Fatalf("demo lazydispatch.yml is invalid: %v", err)
+	}
+
+	if _, ok := cfg.GetChain("ship"); !ok {
+		t.Error("ship chain missing")
+	}
+
+	if _, err := client.ReadFile(config.ConfigFilename, "no-such-branch"); !errors.Is(err, github.ErrNotFound) {
+		t.Errorf("unknown ref: got %v, want ErrNotFound", err)
+	}
+
+	environments, err := client.ListEnvironments()
+	if err != nil || strings.Join(environments, ",") != "staging,production" {
+		t.Errorf("ListEnvironments = %v, %v", environments, err)
+	}
+
+	releases, err := client.ListReleases()
+	if err != nil || len(releases) == 0 || releases[0].TagName != "v1.2.3" {
+		t.Errorf("ListReleases = %+v, %v", releases, err)
+	}
+}
+
+func TestServer_RunLifecycle(t *testing.T) {
+	_, client, clock := newServer(t)
+
+	run := dispatch(t, client, runner.RunConfig{
+		Workflow:         "ci.yml",
+		Inputs:           map[string]string{"suite": "all"},
+		CorrelationInput: "correlation_id",
+	})
+
+	if run.Status != github.StatusQueued || !strings.HasPrefix(run.Title, "CI (all) ") || run.Actor.Login != "demo-user" {
+		t.Fatalf("dispatched run = %+v", run)
+	}
+
+	// ci.yml is queued for 4s, then runs 8 steps over 24s
+	clock.Advance(10 * time.Second)
+
+	jobs, err := client.GetWorkflowRunJobs(run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jobs) != 2 || jobs[0].Status != github.StatusInProgress || jobs[1].Status != github.StatusQueued {
+		t.Fatalf("jobs while running = %+v", jobs)
+	}
+
+	if got := jobs[0].Steps[1]; got.Status != github.StatusCompleted || got.Conclusion != github.ConclusionSuccess {
+		t.Errorf("checkout step = %+v", got)
+	}
+
+	partial, _, err := client.Executor().Execute("gh", "run", "view", "4201", "--log", "--job", "9001")
+	if err != nil || !strings.Contains(partial, "lint\tSet up job\t") {
+		t.Fatalf("partial log = %q, %v", partial, err)
+	}
+
+	clock.Advance(time.Minute)
+
+	run, _ = client.GetWorkflowRun(run.ID)
+	if run.Status != github.StatusCompleted || run.Conclusion != github.ConclusionSuccess {
+		t.Fatalf("finished run = %s/%s", run.Status, run.Conclusion)
+	}
+
+	full, _, _ := client.Executor().Execute("gh", "run", "view", "4201", "--log", "--job", "9001")
+	if !strings.HasPrefix(full, partial) || !strings.Contains(full, "##[warning]") {
+		t.Errorf("log is not written incrementally:\n%s", full)
+	}
+}
+
+func TestServer_FailureAndRerun(t *testing.T) {
+	_, client, clock := newServer(t)
+
+	run := dispatch(t, client, runner.RunConfig{Workflow: "e2e.yml"})
+
+	clock.Advance(time.Minute)
+
+	run, _ = client.GetWorkflowRun(run.ID)
+	if run.Conclusion != github.ConclusionFailure {
+		t.Fatalf("first attempt: conclusion %q, want failure", run.Conclusion)
+	}
+
+	jobs, _ := client.GetWorkflowRunJobs(run.ID)
+
+	steps := jobs[0].Steps
+	if steps[2].Conclusion != github.ConclusionFailure || steps[3].Conclusion != github.ConclusionSkipped {
+		t.Errorf("steps = %+v", steps)
+	}
+
+	runLogs, err := logs.NewManager(client, t.TempDir()).GetLogsForRun(run.ID, "e2e.yml", nil)
+	if err != nil {
+		t.Fatalf("GetLogsForRun: %v", err)
+	}
+
+	var errorLines []string
+
+	for _, step := range runLogs.AllSteps() {
+		for _, entry := range step.Entries {
+			if entry.Level == logs.LogLevelError {
+				errorLines = append(errorLines, step.StepName+": "+entry.Content)
+			}
+		}
+	}
+
+	if len(errorLines) == 0 || errorLines[len(errorLines)-1] != "Run Playwright tests: Process completed with exit code 1." {
+		t.Errorf("error lines = %q", errorLines)
+	}
+
+	if err := client.RerunFailedJobs(run.ID); err != nil {
+		t.Fatalf("RerunFailedJobs: %v", err)
+	}
+
+	rerun, _ := client.GetWorkflowRun(run.ID)
+	if rerun.RunAttempt != 2 || rerun.Status != github.StatusQueued {
+		t.Fatalf("rerun = attempt %d, %s", rerun.RunAttempt, rerun.Status)
+	}
+
+	if rerunJobs, _ := client.GetWorkflowRunJobs(run.ID); rerunJobs[0].ID == jobs[0].ID {
+		t.Error("rerun reused the job IDs of the first attempt")
+	}
+
+	clock.Advance(time.Minute)
+
+	if rerun, _ = client.GetWorkflowRun(run.ID); rerun.Conclusion != github.ConclusionSuccess {
+		t.Errorf("second attempt: conclusion %q, want success", rerun.Conclusion)
+	}
+}
+
+func TestServer_Cancel(t *testing.T) {
+	_, client, clock := newServer(t)
+
+	run := dispatch(t, client, runner.RunConfig{Workflow: "deploy.yml", Inputs: map[string]string{"environment": "production"}})
+
+	if run.Title != "Deploy latest to production" {
+		t.Errorf("title = %q", run.Title)
+	}
+
+	// Queued for 3s, then 4s per step: cancel during the checkout
+	clock.Advance(8 * time.Second)
+
+	if err := client.CancelRun(run.ID); err != nil {
+		t.Fatalf("CancelRun: %v", err)
+	}
+
+	clock.Advance(time.Second)
+
+	run, _ = client.GetWorkflowRun(run.ID)
+	if run.Status != github.StatusCompleted || run.Conclusion != github.ConclusionCancelled {
+		t.Fatalf("cancelled run = %s/%s", run.Status, run.Conclusion)
+	}
+
+	jobs, _ := client.GetWorkflowRunJobs(run.ID)
+
+	var conclusions []string
+	for _, step := range jobs[0].Steps {
+		conclusions = append(conclusions, step.Conclusion)
+	}
+
+	if got := strings.Join(conclusions, ","); got != "success,cancelled,skipped,skipped,skipped" {
+		t.Errorf("step conclusions = %s", got)
+	}
+
+	if err := client.CancelRun(run.ID); err == nil {
+		t.Error("cancelling a completed run should fail")
+	}
+}
+
+func TestServer_DispatchErrors(t *testing.T) {
+	_, client, _ := newServer(t)
+
+	tests := []struct {
+		name     string
+		workflow string
+		ref      string
+		inputs   map[string]any
+		reason   apperrors.DispatchFailure
+	}{
+		{"unknown workflow", "missing.yml", "main", nil, apperrors.DispatchWorkflowNotFound},
+		{"unknown ref", "ci.yml", "no-such-branch", nil, apperrors.DispatchInvalidInputs},
+		{"unexpected input", "ci.yml", "main", map[string]any{"nope": "x"}, apperrors.DispatchInvalidInputs},
+		{"missing required input", "release.yml", "main", map[string]any{"version": ""}, apperrors.DispatchInvalidInputs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.DispatchWorkflow(tt.workflow, tt.ref, tt.inputs)
+
+			var dispatchErr *apperrors.DispatchError
+			if !errors.As(err, &dispatchErr) || dispatchErr.Reason != tt.reason {
+				t.Errorf("got %v, want reason %v", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestServer_LogStreamer(t *testing.T) {
+	_, client, clock := newServer(t)
+
+	run := dispatch(t, client, runner.RunConfig{Workflow: "release.yml", Inputs: map[string]string{"version": "v2.0.0"}})
+
+	clock.Advance(time.Minute)
+
+	streamer := logs.NewLogStreamer(client, run.ID, "release.yml")
+	streamer.Start()
+
+	defer streamer.Stop()
+
+	select {
+	case update := <-streamer.Updates():
+		if update.Error != nil || update.Conclusion != github.ConclusionSuccess {
+			t.Fatalf("update = %+v", update)
+		}
+
+		var published bool
+
+		for _, step := range update.NewSteps {
+			for _, entry := range step.Entries {
+				published = published || strings.Contains(entry.Content, "tag=v2.0.0")
+			}
+		}
+
+		if len(update.NewSteps) != 8 || !published {
+			t.Errorf("streamed %d steps, release published: %v", len(update.NewSteps), published)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update from the log streamer")
+	}
+}
+
+func TestServer_Chain(t *testing.T) {
+	server, client, _ := newServer(t)
+	server.SetClock(time.Now)
+
+	// Fast runs with the demo's jobs, outputs and flaky end-to-end tests
+	fast := demo.Behavior{Duration: 20 * time.Millisecond}
+	server.SetBehavior("deploy.yml", fast)
+
+	release := fast
+	release.Outputs = map[string]string{"version": "${{ inputs.version }}"}
+	server.SetBehavior("release.yml", release)
+
+	e2e := fast
+	e2e.Conclusions = []string{github.ConclusionFailure, github.ConclusionSuccess}
+	server.SetBehavior("e2e.yml", e2e)
+
+	chain.SetPollInterval(time.Millisecond)
+	t.Cleanup(func() { chain.SetPollInterval(5 * time.Second) })
+
+	data, _ := client.ReadFile(config.ConfigFilename, "main")
+
+	cfg, err := config.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ship, _ := cfg.GetChain("ship")
+
+	executor := chain.NewExecutor(client, testutil.NewMockRunWatcher(), "ship", ship)
+	executor.SetOutputSource(chain.NewOutputFetcher(client, t.TempDir()))
+
+	if err := executor.Start(map[string]string{"version": "v3.1.0"}, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.DrainChainUpdates(t, executor.Updates(), 10*time.Second)
+
+	state := executor.State()
+	if state.Status != chain.ChainCompleted {
+		t.Fatalf("chain status = %s (%v)", state.Status, state.Error)
+	}
+
+	if got := state.StepResults[1].Inputs["version"]; got != "v3.1.0" {
+		t.Errorf("staging deploy version = %q, want the release step's output", got)
+	}
+
+	if attempts := state.StepResults[2].Attempts; len(attempts) != 2 || attempts[0].Conclusion != github.ConclusionFailure {
+		t.Errorf("e2e attempts = %+v, want a failed attempt re-run", attempts)
+	}
+}
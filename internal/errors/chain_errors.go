@@ -58,6 +58,22 @@ func (e *InterpolationError) Unwrap() error {
 	return e.Cause
 }
 
+// ConditionError represents an `if:` expression that could not be parsed.
+type ConditionError struct {
+	StepIndex  int
+	Workflow   string
+	Expression string
+	Cause      error
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("step %d (%s) has invalid if expression %q: %v", e.StepIndex+1, e.Workflow, e.Expression, e.Cause)
+}
+
+func (e *ConditionError) Unwrap() error {
+	return e.Cause
+}
+
 // RunWaitError represents an error while waiting for a run to complete.
 type RunWaitError struct {
 	RunID  int64
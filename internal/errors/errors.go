@@ -35,3 +35,61 @@ type ValidationBlockedError struct {
 func (e *ValidationBlockedError) Error() string {
 	return fmt.Sprintf("validation failed for input %s: %v", e.Input, e.Errors)
 }
+
+// RunNotFoundError indicates no run could be correlated with a dispatch.
+type RunNotFoundError struct {
+	Workflow string
+	Branch   string
+	Attempts int
+}
+
+func (e *RunNotFoundError) Error() string {
+	return fmt.Sprintf("no run found for workflow %s on %q after %d attempts", e.Workflow, e.Branch, e.Attempts)
+}
+
+// AmbiguousRunError indicates several runs matched a dispatch and none could be chosen safely.
+type AmbiguousRunError struct {
+	Workflow   string
+	Candidates []int64
+}
+
+func (e *AmbiguousRunError) Error() string {
+	return fmt.Sprintf("ambiguous run for workflow %s: %d candidates %v", e.Workflow, len(e.Candidates), e.Candidates)
+}
+
+// DispatchFailure classifies why a workflow dispatch was rejected.
+type DispatchFailure string
+
+const (
+	DispatchUnknown          DispatchFailure = "unknown"
+	DispatchInvalidInputs    DispatchFailure = "invalid_inputs"
+	DispatchWorkflowNotFound DispatchFailure = "workflow_not_found"
+	DispatchForbidden        DispatchFailure = "forbidden"
+)
+
+// DispatchError represents a workflow dispatch rejected by the GitHub API.
+type DispatchError struct {
+	Workflow   string
+	Ref        string
+	StatusCode int
+	Reason     DispatchFailure
+	Message    string
+	Err        error
+}
+
+func (e *DispatchError) Error() string {
+	switch e.Reason {
+	case DispatchInvalidInputs:
+		return fmt.Sprintf("workflow %s rejected inputs: %s", e.Workflow, e.Message)
+	case DispatchWorkflowNotFound:
+		return fmt.Sprintf("workflow %s not found on ref %q or lacks workflow_dispatch", e.Workflow, e.Ref)
+	case DispatchForbidden:
+		return fmt.Sprintf("not allowed to dispatch workflow %s: %s", e.Workflow, e.Message)
+	}
+
+	return fmt.Sprintf("failed to dispatch workflow %s (HTTP %d): %s", e.Workflow, e.StatusCode, e.Message)
+}
+
+func (e *DispatchError) Unwrap() error {
+	return e.Err
+}
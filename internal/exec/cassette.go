@@ -0,0 +1,419 @@
+package exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// RecordEnv is the environment variable that switches UseCassette to record mode.
+// Recording runs real gh commands, including ones that dispatch, cancel, or re-run workflows.
+const RecordEnv = "LAZYDISPATCH_RECORD"
+
+// Cassette is a recording of commands and REST requests with their results, saved as
+// YAML under testdata/.
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is one recorded command or REST request. Command is matched like a
+// MockExecutor key: the name and arguments joined by spaces, where * matches any run of
+// characters. A REST request is recorded as its method and path, e.g. POST repos/o/r/...,
+// with the request body in Body and the decoded response in Stdout.
+//
+// Timestamps in Stdout and Stderr are stored relative to the start of the recording, as
+// in {{ start+1m30s }}, and replayed relative to the start of the replay, so recorded
+// runs look as recent as they were when recorded.
+type Interaction struct {
+	Command string `yaml:"command"`
+	Body    string `yaml:"body,omitempty"`
+	Stdout  string `yaml:"stdout,omitempty"`
+	Stderr  string `yaml:"stderr,omitempty"`
+	Error   string `yaml:"error,omitempty"`
+	Status  int    `yaml:"status,omitempty"` // HTTP status of a failed REST request
+}
+
+// volatileArgs matches parts of commands that change from one run to the next, such as the
+// created>= timestamp of run correlation. Recorded commands have them replaced by *.
+var volatileArgs = regexp.MustCompile(`created=%3E%3D[^&\s]+`)
+
+var (
+	// timestampPattern matches the UTC timestamps of API responses, like created_at.
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z`)
+	// relativePattern matches a timestamp stored relative to the recording.
+	relativePattern = regexp.MustCompile(`\{\{ start([+-][0-9a-zµ.]+) \}\}`)
+)
+
+// relativeTimes replaces the timestamps in s by their offset from start.
+func relativeTimes(s string, start time.Time) string {
+	return timestampPattern.ReplaceAllStringFunc(s, func(match string) string {
+		t, err := time.Parse(time.RFC3339Nano, match)
+		if err != nil {
+			return match
+		}
+
+		offset := t.Sub(start)
+
+		sign := "+"
+		if offset < 0 {
+			sign, offset = "-", -offset
+		}
+
+		return "{{ start" + sign + offset.String() + " }}"
+	})
+}
+
+// absoluteTimes replaces the relative timestamps in s by times from start.
+func absoluteTimes(s string, start time.Time) string {
+	return relativePattern.ReplaceAllStringFunc(s, func(match string) string {
+		offset, err := time.ParseDuration(relativePattern.FindStringSubmatch(match)[1])
+		if err != nil {
+			return match
+		}
+
+		layout := time.RFC3339
+		if offset%time.Second != 0 {
+			layout = time.RFC3339Nano
+		}
+
+		return start.Add(offset).UTC().Format(layout)
+	})
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := yaml.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, creating its directory.
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Recorder runs commands through another executor and REST requests through a REST
+// client, and records them with their results.
+type Recorder struct {
+	executor CommandExecutor
+	rest     RESTClient
+	start    time.Time
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a recorder that runs commands with executor and sends REST requests
+// with rest, which may be nil when the test sends none.
+func NewRecorder(executor CommandExecutor, rest RESTClient) *Recorder {
+	// Whole seconds keep the offsets of API timestamps, which have no fraction, whole too
+	return &Recorder{executor: executor, rest: rest, start: time.Now().Truncate(time.Second)}
+}
+
+// Execute runs the command and records it.
+func (r *Recorder) Execute(name string, args ...string) (string, string, error) {
+	stdout, stderr, err := r.executor.Execute(name, args...)
+
+	interaction := Interaction{
+		Command: volatileArgs.ReplaceAllString(commandKey(name, args), "created=%3E%3D*"),
+		Stdout:  relativeTimes(stdout, r.start),
+		Stderr:  relativeTimes(stderr, r.start),
+	}
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+
+	r.record(interaction)
+
+	return stdout, stderr, err
+}
+
+// Post sends the request and records it.
+func (r *Recorder) Post(path string, body io.Reader, response interface{}) error {
+	if r.rest == nil {
+		return errors.New("recorder: no REST client to send the request with")
+	}
+
+	var data []byte
+
+	if body != nil {
+		var err error
+		if data, err = io.ReadAll(body); err != nil {
+			return err
+		}
+	}
+
+	err := r.rest.Post(path, bytes.NewReader(data), response)
+
+	interaction := Interaction{Command: "POST " + path, Body: string(data)}
+
+	var httpErr *api.HTTPError
+
+	switch {
+	case errors.As(err, &httpErr):
+		interaction.Error, interaction.Status = httpErr.Message, httpErr.StatusCode
+	case err != nil:
+		interaction.Error = err.Error()
+	case response != nil:
+		if encoded, encodeErr := json.Marshal(response); encodeErr == nil {
+			interaction.Stdout = relativeTimes(string(encoded), r.start)
+		}
+	}
+
+	r.record(interaction)
+
+	return err
+}
+
+func (r *Recorder) record(interaction Interaction) {
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+}
+
+// Cassette returns a copy of what has been recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Replayer serves the results recorded in a cassette. A command recorded several times,
+// like a run polled while it is queued, in progress, and completed, gets the results in
+// the order they were recorded; once they run out, the last one is repeated.
+type Replayer struct {
+	mu       sync.Mutex
+	commands []string                 // in the order first recorded
+	results  map[string][]Interaction // by command
+	served   map[string]int
+	start    time.Time
+
+	// ExecutedCommands tracks all commands that were executed.
+	ExecutedCommands []ExecutedCommand
+}
+
+// NewReplayer creates an executor that replays cassette.
+func NewReplayer(cassette *Cassette) *Replayer {
+	r := &Replayer{
+		results: make(map[string][]Interaction),
+		served:  make(map[string]int),
+		start:   time.Now(),
+	}
+
+	for _, interaction := range cassette.Interactions {
+		if _, ok := r.results[interaction.Command]; !ok {
+			r.commands = append(r.commands, interaction.Command)
+		}
+
+		r.results[interaction.Command] = append(r.results[interaction.Command], interaction)
+	}
+
+	return r
+}
+
+// Execute returns the next recorded result of the command. Commands are matched exactly
+// first, then against recorded commands containing wildcards.
+func (r *Replayer) Execute(name string, args ...string) (string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ExecutedCommands = append(r.ExecutedCommands, ExecutedCommand{Name: name, Args: args})
+
+	result, ok := r.next(commandKey(name, args))
+	if !ok {
+		return "", "", fmt.Errorf("replayer: no recorded result for command: %s", commandKey(name, args))
+	}
+
+	var err error
+	if result.Error != "" {
+		err = errors.New(result.Error)
+	}
+
+	return result.Stdout, result.Stderr, err
+}
+
+// Post returns the next recorded result of the request, failing when the request body
+// differs from the recorded one.
+func (r *Replayer) Post(path string, body io.Reader, response interface{}) error {
+	var data []byte
+
+	if body != nil {
+		var err error
+		if data, err = io.ReadAll(body); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ExecutedCommands = append(r.ExecutedCommands, ExecutedCommand{Name: "POST", Args: []string{path, string(data)}})
+
+	result, ok := r.next("POST " + path)
+	switch {
+	case !ok:
+		return fmt.Errorf("replayer: no recorded result for request: POST %s", path)
+	case result.Body != string(data):
+		return fmt.Errorf("replayer: body of POST %s differs from the recording: got %s, recorded %s", path, data, result.Body)
+	case result.Status != 0:
+		return &api.HTTPError{StatusCode: result.Status, Message: result.Error}
+	case result.Error != "":
+		return errors.New(result.Error)
+	case response != nil && result.Stdout != "":
+		return json.Unmarshal([]byte(result.Stdout), response)
+	}
+
+	return nil
+}
+
+// next returns the result to serve for a command or request, with its timestamps made
+// relative to the start of the replay.
+func (r *Replayer) next(cmdKey string) (Interaction, bool) {
+	recorded, ok := r.lookup(cmdKey)
+	if !ok {
+		return Interaction{}, false
+	}
+
+	results := r.results[recorded]
+	result := results[min(r.served[recorded], len(results)-1)]
+	r.served[recorded]++
+
+	result.Stdout = absoluteTimes(result.Stdout, r.start)
+	result.Stderr = absoluteTimes(result.Stderr, r.start)
+
+	return result, true
+}
+
+func (r *Replayer) lookup(cmdKey string) (string, bool) {
+	if _, ok := r.results[cmdKey]; ok {
+		return cmdKey, true
+	}
+
+	for _, recorded := range r.commands {
+		if strings.Contains(recorded, "*") && matchesWildcard(cmdKey, recorded) {
+			return recorded, true
+		}
+	}
+
+	return "", false
+}
+
+// Unplayed lists recorded commands that have not been executed, or were executed fewer
+// times than they were recorded.
+func (r *Replayer) Unplayed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unplayed []string
+
+	for _, recorded := range r.commands {
+		if r.served[recorded] < len(r.results[recorded]) {
+			unplayed = append(unplayed, recorded)
+		}
+	}
+
+	return unplayed
+}
+
+// CassettePlayer serves the gh commands and REST requests of a test, such as those of a
+// github.Client given it as executor and REST client.
+type CassettePlayer interface {
+	CommandExecutor
+	RESTClient
+}
+
+// UseCassette returns the commands and REST requests for a test backed by the cassette at
+// path. It replays the cassette, failing the test when it cannot be read. With RecordEnv
+// set, it runs real commands and requests instead and saves them to path once the test
+// finishes.
+func UseCassette(t testing.TB, path string) CassettePlayer {
+	t.Helper()
+
+	if os.Getenv(RecordEnv) != "" {
+		rest, err := api.DefaultRESTClient()
+		if err != nil {
+			t.Fatalf("recording a cassette requires gh credentials: %v", err)
+		}
+
+		recorder := NewRecorder(&RealExecutor{allowMutations: true}, &RealRESTClient{rest: rest, allowMutations: true})
+
+		t.Cleanup(func() {
+			if err := recorder.Cassette().Save(path); err != nil {
+				t.Errorf("failed to save cassette: %v", err)
+			}
+		})
+
+		return recorder
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette (record it with %s=1): %v", RecordEnv, err)
+	}
+
+	return NewReplayer(cassette)
+}
+
+func commandKey(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
+
+// matchesWildcard reports whether s matches pattern, where * matches any run of characters.
+func matchesWildcard(s, pattern string) bool {
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+
+	s = s[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 {
+			return strings.HasSuffix(s, part)
+		}
+
+		idx := strings.Index(s, part)
+		if idx < 0 {
+			return false
+		}
+
+		s = s[idx+len(part):]
+	}
+
+	return s == ""
+}
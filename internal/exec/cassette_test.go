@@ -0,0 +1,232 @@
+package exec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestReplayer_ServesSequenceAndRepeatsLast(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []Interaction{
+		{Command: "gh api repos/o/r/actions/runs/1", Stdout: "queued"},
+		{Command: "gh api user", Stdout: "octocat"},
+		{Command: "gh api repos/o/r/actions/runs/1", Stdout: "in_progress"},
+		{Command: "gh api repos/o/r/actions/runs/1", Stdout: "completed"},
+	}})
+
+	var got []string
+
+	for range 4 {
+		stdout, _, err := replayer.Execute("gh", "api", "repos/o/r/actions/runs/1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got = append(got, stdout)
+	}
+
+	want := []string{"queued", "in_progress", "completed", "completed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if unplayed := replayer.Unplayed(); !reflect.DeepEqual(unplayed, []string{"gh api user"}) {
+		t.Errorf("Unplayed: got %v, want [gh api user]", unplayed)
+	}
+
+	if len(replayer.ExecutedCommands) != 4 {
+		t.Errorf("ExecutedCommands: got %d, want 4", len(replayer.ExecutedCommands))
+	}
+}
+
+func TestReplayer_Wildcards(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []Interaction{
+		{Command: "gh api repos/o/r/actions/workflows/ci.yml/runs?created=%3E%3D*&per_page=20", Stdout: "runs"},
+		{Command: "gh run view * --log", Stdout: "log"},
+	}})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"api", "repos/o/r/actions/workflows/ci.yml/runs?created=%3E%3D2026-10-14T10%3A02%3A26Z&per_page=20"}, "runs"},
+		{[]string{"run", "view", "42", "--log"}, "log"},
+	}
+
+	for _, tt := range tests {
+		stdout, _, err := replayer.Execute("gh", tt.args...)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.args, err)
+		}
+
+		if stdout != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.args, stdout, tt.want)
+		}
+	}
+
+	if _, _, err := replayer.Execute("gh", "run", "view", "42", "--log", "--job", "7"); err == nil {
+		t.Error("expected an error for a command that was not recorded")
+	}
+}
+
+func TestReplayer_Errors(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []Interaction{
+		{Command: "gh api repos/o/r/actions/runs/9", Stderr: "gh: Not Found (HTTP 404)", Error: "exit status 1"},
+	}})
+
+	_, stderr, err := replayer.Execute("gh", "api", "repos/o/r/actions/runs/9")
+	if err == nil || err.Error() != "exit status 1" {
+		t.Errorf("error: got %v, want exit status 1", err)
+	}
+
+	if stderr != "gh: Not Found (HTTP 404)" {
+		t.Errorf("stderr: got %q", stderr)
+	}
+}
+
+func TestRecorder_RecordsAndRoundTrips(t *testing.T) {
+	mock := NewMockExecutor()
+	mock.AddCommand("gh", []string{"api", "user"}, `{"login":"octocat"}`, "", nil)
+	mock.AddCommand("gh", []string{"api", "repos/o/r/actions/workflows/ci.yml/runs?created=%3E%3D2026-10-14T10%3A02%3A26Z&per_page=20"},
+		`{"workflow_runs":[]}`, "", nil)
+	mock.AddCommand("gh", []string{"run", "cancel", "1"}, "", "run 1 has already completed", errors.New("exit status 1"))
+
+	recorder := NewRecorder(mock, nil)
+	recorder.Execute("gh", "api", "user")
+	recorder.Execute("gh", "api", "repos/o/r/actions/workflows/ci.yml/runs?created=%3E%3D2026-10-14T10%3A02%3A26Z&per_page=20")
+	recorder.Execute("gh", "run", "cancel", "1")
+
+	want := []Interaction{
+		{Command: "gh api user", Stdout: `{"login":"octocat"}`},
+		{Command: "gh api repos/o/r/actions/workflows/ci.yml/runs?created=%3E%3D*&per_page=20", Stdout: `{"workflow_runs":[]}`},
+		{Command: "gh run cancel 1", Stderr: "run 1 has already completed", Error: "exit status 1"},
+	}
+
+	if got := recorder.Cassette().Interactions; !reflect.DeepEqual(got, want) {
+		t.Errorf("Interactions:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassettes", "recorded.yaml")
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Interactions, want) {
+		t.Errorf("loaded:\ngot  %+v\nwant %+v", loaded.Interactions, want)
+	}
+}
+
+func TestRecorder_RelativeTimes(t *testing.T) {
+	mock := NewMockExecutor()
+	recorder := NewRecorder(mock, nil)
+
+	created := recorder.start.Add(90 * time.Second).UTC().Format(time.RFC3339)
+	mock.AddCommand("gh", []string{"api", "repos/o/r/actions/runs/1"}, `{"created_at":"`+created+`"}`, "", nil)
+	recorder.Execute("gh", "api", "repos/o/r/actions/runs/1")
+
+	recorded := recorder.Cassette().Interactions[0].Stdout
+	if !strings.HasPrefix(recorded, `{"created_at":"{{ start+1m30`) {
+		t.Fatalf("recorded %s, want the time relative to the recording", recorded)
+	}
+
+	replayer := NewReplayer(recorder.Cassette())
+
+	stdout, _, err := replayer.Execute("gh", "api", "repos/o/r/actions/runs/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var run struct {
+		CreatedAt time.Time `json:"created_at"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &run); err != nil {
+		t.Fatalf("replayed %s: %v", stdout, err)
+	}
+
+	if offset := run.CreatedAt.Sub(replayer.start); offset < 89*time.Second || offset > 91*time.Second {
+		t.Errorf("replayed created_at %v after the replay started, want 1m30s", offset)
+	}
+}
+
+// restFunc adapts a function to RESTClient.
+type restFunc func(path string, body io.Reader, response interface{}) error
+
+func (f restFunc) Post(path string, body io.Reader, response interface{}) error {
+	return f(path, body, response)
+}
+
+func TestRecorder_RecordsRESTRequests(t *testing.T) {
+	recorder := NewRecorder(NewMockExecutor(), restFunc(func(path string, body io.Reader, _ interface{}) error {
+		if strings.Contains(path, "missing.yml") {
+			return &api.HTTPError{StatusCode: 404, Message: "Not Found"}
+		}
+
+		return nil
+	}))
+
+	dispatch := "repos/o/r/actions/workflows/ci.yml/dispatches"
+	missing := "repos/o/r/actions/workflows/missing.yml/dispatches"
+
+	if err := recorder.Post(dispatch, strings.NewReader(`{"ref":"main"}`), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = recorder.Post(missing, strings.NewReader(`{"ref":"main"}`), nil)
+
+	want := []Interaction{
+		{Command: "POST " + dispatch, Body: `{"ref":"main"}`},
+		{Command: "POST " + missing, Body: `{"ref":"main"}`, Error: "Not Found", Status: 404},
+	}
+	if got := recorder.Cassette().Interactions; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Interactions:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	replayer := NewReplayer(recorder.Cassette())
+
+	if err := replayer.Post(dispatch, strings.NewReader(`{"ref":"main"}`), nil); err != nil {
+		t.Errorf("replayed dispatch: %v", err)
+	}
+
+	var httpErr *api.HTTPError
+	if err := replayer.Post(missing, strings.NewReader(`{"ref":"main"}`), nil); !errors.As(err, &httpErr) || httpErr.StatusCode != 404 {
+		t.Errorf("replayed failure: got %v, want HTTP 404", err)
+	}
+
+	if err := replayer.Post(dispatch, strings.NewReader(`{"ref":"next"}`), nil); err == nil {
+		t.Error("expected an error for a body that differs from the recording")
+	}
+}
+
+func TestMatchesWildcard(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"gh api user", "gh api user", true},
+		{"gh api users", "gh api user", false},
+		{"gh run view 42 --log", "gh run view * --log", true},
+		{"gh run view 42 --log --job 1", "gh run view * --log", false},
+		{"gh api a?x=1&y=2", "gh api a?x=*&y=*", true},
+		{"gh api a?x=1", "*", true},
+		{"abcabc", "a*c", true},
+		{"abcab", "a*c", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesWildcard(tt.s, tt.pattern); got != tt.want {
+			t.Errorf("matchesWildcard(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+}
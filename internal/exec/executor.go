@@ -18,7 +18,9 @@ type CommandExecutor interface {
 }
 
 // RealExecutor executes actual system commands.
-type RealExecutor struct{}
+type RealExecutor struct {
+	allowMutations bool // set while recording a cassette, which asks for real dispatches
+}
 
 // NewRealExecutor creates an executor that runs real commands.
 func NewRealExecutor() *RealExecutor {
@@ -29,11 +31,11 @@ func NewRealExecutor() *RealExecutor {
 // It includes a safety check to prevent accidental mutation of GitHub resources during tests.
 func (e *RealExecutor) Execute(name string, args ...string) (string, string, error) {
 	// Safety check: Prevent mutation commands during tests
-	if testing.Testing() && isMutationCommand(name, args) {
+	if testing.Testing() && !e.allowMutations && isMutationCommand(name, args) {
 		panic(fmt.Sprintf(
 			"SAFETY VIOLATION: Attempted to run mutation command during test: %s %s\n"+
 				"This could modify real GitHub resources!\n"+
-				"Use exec.MockExecutor or runner.SetExecutor() in your test instead.",
+				"Use exec.MockExecutor, exec.UseCassette or runner.SetExecutor() in your test instead.",
 			name, strings.Join(args, " "),
 		))
 	}
@@ -95,5 +97,33 @@ func isMutationCommand(name string, args []string) bool {
 		return true // Block cancel, rerun, etc.
 	}
 
+	// "gh api" is read-only unless it sends a non-GET request, e.g. POST .../runs/{id}/cancel
+	if subcommand == "api" {
+		return isMutatingAPICall(args[1:])
+	}
+
 	return mutationCommands[subcommand]
 }
+
+// isMutatingAPICall reports whether gh api arguments send anything other than a GET.
+// gh api switches to POST when fields or an input body are given without an explicit method.
+func isMutatingAPICall(args []string) bool {
+	hasBody := false
+
+	for i, arg := range args {
+		switch {
+		case arg == "-X" || arg == "--method":
+			if i+1 < len(args) {
+				return !strings.EqualFold(args[i+1], "GET")
+			}
+		case strings.HasPrefix(arg, "--method="):
+			return !strings.EqualFold(strings.TrimPrefix(arg, "--method="), "GET")
+		case strings.HasPrefix(arg, "-X") && len(arg) > 2:
+			return !strings.EqualFold(arg[2:], "GET")
+		case arg == "-f" || arg == "-F" || arg == "--field" || arg == "--raw-field" || arg == "--input":
+			hasBody = true
+		}
+	}
+
+	return hasBody
+}
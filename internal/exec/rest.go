@@ -0,0 +1,38 @@
+package exec
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+// RESTClient is the subset of go-gh's api.RESTClient used for native API calls.
+type RESTClient interface {
+	Post(path string, body io.Reader, response interface{}) error
+}
+
+// RealRESTClient sends requests through a real REST client, with the same safety check
+// as RealExecutor: every request it sends can mutate GitHub resources.
+type RealRESTClient struct {
+	rest           RESTClient
+	allowMutations bool // set while recording a cassette, which asks for real dispatches
+}
+
+// NewRealRESTClient wraps rest, typically go-gh's default REST client.
+func NewRealRESTClient(rest RESTClient) *RealRESTClient {
+	return &RealRESTClient{rest: rest}
+}
+
+// Post sends the request. It panics during tests instead of reaching GitHub.
+func (c *RealRESTClient) Post(path string, body io.Reader, response interface{}) error {
+	if testing.Testing() && !c.allowMutations {
+		panic(fmt.Sprintf(
+			"SAFETY VIOLATION: Attempted to send a REST request during test: POST %s\n"+
+				"This could modify real GitHub resources!\n"+
+				"Use a mock REST client with github.Client.WithRESTClient or exec.UseCassette in your test instead.",
+			path,
+		))
+	}
+
+	return c.rest.Post(path, body, response)
+}
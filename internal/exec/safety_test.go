@@ -119,6 +119,42 @@ func TestIsMutationCommand(t *testing.T) {
 			args:       []string{"api", "repos/owner/repo/actions/runs"},
 			isMutation: false,
 		},
+		{
+			name:       "gh run rerun is mutation",
+			command:    "gh",
+			args:       []string{"run", "rerun", "123", "--repo", "owner/repo"},
+			isMutation: true,
+		},
+		{
+			name:       "gh run rerun --failed is mutation",
+			command:    "gh",
+			args:       []string{"run", "rerun", "123", "--failed", "--repo", "owner/repo"},
+			isMutation: true,
+		},
+		{
+			name:       "gh api POST is mutation",
+			command:    "gh",
+			args:       []string{"api", "-X", "POST", "repos/owner/repo/actions/runs/123/cancel"},
+			isMutation: true,
+		},
+		{
+			name:       "gh api --method=post is mutation",
+			command:    "gh",
+			args:       []string{"api", "--method=post", "repos/owner/repo/actions/runs/123/rerun"},
+			isMutation: true,
+		},
+		{
+			name:       "gh api with fields defaults to POST",
+			command:    "gh",
+			args:       []string{"api", "repos/owner/repo/actions/runs/123/rerun-failed-jobs", "-f", "enable_debug_logging=true"},
+			isMutation: true,
+		},
+		{
+			name:       "gh api explicit GET with fields is read-only",
+			command:    "gh",
+			args:       []string{"api", "-X", "GET", "repos/owner/repo/actions/runs", "-f", "branch=main"},
+			isMutation: false,
+		},
 		{
 			name:       "non-gh command is safe",
 			command:    "echo",
@@ -143,3 +179,14 @@ func TestIsMutationCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestRealRESTClient_SafetyCheck_BlocksRequests(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for REST request during test")
+		}
+	}()
+
+	// The wrapped client is never reached
+	_ = NewRealRESTClient(nil).Post("repos/owner/repo/actions/workflows/ci.yml/dispatches", nil, nil)
+}
@@ -42,19 +42,21 @@ func Load() (*Store, error) {
 	return LoadFrom(CachePath())
 }
 
-// LoadFrom reads the store from a specific path.
+// LoadFrom reads the store from a specific path, which Save then writes to.
 func LoadFrom(path string) (*Store, error) {
+	store := NewStore()
+	store.path = path
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewStore(), nil
+			return store, nil
 		}
 
 		return nil, err
 	}
 
-	var store Store
-	if err := json.Unmarshal(data, &store); err != nil {
+	if err := json.Unmarshal(data, store); err != nil {
 		return nil, err
 	}
 
@@ -62,11 +64,15 @@ func LoadFrom(path string) (*Store, error) {
 		store.Entries = make(map[string][]HistoryEntry)
 	}
 
-	return &store, nil
+	return store, nil
 }
 
-// Save writes the store to disk.
+// Save writes the store to the path it was loaded from, or CachePath for a new store.
 func (s *Store) Save() error {
+	if s.path != "" {
+		return s.SaveTo(s.path)
+	}
+
 	return s.SaveTo(CachePath())
 }
 
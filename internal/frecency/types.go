@@ -13,6 +13,8 @@ const (
 // Store holds frecency history keyed by repository (org/repo).
 type Store struct {
 	Entries map[string][]HistoryEntry `json:"entries"`
+
+	path string // file Save writes to; empty for CachePath
 }
 
 // ChainStepResult represents the result of a single step in a chain run.
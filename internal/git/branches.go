@@ -1,4 +1,4 @@
-// Package git provides Git operations for branch discovery and management.
+// Package git provides Git operations for branch and tag discovery and management.
 package git
 
 import (
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// FetchTags retrieves the repository's tags, most recently created first.
+func FetchTags(ctx context.Context) ([]string, error) {
+	return fetchTagsWithRunner(ctx, runner)
+}
+
+func fetchTagsWithRunner(ctx context.Context, r CommandRunner) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	output, err := r.RunCommand(ctx, "tag", "--list", "--sort=-creatordate")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if tag := strings.TrimSpace(line); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,23 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFetchTags(t *testing.T) {
+	tags, err := fetchTagsWithRunner(context.Background(), &mockCommandRunner{output: []byte("v1.2.0\nv1.1.0\n\nv1.0.0\n")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"v1.2.0", "v1.1.0", "v1.0.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %v, want %v", tags, want)
+	}
+
+	if _, err := fetchTagsWithRunner(context.Background(), &mockCommandRunner{err: errors.New("not a git repository")}); err == nil {
+		t.Error("expected the git error")
+	}
+}
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ListRunArtifacts fetches the artifacts uploaded by a workflow run.
+func (c *Client) ListRunArtifacts(runID int64) ([]Artifact, error) {
+	path := fmt.Sprintf("repos/%s/%s/actions/runs/%d/artifacts", c.owner, c.repo, runID)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var artifactsResp ArtifactsResponse
+	if err := json.Unmarshal([]byte(stdout), &artifactsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse artifacts: %w", err)
+	}
+
+	return artifactsResp.Artifacts, nil
+}
+
+// DownloadArtifact fetches an artifact's zip archive.
+func (c *Client) DownloadArtifact(artifactID int64) ([]byte, error) {
+	path := fmt.Sprintf("repos/%s/%s/actions/artifacts/%d/zip", c.owner, c.repo, artifactID)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	return []byte(stdout), nil
+}
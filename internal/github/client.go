@@ -3,24 +3,42 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/kyleking/gh-lazydispatch/internal/exec"
 )
 
 // Client wraps the GitHub API via gh CLI.
 type Client struct {
 	executor exec.CommandExecutor
+	rest     RESTClient
 	owner    string
 	repo     string
+
+	loginMu sync.Mutex
+	login   string
 }
 
 // NewClient creates a new GitHub API client for the specified repository.
-// Uses the real gh CLI executor by default.
+// Uses the real gh CLI executor by default, plus go-gh's REST client when credentials resolve.
 func NewClient(repoFullName string) (*Client, error) {
-	return NewClientWithExecutor(repoFullName, exec.NewRealExecutor())
+	client, err := NewClientWithExecutor(repoFullName, exec.NewRealExecutor())
+	if err != nil {
+		return nil, err
+	}
+
+	if rest, restErr := api.DefaultRESTClient(); restErr == nil {
+		client.rest = exec.NewRealRESTClient(rest)
+	}
+
+	return client, nil
 }
 
 // NewClientWithExecutor creates a new GitHub API client with a custom executor.
@@ -96,6 +114,81 @@ func (c *Client) GetLatestRun(workflowName string) (*WorkflowRun, error) {
 	return &runsResp.WorkflowRuns[0], nil
 }
 
+// ListWorkflowRuns fetches runs of a single workflow, newest first, narrowed by filter.
+func (c *Client) ListWorkflowRuns(filter RunFilter) ([]WorkflowRun, error) {
+	if filter.Workflow == "" {
+		return nil, errors.New("workflow is required to list runs")
+	}
+
+	query := url.Values{}
+	if filter.Event != "" {
+		query.Set("event", filter.Event)
+	}
+
+	if filter.Branch != "" {
+		query.Set("branch", filter.Branch)
+	}
+
+	if filter.Actor != "" {
+		query.Set("actor", filter.Actor)
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		query.Set("created", ">="+filter.CreatedAfter.UTC().Format(time.RFC3339))
+	}
+
+	if filter.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(filter.PerPage))
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs", c.owner, c.repo, url.PathEscape(filter.Workflow))
+	if encoded := query.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var runsResp RunsResponse
+	if err := json.Unmarshal([]byte(stdout), &runsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse runs: %w", err)
+	}
+
+	return runsResp.WorkflowRuns, nil
+}
+
+// CurrentUser returns the login of the authenticated user.
+// The result is cached after the first successful lookup.
+func (c *Client) CurrentUser() (string, error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if c.login != "" {
+		return c.login, nil
+	}
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", "user")
+	if err != nil {
+		return "", fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var user Actor
+	if err := json.Unmarshal([]byte(stdout), &user); err != nil {
+		return "", fmt.Errorf("failed to parse user: %w", err)
+	}
+
+	c.login = user.Login
+
+	return c.login, nil
+}
+
+// Executor returns the executor gh commands are run with.
+func (c *Client) Executor() exec.CommandExecutor {
+	return c.executor
+}
+
 // Owner returns the repository owner.
 func (c *Client) Owner() string {
 	return c.owner
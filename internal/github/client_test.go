@@ -3,6 +3,8 @@ package github_test
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -395,3 +397,295 @@ func TestClient_CommandsExecuted(t *testing.T) {
 		t.Errorf("expected 'gh api ...' command, got %v", cmd.Args)
 	}
 }
+
+func TestClient_ListWorkflowRuns(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+
+	resp := github.RunsResponse{
+		WorkflowRuns: []github.WorkflowRun{
+			{ID: 2, Event: github.EventWorkflowDispatch, Actor: github.Actor{Login: "octocat"}},
+			{ID: 1, Event: github.EventWorkflowDispatch},
+		},
+	}
+	respJSON, _ := json.Marshal(resp)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/workflows/ci.yml/runs?branch=main&event=workflow_dispatch&per_page=5"},
+		string(respJSON), "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	runs, err := client.ListWorkflowRuns(github.RunFilter{
+		Workflow: "ci.yml",
+		Event:    github.EventWorkflowDispatch,
+		Branch:   "main",
+		PerPage:  5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(runs) != 2 || runs[0].Actor.Login != "octocat" {
+		t.Errorf("unexpected runs: %+v", runs)
+	}
+
+	if _, err := client.ListWorkflowRuns(github.RunFilter{}); err == nil {
+		t.Error("expected error when workflow is empty")
+	}
+}
+
+func TestClient_CurrentUser(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "user"}, `{"login":"octocat"}`, "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	for range 2 {
+		login, err := client.CurrentUser()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if login != "octocat" {
+			t.Errorf("login = %q, want octocat", login)
+		}
+	}
+
+	if len(mockExec.ExecutedCommands) != 1 {
+		t.Errorf("expected cached lookup, got %d commands", len(mockExec.ExecutedCommands))
+	}
+}
+
+func TestClient_Artifacts(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/runs/42/artifacts"},
+		`{"total_count":1,"artifacts":[{"id":7,"name":"lazydispatch-outputs","size_in_bytes":120}]}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/artifacts/7/zip"}, "PK\x03\x04", "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	artifacts, err := client.ListRunArtifacts(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(artifacts) != 1 || artifacts[0].ID != 7 || artifacts[0].Name != "lazydispatch-outputs" {
+		t.Fatalf("unexpected artifacts: %+v", artifacts)
+	}
+
+	data, err := client.DownloadArtifact(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(data) != "PK\x03\x04" {
+		t.Errorf("data = %q", data)
+	}
+}
+
+func TestClient_DownloadRunLogs(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/runs/42/logs"}, "PK\x03\x04", "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/runs/43/logs"}, "", "HTTP 404", errors.New("exit status 1"))
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	data, err := client.DownloadRunLogs(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(data) != "PK\x03\x04" {
+		t.Errorf("data = %q", data)
+	}
+
+	if _, err := client.DownloadRunLogs(43); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+}
+
+func TestClient_GetJobLogs(t *testing.T) {
+	jobLogsArgs := func(jobID int64, offset int) []string {
+		return []string{"api", "--include", "-H", fmt.Sprintf("Range: bytes=%d-", offset), fmt.Sprintf("repos/owner/repo/actions/jobs/%d/logs", jobID)}
+	}
+
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", jobLogsArgs(1, 6), "HTTP/2.0 206 Partial Content\nContent-Range: bytes 6-10/11\n\nline2", "", nil)
+	mockExec.AddCommand("gh", jobLogsArgs(1, 11), "HTTP/2.0 416 Range Not Satisfiable\n\n", "gh: HTTP 416", errors.New("exit status 1"))
+	mockExec.AddCommand("gh", jobLogsArgs(2, 6), "HTTP/2.0 200 OK\n\nline1\nline2", "", nil)
+	mockExec.AddCommand("gh", jobLogsArgs(3, 0), "HTTP/2.0 404 Not Found\n\n", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	tests := []struct {
+		name    string
+		jobID   int64
+		offset  int
+		want    string
+		wantErr bool
+	}{
+		{name: "partial content", jobID: 1, offset: 6, want: "line2"},
+		{name: "nothing new", jobID: 1, offset: 11},
+		{name: "range ignored", jobID: 2, offset: 6, want: "line2"},
+		{name: "not found", jobID: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetJobLogs(tt.jobID, tt.offset)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("GetJobLogs() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_RunActions(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *github.Client) error
+		args []string
+	}{
+		{"cancel", func(c *github.Client) error { return c.CancelRun(42) }, []string{"run", "cancel", "42", "--repo", "owner/repo"}},
+		{"rerun", func(c *github.Client) error { return c.RerunRun(42) }, []string{"run", "rerun", "42", "--repo", "owner/repo"}},
+		{"rerun failed", func(c *github.Client) error { return c.RerunFailedJobs(42) }, []string{"run", "rerun", "42", "--failed", "--repo", "owner/repo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor()
+			mockExec.AddCommand("gh", tt.args, "", "", nil)
+
+			client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+			if err := tt.call(client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mockExec.ExecutedCommands) != 1 {
+				t.Fatalf("expected 1 command, got %d", len(mockExec.ExecutedCommands))
+			}
+
+			mockExec.Reset()
+			mockExec.AddCommand("gh", tt.args, "", "run 42 cannot be cancelled", errors.New("exit status 1"))
+
+			if err := tt.call(client); err == nil {
+				t.Error("expected error when gh fails")
+			}
+		})
+	}
+}
+
+func TestClient_Contents(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/contents/.github/workflows?ref=release%2F1.0"},
+		`[{"name":"deploy.yml","path":".github/workflows/deploy.yml","type":"file"},{"name":"scripts","type":"dir"}]`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/contents/.github/workflows/deploy.yml"},
+		`{"name":"deploy.yml","type":"file","encoding":"base64","content":"bmFtZTog\nRGVwbG95\n"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/contents/.github/lazydispatch.yml"},
+		"", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	names, err := client.ListFiles(".github/workflows", "release/1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(names) != 1 || names[0] != "deploy.yml" {
+		t.Errorf("ListFiles() = %v, want [deploy.yml]", names)
+	}
+
+	data, err := client.ReadFile(".github/workflows/deploy.yml", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(data) != "name: Deploy" {
+		t.Errorf("ReadFile() = %q", data)
+	}
+
+	if _, err := client.ReadFile(".github/lazydispatch.yml", ""); !errors.Is(err, github.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClient_RepositoryAndBranches(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo"}, `{"full_name":"owner/repo","default_branch":"trunk"}`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/branches?per_page=100"}, `[{"name":"trunk"},{"name":"feature"}]`, "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	repo, err := client.GetRepository()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.DefaultBranch != "trunk" {
+		t.Errorf("DefaultBranch = %q, want trunk", repo.DefaultBranch)
+	}
+
+	branches, err := client.ListBranches()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(branches, ",") != "trunk,feature" {
+		t.Errorf("ListBranches() = %v", branches)
+	}
+}
+
+func TestClient_ListEnvironments(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/environments?per_page=100"},
+		`{"total_count":2,"environments":[{"id":1,"name":"staging"},{"id":2,"name":"production"}]}`, "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	envs, err := client.ListEnvironments()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(envs, ",") != "staging,production" {
+		t.Errorf("ListEnvironments() = %v", envs)
+	}
+}
+
+func TestClient_ListTagsReleasesAndPullRequests(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "--paginate", "repos/owner/repo/tags?per_page=100"},
+		"[{\"name\":\"v1.2.0\"},{\"name\":\"v1.1.0\"}]\n[{\"name\":\"v0.9.0\"}]", "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/releases?per_page=100"},
+		`[{"tag_name":"v1.2.0","name":"Spring release","draft":false,"prerelease":true}]`, "", nil)
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/pulls?state=open&per_page=100"},
+		`[{"number":42,"title":"Fix login","head":{"ref":"fix/login"}}]`, "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	tags, err := client.ListTags()
+	if err != nil || strings.Join(tags, ",") != "v1.2.0,v1.1.0,v0.9.0" {
+		t.Errorf("ListTags() = %v, %v", tags, err)
+	}
+
+	releases, err := client.ListReleases()
+	if err != nil || len(releases) != 1 || releases[0].TagName != "v1.2.0" || !releases[0].Prerelease {
+		t.Errorf("ListReleases() = %+v, %v", releases, err)
+	}
+
+	pulls, err := client.ListOpenPullRequests()
+	if err != nil || len(pulls) != 1 || pulls[0].Number != 42 || pulls[0].Head.Ref != "fix/login" {
+		t.Errorf("ListOpenPullRequests() = %+v, %v", pulls, err)
+	}
+}
+
+func TestClient_ListTags_Error(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "--paginate", "repos/owner/repo/tags?per_page=100"},
+		"", "gh: Not Found (HTTP 404)", errors.New("exit status 1"))
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+
+	if _, err := client.ListTags(); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("expected the gh error, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotFound is returned when the requested file, directory or ref does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ListFiles returns the names of the files in a repository directory at ref.
+// An empty ref reads the default branch.
+func (c *Client) ListFiles(dir, ref string) ([]string, error) {
+	stdout, err := c.getContents(dir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ContentEntry
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse directory listing of %s: %w", dir, err)
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if entry.Type == "file" {
+			names = append(names, entry.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// ReadFile returns the content of a repository file at ref.
+// An empty ref reads the default branch.
+func (c *Client) ReadFile(path, ref string) ([]byte, error) {
+	stdout, err := c.getContents(path, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry ContentEntry
+	if err := json.Unmarshal([]byte(stdout), &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse contents of %s: %w", path, err)
+	}
+
+	if entry.Type != "file" || entry.Encoding != "base64" {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	// The API wraps the base64 content at 60 characters
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(entry.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode contents of %s: %w", path, err)
+	}
+
+	return data, nil
+}
+
+func (c *Client) getContents(path, ref string) (string, error) {
+	apiPath := fmt.Sprintf("repos/%s/%s/contents/%s", c.owner, c.repo, strings.TrimPrefix(path, "/"))
+	if ref != "" {
+		apiPath += "?ref=" + url.QueryEscape(ref)
+	}
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", apiPath)
+	if err != nil {
+		if strings.Contains(stderr, "HTTP 404") {
+			return "", fmt.Errorf("%s: %w", path, ErrNotFound)
+		}
+
+		return "", fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	return stdout, nil
+}
+
+// GetRepository fetches the repository's metadata.
+func (c *Client) GetRepository() (*Repository, error) {
+	path := fmt.Sprintf("repos/%s/%s", c.owner, c.repo)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var repo Repository
+	if err := json.Unmarshal([]byte(stdout), &repo); err != nil {
+		return nil, fmt.Errorf("failed to parse repository: %w", err)
+	}
+
+	return &repo, nil
+}
+
+// ListBranches returns the names of the repository's branches, up to 100.
+func (c *Client) ListBranches() ([]string, error) {
+	path := fmt.Sprintf("repos/%s/%s/branches?per_page=100", c.owner, c.repo)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var branches []Branch
+	if err := json.Unmarshal([]byte(stdout), &branches); err != nil {
+		return nil, fmt.Errorf("failed to parse branches: %w", err)
+	}
+
+	names := make([]string, len(branches))
+	for i, branch := range branches {
+		names[i] = branch.Name
+	}
+
+	return names, nil
+}
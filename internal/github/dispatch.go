@@ -0,0 +1,82 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	apperrors "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+)
+
+// RESTClient is the subset of go-gh's api.RESTClient used for native API calls.
+type RESTClient = exec.RESTClient
+
+// ErrRESTUnavailable is returned when a native API call is attempted without a REST client.
+// Callers should fall back to the gh CLI.
+var ErrRESTUnavailable = errors.New("REST client unavailable")
+
+type dispatchRequest struct {
+	Ref    string         `json:"ref"`
+	Inputs map[string]any `json:"inputs,omitempty"`
+}
+
+// WithRESTClient sets the REST client used for native API calls.
+func (c *Client) WithRESTClient(rest RESTClient) *Client {
+	c.rest = rest
+	return c
+}
+
+// HasRESTClient reports whether native API calls are available.
+func (c *Client) HasRESTClient() bool {
+	return c.rest != nil
+}
+
+// DispatchWorkflow triggers a workflow_dispatch event through the REST API.
+// Inputs are sent as typed JSON values. Rejections are returned as *errors.DispatchError.
+func (c *Client) DispatchWorkflow(workflow, ref string, inputs map[string]any) error {
+	if c.rest == nil {
+		return ErrRESTUnavailable
+	}
+
+	body, err := json.Marshal(dispatchRequest{Ref: ref, Inputs: inputs})
+	if err != nil {
+		return fmt.Errorf("failed to encode dispatch request: %w", err)
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/dispatches", c.owner, c.repo, url.PathEscape(workflow))
+
+	err = c.rest.Post(path, bytes.NewReader(body), nil)
+	if err == nil {
+		return nil
+	}
+
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return fmt.Errorf("dispatch request failed: %w", err)
+	}
+
+	dispatchErr := &apperrors.DispatchError{
+		Workflow:   workflow,
+		Ref:        ref,
+		StatusCode: httpErr.StatusCode,
+		Reason:     apperrors.DispatchUnknown,
+		Message:    httpErr.Message,
+		Err:        err,
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusUnprocessableEntity:
+		dispatchErr.Reason = apperrors.DispatchInvalidInputs
+	case http.StatusNotFound:
+		dispatchErr.Reason = apperrors.DispatchWorkflowNotFound
+	case http.StatusForbidden:
+		dispatchErr.Reason = apperrors.DispatchForbidden
+	}
+
+	return dispatchErr
+}
@@ -0,0 +1,108 @@
+package github_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	apperrors "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+type fakeREST struct {
+	path string
+	body map[string]any
+	err  error
+}
+
+func (f *fakeREST) Post(path string, body io.Reader, _ interface{}) error {
+	f.path = path
+
+	data, _ := io.ReadAll(body)
+	_ = json.Unmarshal(data, &f.body)
+
+	return f.err
+}
+
+func TestClient_DispatchWorkflow(t *testing.T) {
+	tests := []struct {
+		name       string
+		restErr    error
+		wantReason apperrors.DispatchFailure
+		wantErr    bool
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:       "unknown input",
+			restErr:    &api.HTTPError{StatusCode: 422, Message: "Unexpected inputs provided: [\"bogus\"]"},
+			wantReason: apperrors.DispatchInvalidInputs,
+			wantErr:    true,
+		},
+		{
+			name:       "workflow not on ref",
+			restErr:    &api.HTTPError{StatusCode: 404, Message: "Not Found"},
+			wantReason: apperrors.DispatchWorkflowNotFound,
+			wantErr:    true,
+		},
+		{
+			name:    "transport error",
+			restErr: errors.New("connection refused"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest := &fakeREST{err: tt.restErr}
+			client, _ := github.NewClientWithExecutor("owner/repo", exec.NewMockExecutor())
+			client.WithRESTClient(rest)
+
+			err := client.DispatchWorkflow("deploy.yml", "main", map[string]any{"dry_run": true, "replicas": 3.0})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if rest.path != "repos/owner/repo/actions/workflows/deploy.yml/dispatches" {
+				t.Errorf("path = %q", rest.path)
+			}
+
+			if rest.body["ref"] != "main" {
+				t.Errorf("ref = %v, want main", rest.body["ref"])
+			}
+
+			inputs, _ := rest.body["inputs"].(map[string]any)
+			if inputs["dry_run"] != true || inputs["replicas"] != 3.0 {
+				t.Errorf("inputs not typed: %v", inputs)
+			}
+
+			var dispatchErr *apperrors.DispatchError
+			if tt.wantReason != "" {
+				if !errors.As(err, &dispatchErr) {
+					t.Fatalf("expected DispatchError, got %T", err)
+				}
+
+				if dispatchErr.Reason != tt.wantReason {
+					t.Errorf("Reason = %q, want %q", dispatchErr.Reason, tt.wantReason)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_DispatchWorkflow_NoREST(t *testing.T) {
+	client, _ := github.NewClientWithExecutor("owner/repo", exec.NewMockExecutor())
+
+	if client.HasRESTClient() {
+		t.Error("expected no REST client")
+	}
+
+	if err := client.DispatchWorkflow("deploy.yml", "main", nil); !errors.Is(err, github.ErrRESTUnavailable) {
+		t.Errorf("error = %v, want ErrRESTUnavailable", err)
+	}
+}
@@ -0,0 +1,28 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ListEnvironments returns the names of the repository's deployment environments, up to 100.
+func (c *Client) ListEnvironments() ([]string, error) {
+	path := fmt.Sprintf("repos/%s/%s/environments?per_page=100", c.owner, c.repo)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var envResp EnvironmentsResponse
+	if err := json.Unmarshal([]byte(stdout), &envResp); err != nil {
+		return nil, fmt.Errorf("failed to parse environments: %w", err)
+	}
+
+	names := make([]string, len(envResp.Environments))
+	for i, env := range envResp.Environments {
+		names[i] = env.Name
+	}
+
+	return names, nil
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ListTags returns the names of all the repository's tags. The API does not order them
+// by version, so they are all listed for the latest one to be found.
+func (c *Client) ListTags() ([]string, error) {
+	tags, err := getAllPages[Tag](c, "tags")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+
+	return names, nil
+}
+
+// ListReleases returns the repository's releases, newest first, up to 100.
+func (c *Client) ListReleases() ([]Release, error) {
+	var releases []Release
+	if err := c.getList("releases", &releases); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// ListOpenPullRequests returns the repository's open pull requests, newest first, up to 100.
+func (c *Client) ListOpenPullRequests() ([]PullRequest, error) {
+	var pulls []PullRequest
+	if err := c.getList("pulls?state=open", &pulls); err != nil {
+		return nil, err
+	}
+
+	return pulls, nil
+}
+
+// getList fetches the first page of 100 items of a repository list endpoint into v.
+func (c *Client) getList(endpoint string, v any) error {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/%s%sper_page=100", c.owner, c.repo, endpoint, sep)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	if err := json.Unmarshal([]byte(stdout), v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// getAllPages fetches every page of a repository list endpoint. gh api --paginate writes
+// the JSON array of each page one after another.
+func getAllPages[T any](c *Client, endpoint string) ([]T, error) {
+	path := fmt.Sprintf("repos/%s/%s/%s?per_page=100", c.owner, c.repo, endpoint)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", "--paginate", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	var items []T
+
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	for dec.More() {
+		var page []T
+		if err := dec.Decode(&page); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", endpoint, err)
+		}
+
+		items = append(items, page...)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,39 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CancelRun requests cancellation of a queued or in-progress run.
+func (c *Client) CancelRun(runID int64) error {
+	_, stderr, err := c.executor.Execute("gh", "run", "cancel", strconv.FormatInt(runID, 10),
+		"--repo", c.owner+"/"+c.repo)
+	if err != nil {
+		return fmt.Errorf("gh run cancel failed: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
+
+// RerunRun re-runs every job of a completed run as a new attempt of the same run.
+func (c *Client) RerunRun(runID int64) error {
+	_, stderr, err := c.executor.Execute("gh", "run", "rerun", strconv.FormatInt(runID, 10),
+		"--repo", c.owner+"/"+c.repo)
+	if err != nil {
+		return fmt.Errorf("gh run rerun failed: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
+
+// RerunFailedJobs re-runs the failed jobs of a completed run as a new attempt of the same run.
+func (c *Client) RerunFailedJobs(runID int64) error {
+	_, stderr, err := c.executor.Execute("gh", "run", "rerun", strconv.FormatInt(runID, 10), "--failed",
+		"--repo", c.owner+"/"+c.repo)
+	if err != nil {
+		return fmt.Errorf("gh run rerun failed: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DownloadRunLogs fetches the zip archive of a run's logs. The archive holds one
+// directory per job with a `<n>_<step>.txt` file per step, plus a `<n>_<job>.txt`
+// file with each job's full log. GitHub only serves it once the run has finished.
+func (c *Client) DownloadRunLogs(runID int64) ([]byte, error) {
+	path := fmt.Sprintf("repos/%s/%s/actions/runs/%d/logs", c.owner, c.repo, runID)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", path)
+	if err != nil {
+		return nil, fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	}
+
+	return []byte(stdout), nil
+}
+
+// GetJobLogs returns the part of a job's log past offset bytes. Only that range is
+// requested, so a running job's log is followed without downloading it again. It
+// returns "" when nothing has been written past offset.
+func (c *Client) GetJobLogs(jobID int64, offset int) (string, error) {
+	path := fmt.Sprintf("repos/%s/%s/actions/jobs/%d/logs", c.owner, c.repo, jobID)
+
+	stdout, stderr, err := c.executor.Execute("gh", "api", "--include",
+		"-H", fmt.Sprintf("Range: bytes=%d-", offset), path)
+
+	status, body := splitResponse(stdout)
+
+	switch {
+	case status == http.StatusRequestedRangeNotSatisfiable:
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("gh api failed: %w (stderr: %s)", err, stderr)
+	case status == http.StatusPartialContent:
+		return body, nil
+	case offset >= len(body):
+		return "", nil
+	}
+
+	// The range was ignored and the whole log sent
+	return body[offset:], nil
+}
+
+// splitResponse splits the output of gh api --include into the status code and body.
+// The status is 0 when the output has no headers.
+func splitResponse(output string) (int, string) {
+	headers, body, ok := strings.Cut(output, "\r\n\r\n")
+	if !ok {
+		headers, body, ok = strings.Cut(output, "\n\n")
+	}
+
+	if !ok || !strings.HasPrefix(headers, "HTTP/") {
+		return 0, output
+	}
+
+	fields := strings.Fields(headers)
+	if len(fields) < 2 {
+		return 0, body
+	}
+
+	status, _ := strconv.Atoi(fields[1])
+
+	return status, body
+}
@@ -12,6 +12,30 @@ type WorkflowRun struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 	HTMLURL    string    `json:"html_url"`
 	HeadBranch string    `json:"head_branch"`
+	HeadSHA    string    `json:"head_sha"`
+	RunAttempt int       `json:"run_attempt"`
+	Event      string    `json:"event"`
+	Title      string    `json:"display_title"`
+	Actor      Actor     `json:"actor"`
+}
+
+// Actor represents the GitHub user that triggered a run.
+type Actor struct {
+	Login string `json:"login"`
+}
+
+// EventWorkflowDispatch is the event name for manually dispatched runs.
+const EventWorkflowDispatch = "workflow_dispatch"
+
+// RunFilter narrows the runs returned by ListWorkflowRuns.
+// Zero-valued fields are not sent to the API.
+type RunFilter struct {
+	Workflow     string
+	Event        string
+	Branch       string
+	Actor        string
+	CreatedAfter time.Time
+	PerPage      int
 }
 
 // RunStatus constants
@@ -27,6 +51,7 @@ const (
 	ConclusionFailure   = "failure"
 	ConclusionCancelled = "cancelled"
 	ConclusionSkipped   = "skipped"
+	ConclusionTimedOut  = "timed_out"
 )
 
 // IsActive returns true if the run is still in progress.
@@ -68,3 +93,71 @@ type RunsResponse struct {
 	TotalCount   int           `json:"total_count"`
 	WorkflowRuns []WorkflowRun `json:"workflow_runs"`
 }
+
+// Artifact represents a file archive uploaded by a workflow run.
+type Artifact struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+	Expired     bool   `json:"expired"`
+}
+
+// ArtifactsResponse represents the API response for listing a run's artifacts.
+type ArtifactsResponse struct {
+	TotalCount int        `json:"total_count"`
+	Artifacts  []Artifact `json:"artifacts"`
+}
+
+// ContentEntry represents a file or directory returned by the repository contents API.
+// Content is only set when a single file is requested.
+type ContentEntry struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Type     string `json:"type"` // "file", "dir", "symlink", or "submodule"
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// Repository represents the repository fields lazydispatch reads.
+type Repository struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// Branch represents a branch of a repository.
+type Branch struct {
+	Name string `json:"name"`
+}
+
+// Tag represents a tag of a repository.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// Release represents a release of a repository.
+type Release struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// PullRequest represents the pull request fields lazydispatch reads.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Head   struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+// Environment represents a deployment environment of a repository.
+type Environment struct {
+	Name string `json:"name"`
+}
+
+// EnvironmentsResponse represents the response from the environments API.
+type EnvironmentsResponse struct {
+	TotalCount   int           `json:"total_count"`
+	Environments []Environment `json:"environments"`
+}
@@ -653,7 +653,7 @@ Deployment successful despite warnings
 			if entry.Level == logs.LogLevelWarning {
 				warningCount++
 
-				if entry.Content == "##[warning]Deprecation notice: API v1 will be sunset in 6 months" {
+				if entry.Content == "Deprecation notice: API v1 will be sunset in 6 months" {
 					foundDeprecation = true
 				}
 			}
@@ -661,7 +661,7 @@ Deployment successful despite warnings
 			if entry.Level == logs.LogLevelError {
 				errorCount++
 
-				if entry.Content == "##[error]Non-critical error: Cache miss for dependency X" {
+				if entry.Content == "Non-critical error: Cache miss for dependency X" {
 					foundCacheMiss = true
 				}
 			}
@@ -0,0 +1,201 @@
+package logs
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+// RunLogsDownloader downloads the zip archive served by `actions/runs/{id}/logs`.
+// github.Client implements it.
+type RunLogsDownloader interface {
+	DownloadRunLogs(runID int64) ([]byte, error)
+}
+
+// ArchiveFetcher fetches a run's logs from its zip archive with a single
+// download, instead of one `gh run view --log` call per job.
+type ArchiveFetcher struct {
+	client     GitHubClient
+	downloader RunLogsDownloader
+}
+
+// NewArchiveFetcher creates a fetcher that reads logs from the run log archive.
+func NewArchiveFetcher(client GitHubClient, downloader RunLogsDownloader) *ArchiveFetcher {
+	return &ArchiveFetcher{
+		client:     client,
+		downloader: downloader,
+	}
+}
+
+// FetchStepLogs downloads the run's log archive and returns a StepLogs for each
+// step that wrote a log file.
+func (f *ArchiveFetcher) FetchStepLogs(runID int64, workflow string) ([]*StepLogs, error) {
+	jobs, err := f.client.GetWorkflowRunJobs(runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jobs: %w", err)
+	}
+
+	archive, err := f.downloader.DownloadRunLogs(runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download log archive: %w", err)
+	}
+
+	return ParseRunLogsArchive(archive, jobs, workflow, runID)
+}
+
+// archiveJob holds the files of one job in a run log archive.
+type archiveJob struct {
+	steps    map[int]*zip.File // per-step log files by step number
+	combined *zip.File         // the job's full log, `<n>_<job>.txt` at the archive root
+}
+
+// ParseRunLogsArchive parses a run log archive into step logs, numbered like
+// GHFetcher.FetchStepLogsReal so either source can be extended by a LogStreamer. Step files
+// (`<job>/<n>_<step>.txt`) are matched to job.Steps by their number prefix, so
+// every line is attributed to the step that wrote it. A job without step files
+// falls back to splitting its combined log file.
+func ParseRunLogsArchive(archive []byte, jobs []github.Job, workflow string, runID int64) ([]*StepLogs, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log archive: %w", err)
+	}
+
+	archiveJobs := indexArchive(reader)
+
+	var allStepLogs []*StepLogs
+
+	stepIndex := 0
+
+	for _, job := range jobs {
+		startIndex := stepIndex
+		stepIndex += len(job.Steps)
+
+		files, ok := archiveJobs[archiveName(job.Name)]
+		if !ok {
+			continue
+		}
+
+		segments := make([][]LogEntry, len(job.Steps))
+		errs := make([]error, len(job.Steps))
+
+		if len(files.steps) > 0 {
+			for i, step := range job.Steps {
+				if file := files.steps[step.Number]; file != nil {
+					segments[i], errs[i] = readArchiveFile(file, step.Name)
+				}
+			}
+		} else if files.combined != nil {
+			entries, err := readArchiveFile(files.combined, "")
+			segments = splitJobEntries(job, entries)
+
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+
+		for i, entries := range segments {
+			if entries == nil && errs[i] == nil {
+				continue
+			}
+
+			step := job.Steps[i]
+			for j := range entries {
+				entries[j].StepName = step.Name
+				entries[j].JobName = job.Name
+			}
+
+			allStepLogs = append(allStepLogs, &StepLogs{
+				StepIndex:  startIndex + i,
+				Workflow:   workflow,
+				RunID:      runID,
+				JobName:    job.Name,
+				StepName:   step.Name,
+				Status:     step.Status,
+				Conclusion: step.Conclusion,
+				Entries:    entries,
+				Error:      errs[i],
+				FetchedAt:  time.Now(),
+			})
+		}
+	}
+
+	return allStepLogs, nil
+}
+
+// indexArchive groups the files of a run log archive by job name.
+func indexArchive(reader *zip.Reader) map[string]*archiveJob {
+	jobs := make(map[string]*archiveJob)
+
+	job := func(name string) *archiveJob {
+		name = archiveName(name)
+		if jobs[name] == nil {
+			jobs[name] = &archiveJob{steps: make(map[int]*zip.File)}
+		}
+
+		return jobs[name]
+	}
+
+	for _, file := range reader.File {
+		dir, base := path.Split(file.Name)
+
+		number, name, ok := splitNumberPrefix(strings.TrimSuffix(base, ".txt"))
+		if !ok {
+			continue // e.g. system.txt, which holds runner diagnostics
+		}
+
+		if dir == "" {
+			job(name).combined = file
+		} else {
+			job(strings.TrimSuffix(dir, "/")).steps[number] = file
+		}
+	}
+
+	return jobs
+}
+
+// splitNumberPrefix splits "3_Run tests" into 3 and "Run tests".
+func splitNumberPrefix(name string) (int, string, bool) {
+	prefix, rest, found := strings.Cut(name, "_")
+	if !found {
+		return 0, "", false
+	}
+
+	number, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, "", false
+	}
+
+	return number, rest, true
+}
+
+// archiveName normalizes a job name for matching against archive paths,
+// from which GitHub drops characters that are not valid in file names.
+func archiveName(name string) string {
+	return strings.TrimSpace(archiveNameReplacer.Replace(name))
+}
+
+var archiveNameReplacer = strings.NewReplacer(
+	"/", "", "\\", "", ":", "", "*", "", "?", "", "\"", "", "<", "", ">", "", "|", "",
+)
+
+// readArchiveFile parses one log file of the archive as it is decompressed.
+func readArchiveFile(file *zip.File, stepName string) ([]LogEntry, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	entries, err := ParseLogReader(rc, stepName)
+	if err != nil {
+		return entries, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return entries, nil
+}
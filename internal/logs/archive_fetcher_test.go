@@ -0,0 +1,161 @@
+package logs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
+	"github.com/kyleking/gh-lazydispatch/internal/testutil"
+)
+
+func runLogsArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func archiveTestJobs() []github.Job {
+	return []github.Job{
+		{
+			ID:   1,
+			Name: "build",
+			Steps: []github.Step{
+				{Name: "Set up job", Number: 1, Conclusion: github.ConclusionSuccess},
+				{Name: "Run tests", Number: 2, Conclusion: github.ConclusionFailure},
+				{Name: "Upload", Number: 3, Conclusion: github.ConclusionSkipped},
+			},
+		},
+		{
+			ID:    2,
+			Name:  "lint / vet",
+			Steps: []github.Step{{Name: "Set up job", Number: 1}, {Name: "Run vet", Number: 2}},
+		},
+	}
+}
+
+func TestParseRunLogsArchive(t *testing.T) {
+	archive := runLogsArchive(t, map[string]string{
+		"build/1_Set up job.txt": "2024-01-01T00:00:01Z Current runner version: '2.311.0'\n",
+		"build/2_Run tests.txt": "2024-01-01T00:00:02Z ##[group]Run go test ./...\n" +
+			"2024-01-01T00:00:03Z ##[group]Downloading modules\n" +
+			"2024-01-01T00:00:04Z go: downloading example.com/mod v1.0.0\n" +
+			"2024-01-01T00:00:05Z ##[endgroup]\n" +
+			"2024-01-01T00:00:06Z ##[endgroup]\n" +
+			"2024-01-01T00:00:07Z --- FAIL: TestApp\n" +
+			"2024-01-01T00:00:08Z ##[error]Process completed with exit code 1.\n",
+		"build/system.txt": "runner diagnostics\n",
+		"1_build.txt":      "ignored while step files exist\n",
+		"2_lint  vet.txt": "2024-01-01T00:00:01Z ##[group]Set up job\n" +
+			"2024-01-01T00:00:01Z Runner\n" +
+			"2024-01-01T00:00:01Z ##[endgroup]\n" +
+			"2024-01-01T00:00:02Z ##[group]Run go vet\n" +
+			"2024-01-01T00:00:03Z ok\n" +
+			"2024-01-01T00:00:04Z ##[endgroup]\n",
+	})
+
+	stepLogs, err := logs.ParseRunLogsArchive([]byte(archive), archiveTestJobs(), "ci.yml", 42)
+	if err != nil {
+		t.Fatalf("ParseRunLogsArchive failed: %v", err)
+	}
+
+	want := []struct {
+		job, step string
+		index     int
+		entries   int
+	}{
+		{"build", "Set up job", 0, 1},
+		{"build", "Run tests", 1, 5},
+		{"lint / vet", "Set up job", 3, 2},
+		{"lint / vet", "Run vet", 4, 2},
+	}
+
+	if len(stepLogs) != len(want) {
+		t.Fatalf("expected %d steps, got %d", len(want), len(stepLogs))
+	}
+
+	for i, w := range want {
+		sl := stepLogs[i]
+		if sl.JobName != w.job || sl.StepName != w.step || len(sl.Entries) != w.entries {
+			t.Errorf("step %d: got %s/%s with %d entries, want %s/%s with %d",
+				i, sl.JobName, sl.StepName, len(sl.Entries), w.job, w.step, w.entries)
+		}
+
+		if sl.StepIndex != w.index || sl.RunID != 42 || sl.Workflow != "ci.yml" {
+			t.Errorf("step %d: unexpected metadata %+v", i, sl)
+		}
+
+		for _, entry := range sl.Entries {
+			if entry.StepName != w.step {
+				t.Errorf("step %d: entry %q attributed to %q", i, entry.Content, entry.StepName)
+			}
+		}
+	}
+
+	// Nested groups stay inside the step that emitted them
+	last := stepLogs[1].Entries[len(stepLogs[1].Entries)-1]
+	if last.Level != logs.LogLevelError || last.Content != "Process completed with exit code 1." {
+		t.Errorf("unexpected last entry of Run tests: %+v", last)
+	}
+}
+
+func TestParseRunLogsArchive_InvalidZip(t *testing.T) {
+	if _, err := logs.ParseRunLogsArchive([]byte("not a zip"), archiveTestJobs(), "ci.yml", 42); err == nil {
+		t.Error("expected error for invalid archive")
+	}
+}
+
+func TestArchiveFetcher_FetchStepLogs(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddGHAPIJobs("owner", "repo", 42, testutil.MustMarshalJSON(t, github.JobsResponse{Jobs: archiveTestJobs()}))
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/runs/42/logs"}, runLogsArchive(t, map[string]string{
+		"build/2_Run tests.txt": "2024-01-01T00:00:02Z ok\n",
+	}), "", nil)
+
+	client, err := github.NewClientWithExecutor("owner/repo", mockExec)
+	if err != nil {
+		t.Fatalf("failed to create GitHub client: %v", err)
+	}
+
+	stepLogs, err := logs.NewArchiveFetcher(client, client).FetchStepLogs(42, "ci.yml")
+	if err != nil {
+		t.Fatalf("FetchStepLogs failed: %v", err)
+	}
+
+	if len(stepLogs) != 1 || stepLogs[0].StepName != "Run tests" {
+		t.Fatalf("unexpected step logs: %+v", stepLogs)
+	}
+
+	// One call for the jobs, one for the archive, regardless of the number of jobs
+	if len(mockExec.ExecutedCommands) != 2 {
+		t.Errorf("expected 2 gh commands, got %d", len(mockExec.ExecutedCommands))
+	}
+
+	mockExec.AddCommand("gh", []string{"api", "repos/owner/repo/actions/runs/42/logs"}, "", "HTTP 404", errors.New("exit status 1"))
+
+	if _, err := logs.NewArchiveFetcher(client, client).FetchStepLogs(42, "ci.yml"); err == nil {
+		t.Error("expected error when the archive is unavailable")
+	}
+}
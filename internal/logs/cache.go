@@ -2,80 +2,198 @@
 package logs
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
-// Cache stores fetched logs locally for quick access.
+// DefaultCacheMaxSizeMB is the default size cap of the log cache.
+const DefaultCacheMaxSizeMB = 200
+
+// cacheFormatVersion is written to the index and to the header of every cache file.
+// Files written with another version are ignored.
+const cacheFormatVersion = 1
+
+// cacheIndexFile is the name of the on-disk index within the cache directory.
+const cacheIndexFile = "index.json"
+
+// CacheKey identifies the logs of one attempt of a workflow run.
+// The logs of a completed attempt never change, so entries do not expire.
+type CacheKey struct {
+	Repo    string `json:"repo"` // owner/name
+	RunID   int64  `json:"run_id"`
+	Attempt int    `json:"attempt"`
+}
+
+// Cache stores the logs of completed runs on disk as gzip'd NDJSON, one file
+// per run attempt, evicting the least recently used files beyond a size cap.
 type Cache struct {
 	cacheDir string
-	mu       sync.RWMutex
-	entries  map[string]*CacheEntry // keyed by chainName:runID
+	maxBytes int64
+	mu       sync.Mutex
+	entries  map[CacheKey]*CacheEntry
+	dirty    bool // access times changed since the index was saved
 }
 
-// CacheEntry represents cached log data.
+// CacheEntry is the index record of one cached run attempt.
 type CacheEntry struct {
-	ChainName string
-	RunID     int64
-	Logs      *RunLogs
-	CachedAt  time.Time
-	TTL       time.Duration
+	CacheKey
+	File       string    `json:"file"` // relative to the cache directory
+	Size       int64     `json:"size"` // compressed size in bytes
+	LastAccess time.Time `json:"last_access"`
+	Patterns   string    `json:"patterns,omitempty"` // custom level patterns the logs were parsed with
+}
+
+// cacheIndex is the on-disk form of the index.
+type cacheIndex struct {
+	Version int           `json:"version"`
+	Entries []*CacheEntry `json:"entries"`
+}
+
+// cacheHeader is the first line of a cache file.
+type cacheHeader struct {
+	Version int `json:"version"`
+	CacheKey
 }
 
-// NewCache creates a new log cache.
+// cacheRecord is one line after the header: a step, followed by its entries.
+type cacheRecord struct {
+	Step  *cachedStep `json:"step,omitempty"`
+	Entry *LogEntry   `json:"entry,omitempty"`
+}
+
+type cachedStep struct {
+	Index      int       `json:"index"`
+	Workflow   string    `json:"workflow"`
+	RunID      int64     `json:"run_id"`
+	JobName    string    `json:"job,omitempty"`
+	StepName   string    `json:"name"`
+	Status     string    `json:"status,omitempty"`
+	Conclusion string    `json:"conclusion,omitempty"`
+	FetchedAt  time.Time `json:"fetched_at"`
+}
+
+// DefaultCacheDir returns the directory lazydispatch caches logs and run outputs in.
+func DefaultCacheDir() string {
+	cacheDir, _ := os.UserCacheDir()
+
+	return filepath.Join(cacheDir, "lazydispatch", "logs")
+}
+
+// NewCache creates a new log cache capped at DefaultCacheMaxSizeMB.
 // cacheDir should be something like ~/.cache/lazydispatch/logs/
 func NewCache(cacheDir string) *Cache {
 	return &Cache{
 		cacheDir: cacheDir,
-		entries:  make(map[string]*CacheEntry),
+		maxBytes: DefaultCacheMaxSizeMB << 20,
+		entries:  make(map[CacheKey]*CacheEntry),
+	}
+}
+
+// SetMaxSizeMB changes the size cap, evicting entries beyond it.
+func (c *Cache) SetMaxSizeMB(mb int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxBytes = int64(mb) << 20
+
+	if c.evict() {
+		return c.saveIndex()
 	}
+
+	return nil
 }
 
-// Get retrieves cached logs if available and not expired.
-func (c *Cache) Get(chainName string, runID int64) (*RunLogs, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// Get returns the cached logs of a run attempt.
+func (c *Cache) Get(key CacheKey) ([]*StepLogs, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.read(c.entries[key])
+}
+
+// Latest returns the cached logs of the most recent cached attempt of a run,
+// for when the current attempt cannot be looked up, e.g. while offline.
+func (c *Cache) Latest(repo string, runID int64) ([]*StepLogs, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var latest *CacheEntry
+
+	for key, entry := range c.entries {
+		if key.Repo == repo && key.RunID == runID && (latest == nil || key.Attempt > latest.Attempt) {
+			latest = entry
+		}
+	}
 
-	key := c.makeKey(chainName, runID)
+	return c.read(latest)
+}
 
-	entry, ok := c.entries[key]
-	if !ok {
+// read decodes an entry's file and marks it as used. The access time is only
+// kept in memory until the index is next saved. Callers hold c.mu.
+func (c *Cache) read(entry *CacheEntry) ([]*StepLogs, bool) {
+	if entry == nil {
 		return nil, false
 	}
 
-	// Check if expired
-	if time.Since(entry.CachedAt) > entry.TTL {
+	steps, err := readCacheFile(filepath.Join(c.cacheDir, entry.File), entry.CacheKey)
+	if err == nil && entry.Patterns != levelPatternsFingerprint() {
+		err = errors.New("parsed with other level patterns")
+	}
+
+	if err != nil {
+		// Unreadable, missing or stale: forget it so the logs are fetched again
+		delete(c.entries, entry.CacheKey)
+		os.Remove(filepath.Join(c.cacheDir, entry.File))
+		c.dirty = true
+
 		return nil, false
 	}
 
-	return entry.Logs, true
+	entry.LastAccess = time.Now()
+	c.dirty = true
+
+	return steps, true
 }
 
-// Put stores logs in the cache.
-func (c *Cache) Put(chainName string, runID int64, logs *RunLogs, ttl time.Duration) error {
+// Put stores the logs of a completed run attempt, then evicts the least
+// recently used entries until the cache fits its size cap.
+func (c *Cache) Put(key CacheKey, steps []*StepLogs) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := c.makeKey(chainName, runID)
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
 	entry := &CacheEntry{
-		ChainName: chainName,
-		RunID:     runID,
-		Logs:      logs,
-		CachedAt:  time.Now(),
-		TTL:       ttl,
+		CacheKey:   key,
+		File:       c.makeFilename(key),
+		LastAccess: time.Now(),
+		Patterns:   levelPatternsFingerprint(),
 	}
 
+	size, err := writeCacheFile(filepath.Join(c.cacheDir, entry.File), key, steps)
+	if err != nil {
+		return err
+	}
+
+	entry.Size = size
 	c.entries[key] = entry
+	c.evict()
 
-	// Persist to disk
-	return c.persistEntry(key, entry)
+	return c.saveIndex()
 }
 
-// Load loads the cache from disk.
+// Load reads the index from disk, dropping entries whose files are gone.
 func (c *Cache) Load() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -84,110 +202,131 @@ func (c *Cache) Load() error {
 		return fmt.Errorf("failed to create cache dir: %w", err)
 	}
 
-	entries, err := os.ReadDir(c.cacheDir)
-	if err != nil {
-		return fmt.Errorf("failed to read cache dir: %w", err)
+	data, err := os.ReadFile(filepath.Join(c.cacheDir, cacheIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
-			continue
-		}
-
-		path := filepath.Join(c.cacheDir, entry.Name())
-
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue // Skip invalid entries
-		}
+	if err != nil {
+		return fmt.Errorf("failed to read cache index: %w", err)
+	}
 
-		var cacheEntry CacheEntry
-		if err := json.Unmarshal(data, &cacheEntry); err != nil {
-			continue // Skip invalid entries
-		}
+	var index cacheIndex
+	if err := json.Unmarshal(data, &index); err != nil || index.Version != cacheFormatVersion {
+		return nil // Start over with an empty cache
+	}
 
-		// Check if expired
-		if time.Since(cacheEntry.CachedAt) > cacheEntry.TTL {
-			os.Remove(path) // Clean up expired entry
+	for _, entry := range index.Entries {
+		if _, err := os.Stat(filepath.Join(c.cacheDir, entry.File)); err != nil {
 			continue
 		}
 
-		key := c.makeKey(cacheEntry.ChainName, cacheEntry.RunID)
-		c.entries[key] = &cacheEntry
+		c.entries[entry.CacheKey] = entry
+	}
+
+	if c.evict() {
+		return c.saveIndex()
 	}
 
 	return nil
 }
 
-// Clear removes expired entries from the cache.
-func (c *Cache) Clear() error {
+// Close saves the access times of entries read since the index was last saved.
+func (c *Cache) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for key, entry := range c.entries {
-		if time.Since(entry.CachedAt) > entry.TTL {
-			delete(c.entries, key)
+	if !c.dirty {
+		return nil
+	}
+
+	return c.saveIndex()
+}
 
-			// Remove from disk
-			filename := c.makeFilename(key)
-			path := filepath.Join(c.cacheDir, filename)
-			os.Remove(path)
+// evict removes least recently used entries until the cache fits its size cap.
+// It reports whether anything was removed. Callers hold c.mu.
+func (c *Cache) evict() bool {
+	var total int64
+
+	entries := make([]*CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+		total += entry.Size
+	}
+
+	if total <= c.maxBytes {
+		return false
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccess.Before(entries[j].LastAccess)
+	})
+
+	for _, entry := range entries {
+		if total <= c.maxBytes {
+			break
 		}
+
+		delete(c.entries, entry.CacheKey)
+		os.Remove(filepath.Join(c.cacheDir, entry.File))
+
+		total -= entry.Size
 	}
 
-	return nil
+	return true
 }
 
-// persistEntry writes a cache entry to disk.
-func (c *Cache) persistEntry(key string, entry *CacheEntry) error {
-	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
-		return fmt.Errorf("failed to create cache dir: %w", err)
+// saveIndex writes the index to disk. Callers hold c.mu.
+func (c *Cache) saveIndex() error {
+	index := cacheIndex{Version: cacheFormatVersion, Entries: make([]*CacheEntry, 0, len(c.entries))}
+	for _, entry := range c.entries {
+		index.Entries = append(index.Entries, entry)
 	}
 
-	data, err := json.Marshal(entry)
+	sort.Slice(index.Entries, func(i, j int) bool {
+		return index.Entries[i].File < index.Entries[j].File
+	})
+
+	data, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal entry: %w", err)
+		return fmt.Errorf("failed to marshal cache index: %w", err)
 	}
 
-	filename := c.makeFilename(key)
-	path := filepath.Join(c.cacheDir, filename)
+	path := filepath.Join(c.cacheDir, cacheIndexFile)
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write cache file: %w", err)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
 	}
 
-	return nil
-}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	c.dirty = false
 
-// makeKey creates a unique key for a chain run.
-func (c *Cache) makeKey(chainName string, runID int64) string {
-	return fmt.Sprintf("%s:%d", chainName, runID)
+	return nil
 }
 
-// makeFilename creates a filesystem-safe filename from a key.
-func (c *Cache) makeFilename(key string) string {
-	// Replace colons and slashes for filesystem safety
-	safe := key
-	safe = filepath.Base(safe)
+// makeFilename creates a filesystem-safe filename for a run attempt.
+func (c *Cache) makeFilename(key CacheKey) string {
+	repo := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key.Repo)
 
-	return safe + ".json"
+	return fmt.Sprintf("%s_%d_%d.ndjson.gz", repo, key.RunID, key.Attempt)
 }
 
 // Stats returns cache statistics.
 func (c *Cache) Stats() CacheStats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	stats := CacheStats{
 		TotalEntries: len(c.entries),
+		MaxBytes:     c.maxBytes,
 	}
 
 	for _, entry := range c.entries {
-		if time.Since(entry.CachedAt) > entry.TTL {
-			stats.ExpiredEntries++
-		} else {
-			stats.ValidEntries++
-		}
+		stats.TotalBytes += entry.Size
 	}
 
 	return stats
@@ -195,7 +334,123 @@ func (c *Cache) Stats() CacheStats {
 
 // CacheStats provides cache metrics.
 type CacheStats struct {
-	TotalEntries   int
-	ValidEntries   int
-	ExpiredEntries int
+	TotalEntries int
+	TotalBytes   int64
+	MaxBytes     int64
+}
+
+// writeCacheFile writes steps as gzip'd NDJSON and returns the file size.
+func writeCacheFile(path string, key CacheKey, steps []*StepLogs) (int64, error) {
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+
+	err = enc.Encode(cacheHeader{Version: cacheFormatVersion, CacheKey: key})
+
+	for _, step := range steps {
+		if err != nil {
+			break
+		}
+
+		err = enc.Encode(cacheRecord{Step: &cachedStep{
+			Index:      step.StepIndex,
+			Workflow:   step.Workflow,
+			RunID:      step.RunID,
+			JobName:    step.JobName,
+			StepName:   step.StepName,
+			Status:     step.Status,
+			Conclusion: step.Conclusion,
+			FetchedAt:  step.FetchedAt,
+		}})
+
+		for i := range step.Entries {
+			if err != nil {
+				break
+			}
+
+			err = enc.Encode(cacheRecord{Entry: &step.Entries[i]})
+		}
+	}
+
+	err = errors.Join(err, zw.Close(), f.Close())
+	if err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// readCacheFile decodes a file written by writeCacheFile.
+func readCacheFile(path string, key CacheKey) ([]*StepLogs, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(zr)
+
+	var header cacheHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, err
+	}
+
+	if header.Version != cacheFormatVersion || header.CacheKey != key {
+		return nil, fmt.Errorf("cache file %s does not hold %+v", path, key)
+	}
+
+	var steps []*StepLogs
+
+	for {
+		var record cacheRecord
+
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return steps, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case record.Step != nil:
+			s := record.Step
+			steps = append(steps, &StepLogs{
+				StepIndex:  s.Index,
+				Workflow:   s.Workflow,
+				RunID:      s.RunID,
+				JobName:    s.JobName,
+				StepName:   s.StepName,
+				Status:     s.Status,
+				Conclusion: s.Conclusion,
+				FetchedAt:  s.FetchedAt,
+			})
+		case record.Entry != nil && len(steps) > 0:
+			last := steps[len(steps)-1]
+			last.Entries = append(last.Entries, *record.Entry)
+		}
+	}
 }
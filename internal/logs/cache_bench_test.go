@@ -1,68 +1,70 @@
 package logs
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
 
-func BenchmarkCache_Put(b *testing.B) {
-	cache := NewCache(b.TempDir())
-	runLogs := NewRunLogs("test", "main")
+func benchCacheSteps(steps, entries int) []*StepLogs {
+	stepLogs := make([]*StepLogs, 0, steps)
 
-	// Add realistic number of log entries
-	for range 100 {
-		runLogs.AddStep(&StepLogs{
-			Entries: make([]LogEntry, 100),
-		})
+	for i := range steps {
+		step := &StepLogs{StepIndex: i, StepName: fmt.Sprintf("step-%d", i)}
+
+		for j := range entries {
+			step.Entries = append(step.Entries, LogEntry{
+				Timestamp: time.Date(2024, 1, 1, 12, 0, j%60, 0, time.UTC),
+				Content:   fmt.Sprintf("Log line %d in step %d", j, i),
+				Level:     LogLevelInfo,
+				StepName:  step.StepName,
+			})
+		}
+
+		stepLogs = append(stepLogs, step)
 	}
 
+	return stepLogs
+}
+
+func BenchmarkCache_Put(b *testing.B) {
+	cache := NewCache(b.TempDir())
+	steps := benchCacheSteps(10, 100)
+
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := range b.N {
-		cache.Put("test", int64(i), runLogs, 1*time.Hour)
+		cache.Put(CacheKey{Repo: "owner/repo", RunID: int64(i % 100), Attempt: 1}, steps)
 	}
 }
 
 func BenchmarkCache_Get(b *testing.B) {
 	cache := NewCache(b.TempDir())
-	runLogs := NewRunLogs("test", "main")
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
 
-	// Add realistic data
-	for range 100 {
-		runLogs.AddStep(&StepLogs{
-			Entries: make([]LogEntry, 100),
-		})
-	}
-
-	cache.Put("test", 123, runLogs, 1*time.Hour)
+	cache.Put(key, benchCacheSteps(10, 100))
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for range b.N {
-		cache.Get("test", 123)
+		cache.Get(key)
 	}
 }
 
 func BenchmarkCache_ConcurrentAccess(b *testing.B) {
 	cache := NewCache(b.TempDir())
-	runLogs := NewRunLogs("test", "main")
-
-	// Add realistic data
-	for range 50 {
-		runLogs.AddStep(&StepLogs{
-			Entries: make([]LogEntry, 50),
-		})
-	}
+	steps := benchCacheSteps(5, 50)
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			cache.Put("test", 123, runLogs, 1*time.Hour)
-			cache.Get("test", 123)
+			cache.Put(key, steps)
+			cache.Get(key)
 		}
 	})
 }
@@ -72,15 +74,8 @@ func BenchmarkCache_Load(b *testing.B) {
 	cache1 := NewCache(cacheDir)
 
 	// Setup: Add entries
-	for i := range 10 {
-		runLogs := NewRunLogs("test", "main")
-		for range 50 {
-			runLogs.AddStep(&StepLogs{
-				Entries: make([]LogEntry, 50),
-			})
-		}
-
-		cache1.Put("test", int64(i), runLogs, 1*time.Hour)
+	for i := range 100 {
+		cache1.Put(CacheKey{Repo: "owner/repo", RunID: int64(i), Attempt: 1}, benchCacheSteps(1, 10))
 	}
 
 	b.ResetTimer()
@@ -97,8 +92,7 @@ func BenchmarkCache_Stats(b *testing.B) {
 
 	// Add entries
 	for i := range 100 {
-		runLogs := NewRunLogs("test", "main")
-		cache.Put("test", int64(i), runLogs, 1*time.Hour)
+		cache.Put(CacheKey{Repo: "owner/repo", RunID: int64(i), Attempt: 1}, benchCacheSteps(1, 1))
 	}
 
 	b.ResetTimer()
@@ -109,80 +103,18 @@ func BenchmarkCache_Stats(b *testing.B) {
 	}
 }
 
-func BenchmarkCache_Clear(b *testing.B) {
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for range b.N {
-		b.StopTimer()
-		cache := NewCache(b.TempDir())
-
-		// Add mix of valid and expired entries
-		for j := range 50 {
-			runLogs := NewRunLogs("test", "main")
-
-			ttl := 1 * time.Hour
-			if j%2 == 0 {
-				ttl = 1 * time.Millisecond
-			}
-
-			cache.Put("test", int64(j), runLogs, ttl)
-		}
-
-		time.Sleep(5 * time.Millisecond) // Wait for some to expire
-
-		b.StartTimer()
-		cache.Clear()
-	}
-}
-
-func BenchmarkCache_MakeKey(b *testing.B) {
-	cache := NewCache(b.TempDir())
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := range b.N {
-		cache.makeKey("test-chain", int64(i))
-	}
-}
-
-func BenchmarkCache_PutGet_SmallLogs(b *testing.B) {
-	cache := NewCache(b.TempDir())
-	runLogs := NewRunLogs("test", "main")
-
-	// Small logs: 10 steps with 10 entries each
-	for range 10 {
-		runLogs.AddStep(&StepLogs{
-			Entries: make([]LogEntry, 10),
-		})
-	}
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := range b.N {
-		cache.Put("test", int64(i%100), runLogs, 1*time.Hour)
-		cache.Get("test", int64(i%100))
-	}
-}
-
 func BenchmarkCache_PutGet_LargeLogs(b *testing.B) {
 	cache := NewCache(b.TempDir())
-	runLogs := NewRunLogs("test", "main")
 
-	// Large logs: 200 steps with 500 entries each
-	for range 200 {
-		runLogs.AddStep(&StepLogs{
-			Entries: make([]LogEntry, 500),
-		})
-	}
+	// Large logs: 20 steps with 5000 entries each
+	steps := benchCacheSteps(20, 5000)
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := range b.N {
-		cache.Put("test", int64(i%100), runLogs, 1*time.Hour)
-		cache.Get("test", int64(i%100))
+		key := CacheKey{Repo: "owner/repo", RunID: int64(i % 10), Attempt: 1}
+		cache.Put(key, steps)
+		cache.Get(key)
 	}
 }
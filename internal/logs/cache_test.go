@@ -1,14 +1,45 @@
 package logs
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
 )
 
+func testCacheSteps(entries int) []*StepLogs {
+	steps := []*StepLogs{
+		{
+			StepIndex:  0,
+			Workflow:   "ci.yml",
+			RunID:      123,
+			JobName:    "build",
+			StepName:   "Run tests",
+			Status:     "completed",
+			Conclusion: "failure",
+			FetchedAt:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for i := range entries {
+		steps[0].Entries = append(steps[0].Entries, LogEntry{
+			Timestamp: time.Date(2024, 1, 1, 12, 0, i%60, 0, time.UTC),
+			Content:   "line",
+			Level:     LogLevelInfo,
+			StepName:  "Run tests",
+		})
+	}
+
+	return steps
+}
+
 func TestCache_NewCache(t *testing.T) {
 	cacheDir := t.TempDir()
 	cache := NewCache(cacheDir)
@@ -24,55 +55,114 @@ func TestCache_NewCache(t *testing.T) {
 	if cache.entries == nil {
 		t.Error("expected non-nil entries map")
 	}
+
+	if cache.maxBytes != DefaultCacheMaxSizeMB<<20 {
+		t.Errorf("maxBytes: got %d, want %d", cache.maxBytes, DefaultCacheMaxSizeMB<<20)
+	}
 }
 
 func TestCache_GetPut(t *testing.T) {
 	cache := NewCache(t.TempDir())
-	runLogs := NewRunLogs("test", "main")
-	runLogs.AddStep(&StepLogs{StepName: "build"})
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
 
-	// Put logs into cache
-	err := cache.Put("test", 123, runLogs, 1*time.Hour)
-	if err != nil {
+	steps := testCacheSteps(3)
+	steps[0].Entries[1] = LogEntry{
+		Content:    "unused variable",
+		Level:      LogLevelError,
+		StepName:   "Run tests",
+		Annotation: &Annotation{File: "main.go", Line: 3},
+		Spans:      []Span{{Text: "unused variable", Style: SpanStyle{Foreground: "1"}}},
+		Depth:      1,
+	}
+
+	if err := cache.Put(key, steps); err != nil {
 		t.Fatalf("Put failed: %v", err)
 	}
 
-	// Get logs from cache
-	retrieved, found := cache.Get("test", 123)
+	retrieved, found := cache.Get(key)
 	if !found {
 		t.Fatal("expected to find cached logs")
 	}
 
-	if retrieved == nil {
-		t.Fatal("expected non-nil logs")
+	if len(retrieved) != 1 {
+		t.Fatalf("expected 1 step, got %d", len(retrieved))
+	}
+
+	got, want := retrieved[0], steps[0]
+	if got.StepName != want.StepName || got.JobName != want.JobName || got.Conclusion != want.Conclusion ||
+		got.RunID != want.RunID || !got.FetchedAt.Equal(want.FetchedAt) {
+		t.Errorf("step: got %+v, want %+v", got, want)
 	}
 
-	if retrieved.ChainName != "test" {
-		t.Errorf("ChainName: got %q, want %q", retrieved.ChainName, "test")
+	if len(got.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(got.Entries))
 	}
 
-	if len(retrieved.Steps) != 1 {
-		t.Errorf("expected 1 step, got %d", len(retrieved.Steps))
+	entry := got.Entries[1]
+	if entry.Content != "unused variable" || entry.Annotation == nil || *entry.Annotation != *want.Entries[1].Annotation ||
+		len(entry.Spans) != 1 || entry.Spans[0] != want.Entries[1].Spans[0] || entry.Depth != 1 {
+		t.Errorf("entry: got %+v, want %+v", entry, want.Entries[1])
+	}
+
+	if !got.Entries[0].Timestamp.Equal(want.Entries[0].Timestamp) {
+		t.Errorf("timestamp: got %s, want %s", got.Entries[0].Timestamp, want.Entries[0].Timestamp)
+	}
+
+	// Other attempts of the same run are separate entries
+	if _, found := cache.Get(CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 2}); found {
+		t.Error("expected attempt 2 not found")
 	}
 }
 
-func TestCache_Expiration(t *testing.T) {
+func TestCache_LevelPatternsChanged(t *testing.T) {
+	t.Cleanup(func() { SetLevelPatterns(LevelPatterns{}) })
+
 	cache := NewCache(t.TempDir())
-	runLogs := NewRunLogs("test", "main")
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
 
-	// Put with very short TTL
-	err := cache.Put("test", 123, runLogs, 1*time.Millisecond)
-	if err != nil {
-		t.Fatalf("Put failed: %v", err)
+	if err := cache.Put(key, testCacheSteps(1)); err != nil {
+		t.Fatal(err)
 	}
 
-	// Wait for expiration
-	time.Sleep(5 * time.Millisecond)
+	if err := SetLevelPatterns(LevelPatterns{Error: []string{"^FAIL:"}}); err != nil {
+		t.Fatal(err)
+	}
 
-	// Try to get expired entry
-	_, found := cache.Get("test", 123)
-	if found {
-		t.Error("expected expired entry not found")
+	// Levels were detected with other patterns, so the logs must be parsed again
+	if _, found := cache.Get(key); found {
+		t.Error("expected a miss after the level patterns changed")
+	}
+
+	if err := cache.Put(key, testCacheSteps(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := cache.Get(key); !found {
+		t.Error("expected a hit for logs parsed with the current patterns")
+	}
+}
+
+func TestCache_Latest(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		steps := testCacheSteps(attempt)
+		if err := cache.Put(CacheKey{Repo: "owner/repo", RunID: 123, Attempt: attempt}, steps); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	latest, found := cache.Latest("owner/repo", 123)
+	if !found {
+		t.Fatal("expected to find latest attempt")
+	}
+
+	if len(latest[0].Entries) != 2 {
+		t.Errorf("expected attempt 2 with 2 entries, got %d entries", len(latest[0].Entries))
+	}
+
+	if _, found := cache.Latest("other/repo", 123); found {
+		t.Error("expected no entry for another repository")
 	}
 }
 
@@ -80,135 +170,195 @@ func TestCache_Load(t *testing.T) {
 	cacheDir := t.TempDir()
 	cache1 := NewCache(cacheDir)
 
-	// Add entries to first cache instance
-	runLogs1 := NewRunLogs("test1", "main")
-	runLogs2 := NewRunLogs("test2", "develop")
+	key1 := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
+	key2 := CacheKey{Repo: "owner/repo", RunID: 456, Attempt: 3}
 
-	err := cache1.Put("test1", 123, runLogs1, 1*time.Hour)
-	if err != nil {
-		t.Fatalf("Put test1 failed: %v", err)
+	if err := cache1.Put(key1, testCacheSteps(1)); err != nil {
+		t.Fatalf("Put 1 failed: %v", err)
 	}
 
-	err = cache1.Put("test2", 456, runLogs2, 1*time.Hour)
-	if err != nil {
-		t.Fatalf("Put test2 failed: %v", err)
+	if err := cache1.Put(key2, testCacheSteps(2)); err != nil {
+		t.Fatalf("Put 2 failed: %v", err)
 	}
 
 	// Create new cache instance and load from disk
 	cache2 := NewCache(cacheDir)
 
-	err = cache2.Load()
-	if err != nil {
+	if err := cache2.Load(); err != nil {
 		t.Fatalf("Load failed: %v", err)
 	}
 
-	// Verify entries were loaded
-	retrieved1, found := cache2.Get("test1", 123)
-	if !found {
-		t.Error("expected to find test1 after load")
+	for _, key := range []CacheKey{key1, key2} {
+		if _, found := cache2.Get(key); !found {
+			t.Errorf("expected to find %+v after load", key)
+		}
 	}
+}
 
-	if retrieved1.ChainName != "test1" {
-		t.Errorf("test1 ChainName: got %q, want %q", retrieved1.ChainName, "test1")
+func TestCache_LoadDropsMissingFiles(t *testing.T) {
+	cacheDir := t.TempDir()
+	cache1 := NewCache(cacheDir)
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
+
+	if err := cache1.Put(key, testCacheSteps(1)); err != nil {
+		t.Fatalf("Put failed: %v", err)
 	}
 
-	retrieved2, found := cache2.Get("test2", 456)
-	if !found {
-		t.Error("expected to find test2 after load")
+	if err := os.Remove(filepath.Join(cacheDir, cache1.makeFilename(key))); err != nil {
+		t.Fatal(err)
+	}
+
+	cache2 := NewCache(cacheDir)
+	if err := cache2.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
 	}
 
-	if retrieved2.ChainName != "test2" {
-		t.Errorf("test2 ChainName: got %q, want %q", retrieved2.ChainName, "test2")
+	if stats := cache2.Stats(); stats.TotalEntries != 0 {
+		t.Errorf("expected 0 entries, got %d", stats.TotalEntries)
 	}
 }
 
-func TestCache_LoadWithExpiredEntries(t *testing.T) {
+func TestCache_InvalidIndex(t *testing.T) {
 	cacheDir := t.TempDir()
-	cache1 := NewCache(cacheDir)
-
-	// Add entry with short TTL
-	runLogs := NewRunLogs("test", "main")
 
-	err := cache1.Put("test", 123, runLogs, 1*time.Millisecond)
+	err := os.WriteFile(filepath.Join(cacheDir, cacheIndexFile), []byte("{invalid json}"), 0644)
 	if err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// Load should start over with an empty cache
+	cache := NewCache(cacheDir)
+
+	if err := cache.Load(); err != nil {
+		t.Fatalf("Load should not fail on an invalid index: %v", err)
+	}
+
+	if stats := cache.Stats(); stats.TotalEntries != 0 {
+		t.Errorf("expected 0 entries, got %d", stats.TotalEntries)
+	}
+}
+
+func TestCache_CorruptFile(t *testing.T) {
+	cacheDir := t.TempDir()
+	cache := NewCache(cacheDir)
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
+
+	if err := cache.Put(key, testCacheSteps(1)); err != nil {
 		t.Fatalf("Put failed: %v", err)
 	}
 
-	// Wait for expiration
-	time.Sleep(5 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(cacheDir, cache.makeFilename(key)), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
 
-	// Load into new cache - expired entries should be cleaned up
-	cache2 := NewCache(cacheDir)
+	if _, found := cache.Get(key); found {
+		t.Error("expected corrupt entry not found")
+	}
 
-	err = cache2.Load()
-	if err != nil {
-		t.Fatalf("Load failed: %v", err)
+	if stats := cache.Stats(); stats.TotalEntries != 0 {
+		t.Errorf("expected corrupt entry dropped, got %d entries", stats.TotalEntries)
 	}
+}
 
-	// Expired entry should not be loaded
-	_, found := cache2.Get("test", 123)
-	if found {
-		t.Error("expected expired entry not loaded")
+func TestCache_LRUEviction(t *testing.T) {
+	cacheDir := t.TempDir()
+	cache := NewCache(cacheDir)
+
+	keys := []CacheKey{
+		{Repo: "owner/repo", RunID: 1, Attempt: 1},
+		{Repo: "owner/repo", RunID: 2, Attempt: 1},
+		{Repo: "owner/repo", RunID: 3, Attempt: 1},
 	}
 
-	// Verify file was removed
-	files, err := os.ReadDir(cacheDir)
-	if err != nil {
-		t.Fatalf("ReadDir failed: %v", err)
+	for _, key := range keys {
+		if err := cache.Put(key, testCacheSteps(10)); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	// Mark run 1 as recently used, leaving run 2 as the least recently used
+	for i, key := range keys {
+		cache.entries[key].LastAccess = time.Now().Add(time.Duration(i-len(keys)) * time.Minute)
+	}
+
+	cache.Get(keys[0])
+
+	// Shrink the cap to fit two of the three entries
+	size := cache.entries[keys[0]].Size
+	cache.maxBytes = 2*size + size/2
+
+	if !cache.evict() {
+		t.Fatal("expected eviction")
 	}
 
-	if len(files) != 0 {
-		t.Errorf("expected 0 cache files, got %d", len(files))
+	if _, found := cache.entries[keys[1]]; found {
+		t.Error("expected least recently used entry evicted")
+	}
+
+	for _, key := range []CacheKey{keys[0], keys[2]} {
+		if _, found := cache.entries[key]; !found {
+			t.Errorf("expected %+v kept", key)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, cache.makeFilename(keys[1]))); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected evicted file removed, got %v", err)
 	}
 }
 
-func TestCache_Clear(t *testing.T) {
+func TestCache_AccessSavedOnClose(t *testing.T) {
 	cacheDir := t.TempDir()
 	cache := NewCache(cacheDir)
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
+
+	if err := cache.Put(key, testCacheSteps(1)); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
 
-	// Add valid and expired entries
-	runLogs1 := NewRunLogs("valid", "main")
-	runLogs2 := NewRunLogs("expired", "main")
+	indexPath := filepath.Join(cacheDir, cacheIndexFile)
 
-	err := cache.Put("valid", 123, runLogs1, 1*time.Hour)
+	saved, err := os.ReadFile(indexPath)
 	if err != nil {
-		t.Fatalf("Put valid failed: %v", err)
+		t.Fatal(err)
 	}
 
-	err = cache.Put("expired", 456, runLogs2, 1*time.Millisecond)
-	if err != nil {
-		t.Fatalf("Put expired failed: %v", err)
+	cache.entries[key].LastAccess = time.Now().Add(-time.Hour)
+
+	if _, found := cache.Get(key); !found {
+		t.Fatal("expected a cache hit")
 	}
 
-	// Wait for one to expire
-	time.Sleep(5 * time.Millisecond)
+	if current, _ := os.ReadFile(indexPath); string(current) != string(saved) {
+		t.Error("a cache hit should not rewrite the index")
+	}
 
-	// Clear expired entries
-	err = cache.Clear()
-	if err != nil {
-		t.Fatalf("Clear failed: %v", err)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
 	}
 
-	// Valid entry should still exist
-	_, found := cache.Get("valid", 123)
-	if !found {
-		t.Error("expected valid entry still exists")
+	reloaded := NewCache(cacheDir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
 	}
 
-	// Expired entry should be removed
-	_, found = cache.Get("expired", 456)
-	if found {
-		t.Error("expected expired entry removed")
+	if got := reloaded.entries[key].LastAccess; !got.Equal(cache.entries[key].LastAccess) {
+		t.Errorf("LastAccess = %v, want the time of the hit %v", got, cache.entries[key].LastAccess)
 	}
+}
 
-	// Verify file was removed
-	files, err := os.ReadDir(cacheDir)
-	if err != nil {
-		t.Fatalf("ReadDir failed: %v", err)
+func TestCache_SetMaxSizeMB(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	if err := cache.Put(CacheKey{Repo: "owner/repo", RunID: 1, Attempt: 1}, testCacheSteps(10)); err != nil {
+		t.Fatalf("Put failed: %v", err)
 	}
 
-	if len(files) != 1 {
-		t.Errorf("expected 1 cache file, got %d", len(files))
+	if err := cache.SetMaxSizeMB(0); err != nil {
+		t.Fatalf("SetMaxSizeMB failed: %v", err)
+	}
+
+	if stats := cache.Stats(); stats.TotalEntries != 0 || stats.TotalBytes != 0 || stats.MaxBytes != 0 {
+		t.Errorf("expected empty cache, got %+v", stats)
 	}
 }
 
@@ -221,42 +371,16 @@ func TestCache_Stats(t *testing.T) {
 		t.Errorf("TotalEntries: got %d, want 0", stats.TotalEntries)
 	}
 
-	// Add valid entries
-	runLogs1 := NewRunLogs("test1", "main")
-	runLogs2 := NewRunLogs("test2", "main")
-
-	cache.Put("test1", 123, runLogs1, 1*time.Hour)
-	cache.Put("test2", 456, runLogs2, 1*time.Hour)
+	cache.Put(CacheKey{Repo: "owner/repo", RunID: 1, Attempt: 1}, testCacheSteps(1))
+	cache.Put(CacheKey{Repo: "owner/repo", RunID: 2, Attempt: 1}, testCacheSteps(1))
 
 	stats = cache.Stats()
 	if stats.TotalEntries != 2 {
 		t.Errorf("TotalEntries: got %d, want 2", stats.TotalEntries)
 	}
 
-	if stats.ValidEntries != 2 {
-		t.Errorf("ValidEntries: got %d, want 2", stats.ValidEntries)
-	}
-
-	if stats.ExpiredEntries != 0 {
-		t.Errorf("ExpiredEntries: got %d, want 0", stats.ExpiredEntries)
-	}
-
-	// Add expired entry
-	runLogs3 := NewRunLogs("test3", "main")
-	cache.Put("test3", 789, runLogs3, 1*time.Millisecond)
-	time.Sleep(5 * time.Millisecond)
-
-	stats = cache.Stats()
-	if stats.TotalEntries != 3 {
-		t.Errorf("TotalEntries: got %d, want 3", stats.TotalEntries)
-	}
-
-	if stats.ValidEntries != 2 {
-		t.Errorf("ValidEntries: got %d, want 2", stats.ValidEntries)
-	}
-
-	if stats.ExpiredEntries != 1 {
-		t.Errorf("ExpiredEntries: got %d, want 1", stats.ExpiredEntries)
+	if stats.TotalBytes <= 0 {
+		t.Errorf("TotalBytes: got %d, want > 0", stats.TotalBytes)
 	}
 }
 
@@ -277,8 +401,7 @@ func TestCache_ConcurrentAccess(t *testing.T) {
 			defer wg.Done()
 
 			for j := range opsPerGoroutine {
-				runLogs := NewRunLogs("test", "main")
-				cache.Put("test", int64(id*opsPerGoroutine+j), runLogs, 1*time.Hour)
+				cache.Put(CacheKey{Repo: "owner/repo", RunID: int64(id*opsPerGoroutine + j), Attempt: 1}, testCacheSteps(1))
 			}
 		}(i)
 	}
@@ -289,7 +412,7 @@ func TestCache_ConcurrentAccess(t *testing.T) {
 			defer wg.Done()
 
 			for j := range opsPerGoroutine {
-				cache.Get("test", int64(id*opsPerGoroutine+j))
+				cache.Get(CacheKey{Repo: "owner/repo", RunID: int64(id*opsPerGoroutine + j), Attempt: 1})
 			}
 		}(i)
 	}
@@ -305,52 +428,23 @@ func TestCache_ConcurrentAccess(t *testing.T) {
 	}
 }
 
-func TestCache_InvalidJSON(t *testing.T) {
-	cacheDir := t.TempDir()
-
-	// Write invalid JSON to cache file
-	invalidJSON := []byte("{invalid json}")
-	filename := filepath.Join(cacheDir, "test_123.json")
-
-	err := os.WriteFile(filename, invalidJSON, 0644)
-	if err != nil {
-		t.Fatalf("WriteFile failed: %v", err)
-	}
-
-	// Load should skip invalid entries
-	cache := NewCache(cacheDir)
-
-	err = cache.Load()
-	if err != nil {
-		t.Fatalf("Load should not fail on invalid JSON: %v", err)
-	}
-
-	// Verify no entries loaded
-	stats := cache.Stats()
-	if stats.TotalEntries != 0 {
-		t.Errorf("expected 0 entries, got %d", stats.TotalEntries)
-	}
-}
-
-func TestCache_MakeKey(t *testing.T) {
+func TestCache_MakeFilename(t *testing.T) {
 	cache := NewCache(t.TempDir())
 
 	tests := []struct {
-		name      string
-		chainName string
-		runID     int64
-		want      string
+		name string
+		key  CacheKey
+		want string
 	}{
-		{"simple", "test", 123, "test:123"},
-		{"with hyphen", "my-chain", 456, "my-chain:456"},
-		{"large run ID", "test", 9876543210, "test:9876543210"},
+		{"simple", CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}, "owner_repo_123_1.ndjson.gz"},
+		{"large run ID", CacheKey{Repo: "o/r", RunID: 9876543210, Attempt: 2}, "o_r_9876543210_2.ndjson.gz"},
+		{"no repo", CacheKey{RunID: 5, Attempt: 1}, "_5_1.ndjson.gz"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cache.makeKey(tt.chainName, tt.runID)
-			if got != tt.want {
-				t.Errorf("makeKey: got %q, want %q", got, tt.want)
+			if got := cache.makeFilename(tt.key); got != tt.want {
+				t.Errorf("makeFilename: got %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -360,87 +454,221 @@ func TestCache_GetNonExistent(t *testing.T) {
 	cache := NewCache(t.TempDir())
 
 	// Try to get non-existent entry
-	_, found := cache.Get("nonexistent", 999)
+	_, found := cache.Get(CacheKey{Repo: "owner/repo", RunID: 999, Attempt: 1})
 	if found {
 		t.Error("expected not found for non-existent entry")
 	}
 }
 
-func TestCache_PersistEntry(t *testing.T) {
+func TestCache_FileFormat(t *testing.T) {
 	cacheDir := t.TempDir()
 	cache := NewCache(cacheDir)
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
 
-	runLogs := NewRunLogs("test", "main")
-	runLogs.AddStep(&StepLogs{StepName: "build"})
+	if err := cache.Put(key, testCacheSteps(2)); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
 
-	err := cache.Put("test", 123, runLogs, 1*time.Hour)
+	f, err := os.Open(filepath.Join(cacheDir, "owner_repo_123_1.ndjson.gz"))
 	if err != nil {
-		t.Fatalf("Put failed: %v", err)
+		t.Fatalf("Open failed: %v", err)
 	}
+	defer f.Close()
 
-	// Verify file was created
-	files, err := os.ReadDir(cacheDir)
+	zr, err := gzip.NewReader(f)
 	if err != nil {
-		t.Fatalf("ReadDir failed: %v", err)
+		t.Fatalf("expected gzip file: %v", err)
 	}
 
-	if len(files) != 1 {
-		t.Fatalf("expected 1 cache file, got %d", len(files))
+	dec := json.NewDecoder(zr)
+
+	var lines []map[string]json.RawMessage
+
+	for dec.More() {
+		var line map[string]json.RawMessage
+		if err := dec.Decode(&line); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+
+		lines = append(lines, line)
 	}
 
-	// Verify file contents
-	filename := files[0].Name()
+	// header, step, two entries
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
 
-	data, err := os.ReadFile(filepath.Join(cacheDir, filename))
-	if err != nil {
-		t.Fatalf("ReadFile failed: %v", err)
+	if string(lines[0]["version"]) != "1" || string(lines[0]["run_id"]) != "123" {
+		t.Errorf("unexpected header: %v", lines[0])
 	}
 
-	var entry CacheEntry
+	if _, ok := lines[1]["step"]; !ok {
+		t.Errorf("expected step record, got %v", lines[1])
+	}
 
-	err = json.Unmarshal(data, &entry)
-	if err != nil {
-		t.Fatalf("Unmarshal failed: %v", err)
+	if _, ok := lines[2]["entry"]; !ok {
+		t.Errorf("expected entry record, got %v", lines[2])
 	}
+}
 
-	if entry.ChainName != "test" {
-		t.Errorf("ChainName: got %q, want %q", entry.ChainName, "test")
+// countingFetcher counts fetches and returns fixed step logs.
+type countingFetcher struct {
+	steps   []*StepLogs
+	fetches int
+}
+
+func (f *countingFetcher) FetchStepLogs(runID int64, workflow string) ([]*StepLogs, error) {
+	f.fetches++
+	return f.steps, nil
+}
+
+// runStatusClient reports a fixed run status, or an error when offline.
+type runStatusClient struct {
+	run     github.WorkflowRun
+	offline bool
+}
+
+func (c *runStatusClient) GetWorkflowRun(runID int64) (*github.WorkflowRun, error) {
+	if c.offline {
+		return nil, errors.New("network unreachable")
+	}
+
+	run := c.run
+
+	return &run, nil
+}
+
+func (c *runStatusClient) GetWorkflowRunJobs(runID int64) ([]github.Job, error) {
+	return nil, nil
+}
+
+func (c *runStatusClient) Owner() string { return "owner" }
+func (c *runStatusClient) Repo() string  { return "repo" }
+
+func TestManager_CachesCompletedRuns(t *testing.T) {
+	cacheDir := t.TempDir()
+	client := &runStatusClient{run: github.WorkflowRun{Status: "completed", RunAttempt: 1}}
+	fetcher := &countingFetcher{steps: testCacheSteps(2)}
+
+	m := newManager(client, fetcher, cacheDir)
+
+	for range 2 {
+		runLogs, err := m.GetLogsForRun(123, "ci.yml", map[string]string{"env": "prod"})
+		if err != nil {
+			t.Fatalf("GetLogsForRun failed: %v", err)
+		}
+
+		if len(runLogs.Steps) != 1 || len(runLogs.Steps[0].Entries) != 2 {
+			t.Fatalf("unexpected logs: %+v", runLogs.Steps)
+		}
+
+		if len(runLogs.Runs) != 1 || runLogs.Runs[0].Inputs["env"] != "prod" {
+			t.Fatalf("unexpected run info: %+v", runLogs.Runs)
+		}
+	}
+
+	if fetcher.fetches != 1 {
+		t.Errorf("expected 1 fetch, got %d", fetcher.fetches)
+	}
+
+	// A new attempt is fetched again
+	client.run.RunAttempt = 2
+
+	if _, err := m.GetLogsForRun(123, "ci.yml", nil); err != nil {
+		t.Fatalf("GetLogsForRun failed: %v", err)
 	}
 
-	if entry.RunID != 123 {
-		t.Errorf("RunID: got %d, want 123", entry.RunID)
+	if fetcher.fetches != 2 {
+		t.Errorf("expected new attempt to be fetched, got %d fetches", fetcher.fetches)
+	}
+
+	// Offline, a fresh manager serves the latest cached attempt from disk
+	client.offline = true
+	offline := newManager(client, fetcher, cacheDir)
+
+	if err := offline.LoadCache(); err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+
+	if _, err := offline.GetLogsForRun(123, "ci.yml", nil); err != nil {
+		t.Fatalf("GetLogsForRun offline failed: %v", err)
+	}
+
+	if fetcher.fetches != 2 {
+		t.Errorf("expected offline read from cache, got %d fetches", fetcher.fetches)
 	}
 }
 
-func TestCacheEntry_Fields(t *testing.T) {
-	now := time.Now()
-	runLogs := NewRunLogs("test", "main")
+func TestManager_SkipsCacheForActiveRuns(t *testing.T) {
+	client := &runStatusClient{run: github.WorkflowRun{Status: "in_progress", RunAttempt: 1}}
+	fetcher := &countingFetcher{steps: testCacheSteps(1)}
+
+	m := newManager(client, fetcher, t.TempDir())
+
+	m.GetLogsForRun(123, "ci.yml", nil)
+	m.GetLogsForRun(123, "ci.yml", nil)
+
+	if fetcher.fetches != 2 {
+		t.Errorf("expected active run fetched every time, got %d fetches", fetcher.fetches)
+	}
+
+	if stats := m.cache.Stats(); stats.TotalEntries != 0 {
+		t.Errorf("expected nothing cached, got %d entries", stats.TotalEntries)
+	}
+
+	// Logs with fetch errors are not cached either
+	client.run.Status = "completed"
+	fetcher.steps[0].Error = errors.New("gh command failed")
+
+	m.GetLogsForRun(123, "ci.yml", nil)
 
-	entry := &CacheEntry{
-		ChainName: "test-chain",
-		RunID:     12345,
-		Logs:      runLogs,
-		CachedAt:  now,
-		TTL:       1 * time.Hour,
+	if stats := m.cache.Stats(); stats.TotalEntries != 0 {
+		t.Errorf("expected incomplete logs not cached, got %d entries", stats.TotalEntries)
 	}
+}
+
+// recordingClient records the runs it is asked about.
+type recordingClient struct {
+	runStatusClient
+
+	requested []int64
+}
+
+func (c *recordingClient) GetWorkflowRun(runID int64) (*github.WorkflowRun, error) {
+	c.requested = append(c.requested, runID)
+	return c.runStatusClient.GetWorkflowRun(runID)
+}
 
-	if entry.ChainName != "test-chain" {
-		t.Errorf("ChainName: got %q, want %q", entry.ChainName, "test-chain")
+func TestManager_ChainSkipsStepsWithoutRuns(t *testing.T) {
+	client := &recordingClient{runStatusClient: runStatusClient{run: github.WorkflowRun{Status: "completed", RunAttempt: 1}}}
+	fetcher := &countingFetcher{steps: testCacheSteps(1)}
+
+	m := newManager(client, fetcher, t.TempDir())
+
+	state := chain.ChainState{
+		ChainName: "release",
+		StepResults: map[int]*chain.StepResult{
+			0: {Workflow: "build.yml", RunID: 7, Status: chain.StepCompleted},
+			1: {Workflow: "deploy.yml", Status: chain.StepSkipped, SkipReason: "if: false was false"},
+		},
 	}
 
-	if entry.RunID != 12345 {
-		t.Errorf("RunID: got %d, want 12345", entry.RunID)
+	runLogs, err := m.GetLogsForChain(state, "main")
+	if err != nil {
+		t.Fatalf("GetLogsForChain failed: %v", err)
 	}
 
-	if entry.Logs != runLogs {
-		t.Error("Logs mismatch")
+	if len(client.requested) != 1 || client.requested[0] != 7 || fetcher.fetches != 1 {
+		t.Errorf("requested runs %v with %d fetches, want only run 7", client.requested, fetcher.fetches)
 	}
 
-	if entry.CachedAt != now {
-		t.Error("CachedAt mismatch")
+	if len(runLogs.Runs) != 1 {
+		t.Errorf("expected only the step with a run to be reported, got %+v", runLogs.Runs)
 	}
 
-	if entry.TTL != 1*time.Hour {
-		t.Errorf("TTL: got %v, want %v", entry.TTL, 1*time.Hour)
+	for _, step := range runLogs.Steps {
+		if step.Error != nil {
+			t.Errorf("unexpected error step: %+v", step)
+		}
 	}
 }
@@ -0,0 +1,416 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+// ExportFormat selects how logs are written by Export.
+type ExportFormat string
+
+const (
+	ExportText     ExportFormat = "text"     // filtered entries as plain text
+	ExportNDJSON   ExportFormat = "ndjson"   // filtered entries as one JSON object per line
+	ExportMarkdown ExportFormat = "markdown" // failure report of the error lines of failed steps
+)
+
+// Extension returns the file extension for the format, without the dot.
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportNDJSON:
+		return "ndjson"
+	case ExportMarkdown:
+		return "md"
+	default:
+		return "txt"
+	}
+}
+
+// Export writes logs in the given format. Text and NDJSON contain the filtered
+// entries as shown in the logs viewer; the Markdown failure report is built from
+// all of runLogs, regardless of the filter.
+func Export(w io.Writer, format ExportFormat, runLogs *RunLogs, filtered *FilteredResult) error {
+	switch format {
+	case ExportText:
+		return WriteText(w, filtered)
+	case ExportNDJSON:
+		return WriteNDJSON(w, filtered)
+	case ExportMarkdown:
+		return WriteFailureReport(w, runLogs)
+	default:
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+}
+
+// ExportFile writes logs to a new file in dir and returns its path.
+func ExportFile(dir string, format ExportFormat, runLogs *RunLogs, filtered *FilteredResult) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve export directory: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	path := filepath.Join(dir, ExportFilename(runLogs, format, time.Now()))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	if err := Export(f, format, runLogs, filtered); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to export logs: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to export logs: %w", err)
+	}
+
+	return path, nil
+}
+
+// DefaultExportDir returns the directory exports are written to, next to the log cache
+// rather than in the repository lazydispatch was started from.
+func DefaultExportDir() string {
+	cacheDir, _ := os.UserCacheDir()
+
+	return filepath.Join(cacheDir, "lazydispatch", "exports")
+}
+
+// ExportFilename names an export after the chain, or the run when the logs are of a single run.
+func ExportFilename(runLogs *RunLogs, format ExportFormat, now time.Time) string {
+	name := "logs"
+
+	switch {
+	case runLogs.ChainName != "":
+		name = runLogs.ChainName
+	case len(runLogs.Runs) > 0:
+		name = fmt.Sprintf("run-%d", runLogs.Runs[0].RunID)
+	}
+
+	name = strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "_").Replace(name)
+
+	return fmt.Sprintf("lazydispatch-%s-%s.%s", name, now.Format("20060102-150405"), format.Extension())
+}
+
+// WriteText writes filtered entries as plain text, one section per step.
+func WriteText(w io.Writer, filtered *FilteredResult) error {
+	for i, step := range filtered.Steps {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "== %s ==\n", stepTitle(step.Workflow, step.JobName, step.StepName)); err != nil {
+			return err
+		}
+
+		for _, fe := range step.Entries {
+			entry := fe.Original
+
+			var line strings.Builder
+
+			if !entry.Timestamp.IsZero() {
+				line.WriteString(entry.Timestamp.UTC().Format(time.RFC3339Nano))
+				line.WriteString(" ")
+			}
+
+			fmt.Fprintf(&line, "[%s] %s", entry.Level, strings.Repeat("  ", entry.Depth))
+
+			if loc := entry.Annotation.Location(); loc != "" {
+				line.WriteString(loc + ": ")
+			}
+
+			line.WriteString(entry.Content)
+			line.WriteString("\n")
+
+			if _, err := io.WriteString(w, line.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// exportRecord is one line of an NDJSON export.
+type exportRecord struct {
+	Workflow  string     `json:"workflow,omitempty"`
+	RunID     int64      `json:"run_id,omitempty"`
+	Job       string     `json:"job,omitempty"`
+	Step      string     `json:"step"`
+	StepIndex int        `json:"step_index"`
+	Level     LogLevel   `json:"level"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Content   string     `json:"content"`
+	Group     bool       `json:"group,omitempty"`
+	File      string     `json:"file,omitempty"`
+	Line      int        `json:"line,omitempty"`
+}
+
+// WriteNDJSON writes filtered entries as newline-delimited JSON, one entry per line.
+func WriteNDJSON(w io.Writer, filtered *FilteredResult) error {
+	enc := json.NewEncoder(w)
+
+	for _, step := range filtered.Steps {
+		for _, fe := range step.Entries {
+			entry := fe.Original
+
+			record := exportRecord{
+				Workflow:  step.Workflow,
+				RunID:     step.RunID,
+				Job:       step.JobName,
+				Step:      step.StepName,
+				StepIndex: step.StepIndex,
+				Level:     entry.Level,
+				Content:   entry.Content,
+				Group:     entry.Group,
+			}
+
+			if !entry.Timestamp.IsZero() {
+				ts := entry.Timestamp.UTC()
+				record.Timestamp = &ts
+			}
+
+			if entry.Annotation != nil {
+				record.File = entry.Annotation.File
+				record.Line = entry.Annotation.Line
+			}
+
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteFailureReport writes a Markdown report of the failed steps of each run:
+// the run's URL and inputs, followed by the error lines of each failed step.
+func WriteFailureReport(w io.Writer, runLogs *RunLogs) error {
+	var s strings.Builder
+
+	steps := runLogs.AllSteps()
+	runs := reportRuns(runLogs, steps)
+
+	title := runLogs.ChainName
+	if title == "" && len(runs) == 1 {
+		title = fmt.Sprintf("%s run #%d", runs[0].Workflow, runs[0].RunID)
+	}
+
+	s.WriteString("# Failure report")
+
+	if title != "" {
+		s.WriteString(": " + title)
+	}
+
+	s.WriteString("\n\n")
+
+	if runLogs.Branch != "" {
+		fmt.Fprintf(&s, "Branch: `%s`\n\n", runLogs.Branch)
+	}
+
+	errorFilter, _ := NewFilter(&FilterConfig{Level: FilterErrors, StepIndex: -1})
+	hasFailures := false
+
+	for _, run := range runs {
+		var failed []*StepLogs
+
+		for _, step := range steps {
+			if step.RunID == run.RunID && stepFailed(step) {
+				failed = append(failed, step)
+			}
+		}
+
+		if len(failed) == 0 {
+			continue
+		}
+
+		hasFailures = true
+
+		writeReportRun(&s, run)
+
+		for _, step := range failed {
+			single := NewRunLogs("", "")
+			single.AddStep(step)
+
+			writeReportStep(&s, step, errorFilter.Apply(single))
+		}
+	}
+
+	if !hasFailures {
+		s.WriteString("No failed steps.\n")
+	}
+
+	_, err := io.WriteString(w, s.String())
+
+	return err
+}
+
+// reportRuns returns the recorded runs, followed by runs only known from their steps.
+func reportRuns(runLogs *RunLogs, steps []*StepLogs) []RunInfo {
+	runLogs.mu.RLock()
+	runs := append([]RunInfo(nil), runLogs.Runs...)
+	runLogs.mu.RUnlock()
+
+	seen := make(map[int64]bool, len(runs))
+	for _, run := range runs {
+		seen[run.RunID] = true
+	}
+
+	for _, step := range steps {
+		if !seen[step.RunID] {
+			seen[step.RunID] = true
+			runs = append(runs, RunInfo{RunID: step.RunID, Workflow: step.Workflow})
+		}
+	}
+
+	return runs
+}
+
+// stepFailed reports whether a step belongs in the failure report. Steps
+// without a conclusion, e.g. of runs still in progress, count as failed when
+// they logged errors.
+func stepFailed(step *StepLogs) bool {
+	if step.Error != nil {
+		return true
+	}
+
+	switch step.Conclusion {
+	case github.ConclusionFailure, "timed_out":
+		return true
+	case "":
+		for _, entry := range step.Entries {
+			if entry.Level == LogLevelError {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func writeReportRun(s *strings.Builder, run RunInfo) {
+	fmt.Fprintf(s, "## %s run #%d", run.Workflow, run.RunID)
+
+	if run.Conclusion != "" {
+		fmt.Fprintf(s, " (%s)", run.Conclusion)
+	}
+
+	s.WriteString("\n\n")
+
+	if run.URL != "" {
+		fmt.Fprintf(s, "Run: %s\n\n", run.URL)
+	} else {
+		fmt.Fprintf(s, "Run: `gh run view %d --log`\n\n", run.RunID)
+	}
+
+	if len(run.Inputs) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(run.Inputs))
+	for name := range run.Inputs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	s.WriteString("| Input | Value |\n| --- | --- |\n")
+
+	for _, name := range names {
+		fmt.Fprintf(s, "| %s | %s |\n", markdownCell(name), markdownCell(run.Inputs[name]))
+	}
+
+	s.WriteString("\n")
+}
+
+func writeReportStep(s *strings.Builder, step *StepLogs, errorLines *FilteredResult) {
+	fmt.Fprintf(s, "### ✗ %s", stepTitle("", step.JobName, step.StepName))
+
+	if step.Conclusion != "" {
+		fmt.Fprintf(s, ": %s", step.Conclusion)
+	}
+
+	s.WriteString("\n\n")
+
+	if step.Error != nil {
+		fmt.Fprintf(s, "_Logs unavailable: %v_\n\n", step.Error)
+		return
+	}
+
+	if errorLines.TotalEntries() == 0 {
+		s.WriteString("_No error lines in the log._\n\n")
+		return
+	}
+
+	var lines strings.Builder
+
+	for _, fs := range errorLines.Steps {
+		for _, fe := range fs.Entries {
+			if loc := fe.Original.Annotation.Location(); loc != "" {
+				lines.WriteString(loc + ": ")
+			}
+
+			lines.WriteString(fe.Original.Content)
+			lines.WriteString("\n")
+		}
+	}
+
+	// Use a fence longer than any backtick run in the log
+	fence := "```"
+	for strings.Contains(lines.String(), fence) {
+		fence += "`"
+	}
+
+	s.WriteString(fence + "\n" + lines.String() + fence + "\n\n")
+}
+
+// stepTitle joins the non-empty parts of a step's name.
+func stepTitle(workflow, job, step string) string {
+	var parts []string
+
+	for _, part := range []string{workflow, job, step} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " / ")
+}
+
+// markdownCell formats a value as code in a Markdown table cell.
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\n", " ")
+
+	if value == "" {
+		return "_(empty)_"
+	}
+
+	// Use a fence longer than any backtick run in the value, padded so a
+	// leading or trailing backtick is not read as part of the fence
+	fence := "`"
+	for strings.Contains(value, fence) {
+		fence += "`"
+	}
+
+	if strings.HasPrefix(value, "`") || strings.HasSuffix(value, "`") {
+		value = " " + value + " "
+	}
+
+	return fence + value + fence
+}
@@ -0,0 +1,241 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+func testExportLogs() *RunLogs {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	runLogs := NewRunLogs("deploy", "main")
+	runLogs.AddRun(RunInfo{
+		RunID:      101,
+		Workflow:   "build.yml",
+		URL:        "https://github.com/owner/repo/actions/runs/101",
+		Conclusion: github.ConclusionSuccess,
+		Inputs:     map[string]string{"version": "1.2.3"},
+	})
+	runLogs.AddRun(RunInfo{
+		RunID:      102,
+		Workflow:   "deploy.yml",
+		URL:        "https://github.com/owner/repo/actions/runs/102",
+		Conclusion: github.ConclusionFailure,
+		Inputs:     map[string]string{"env": "prod", "note": "a|b"},
+	})
+
+	runLogs.AddStep(&StepLogs{
+		StepIndex: 0, Workflow: "build.yml", RunID: 101, JobName: "build", StepName: "Compile",
+		Conclusion: github.ConclusionSuccess,
+		Entries: []LogEntry{
+			{Timestamp: ts, Content: "compiling", Level: LogLevelInfo},
+			{Timestamp: ts, Content: "retrying flaky download", Level: LogLevelError},
+		},
+	})
+	runLogs.AddStep(&StepLogs{
+		StepIndex: 1, Workflow: "deploy.yml", RunID: 102, JobName: "deploy", StepName: "Apply",
+		Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{
+			{Timestamp: ts.Add(time.Second), Content: "applying", Level: LogLevelInfo},
+			{Content: "missing permission", Level: LogLevelError, Annotation: &Annotation{File: "deploy.tf", Line: 7}},
+			{Timestamp: ts.Add(2 * time.Second), Content: "Process completed with exit code 1.", Level: LogLevelError},
+		},
+	})
+	runLogs.AddStep(&StepLogs{
+		StepIndex: 1, Workflow: "deploy.yml", RunID: 102, JobName: "deploy", StepName: "Notify",
+		Conclusion: github.ConclusionFailure,
+		Error:      errors.New("log not found"),
+	})
+
+	return runLogs
+}
+
+func applyFilter(t *testing.T, runLogs *RunLogs, cfg *FilterConfig) *FilteredResult {
+	t.Helper()
+
+	filter, err := NewFilter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filter.Apply(runLogs)
+}
+
+func TestWriteText(t *testing.T) {
+	runLogs := testExportLogs()
+	filtered := applyFilter(t, runLogs, &FilterConfig{Level: FilterErrors, StepIndex: 1})
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, filtered); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "== deploy.yml / deploy / Apply ==\n" +
+		"[error] deploy.tf:7: missing permission\n" +
+		"2024-01-01T12:00:02Z [error] Process completed with exit code 1.\n"
+
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	runLogs := testExportLogs()
+	filtered := applyFilter(t, runLogs, &FilterConfig{Level: FilterAll, SearchTerm: "appl", StepIndex: -1})
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, filtered); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %q", len(lines), buf.String())
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"workflow":   "deploy.yml",
+		"run_id":     float64(102),
+		"job":        "deploy",
+		"step":       "Apply",
+		"step_index": float64(1),
+		"level":      "info",
+		"timestamp":  "2024-01-01T12:00:01Z",
+		"content":    "applying",
+	}
+
+	if len(record) != len(want) {
+		t.Errorf("got fields %v, want %v", record, want)
+	}
+
+	for k, v := range want {
+		if record[k] != v {
+			t.Errorf("%s: got %v, want %v", k, record[k], v)
+		}
+	}
+}
+
+func TestWriteFailureReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFailureReport(&buf, testExportLogs()); err != nil {
+		t.Fatal(err)
+	}
+
+	report := buf.String()
+
+	for _, want := range []string{
+		"# Failure report: deploy\n",
+		"Branch: `main`",
+		"## deploy.yml run #102 (failure)\n\nRun: https://github.com/owner/repo/actions/runs/102\n",
+		"| `env` | `prod` |\n| `note` | `a\\|b` |\n",
+		"### ✗ deploy / Apply: failure\n\n```\ndeploy.tf:7: missing permission\nProcess completed with exit code 1.\n```\n",
+		"### ✗ deploy / Notify: failure\n\n_Logs unavailable: log not found_\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	// Successful runs and info lines are left out, even when they logged errors
+	for _, unwanted := range []string{"build.yml", "retrying flaky download", "applying"} {
+		if strings.Contains(report, unwanted) {
+			t.Errorf("report should not contain %q:\n%s", unwanted, report)
+		}
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"prod", "`prod`"},
+		{"", "_(empty)_"},
+		{"a|b", "`a\\|b`"},
+		{"line\nbreak", "`line break`"},
+		{"run `make test`", "`` run `make test` ``"},
+		{"``quoted``", "``` ``quoted`` ```"},
+	}
+
+	for _, tt := range tests {
+		if got := markdownCell(tt.value); got != tt.want {
+			t.Errorf("markdownCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFailureReport_NoFailures(t *testing.T) {
+	runLogs := NewRunLogs("", "")
+	runLogs.AddRun(RunInfo{RunID: 7, Workflow: "ci.yml"})
+	runLogs.AddStep(&StepLogs{RunID: 7, Workflow: "ci.yml", StepName: "Test", Conclusion: github.ConclusionSuccess})
+
+	var buf bytes.Buffer
+	if err := WriteFailureReport(&buf, runLogs); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "# Failure report: ci.yml run #7\n\nNo failed steps.\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExportFile(t *testing.T) {
+	dir := t.TempDir()
+	runLogs := testExportLogs()
+
+	path, err := ExportFile(dir, ExportMarkdown, runLogs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "lazydispatch-deploy-") || filepath.Ext(path) != ".md" {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(data), "# Failure report") {
+		t.Errorf("unexpected content:\n%s", data)
+	}
+
+	// The export directory is created when missing
+	nested, err := ExportFile(filepath.Join(dir, "exports"), ExportText, runLogs, &FilteredResult{})
+	if err != nil || filepath.Dir(nested) != filepath.Join(dir, "exports") {
+		t.Errorf("export to a missing directory: got %s, %v", nested, err)
+	}
+
+	if _, err := ExportFile(filepath.Join(path, "exports"), ExportText, runLogs, &FilteredResult{}); err == nil {
+		t.Error("expected an error for a directory below a file")
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	now := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	runLogs := NewRunLogs("", "")
+	runLogs.AddRun(RunInfo{RunID: 42})
+
+	if got, want := ExportFilename(runLogs, ExportNDJSON, now), "lazydispatch-run-42-20240304-050607.ndjson"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if got, want := ExportFilename(NewRunLogs("release/prod", ""), ExportText, now), "lazydispatch-release_prod-20240304-050607.txt"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
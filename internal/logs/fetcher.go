@@ -1,9 +1,7 @@
 package logs
 
 import (
-	"bufio"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
@@ -103,67 +101,6 @@ func (f *Fetcher) generateSyntheticLogs(jobName string, step github.Step) []LogE
 	return entries
 }
 
-// ParseLogOutput parses raw log text into LogEntry structs.
-// Detects log levels based on common patterns.
-func ParseLogOutput(rawLogs string, stepName string) []LogEntry {
-	var entries []LogEntry
-
-	scanner := bufio.NewScanner(strings.NewReader(rawLogs))
-
-	errorPatterns := []*regexp.Regexp{
-		regexp.MustCompile(`(?i)\berror\b`),
-		regexp.MustCompile(`(?i)\bfailed\b`),
-		regexp.MustCompile(`(?i)\bfailure\b`),
-		regexp.MustCompile(`(?i)✗`),
-	}
-
-	warningPatterns := []*regexp.Regexp{
-		regexp.MustCompile(`(?i)\bwarning\b`),
-		regexp.MustCompile(`(?i)\bwarn\b`),
-		regexp.MustCompile(`(?i)⚠`),
-	}
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
-		level := detectLogLevel(line, errorPatterns, warningPatterns)
-
-		entries = append(entries, LogEntry{
-			Timestamp: time.Now(), // Would extract from log line if available
-			Content:   line,
-			Level:     level,
-			StepName:  stepName,
-		})
-	}
-
-	return entries
-}
-
-// detectLogLevel determines the log level based on content.
-func detectLogLevel(line string, errorPatterns, warningPatterns []*regexp.Regexp) LogLevel {
-	for _, pattern := range errorPatterns {
-		if pattern.MatchString(line) {
-			return LogLevelError
-		}
-	}
-
-	for _, pattern := range warningPatterns {
-		if pattern.MatchString(line) {
-			return LogLevelWarning
-		}
-	}
-
-	// Check for debug indicators
-	if strings.Contains(strings.ToLower(line), "debug") {
-		return LogLevelDebug
-	}
-
-	return LogLevelInfo
-}
-
 // FetchRunSummary creates a summary of failed steps without full logs.
 func (f *Fetcher) FetchRunSummary(runID int64) (string, error) {
 	jobs, err := f.client.GetWorkflowRunJobs(runID)
@@ -47,11 +47,18 @@ func (f *Filter) Apply(runLogs *RunLogs) *FilteredResult {
 			continue
 		}
 
+		if name := f.config.StepName; name != "" && !strings.EqualFold(step.StepName, name) && !strings.EqualFold(step.Workflow, name) {
+			continue
+		}
+
 		filteredStep := &FilteredStepLogs{
-			StepIndex: step.StepIndex,
-			Workflow:  step.Workflow,
-			StepName:  step.StepName,
-			Entries:   make([]FilteredLogEntry, 0),
+			StepIndex:  step.StepIndex,
+			Workflow:   step.Workflow,
+			RunID:      step.RunID,
+			JobName:    step.JobName,
+			StepName:   step.StepName,
+			Conclusion: step.Conclusion,
+			Entries:    make([]FilteredLogEntry, 0),
 		}
 
 		for i, entry := range step.Entries {
@@ -178,10 +185,13 @@ func (fr *FilteredResult) TotalEntries() int {
 
 // FilteredStepLogs contains filtered logs for a single step.
 type FilteredStepLogs struct {
-	StepIndex int
-	Workflow  string
-	StepName  string
-	Entries   []FilteredLogEntry
+	StepIndex  int
+	Workflow   string
+	RunID      int64
+	JobName    string
+	StepName   string
+	Conclusion string
+	Entries    []FilteredLogEntry
 }
 
 // FilteredLogEntry wraps a log entry with match information.
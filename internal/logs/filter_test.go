@@ -261,6 +261,36 @@ func TestFilter_StepIndexFilter(t *testing.T) {
 	}
 }
 
+func TestFilter_StepNameFilter(t *testing.T) {
+	runLogs := NewRunLogs("test", "main")
+	runLogs.AddStep(&StepLogs{StepIndex: 0, Workflow: "ci.yml", StepName: "Run tests", Entries: []LogEntry{{Content: "ok"}}})
+	runLogs.AddStep(&StepLogs{StepIndex: 1, Workflow: "ci.yml", StepName: "Lint", Entries: []LogEntry{{Content: "ok"}}})
+	runLogs.AddStep(&StepLogs{StepIndex: 2, Workflow: "deploy.yml", StepName: "Apply", Entries: []LogEntry{{Content: "ok"}}})
+
+	tests := []struct {
+		stepName      string
+		expectedSteps int
+	}{
+		{"", 3},
+		{"run TESTS", 1},
+		{"ci.yml", 2},
+		{"missing", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stepName, func(t *testing.T) {
+			filter, err := NewFilter(&FilterConfig{Level: FilterAll, StepIndex: -1, StepName: tt.stepName})
+			if err != nil {
+				t.Fatalf("NewFilter failed: %v", err)
+			}
+
+			if got := len(filter.Apply(runLogs).Steps); got != tt.expectedSteps {
+				t.Errorf("expected %d steps, got %d", tt.expectedSteps, got)
+			}
+		})
+	}
+}
+
 func TestFilter_FindMatches(t *testing.T) {
 	tests := []struct {
 		name          string
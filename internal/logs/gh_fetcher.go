@@ -1,10 +1,8 @@
 package logs
 
 import (
-	"bufio"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/kyleking/gh-lazydispatch/internal/exec"
@@ -18,13 +16,29 @@ type GHFetcher struct {
 }
 
 // NewGHFetcher creates a fetcher that uses gh CLI for real log access.
+// gh runs through the client's executor when it has one, like github.Client.
 func NewGHFetcher(client GitHubClient) *GHFetcher {
 	return &GHFetcher{
 		client:   client,
-		executor: exec.NewRealExecutor(),
+		executor: clientExecutor(client),
 	}
 }
 
+// executorProvider is implemented by clients that run gh through an executor, like github.Client.
+type executorProvider interface {
+	Executor() exec.CommandExecutor
+}
+
+// clientExecutor returns the executor of client, so logs are read from the same
+// place as runs, e.g. a fake server. Other clients use the real gh CLI.
+func clientExecutor(client GitHubClient) exec.CommandExecutor {
+	if provider, ok := client.(executorProvider); ok && provider.Executor() != nil {
+		return provider.Executor()
+	}
+
+	return exec.NewRealExecutor()
+}
+
 // NewGHFetcherWithExecutor creates a fetcher with a custom executor (for testing).
 func NewGHFetcherWithExecutor(client GitHubClient, executor exec.CommandExecutor) *GHFetcher {
 	return &GHFetcher{
@@ -71,7 +85,7 @@ func (f *GHFetcher) FetchStepLogsReal(runID int64, workflow string) ([]*StepLogs
 		// Parse logs into steps
 		stepLogs := f.parseJobLogsIntoSteps(job, jobLogs, workflow, runID, stepIndex)
 		allStepLogs = append(allStepLogs, stepLogs...)
-		stepIndex += len(stepLogs)
+		stepIndex += len(job.Steps)
 	}
 
 	return allStepLogs, nil
@@ -101,67 +115,29 @@ func (f *GHFetcher) parseJobLogsIntoSteps(
 	runID int64,
 	startIndex int,
 ) []*StepLogs {
-	// GitHub logs format:
-	// ##[group]Run actions/checkout@v4
-	// ... log lines ...
-	// ##[endgroup]
-	//
-	// ##[group]Install dependencies
-	// ... log lines ...
-	// ##[endgroup]
-	var stepLogs []*StepLogs
-
-	scanner := bufio.NewScanner(strings.NewReader(rawLogs))
-
-	currentStepIdx := -1
-
-	var currentLines []string
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Detect step boundaries
-		if strings.HasPrefix(line, "##[group]") {
-			// Save previous step if any
-			if currentStepIdx >= 0 && currentStepIdx < len(job.Steps) {
-				step := job.Steps[currentStepIdx]
-				stepLogs = append(stepLogs, &StepLogs{
-					StepIndex:  startIndex + currentStepIdx,
-					Workflow:   workflow,
-					RunID:      runID,
-					JobName:    job.Name,
-					StepName:   step.Name,
-					Status:     step.Status,
-					Conclusion: step.Conclusion,
-					Entries:    ParseLogOutput(strings.Join(currentLines, "\n"), step.Name),
-					FetchedAt:  time.Now(),
-				})
-			}
+	segments := splitJobEntries(job, ParseLogOutput(rawLogs, ""))
 
-			// Start new step
-			currentStepIdx++
-			currentLines = make([]string, 0)
-			currentLines = append(currentLines, line)
-		} else if strings.HasPrefix(line, "##[endgroup]") {
-			currentLines = append(currentLines, line)
-		} else {
-			// Regular log line
-			currentLines = append(currentLines, line)
+	stepLogs := make([]*StepLogs, 0, len(segments))
+
+	for i, entries := range segments {
+		if entries == nil {
+			continue
+		}
+
+		step := job.Steps[i]
+		for j := range entries {
+			entries[j].StepName = step.Name
 		}
-	}
 
-	// Save last step
-	if currentStepIdx >= 0 && currentStepIdx < len(job.Steps) {
-		step := job.Steps[currentStepIdx]
 		stepLogs = append(stepLogs, &StepLogs{
-			StepIndex:  startIndex + currentStepIdx,
+			StepIndex:  startIndex + i,
 			Workflow:   workflow,
 			RunID:      runID,
 			JobName:    job.Name,
 			StepName:   step.Name,
 			Status:     step.Status,
 			Conclusion: step.Conclusion,
-			Entries:    ParseLogOutput(strings.Join(currentLines, "\n"), step.Name),
+			Entries:    entries,
 			FetchedAt:  time.Now(),
 		})
 	}
@@ -169,6 +145,64 @@ func (f *GHFetcher) parseJobLogsIntoSteps(
 	return stepLogs
 }
 
+// splitJobEntries assigns a job's log entries to its steps, indexed like job.Steps.
+// See stepTracker for how lines are attributed.
+func splitJobEntries(job github.Job, entries []LogEntry) [][]LogEntry {
+	segments := make([][]LogEntry, len(job.Steps))
+	tracker := newStepTracker(job)
+
+	for _, entry := range entries {
+		if i := tracker.next(entry); i >= 0 && i < len(segments) {
+			segments[i] = append(segments[i], entry)
+		}
+	}
+
+	return segments
+}
+
+// stepTracker attributes consecutive log entries of a job to its steps.
+// Output of `gh run view --log` names each line's step; otherwise every top-level
+// group starts the next step, as in:
+//
+//	##[group]Run actions/checkout@v4
+//	... log lines, possibly with nested groups ...
+//	##[endgroup]
+type stepTracker struct {
+	stepByName map[string]int
+	current    int
+	lastName   string
+}
+
+func newStepTracker(job github.Job) *stepTracker {
+	stepByName := make(map[string]int, len(job.Steps))
+	for i, step := range job.Steps {
+		stepByName[step.Name] = i
+	}
+
+	return &stepTracker{stepByName: stepByName, current: -1}
+}
+
+// next returns the index in job.Steps of the step entry belongs to, or -1
+// for lines before the first step.
+func (t *stepTracker) next(entry LogEntry) int {
+	switch {
+	case entry.StepName != "":
+		if entry.StepName != t.lastName {
+			t.lastName = entry.StepName
+
+			if i, ok := t.stepByName[entry.StepName]; ok {
+				t.current = i
+			} else {
+				t.current++
+			}
+		}
+	case entry.Group && entry.Depth == 0:
+		t.current++
+	}
+
+	return t.current
+}
+
 // FetchWorkflowLogs fetches all logs for a workflow run (all jobs).
 func (f *GHFetcher) FetchWorkflowLogs(runID int64) (string, error) {
 	// Use gh CLI to view all logs
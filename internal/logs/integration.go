@@ -2,8 +2,10 @@ package logs
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
 )
 
 // LogFetcher defines the interface for fetching logs.
@@ -14,10 +16,18 @@ type LogFetcher interface {
 // Manager coordinates log fetching, caching, and access.
 type Manager struct {
 	fetcher    LogFetcher
+	client     GitHubClient
+	repo       string
 	cache      *Cache
 	useRealAPI bool
 }
 
+// repoNamer is implemented by clients that know their repository, like github.Client.
+type repoNamer interface {
+	Owner() string
+	Repo() string
+}
+
 // NewManager creates a new log manager that uses gh CLI if available.
 func NewManager(client GitHubClient, cacheDir string) *Manager {
 	var fetcher LogFetcher
@@ -25,20 +35,52 @@ func NewManager(client GitHubClient, cacheDir string) *Manager {
 	useRealAPI := false
 
 	// Try to use GHFetcher if gh CLI is available
-	if err := CheckGHCLIAvailable(); err == nil {
+	if err := CheckGHCLIAvailableWithExecutor(clientExecutor(client)); err == nil {
 		ghFetcher := NewGHFetcher(client)
 		fetcher = &ghFetcherAdapter{ghFetcher: ghFetcher}
 		useRealAPI = true
+
+		// Prefer the run log archive, falling back to per-job logs while the run
+		// is still in progress and the archive is not available yet
+		if downloader, ok := client.(RunLogsDownloader); ok {
+			fetcher = &fallbackFetcher{
+				primary:  NewArchiveFetcher(client, downloader),
+				fallback: fetcher,
+			}
+		}
 	} else {
 		// Fall back to synthetic logs
 		fetcher = NewFetcher(client)
 	}
 
-	return &Manager{
-		fetcher:    fetcher,
-		cache:      NewCache(cacheDir),
-		useRealAPI: useRealAPI,
+	m := newManager(client, fetcher, cacheDir)
+	m.useRealAPI = useRealAPI
+
+	return m
+}
+
+func newManager(client GitHubClient, fetcher LogFetcher, cacheDir string) *Manager {
+	m := &Manager{
+		fetcher: fetcher,
+		client:  client,
+		cache:   NewCache(cacheDir),
+	}
+
+	if named, ok := client.(repoNamer); ok {
+		m.repo = named.Owner() + "/" + named.Repo()
 	}
+
+	return m
+}
+
+// ForClient returns a manager for another repository's client that shares this
+// manager's cache, so managers of several repositories do not overwrite each
+// other's cache index.
+func (m *Manager) ForClient(client GitHubClient) *Manager {
+	other := NewManager(client, m.cache.cacheDir)
+	other.cache = m.cache
+
+	return other
 }
 
 // ghFetcherAdapter adapts GHFetcher to LogFetcher interface.
@@ -55,13 +97,41 @@ func (a *ghFetcherAdapter) FetchStepLogs(runID int64, workflow string) ([]*StepL
 	return logs, nil
 }
 
+// fallbackFetcher uses fallback when primary fails.
+type fallbackFetcher struct {
+	primary  LogFetcher
+	fallback LogFetcher
+}
+
+func (f *fallbackFetcher) FetchStepLogs(runID int64, workflow string) ([]*StepLogs, error) {
+	stepLogs, err := f.primary.FetchStepLogs(runID, workflow)
+	if err == nil {
+		return stepLogs, nil
+	}
+
+	return f.fallback.FetchStepLogs(runID, workflow)
+}
+
 // GetLogsForChain fetches or retrieves cached logs for a chain execution.
 func (m *Manager) GetLogsForChain(chainState chain.ChainState, branch string) (*RunLogs, error) {
 	runLogs := NewRunLogs(chainState.ChainName, branch)
 
-	// Fetch logs for each completed step
+	// Fetch logs for each completed step. Steps skipped without a run, e.g. by their
+	// if: expression, have nothing to fetch.
 	for idx, result := range chainState.StepResults {
-		stepLogs, err := m.fetcher.FetchStepLogs(result.RunID, result.Workflow)
+		if result == nil || result.RunID == 0 {
+			continue
+		}
+
+		runLogs.AddRun(RunInfo{
+			RunID:      result.RunID,
+			Workflow:   result.Workflow,
+			URL:        result.RunURL,
+			Conclusion: result.Conclusion,
+			Inputs:     result.Inputs,
+		})
+
+		stepLogs, _, err := m.fetchRun(result.RunID, result.Workflow)
 		if err != nil {
 			// Store error but continue with other steps
 			runLogs.AddStep(&StepLogs{
@@ -84,15 +154,24 @@ func (m *Manager) GetLogsForChain(chainState chain.ChainState, branch string) (*
 	return runLogs, nil
 }
 
-// GetLogsForRun fetches logs for a single workflow run.
-func (m *Manager) GetLogsForRun(runID int64, workflow string) (*RunLogs, error) {
+// GetLogsForRun fetches logs for a single workflow run. inputs are the run's dispatched
+// inputs, shown in failure reports; nil when unknown.
+func (m *Manager) GetLogsForRun(runID int64, workflow string, inputs map[string]string) (*RunLogs, error) {
 	runLogs := NewRunLogs("", "")
 
-	stepLogs, err := m.fetcher.FetchStepLogs(runID, workflow)
+	stepLogs, run, err := m.fetchRun(runID, workflow)
 	if err != nil {
 		return nil, err
 	}
 
+	info := RunInfo{RunID: runID, Workflow: workflow, Inputs: inputs}
+	if run != nil {
+		info.URL = run.HTMLURL
+		info.Conclusion = run.Conclusion
+	}
+
+	runLogs.AddRun(info)
+
 	for _, sl := range stepLogs {
 		runLogs.AddStep(sl)
 	}
@@ -100,12 +179,67 @@ func (m *Manager) GetLogsForRun(runID int64, workflow string) (*RunLogs, error)
 	return runLogs, nil
 }
 
-// LoadCache loads the log cache from disk.
+// fetchRun returns the logs of a run's current attempt. Logs of completed
+// attempts come from the cache when present and are cached otherwise. When the
+// run cannot be looked up, e.g. while offline, the latest cached attempt is used
+// and the returned run is nil.
+func (m *Manager) fetchRun(runID int64, workflow string) ([]*StepLogs, *github.WorkflowRun, error) {
+	run, err := m.client.GetWorkflowRun(runID)
+	if err != nil {
+		if cached, ok := m.cache.Latest(m.repo, runID); ok {
+			return cached, nil, nil
+		}
+
+		stepLogs, err := m.fetcher.FetchStepLogs(runID, workflow)
+
+		return stepLogs, nil, err
+	}
+
+	key := CacheKey{Repo: m.repo, RunID: runID, Attempt: run.RunAttempt}
+	completed := run.Status == github.StatusCompleted
+
+	if completed {
+		if cached, ok := m.cache.Get(key); ok {
+			return cached, run, nil
+		}
+	}
+
+	stepLogs, err := m.fetcher.FetchStepLogs(runID, workflow)
+	if err != nil {
+		return nil, run, err
+	}
+
+	if completed && fetchedCompletely(stepLogs) {
+		if err := m.cache.Put(key, stepLogs); err != nil {
+			log.Printf("warning: failed to cache logs for run %d: %v", runID, err)
+		}
+	}
+
+	return stepLogs, run, nil
+}
+
+// fetchedCompletely reports whether no step failed to fetch, so the logs can be cached.
+func fetchedCompletely(stepLogs []*StepLogs) bool {
+	for _, sl := range stepLogs {
+		if sl.Error != nil {
+			return false
+		}
+	}
+
+	return len(stepLogs) > 0
+}
+
+// LoadCache loads the log cache index from disk.
 func (m *Manager) LoadCache() error {
 	return m.cache.Load()
 }
 
-// ClearExpired removes expired entries from the cache.
-func (m *Manager) ClearExpired() error {
-	return m.cache.Clear()
+// CloseCache saves the log cache index, recording which logs were read last.
+func (m *Manager) CloseCache() error {
+	return m.cache.Close()
+}
+
+// SetCacheMaxSizeMB changes the size cap of the log cache.
+func (m *Manager) SetCacheMaxSizeMB(mb int) error {
+	return m.cache.SetMaxSizeMB(mb)
 }
@@ -83,7 +83,7 @@ func TestIntegration_SuccessfulWorkflowRun(t *testing.T) {
 	foundCheckout := false
 
 	for _, entry := range stepLogs[0].Entries {
-		if entry.Content == "##[group]Run actions/checkout@v4" {
+		if entry.Group && entry.Content == "Run actions/checkout@v4" {
 			foundCheckout = true
 			break
 		}
@@ -0,0 +1,538 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Annotation is the source location attached to an `::error file=...,line=...::` workflow command.
+type Annotation struct {
+	File      string
+	Line      int
+	EndLine   int
+	Column    int
+	EndColumn int
+	Title     string
+}
+
+// Location formats the annotation as file:line[:col], or "" when it names no file.
+func (a *Annotation) Location() string {
+	if a == nil || a.File == "" {
+		return ""
+	}
+
+	loc := a.File
+	if a.Line > 0 {
+		loc += ":" + strconv.Itoa(a.Line)
+
+		if a.Column > 0 {
+			loc += ":" + strconv.Itoa(a.Column)
+		}
+	}
+
+	return loc
+}
+
+// Span is a run of log text sharing one ANSI style.
+type Span struct {
+	Text  string
+	Style SpanStyle
+}
+
+// SpanStyle is the SGR state of a span. Colors use lipgloss notation: an ANSI
+// index ("1", "208") or a hex value ("#ff8800"); empty means the default color.
+type SpanStyle struct {
+	Foreground string
+	Background string
+	Bold       bool
+	Faint      bool
+	Italic     bool
+	Underline  bool
+	Reverse    bool
+}
+
+// IsZero reports whether the style is the terminal default.
+func (s SpanStyle) IsZero() bool {
+	return s == SpanStyle{}
+}
+
+// logTimestampPattern matches the ISO 8601 prefix GitHub puts on every log line.
+var logTimestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z `)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+var (
+	errorPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\berror\b`),
+		regexp.MustCompile(`(?i)\bfailed\b`),
+		regexp.MustCompile(`(?i)\bfailure\b`),
+		regexp.MustCompile(`(?i)✗`),
+	}
+
+	warningPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bwarning\b`),
+		regexp.MustCompile(`(?i)\bwarn\b`),
+		regexp.MustCompile(`(?i)⚠`),
+	}
+)
+
+// LevelPatterns are regular expressions that classify unmarked log lines,
+// checked before the built-in wording rules: error, then warning, then debug.
+type LevelPatterns struct {
+	Error   []string
+	Warning []string
+	Debug   []string
+}
+
+// compiledLevelPatterns holds the patterns set with SetLevelPatterns.
+type compiledLevelPatterns struct {
+	error, warning, debug []*regexp.Regexp
+	fingerprint           string
+}
+
+var customLevelPatterns atomic.Pointer[compiledLevelPatterns]
+
+// SetLevelPatterns sets the custom level patterns used for logs parsed from now
+// on. Invalid patterns are rejected and leave the current ones in place.
+func SetLevelPatterns(patterns LevelPatterns) error {
+	var compiled compiledLevelPatterns
+
+	for _, set := range []struct {
+		name     string
+		patterns []string
+		into     *[]*regexp.Regexp
+	}{
+		{"error", patterns.Error, &compiled.error},
+		{"warning", patterns.Warning, &compiled.warning},
+		{"debug", patterns.Debug, &compiled.debug},
+	} {
+		for _, pattern := range set.patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", set.name, pattern, err)
+			}
+
+			*set.into = append(*set.into, re)
+		}
+	}
+
+	if len(compiled.error)+len(compiled.warning)+len(compiled.debug) == 0 {
+		customLevelPatterns.Store(nil)
+		return nil
+	}
+
+	compiled.fingerprint = fmt.Sprintf("%q", [][]string{patterns.Error, patterns.Warning, patterns.Debug})
+	customLevelPatterns.Store(&compiled)
+
+	return nil
+}
+
+// levelPatternsFingerprint identifies the custom level patterns in effect, so
+// logs parsed with other patterns are not served from the cache.
+func levelPatternsFingerprint() string {
+	if custom := customLevelPatterns.Load(); custom != nil {
+		return custom.fingerprint
+	}
+
+	return ""
+}
+
+// markerLevels maps `##[name]` log markers and `::name` workflow commands to levels.
+var markerLevels = map[string]LogLevel{
+	"error":   LogLevelError,
+	"warning": LogLevelWarning,
+	"notice":  LogLevelInfo,
+	"debug":   LogLevelDebug,
+}
+
+// Parser parses GitHub Actions log output line by line. It keeps group nesting
+// and the last timestamp between calls, so streamed chunks can be fed in order.
+type Parser struct {
+	stepName string
+	depth    int
+	lastTime time.Time
+}
+
+// NewParser creates a parser that attributes lines without a step column to stepName.
+func NewParser(stepName string) *Parser {
+	return &Parser{stepName: stepName}
+}
+
+// ParseLogOutput parses raw log text into LogEntry structs.
+// See Parser.ParseLine for the recognized format.
+func ParseLogOutput(rawLogs string, stepName string) []LogEntry {
+	entries, _ := ParseLogReader(strings.NewReader(rawLogs), stepName)
+
+	return entries
+}
+
+// ParseLogReader parses log lines as they are read from r. On a read error it
+// returns the entries parsed so far along with the error.
+func ParseLogReader(r io.Reader, stepName string) ([]LogEntry, error) {
+	var entries []LogEntry
+
+	p := NewParser(stepName)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		if entry, ok := p.ParseLine(scanner.Text()); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// ParseLine parses one log line. It accepts the `gh run view --log` format
+// (`job<TAB>step<TAB>timestamp message`), the per-step files of the REST log
+// archive (`timestamp message`), and bare messages. ok is false for lines that
+// produce no entry: blank lines and group ends.
+//
+// ##[group] and ::group:: lines become entries with Group set whose Content is
+// the title; the lines up to the matching end are one Depth deeper. ##[error],
+// ::error and the other annotation markers set the level and are stripped from
+// Content, with any file= and line= properties kept in Annotation. ANSI color
+// codes are removed from Content and preserved as Spans.
+func (p *Parser) ParseLine(line string) (LogEntry, bool) {
+	entry := LogEntry{StepName: p.stepName}
+
+	line = strings.TrimSuffix(line, "\r")
+
+	if job, step, rest, ok := splitStepColumns(line); ok {
+		entry.JobName = job
+		entry.StepName = step
+		line = rest
+	}
+
+	line = strings.TrimPrefix(line, "\ufeff")
+
+	if loc := logTimestampPattern.FindStringIndex(line); loc != nil {
+		if ts, err := time.Parse(time.RFC3339Nano, line[:loc[1]-1]); err == nil {
+			p.lastTime = ts
+		}
+
+		line = line[loc[1]:]
+	}
+
+	entry.Timestamp = p.lastTime
+
+	text, spans := parseANSI(line)
+	if strings.TrimSpace(text) == "" {
+		return LogEntry{}, false
+	}
+
+	entry.Depth = p.depth
+
+	switch {
+	case text == "##[endgroup]" || text == "::endgroup::":
+		p.depth = max(p.depth-1, 0)
+		return LogEntry{}, false
+	case strings.HasPrefix(text, "##[group]"):
+		entry.Group = true
+		text = strings.TrimPrefix(text, "##[group]")
+	case strings.HasPrefix(text, "::group::"):
+		entry.Group = true
+		text = strings.TrimPrefix(text, "::group::")
+	}
+
+	if entry.Group {
+		p.depth++
+		entry.Content = text
+		entry.Level = LogLevelInfo
+
+		return entry, true
+	}
+
+	if level, message, ok := parseMarker(text); ok {
+		entry.Level = level
+		entry.Content = message
+	} else if level, message, annotation, ok := parseWorkflowCommand(text); ok {
+		entry.Level = level
+		entry.Content = message
+		entry.Annotation = annotation
+	} else {
+		entry.Content = text
+		entry.Spans = spans
+		entry.Level = detectLogLevel(text)
+	}
+
+	return entry, true
+}
+
+// splitStepColumns splits the job and step columns of `gh run view --log` output.
+func splitStepColumns(line string) (job, step, rest string, ok bool) {
+	job, rest, found := strings.Cut(line, "\t")
+	if !found {
+		return "", "", line, false
+	}
+
+	step, rest, found = strings.Cut(rest, "\t")
+	if !found || !logTimestampPattern.MatchString(strings.TrimPrefix(rest, "\ufeff")) {
+		return "", "", line, false
+	}
+
+	return job, step, rest, true
+}
+
+// parseMarker handles `##[error]message` style markers from rendered logs.
+func parseMarker(text string) (LogLevel, string, bool) {
+	if !strings.HasPrefix(text, "##[") {
+		return "", "", false
+	}
+
+	name, message, found := strings.Cut(text[3:], "]")
+	if !found {
+		return "", "", false
+	}
+
+	level, ok := markerLevels[name]
+
+	return level, message, ok
+}
+
+// parseWorkflowCommand handles `::error file=a.go,line=3,title=T::message` commands.
+func parseWorkflowCommand(text string) (LogLevel, string, *Annotation, bool) {
+	if !strings.HasPrefix(text, "::") {
+		return "", "", nil, false
+	}
+
+	command, message, found := strings.Cut(text[2:], "::")
+	if !found {
+		return "", "", nil, false
+	}
+
+	name, props, _ := strings.Cut(command, " ")
+
+	level, ok := markerLevels[name]
+	if !ok {
+		return "", "", nil, false
+	}
+
+	var annotation *Annotation
+
+	for _, prop := range strings.Split(props, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(prop), "=")
+		if !found {
+			continue
+		}
+
+		if annotation == nil {
+			annotation = &Annotation{}
+		}
+
+		value = unescapeProperty(value)
+
+		switch key {
+		case "file":
+			annotation.File = value
+		case "line":
+			annotation.Line, _ = strconv.Atoi(value)
+		case "endLine":
+			annotation.EndLine, _ = strconv.Atoi(value)
+		case "col":
+			annotation.Column, _ = strconv.Atoi(value)
+		case "endColumn":
+			annotation.EndColumn, _ = strconv.Atoi(value)
+		case "title":
+			annotation.Title = value
+		}
+	}
+
+	return level, unescapeData(message), annotation, true
+}
+
+var (
+	dataUnescaper     = strings.NewReplacer("%0D", "\r", "%0A", "\n", "%25", "%")
+	propertyUnescaper = strings.NewReplacer("%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",", "%25", "%")
+)
+
+// unescapeData reverses the escaping the Actions toolkit applies to command messages.
+func unescapeData(s string) string {
+	return dataUnescaper.Replace(s)
+}
+
+// unescapeProperty reverses the escaping the Actions toolkit applies to command properties.
+func unescapeProperty(s string) string {
+	return propertyUnescaper.Replace(s)
+}
+
+// detectLogLevel guesses the level of an unmarked line from its wording.
+func detectLogLevel(line string) LogLevel {
+	if custom := customLevelPatterns.Load(); custom != nil {
+		for _, set := range []struct {
+			patterns []*regexp.Regexp
+			level    LogLevel
+		}{
+			{custom.error, LogLevelError},
+			{custom.warning, LogLevelWarning},
+			{custom.debug, LogLevelDebug},
+		} {
+			for _, pattern := range set.patterns {
+				if pattern.MatchString(line) {
+					return set.level
+				}
+			}
+		}
+	}
+
+	for _, pattern := range errorPatterns {
+		if pattern.MatchString(line) {
+			return LogLevelError
+		}
+	}
+
+	for _, pattern := range warningPatterns {
+		if pattern.MatchString(line) {
+			return LogLevelWarning
+		}
+	}
+
+	if strings.Contains(strings.ToLower(line), "debug") {
+		return LogLevelDebug
+	}
+
+	return LogLevelInfo
+}
+
+// parseANSI strips ANSI escape sequences from line, returning the plain text and,
+// when any SGR styling was applied, the text split into styled spans.
+func parseANSI(line string) (string, []Span) {
+	if !strings.Contains(line, "\x1b[") {
+		return line, nil
+	}
+
+	var (
+		text   strings.Builder
+		spans  []Span
+		style  SpanStyle
+		styled bool
+	)
+
+	emit := func(s string) {
+		if s == "" {
+			return
+		}
+
+		text.WriteString(s)
+
+		if n := len(spans); n > 0 && spans[n-1].Style == style {
+			spans[n-1].Text += s
+			return
+		}
+
+		spans = append(spans, Span{Text: s, Style: style})
+	}
+
+	last := 0
+
+	for _, loc := range ansiPattern.FindAllStringIndex(line, -1) {
+		emit(line[last:loc[0]])
+		last = loc[1]
+
+		seq := line[loc[0]:loc[1]]
+		if seq[len(seq)-1] != 'm' {
+			continue // cursor movement and other non-style sequences are dropped
+		}
+
+		style = applySGR(style, seq[2:len(seq)-1])
+		styled = styled || !style.IsZero()
+	}
+
+	emit(line[last:])
+
+	if !styled {
+		return text.String(), nil
+	}
+
+	return text.String(), spans
+}
+
+// applySGR applies the parameters of a Select Graphic Rendition sequence to style.
+func applySGR(style SpanStyle, params string) SpanStyle {
+	if params == "" {
+		return SpanStyle{}
+	}
+
+	codes := strings.Split(params, ";")
+
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case code == 0:
+			style = SpanStyle{}
+		case code == 1:
+			style.Bold = true
+		case code == 2:
+			style.Faint = true
+		case code == 3:
+			style.Italic = true
+		case code == 4:
+			style.Underline = true
+		case code == 7:
+			style.Reverse = true
+		case code == 22:
+			style.Bold, style.Faint = false, false
+		case code == 23:
+			style.Italic = false
+		case code == 24:
+			style.Underline = false
+		case code == 27:
+			style.Reverse = false
+		case code >= 30 && code <= 37:
+			style.Foreground = strconv.Itoa(code - 30)
+		case code == 39:
+			style.Foreground = ""
+		case code >= 40 && code <= 47:
+			style.Background = strconv.Itoa(code - 40)
+		case code == 49:
+			style.Background = ""
+		case code >= 90 && code <= 97:
+			style.Foreground = strconv.Itoa(code - 90 + 8)
+		case code >= 100 && code <= 107:
+			style.Background = strconv.Itoa(code - 100 + 8)
+		case code == 38 || code == 48:
+			color, consumed := extendedColor(codes[i+1:])
+			i += consumed
+
+			if code == 38 {
+				style.Foreground = color
+			} else {
+				style.Background = color
+			}
+		}
+	}
+
+	return style
+}
+
+// extendedColor parses the arguments of a 38/48 SGR code: `5;n` or `2;r;g;b`.
+func extendedColor(args []string) (string, int) {
+	if len(args) >= 2 && args[0] == "5" {
+		return args[1], 2
+	}
+
+	if len(args) >= 4 && args[0] == "2" {
+		rgb := make([]byte, 3)
+
+		for j := range rgb {
+			v, _ := strconv.Atoi(args[j+1])
+			rgb[j] = byte(min(max(v, 0), 255))
+		}
+
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), 4
+	}
+
+	return "", len(args)
+}
@@ -0,0 +1,253 @@
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+func TestParseLine(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 3, 500000000, time.UTC)
+
+	tests := []struct {
+		name string
+		line string
+		want LogEntry
+	}{
+		{
+			name: "gh run view columns",
+			line: "build\tRun tests\t2024-01-01T12:00:03.5000000Z ok  \tpkg/app\t0.01s",
+			want: LogEntry{Timestamp: ts, Content: "ok  \tpkg/app\t0.01s", Level: LogLevelInfo, JobName: "build", StepName: "Run tests"},
+		},
+		{
+			name: "archive line with BOM",
+			line: "\ufeff2024-01-01T12:00:03.5000000Z Installing",
+			want: LogEntry{Timestamp: ts, Content: "Installing", Level: LogLevelInfo, StepName: "step"},
+		},
+		{
+			name: "error marker",
+			line: "2024-01-01T12:00:03.5Z ##[error]Process completed with exit code 1.",
+			want: LogEntry{Timestamp: ts, Content: "Process completed with exit code 1.", Level: LogLevelError, StepName: "step"},
+		},
+		{
+			name: "warning marker",
+			line: "##[warning]Node.js 16 actions are deprecated",
+			want: LogEntry{Content: "Node.js 16 actions are deprecated", Level: LogLevelWarning, StepName: "step"},
+		},
+		{
+			name: "error command with location",
+			line: "::error file=app/main.go,line=12,col=4,title=Build%3A vet::unused variable%0Ax",
+			want: LogEntry{
+				Content:    "unused variable\nx",
+				Level:      LogLevelError,
+				StepName:   "step",
+				Annotation: &Annotation{File: "app/main.go", Line: 12, Column: 4, Title: "Build: vet"},
+			},
+		},
+		{
+			name: "notice without properties",
+			line: "::notice::Deployed to staging",
+			want: LogEntry{Content: "Deployed to staging", Level: LogLevelInfo, StepName: "step"},
+		},
+		{
+			name: "other workflow commands are plain text",
+			line: "::add-mask::***",
+			want: LogEntry{Content: "::add-mask::***", Level: LogLevelInfo, StepName: "step"},
+		},
+		{
+			name: "unmarked lines fall back to wording",
+			line: "npm WARN deprecated glob@7",
+			want: LogEntry{Content: "npm WARN deprecated glob@7", Level: LogLevelWarning, StepName: "step"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewParser("step").ParseLine(tt.line)
+			if !ok {
+				t.Fatal("expected an entry")
+			}
+
+			if !got.Timestamp.Equal(tt.want.Timestamp) || got.Content != tt.want.Content || got.Level != tt.want.Level ||
+				got.JobName != tt.want.JobName || got.StepName != tt.want.StepName {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			switch {
+			case (got.Annotation == nil) != (tt.want.Annotation == nil):
+				t.Errorf("Annotation: got %+v, want %+v", got.Annotation, tt.want.Annotation)
+			case got.Annotation != nil && *got.Annotation != *tt.want.Annotation:
+				t.Errorf("Annotation: got %+v, want %+v", *got.Annotation, *tt.want.Annotation)
+			}
+		})
+	}
+}
+
+func TestParseLogOutput_Groups(t *testing.T) {
+	raw := `2024-01-01T00:00:01Z ##[group]Run actions/checkout@v4
+2024-01-01T00:00:02Z Syncing repository
+2024-01-01T00:00:03Z ::group::Getting Git version info
+2024-01-01T00:00:04Z git version 2.43.0
+2024-01-01T00:00:05Z ::endgroup::
+
+2024-01-01T00:00:06Z ##[endgroup]
+no timestamp here`
+
+	entries := ParseLogOutput(raw, "checkout")
+
+	want := []struct {
+		content string
+		group   bool
+		depth   int
+		second  int
+	}{
+		{"Run actions/checkout@v4", true, 0, 1},
+		{"Syncing repository", false, 1, 2},
+		{"Getting Git version info", true, 1, 3},
+		{"git version 2.43.0", false, 2, 4},
+		{"no timestamp here", false, 0, 6},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+
+	for i, w := range want {
+		e := entries[i]
+		if e.Content != w.content || e.Group != w.group || e.Depth != w.depth || e.Timestamp.Second() != w.second {
+			t.Errorf("entry %d: got %q group=%v depth=%d ts=%s, want %q group=%v depth=%d second=%d",
+				i, e.Content, e.Group, e.Depth, e.Timestamp, w.content, w.group, w.depth, w.second)
+		}
+	}
+}
+
+func TestParseLine_ANSI(t *testing.T) {
+	entry, ok := NewParser("step").ParseLine("\x1b[1;31mFAIL\x1b[0m pkg/app \x1b[38;5;208m(2.1s)\x1b[39m\x1b[2K")
+	if !ok {
+		t.Fatal("expected an entry")
+	}
+
+	if entry.Content != "FAIL pkg/app (2.1s)" {
+		t.Errorf("Content: got %q", entry.Content)
+	}
+
+	want := []Span{
+		{Text: "FAIL", Style: SpanStyle{Foreground: "1", Bold: true}},
+		{Text: " pkg/app ", Style: SpanStyle{}},
+		{Text: "(2.1s)", Style: SpanStyle{Foreground: "208"}},
+	}
+
+	if len(entry.Spans) != len(want) {
+		t.Fatalf("Spans: got %+v, want %+v", entry.Spans, want)
+	}
+
+	for i := range want {
+		if entry.Spans[i] != want[i] {
+			t.Errorf("span %d: got %+v, want %+v", i, entry.Spans[i], want[i])
+		}
+	}
+
+	if entry, _ := NewParser("step").ParseLine("\x1b[0mplain\x1b[K"); entry.Spans != nil || entry.Content != "plain" {
+		t.Errorf("reset-only line: got %q with spans %+v", entry.Content, entry.Spans)
+	}
+}
+
+func TestApplySGR_Colors(t *testing.T) {
+	tests := []struct {
+		params string
+		want   SpanStyle
+	}{
+		{"32", SpanStyle{Foreground: "2"}},
+		{"91;44", SpanStyle{Foreground: "9", Background: "4"}},
+		{"38;2;255;136;0", SpanStyle{Foreground: "#ff8800"}},
+		{"48;5;236;3;4", SpanStyle{Background: "236", Italic: true, Underline: true}},
+		{"1;22", SpanStyle{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.params, func(t *testing.T) {
+			if got := applySGR(SpanStyle{}, tt.params); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitJobEntries(t *testing.T) {
+	job := github.Job{Name: "build", Steps: []github.Step{{Name: "Set up job"}, {Name: "Run tests"}, {Name: "Complete job"}}}
+
+	t.Run("step column", func(t *testing.T) {
+		raw := "build\tSet up job\t2024-01-01T00:00:01Z Runner 2.311\n" +
+			"build\tRun tests\t2024-01-01T00:00:02Z ##[group]Run go test\n" +
+			"build\tRun tests\t2024-01-01T00:00:03Z ::group::nested\n" +
+			"build\tRun tests\t2024-01-01T00:00:04Z ok\n" +
+			"build\tComplete job\t2024-01-01T00:00:05Z Cleaning up"
+
+		segments := splitJobEntries(job, ParseLogOutput(raw, ""))
+
+		for i, n := range []int{1, 3, 1} {
+			if len(segments[i]) != n {
+				t.Errorf("step %d: got %d entries, want %d", i, len(segments[i]), n)
+			}
+		}
+	})
+
+	t.Run("top-level groups", func(t *testing.T) {
+		raw := "##[group]Set up job\nRunner\n##[endgroup]\n" +
+			"##[group]Run tests\n##[group]nested\nok\n##[endgroup]\n##[endgroup]\n" +
+			"##[group]Complete job\n##[endgroup]"
+
+		segments := splitJobEntries(job, ParseLogOutput(raw, ""))
+
+		for i, n := range []int{2, 3, 1} {
+			if len(segments[i]) != n {
+				t.Errorf("step %d: got %d entries, want %d", i, len(segments[i]), n)
+			}
+		}
+	})
+}
+
+func TestSetLevelPatterns(t *testing.T) {
+	t.Cleanup(func() { SetLevelPatterns(LevelPatterns{}) })
+
+	if err := SetLevelPatterns(LevelPatterns{Error: []string{"^FAIL:", "^panic:"}, Debug: []string{"^🔍"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line string
+		want LogLevel
+	}{
+		{"FAIL: TestLogin", LogLevelError},
+		{"panic: runtime error", LogLevelError},
+		{"🔍 resolved 12 packages", LogLevelDebug},
+		{"warning: unused import", LogLevelWarning}, // built-in rules still apply
+		{"all good", LogLevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := detectLogLevel(tt.line); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.line, got, tt.want)
+		}
+	}
+
+	fingerprint := levelPatternsFingerprint()
+	if fingerprint == "" {
+		t.Error("expected a fingerprint for custom patterns")
+	}
+
+	if err := SetLevelPatterns(LevelPatterns{Warning: []string{"("}}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+
+	if levelPatternsFingerprint() != fingerprint || detectLogLevel("FAIL: TestLogin") != LogLevelError {
+		t.Error("an invalid pattern should leave the current patterns in place")
+	}
+
+	SetLevelPatterns(LevelPatterns{})
+
+	if levelPatternsFingerprint() != "" || detectLogLevel("FAIL: TestLogin") != LogLevelInfo {
+		t.Error("expected the custom patterns to be cleared")
+	}
+}
@@ -5,7 +5,6 @@ package logs
 import (
 	"path/filepath"
 	"testing"
-	"time"
 )
 
 func TestLinux_CachePath(t *testing.T) {
@@ -37,15 +36,14 @@ func TestLinux_FileOperations(t *testing.T) {
 	cacheDir := t.TempDir()
 	cache := NewCache(cacheDir)
 
-	runLogs := NewRunLogs("test", "main")
-	runLogs.AddStep(&StepLogs{StepName: "build"})
+	key := CacheKey{Repo: "owner/repo", RunID: 123, Attempt: 1}
 
-	err := cache.Put("test", 123, runLogs, 1*time.Hour)
+	err := cache.Put(key, []*StepLogs{{StepName: "build"}})
 	if err != nil {
 		t.Errorf("Put failed on Linux: %v", err)
 	}
 
-	_, found := cache.Get("test", 123)
+	_, found := cache.Get(key)
 	if !found {
 		t.Error("expected to find cached entry on Linux")
 	}
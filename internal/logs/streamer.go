@@ -3,6 +3,7 @@ package logs
 import (
 	"context"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,16 +13,38 @@ import (
 // StreamPollInterval is the interval between log polling for active runs.
 const StreamPollInterval = 2 * time.Second
 
-// StreamState tracks the state of logs for incremental updates.
+// StreamMaxPollInterval caps the backoff applied while polls find no new log lines.
+const StreamMaxPollInterval = 30 * time.Second
+
+// StreamState tracks how much of each job's log has been read.
 type StreamState struct {
-	StepLineCounts map[int]int // map[stepIndex]lineCount
+	Jobs map[int64]*JobStreamState // map[jobID]state
 }
 
 // NewStreamState creates a new StreamState.
 func NewStreamState() *StreamState {
 	return &StreamState{
-		StepLineCounts: make(map[int]int),
+		Jobs: make(map[int64]*JobStreamState),
+	}
+}
+
+// JobStreamState is the read position in one job's log.
+type JobStreamState struct {
+	Offset    int  // bytes of the job log already parsed
+	Completed bool // the job finished and its log has been read to the end
+
+	parser  *Parser
+	tracker *stepTracker
+}
+
+func (s *StreamState) job(job github.Job) *JobStreamState {
+	state, ok := s.Jobs[job.ID]
+	if !ok {
+		state = &JobStreamState{parser: NewParser(""), tracker: newStepTracker(job)}
+		s.Jobs[job.ID] = state
 	}
+
+	return state
 }
 
 // StreamUpdate represents new log content detected during streaming.
@@ -33,20 +56,33 @@ type StreamUpdate struct {
 	Error      error
 }
 
+// JobLogFetcher fetches the part of a job's log past a byte offset.
+// github.Client implements it with a ranged request to the job logs endpoint.
+type JobLogFetcher interface {
+	GetJobLogs(jobID int64, offset int) (string, error)
+}
+
 // LogStreamer polls for incremental log updates from active workflow runs.
+//
+// Each poll fetches only jobs that are running or finished since the last poll,
+// requests only the bytes past the job's last offset, and fetches a completed job
+// for the last time. Polls that find nothing new double the interval, up to
+// StreamMaxPollInterval. When the run completes, every job is read to the end
+// before the final update is sent.
 type LogStreamer struct {
-	fetcher  *GHFetcher
-	client   GitHubClient
-	runID    int64
-	workflow string
-	state    *StreamState
-	updates  chan StreamUpdate
-	ctx      context.Context
-	cancel   context.CancelFunc
-	ticker   *time.Ticker
-	stopOnce sync.Once
-	wg       sync.WaitGroup
-	mu       sync.Mutex
+	fetchJobLog func(runID, jobID int64, offset int) (string, error)
+	client      GitHubClient
+	runID       int64
+	workflow    string
+	state       *StreamState
+	interval    time.Duration
+	updates     chan StreamUpdate
+	ctx         context.Context
+	cancel      context.CancelFunc
+	timer       *time.Timer
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+	mu          sync.Mutex
 }
 
 // NewLogStreamer creates a new LogStreamer for a specific run.
@@ -54,20 +90,42 @@ func NewLogStreamer(client GitHubClient, runID int64, workflow string) *LogStrea
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &LogStreamer{
-		fetcher:  NewGHFetcher(client),
-		client:   client,
-		runID:    runID,
-		workflow: workflow,
-		state:    NewStreamState(),
-		updates:  make(chan StreamUpdate, 50),
-		ctx:      ctx,
-		cancel:   cancel,
+		fetchJobLog: jobLogFetcher(client),
+		client:      client,
+		runID:       runID,
+		workflow:    workflow,
+		state:       NewStreamState(),
+		interval:    StreamPollInterval,
+		updates:     make(chan StreamUpdate, 50),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// jobLogFetcher returns how the streamer reads job logs: ranged requests when the
+// client supports them, and otherwise the full log through gh, skipping what was read.
+func jobLogFetcher(client GitHubClient) func(runID, jobID int64, offset int) (string, error) {
+	if ranged, ok := client.(JobLogFetcher); ok {
+		return func(_, jobID int64, offset int) (string, error) {
+			return ranged.GetJobLogs(jobID, offset)
+		}
+	}
+
+	fetcher := NewGHFetcher(client)
+
+	return func(runID, jobID int64, offset int) (string, error) {
+		raw, err := fetcher.fetchJobLogs(runID, jobID)
+		if err != nil || offset >= len(raw) {
+			return "", err
+		}
+
+		return raw[offset:], nil
 	}
 }
 
 // Start begins polling for log updates.
 func (s *LogStreamer) Start() {
-	s.ticker = time.NewTicker(StreamPollInterval)
+	s.timer = time.NewTimer(s.interval)
 	s.wg.Add(1)
 
 	go s.pollLoop()
@@ -83,12 +141,12 @@ func (s *LogStreamer) Updates() <-chan StreamUpdate {
 func (s *LogStreamer) Stop() {
 	s.stopOnce.Do(func() {
 		s.cancel()
+		s.wg.Wait()
 
-		if s.ticker != nil {
-			s.ticker.Stop()
+		if s.timer != nil {
+			s.timer.Stop()
 		}
 
-		s.wg.Wait()
 		close(s.updates)
 	})
 }
@@ -97,19 +155,30 @@ func (s *LogStreamer) pollLoop() {
 	defer s.wg.Done()
 
 	// Initial poll
-	s.poll()
+	if s.poll() {
+		go s.Stop()
+		return
+	}
 
 	for {
 		select {
 		case <-s.ctx.Done():
 			return
-		case <-s.ticker.C:
-			s.poll()
+		case <-s.timer.C:
+			if s.poll() {
+				// Stop after sending the completion update
+				go s.Stop()
+				return
+			}
+
+			s.timer.Reset(s.interval)
 		}
 	}
 }
 
-func (s *LogStreamer) poll() {
+// poll reads new log lines and sends an update. It returns true once the run
+// has completed and its logs have been flushed.
+func (s *LogStreamer) poll() bool {
 	// Check run status first
 	run, err := s.client.GetWorkflowRun(s.runID)
 	if err != nil {
@@ -118,24 +187,10 @@ func (s *LogStreamer) poll() {
 			Error: err,
 		})
 
-		return
+		return false
 	}
 
-	// Stop polling if run completed
-	if run.Status == github.StatusCompleted {
-		s.sendUpdate(StreamUpdate{
-			RunID:      s.runID,
-			Status:     run.Status,
-			Conclusion: run.Conclusion,
-		})
-		// Stop after sending completion update
-		go s.Stop()
-
-		return
-	}
-
-	// Fetch current logs
-	currentLogs, err := s.fetcher.FetchStepLogsReal(s.runID, s.workflow)
+	jobs, err := s.client.GetWorkflowRunJobs(s.runID)
 	if err != nil {
 		s.sendUpdate(StreamUpdate{
 			RunID:  s.runID,
@@ -143,57 +198,137 @@ func (s *LogStreamer) poll() {
 			Error:  err,
 		})
 
-		return
+		return false
 	}
 
-	// Detect new logs
-	newSteps := s.detectNewLogs(currentLogs)
+	completed := run.Status == github.StatusCompleted
+	newSteps := s.readJobs(jobs, completed)
+
+	if len(newSteps) > 0 {
+		s.interval = StreamPollInterval
+	} else {
+		s.interval = min(s.interval*2, StreamMaxPollInterval)
+	}
 
 	// Always send status update (NewSteps may be nil if no changes)
-	s.sendUpdate(StreamUpdate{
+	update := StreamUpdate{
 		RunID:    s.runID,
 		Status:   run.Status,
 		NewSteps: newSteps,
-	})
+	}
+
+	if completed {
+		update.Conclusion = run.Conclusion
+	}
+
+	s.sendUpdate(update)
+
+	return completed
 }
 
-// detectNewLogs compares current logs against last known state.
-// Returns only steps with new log lines.
-func (s *LogStreamer) detectNewLogs(currentLogs []*StepLogs) []*StepLogs {
+// readJobs fetches the logs of jobs with unread output and returns their new
+// entries. With flush set, every job is read to the end regardless of status.
+func (s *LogStreamer) readJobs(jobs []github.Job, flush bool) []*StepLogs {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	var newSteps []*StepLogs
 
-	for _, stepLog := range currentLogs {
-		currentLineCount := len(stepLog.Entries)
-		lastLineCount := s.state.StepLineCounts[stepLog.StepIndex]
-
-		if currentLineCount > lastLineCount {
-			// New lines detected - create a StepLogs with only new entries
-			newEntries := stepLog.Entries[lastLineCount:]
-
-			newStepLog := &StepLogs{
-				StepIndex:  stepLog.StepIndex,
-				Workflow:   stepLog.Workflow,
-				RunID:      stepLog.RunID,
-				JobName:    stepLog.JobName,
-				StepName:   stepLog.StepName,
-				Status:     stepLog.Status,
-				Conclusion: stepLog.Conclusion,
-				Entries:    newEntries,
-				FetchedAt:  time.Now(),
-				Error:      stepLog.Error,
+	stepIndex := 0
+
+	for _, job := range jobs {
+		startIndex := stepIndex
+		stepIndex += len(job.Steps)
+
+		state := s.state.job(job)
+
+		if state.Completed || (job.Status == github.StatusQueued && !flush) {
+			continue
+		}
+
+		final := flush || job.Status == github.StatusCompleted
+
+		chunk, err := s.fetchJobLog(s.runID, job.ID, state.Offset)
+		if err != nil {
+			// Logs of a running job may not be available yet; a finished
+			// job reports the error on its steps instead
+			if final {
+				state.Completed = true
+				newSteps = append(newSteps, s.stepLogs(job, startIndex, make([][]LogEntry, len(job.Steps)), err)...)
 			}
 
-			newSteps = append(newSteps, newStepLog)
-			s.state.StepLineCounts[stepLog.StepIndex] = currentLineCount
+			continue
 		}
+
+		segments := s.consume(state, job, chunk, final)
+		newSteps = append(newSteps, s.stepLogs(job, startIndex, segments, nil)...)
 	}
 
 	return newSteps
 }
 
+// consume parses chunk, the part of a job log past state.Offset. Unless final is
+// set, a trailing line without a newline is left for the next poll, since the job
+// may still be writing it.
+func (s *LogStreamer) consume(state *JobStreamState, job github.Job, chunk string, final bool) [][]LogEntry {
+	end := len(chunk)
+	if !final {
+		end = strings.LastIndexByte(chunk, '\n') + 1
+	}
+
+	segments := make([][]LogEntry, len(job.Steps))
+
+	if end > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(chunk[:end], "\n"), "\n") {
+			entry, ok := state.parser.ParseLine(line)
+			if !ok {
+				continue
+			}
+
+			if i := state.tracker.next(entry); i >= 0 && i < len(segments) {
+				segments[i] = append(segments[i], entry)
+			}
+		}
+
+		state.Offset += end
+	}
+
+	state.Completed = final
+
+	return segments
+}
+
+// stepLogs wraps new entries of a job's steps, skipping steps with nothing new.
+func (s *LogStreamer) stepLogs(job github.Job, startIndex int, segments [][]LogEntry, err error) []*StepLogs {
+	var stepLogs []*StepLogs
+
+	for i, entries := range segments {
+		if entries == nil && err == nil {
+			continue
+		}
+
+		step := job.Steps[i]
+		for j := range entries {
+			entries[j].StepName = step.Name
+		}
+
+		stepLogs = append(stepLogs, &StepLogs{
+			StepIndex:  startIndex + i,
+			Workflow:   s.workflow,
+			RunID:      s.runID,
+			JobName:    job.Name,
+			StepName:   step.Name,
+			Status:     step.Status,
+			Conclusion: step.Conclusion,
+			Entries:    entries,
+			FetchedAt:  time.Now(),
+			Error:      err,
+		})
+	}
+
+	return stepLogs
+}
+
 func (s *LogStreamer) sendUpdate(update StreamUpdate) {
 	select {
 	case <-s.ctx.Done():
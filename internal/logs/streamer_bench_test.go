@@ -0,0 +1,130 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+const (
+	benchStreamJobs  = 3
+	benchStreamPolls = 60
+	benchStreamLines = 1000 // lines per job once it completes
+)
+
+// streamSimulation is a run whose jobs write their logs over benchStreamPolls
+// polls, finishing one after another.
+type streamSimulation struct {
+	jobs [][]github.Job // job list seen at each poll
+	logs []map[int64]string
+}
+
+func newStreamSimulation() *streamSimulation {
+	lines := make([]string, benchStreamJobs)
+	for j := range lines {
+		var sb strings.Builder
+
+		sb.WriteString("##[group]Set up\n")
+
+		for i := range benchStreamLines {
+			sb.WriteString(fmt.Sprintf("2024-01-01T12:00:%02d.000Z INFO: job %d line %d\n", i%60, j, i))
+		}
+
+		lines[j] = sb.String()
+	}
+
+	sim := &streamSimulation{}
+
+	for p := range benchStreamPolls {
+		var jobs []github.Job
+
+		logs := make(map[int64]string)
+
+		for j := range benchStreamJobs {
+			finish := (j + 1) * benchStreamPolls / benchStreamJobs
+			status := github.StatusInProgress
+
+			written := len(lines[j]) * (p + 1) / finish
+			if p+1 >= finish {
+				status, written = github.StatusCompleted, len(lines[j])
+			}
+
+			jobs = append(jobs, github.Job{ID: int64(j), Status: status, Steps: []github.Step{{Name: "Set up", Number: 1}}})
+			logs[int64(j)] = lines[j][:written]
+		}
+
+		sim.jobs = append(sim.jobs, jobs)
+		sim.logs = append(sim.logs, logs)
+	}
+
+	return sim
+}
+
+// BenchmarkStream_Refetch measures the previous approach: every poll fetches
+// and parses every job's full log, then diffs by line count.
+func BenchmarkStream_Refetch(b *testing.B) {
+	sim := newStreamSimulation()
+
+	var fetched int
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for range b.N {
+		lineCounts := make(map[int]int)
+
+		for p := range benchStreamPolls {
+			stepIndex := 0
+
+			for _, job := range sim.jobs[p] {
+				raw := sim.logs[p][job.ID]
+				fetched += len(raw)
+
+				for i, entries := range splitJobEntries(job, ParseLogOutput(raw, "")) {
+					if len(entries) > lineCounts[stepIndex+i] {
+						lineCounts[stepIndex+i] = len(entries)
+					}
+				}
+
+				stepIndex += len(job.Steps)
+			}
+		}
+	}
+
+	b.ReportMetric(float64(fetched)/float64(b.N), "fetched-B/run")
+}
+
+// BenchmarkStream_Incremental measures LogStreamer, which requests only the
+// bytes past each job's offset and stops fetching a job once it completes.
+// fetched-B/run counts the bytes returned for those requests.
+func BenchmarkStream_Incremental(b *testing.B) {
+	sim := newStreamSimulation()
+
+	var fetched int
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for range b.N {
+		poll := 0
+
+		streamer := NewLogStreamer(&mockGitHubClient{}, 1, "ci.yml")
+		streamer.fetchJobLog = func(runID, jobID int64, offset int) (string, error) {
+			raw := sim.logs[poll][jobID]
+			chunk := raw[min(offset, len(raw)):]
+			fetched += len(chunk)
+
+			return chunk, nil
+		}
+
+		for ; poll < benchStreamPolls; poll++ {
+			streamer.readJobs(sim.jobs[poll], false)
+		}
+
+		streamer.Stop()
+	}
+
+	b.ReportMetric(float64(fetched)/float64(b.N), "fetched-B/run")
+}
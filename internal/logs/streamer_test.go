@@ -2,159 +2,214 @@ package logs
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/kyleking/gh-lazydispatch/internal/github"
 )
 
-func TestLogStreamer_detectNewLogs(t *testing.T) {
-	tests := []struct {
-		name          string
-		initialState  map[int]int // stepIndex -> lineCount
-		currentLogs   []*StepLogs
-		expectedNew   int // number of steps with new logs
-		expectedSteps []int
-	}{
-		{
-			name:         "first poll - all logs are new",
-			initialState: map[int]int{},
-			currentLogs: []*StepLogs{
-				{StepIndex: 0, StepName: "checkout", Entries: makeEntries(5)},
-				{StepIndex: 1, StepName: "setup", Entries: makeEntries(3)},
-			},
-			expectedNew:   2,
-			expectedSteps: []int{0, 1},
-		},
-		{
-			name: "no new logs - same line counts",
-			initialState: map[int]int{
-				0: 5,
-				1: 3,
-			},
-			currentLogs: []*StepLogs{
-				{StepIndex: 0, StepName: "checkout", Entries: makeEntries(5)},
-				{StepIndex: 1, StepName: "setup", Entries: makeEntries(3)},
-			},
-			expectedNew:   0,
-			expectedSteps: []int{},
-		},
-		{
-			name: "incremental update - step 1 has new lines",
-			initialState: map[int]int{
-				0: 5,
-				1: 3,
-			},
-			currentLogs: []*StepLogs{
-				{StepIndex: 0, StepName: "checkout", Entries: makeEntries(5)},
-				{StepIndex: 1, StepName: "setup", Entries: makeEntries(7)},
-			},
-			expectedNew:   1,
-			expectedSteps: []int{1},
-		},
-		{
-			name: "new step appears",
-			initialState: map[int]int{
-				0: 5,
-				1: 3,
-			},
-			currentLogs: []*StepLogs{
-				{StepIndex: 0, StepName: "checkout", Entries: makeEntries(5)},
-				{StepIndex: 1, StepName: "setup", Entries: makeEntries(3)},
-				{StepIndex: 2, StepName: "build", Entries: makeEntries(10)},
-			},
-			expectedNew:   1,
-			expectedSteps: []int{2},
-		},
-		{
-			name: "multiple steps have updates",
-			initialState: map[int]int{
-				0: 5,
-				1: 3,
-				2: 10,
-			},
-			currentLogs: []*StepLogs{
-				{StepIndex: 0, StepName: "checkout", Entries: makeEntries(5)},
-				{StepIndex: 1, StepName: "setup", Entries: makeEntries(8)},
-				{StepIndex: 2, StepName: "build", Entries: makeEntries(15)},
-				{StepIndex: 3, StepName: "test", Entries: makeEntries(20)},
-			},
-			expectedNew:   3,
-			expectedSteps: []int{1, 2, 3},
-		},
+// streamMockClient serves a configurable run and job list.
+type streamMockClient struct {
+	run  github.WorkflowRun
+	jobs []github.Job
+}
+
+func (c *streamMockClient) GetWorkflowRun(runID int64) (*github.WorkflowRun, error) {
+	run := c.run
+	return &run, nil
+}
+
+func (c *streamMockClient) GetWorkflowRunJobs(runID int64) ([]github.Job, error) {
+	return c.jobs, nil
+}
+
+// newTestStreamer creates a streamer reading job logs from the logs map and counting fetches.
+func newTestStreamer(client GitHubClient, jobLogs map[int64]string, fetches map[int64]int) *LogStreamer {
+	streamer := NewLogStreamer(client, 1, "ci.yml")
+	streamer.fetchJobLog = func(runID, jobID int64, offset int) (string, error) {
+		fetches[jobID]++
+
+		raw, ok := jobLogs[jobID]
+		if !ok {
+			return "", errors.New("logs not available")
+		}
+
+		return raw[min(offset, len(raw)):], nil
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Make a copy of initial state for verification later
-			initialStateCopy := make(map[int]int)
-			for k, v := range tt.initialState {
-				initialStateCopy[k] = v
-			}
+	return streamer
+}
 
-			streamer := &LogStreamer{
-				state: &StreamState{
-					StepLineCounts: tt.initialState,
-				},
-			}
+func streamJob(id int64, status string) github.Job {
+	return github.Job{
+		ID:     id,
+		Name:   fmt.Sprintf("job-%d", id),
+		Status: status,
+		Steps:  []github.Step{{Name: "Set up", Number: 1}, {Name: "Run", Number: 2}},
+	}
+}
 
-			newSteps := streamer.detectNewLogs(tt.currentLogs)
+func entryContents(stepLogs []*StepLogs) map[int][]string {
+	contents := make(map[int][]string)
 
-			if len(newSteps) != tt.expectedNew {
-				t.Errorf("expected %d new steps, got %d", tt.expectedNew, len(newSteps))
-			}
+	for _, sl := range stepLogs {
+		for _, entry := range sl.Entries {
+			contents[sl.StepIndex] = append(contents[sl.StepIndex], entry.Content)
+		}
+	}
 
-			// Verify correct step indices were returned
-			gotIndices := make([]int, len(newSteps))
-			for i, step := range newSteps {
-				gotIndices[i] = step.StepIndex
-			}
+	return contents
+}
 
-			if len(gotIndices) != len(tt.expectedSteps) {
-				t.Errorf("step indices: got %v, want %v", gotIndices, tt.expectedSteps)
-				return
-			}
+func TestLogStreamer_readJobs(t *testing.T) {
+	jobLogs := map[int64]string{
+		10: "##[group]Set up\nrunner\n##[endgroup]\n##[group]Run\ncompil",
+	}
+	fetches := map[int64]int{}
+	streamer := newTestStreamer(&mockGitHubClient{}, jobLogs, fetches)
 
-			for i, expected := range tt.expectedSteps {
-				if gotIndices[i] != expected {
-					t.Errorf("step index %d: got %d, want %d", i, gotIndices[i], expected)
-				}
-			}
+	// Poll 1: the running job is read up to its last complete line, the queued job is not fetched
+	got := entryContents(streamer.readJobs([]github.Job{streamJob(10, "in_progress"), streamJob(20, "queued")}, false))
 
-			// Verify only new entries are included (use copy of initial state)
-			for _, newStep := range newSteps {
-				originalStep := findStepByIndex(tt.currentLogs, newStep.StepIndex)
-				if originalStep == nil {
-					t.Fatalf("step %d not found in current logs", newStep.StepIndex)
-				}
+	want := map[int][]string{0: {"Set up", "runner"}, 1: {"Run"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("poll 1: got %v, want %v", got, want)
+	}
 
-				lastCount, exists := initialStateCopy[newStep.StepIndex]
-				if !exists {
-					lastCount = 0
-				}
+	if fetches[20] != 0 {
+		t.Errorf("queued job should not be fetched, got %d fetches", fetches[20])
+	}
 
-				currentCount := len(originalStep.Entries)
-				expectedNewEntries := currentCount - lastCount
+	// Poll 2: only the appended bytes are parsed, including the line that was incomplete before
+	jobLogs[10] += "ing\nok\n"
+	got = entryContents(streamer.readJobs([]github.Job{streamJob(10, "completed"), streamJob(20, "in_progress")}, false))
 
-				if len(newStep.Entries) != expectedNewEntries {
-					t.Errorf("step %d: expected %d new entries (current %d - last %d), got %d",
-						newStep.StepIndex, expectedNewEntries, currentCount, lastCount, len(newStep.Entries))
-				}
-			}
+	want = map[int][]string{1: {"compiling", "ok"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("poll 2: got %v, want %v", got, want)
+	}
 
-			// Verify state was updated
-			for _, step := range tt.currentLogs {
-				if streamer.state.StepLineCounts[step.StepIndex] != len(step.Entries) {
-					t.Errorf("state not updated for step %d: got %d, want %d",
-						step.StepIndex,
-						streamer.state.StepLineCounts[step.StepIndex],
-						len(step.Entries))
-				}
-			}
-		})
+	if state := streamer.state.Jobs[10]; !state.Completed || state.Offset != len(jobLogs[10]) {
+		t.Errorf("poll 2: job 10 state = %+v", state)
+	}
+
+	// Poll 3: the completed job is not fetched again
+	jobLogs[20] = "##[group]Set up\nlast line without newline"
+	streamer.readJobs([]github.Job{streamJob(10, "completed"), streamJob(20, "in_progress")}, false)
+
+	if fetches[10] != 2 {
+		t.Errorf("completed job fetched %d times, want 2", fetches[10])
+	}
+
+	// Flush: the trailing line is read once the run is done
+	got = entryContents(streamer.readJobs([]github.Job{streamJob(10, "completed"), streamJob(20, "completed")}, true))
+
+	want = map[int][]string{2: {"last line without newline"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("flush: got %v, want %v", got, want)
 	}
 }
 
+func TestLogStreamer_readJobs_RequestsNewBytes(t *testing.T) {
+	var offsets []int
+
+	streamer := NewLogStreamer(&mockGitHubClient{}, 1, "ci.yml")
+	streamer.fetchJobLog = func(runID, jobID int64, offset int) (string, error) {
+		offsets = append(offsets, offset)
+
+		if offset == 0 {
+			return "##[group]Set up\nline a\nline b", nil
+		}
+
+		return "line b\n", nil
+	}
+
+	streamer.readJobs([]github.Job{streamJob(10, "in_progress")}, false)
+	got := entryContents(streamer.readJobs([]github.Job{streamJob(10, "in_progress")}, false))
+
+	// The incomplete line is requested again from its start
+	if want := []int{0, len("##[group]Set up\nline a\n")}; fmt.Sprint(offsets) != fmt.Sprint(want) {
+		t.Errorf("offsets = %v, want %v", offsets, want)
+	}
+
+	if want := map[int][]string{0: {"line b"}}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLogStreamer_readJobs_FetchError(t *testing.T) {
+	streamer := newTestStreamer(&mockGitHubClient{}, map[int64]string{}, map[int64]int{})
+
+	// A running job without logs yet is retried on the next poll
+	if newSteps := streamer.readJobs([]github.Job{streamJob(10, "in_progress")}, false); len(newSteps) != 0 {
+		t.Errorf("expected no steps for a running job without logs, got %d", len(newSteps))
+	}
+
+	// A finished job reports the error on its steps
+	newSteps := streamer.readJobs([]github.Job{streamJob(10, "completed")}, false)
+	if len(newSteps) != 2 || newSteps[0].Error == nil {
+		t.Fatalf("expected 2 steps with errors, got %+v", newSteps)
+	}
+}
+
+func TestLogStreamer_PollBackoff(t *testing.T) {
+	client := &streamMockClient{
+		run:  github.WorkflowRun{Status: "in_progress"},
+		jobs: []github.Job{streamJob(10, "in_progress")},
+	}
+	jobLogs := map[int64]string{10: "##[group]Set up\n"}
+	streamer := newTestStreamer(client, jobLogs, map[int64]int{})
+
+	streamer.poll()
+
+	if streamer.interval != StreamPollInterval {
+		t.Errorf("interval after new lines: got %s, want %s", streamer.interval, StreamPollInterval)
+	}
+
+	for range 10 {
+		streamer.poll()
+	}
+
+	if streamer.interval != StreamMaxPollInterval {
+		t.Errorf("interval after idle polls: got %s, want %s", streamer.interval, StreamMaxPollInterval)
+	}
+
+	jobLogs[10] += "more\n"
+	streamer.poll()
+
+	if streamer.interval != StreamPollInterval {
+		t.Errorf("interval after new lines: got %s, want %s", streamer.interval, StreamPollInterval)
+	}
+}
+
+func TestLogStreamer_FinalFlush(t *testing.T) {
+	client := &streamMockClient{
+		run:  github.WorkflowRun{Status: "completed", Conclusion: "failure"},
+		jobs: []github.Job{streamJob(10, "in_progress")},
+	}
+	jobLogs := map[int64]string{10: "##[group]Set up\n##[error]boom"}
+	streamer := newTestStreamer(client, jobLogs, map[int64]int{})
+
+	if !streamer.poll() {
+		t.Fatal("expected poll to report completion")
+	}
+
+	update := <-streamer.Updates()
+	if update.Status != "completed" || update.Conclusion != "failure" {
+		t.Errorf("unexpected completion update: %+v", update)
+	}
+
+	got := entryContents(update.NewSteps)
+
+	want := map[int][]string{0: {"Set up", "boom"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("final flush: got %v, want %v", got, want)
+	}
+
+	streamer.Stop()
+}
+
 func TestStreamState_NewStreamState(t *testing.T) {
 	state := NewStreamState()
 
@@ -162,12 +217,12 @@ func TestStreamState_NewStreamState(t *testing.T) {
 		t.Fatal("expected non-nil state")
 	}
 
-	if state.StepLineCounts == nil {
-		t.Fatal("expected initialized StepLineCounts map")
+	if state.Jobs == nil {
+		t.Fatal("expected initialized Jobs map")
 	}
 
-	if len(state.StepLineCounts) != 0 {
-		t.Errorf("expected empty StepLineCounts, got %d entries", len(state.StepLineCounts))
+	if len(state.Jobs) != 0 {
+		t.Errorf("expected empty Jobs, got %d entries", len(state.Jobs))
 	}
 }
 
@@ -201,31 +256,6 @@ func TestLogStreamer_Creation(t *testing.T) {
 	streamer.Stop()
 }
 
-// Helper functions
-
-func makeEntries(count int) []LogEntry {
-	entries := make([]LogEntry, count)
-	for i := range count {
-		entries[i] = LogEntry{
-			Timestamp: time.Now(),
-			Content:   "test log line",
-			Level:     LogLevelInfo,
-		}
-	}
-
-	return entries
-}
-
-func findStepByIndex(steps []*StepLogs, index int) *StepLogs {
-	for _, step := range steps {
-		if step.StepIndex == index {
-			return step
-		}
-	}
-
-	return nil
-}
-
 func TestLogStreamer_StartStop(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping streamer test in short mode")
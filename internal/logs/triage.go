@@ -0,0 +1,234 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTriageContext is the number of lines kept before and after a failure's first error line.
+const DefaultTriageContext = 3
+
+var (
+	exitCodePattern = regexp.MustCompile(`(?i)\bexit (?:code|status) (\d+)`)
+	digitsPattern   = regexp.MustCompile(`\d+`)
+
+	// processExitPattern matches the line the runner logs after any failing step,
+	// which says nothing about the cause.
+	processExitPattern = regexp.MustCompile(`(?i)^(?:##\[error\])?\s*process completed with exit code \d+\.?$`)
+)
+
+// Failure is the first error line of a failed step, with the lines around it.
+type Failure struct {
+	Workflow    string
+	RunID       int64
+	JobName     string
+	StepName    string
+	Line        LogEntry
+	Before      []LogEntry
+	After       []LogEntry
+	ExitCode    int       // exit code named by the line, -1 if none
+	Time        time.Time // when the line was logged, or the closest earlier line was; zero if unknown
+	Occurrences int       // failed steps whose first error line reads the same
+}
+
+// Triage finds the first error line of every failed step in runLogs, keeping
+// context lines around it. The runner's generic "Process completed with exit
+// code" line is only used when a step logged no other error, and then the line
+// before it tells failures apart. Failures whose lines read the same apart from
+// numbers are merged. The result is ordered by how likely each failure is the
+// root cause: the earliest first, then steps naming an exit code, then the
+// order of the steps.
+func Triage(runLogs *RunLogs, context int) []*Failure {
+	var failures []*Failure
+
+	seen := make(map[string]*Failure)
+
+	for _, step := range runLogs.AllSteps() {
+		if !stepFailed(step) {
+			continue
+		}
+
+		idx := firstError(step.Entries)
+		if idx < 0 {
+			continue
+		}
+
+		line := step.Entries[idx]
+		key := triageKey(step, idx)
+
+		if f, ok := seen[key]; ok {
+			f.Occurrences++
+			continue
+		}
+
+		f := &Failure{
+			Workflow:    step.Workflow,
+			RunID:       step.RunID,
+			JobName:     step.JobName,
+			StepName:    step.StepName,
+			Line:        line,
+			Before:      step.Entries[max(0, idx-context):idx],
+			After:       step.Entries[idx+1 : min(len(step.Entries), idx+1+context)],
+			ExitCode:    -1,
+			Time:        entryTime(step.Entries, idx),
+			Occurrences: 1,
+		}
+
+		for _, entry := range step.Entries[idx:] {
+			if m := exitCodePattern.FindStringSubmatch(entry.Content); m != nil && entry.Level == LogLevelError {
+				f.ExitCode, _ = strconv.Atoi(m[1])
+				break
+			}
+		}
+
+		seen[key] = f
+		failures = append(failures, f)
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		a, b := failures[i], failures[j]
+
+		if !a.Time.Equal(b.Time) {
+			switch {
+			case a.Time.IsZero():
+				return false
+			case b.Time.IsZero():
+				return true
+			default:
+				return a.Time.Before(b.Time)
+			}
+		}
+
+		return a.ExitCode >= 0 && b.ExitCode < 0
+	})
+
+	return failures
+}
+
+// firstError returns the index of the first error-level entry, preferring one
+// that is not the runner's generic exit code line, or -1.
+func firstError(entries []LogEntry) int {
+	generic := -1
+
+	for i, entry := range entries {
+		if entry.Level != LogLevelError {
+			continue
+		}
+
+		if !isProcessExit(entry) {
+			return i
+		}
+
+		if generic < 0 {
+			generic = i
+		}
+	}
+
+	return generic
+}
+
+func isProcessExit(entry LogEntry) bool {
+	return processExitPattern.MatchString(strings.TrimSpace(entry.Content))
+}
+
+// triageKey identifies a failure by its error line with numbers ignored. The
+// generic exit code line is keyed on the closest line before it instead, or on
+// the step when there is none, so unrelated failures are not merged.
+func triageKey(step *StepLogs, idx int) string {
+	normalize := func(s string) string {
+		return digitsPattern.ReplaceAllString(strings.ToLower(strings.TrimSpace(s)), "#")
+	}
+
+	if !isProcessExit(step.Entries[idx]) {
+		return normalize(step.Entries[idx].Content)
+	}
+
+	for i := idx - 1; i >= 0; i-- {
+		if content := strings.TrimSpace(step.Entries[i].Content); content != "" && !isProcessExit(step.Entries[i]) {
+			return "exit after: " + normalize(content)
+		}
+	}
+
+	return "exit in: " + stepTitle(step.Workflow, step.JobName, step.StepName)
+}
+
+// entryTime returns the timestamp of entries[idx], or of the closest earlier
+// entry that has one.
+func entryTime(entries []LogEntry, idx int) time.Time {
+	for i := idx; i >= 0; i-- {
+		if !entries[i].Timestamp.IsZero() {
+			return entries[i].Timestamp
+		}
+	}
+
+	return time.Time{}
+}
+
+// FormatTriage renders failures as a compact plain-text summary of at most
+// limit failures, or all of them when limit is not positive.
+func FormatTriage(failures []*Failure, limit int) string {
+	if len(failures) == 0 {
+		return ""
+	}
+
+	shown := failures
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("Likely root cause first:\n")
+
+	for i, f := range shown {
+		fmt.Fprintf(&sb, "%d. %s", i+1, stepTitle(f.Workflow, f.JobName, f.StepName))
+
+		if f.RunID != 0 {
+			fmt.Fprintf(&sb, " (run #%d)", f.RunID)
+		}
+
+		var notes []string
+
+		if f.ExitCode >= 0 {
+			notes = append(notes, fmt.Sprintf("exit code %d", f.ExitCode))
+		}
+
+		if f.Occurrences > 1 {
+			notes = append(notes, fmt.Sprintf("in %d steps", f.Occurrences))
+		}
+
+		if len(notes) > 0 {
+			sb.WriteString(" [" + strings.Join(notes, ", ") + "]")
+		}
+
+		sb.WriteString("\n")
+
+		for _, entry := range f.Before {
+			sb.WriteString("     " + triageLine(entry) + "\n")
+		}
+
+		sb.WriteString("   > " + triageLine(f.Line) + "\n")
+
+		for _, entry := range f.After {
+			sb.WriteString("     " + triageLine(entry) + "\n")
+		}
+	}
+
+	if hidden := len(failures) - len(shown); hidden > 0 {
+		fmt.Fprintf(&sb, "(%d more)\n", hidden)
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func triageLine(entry LogEntry) string {
+	if loc := entry.Annotation.Location(); loc != "" {
+		return loc + ": " + entry.Content
+	}
+
+	return entry.Content
+}
@@ -0,0 +1,161 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+func joinContents(entries []LogEntry) string {
+	contents := make([]string, len(entries))
+	for i, entry := range entries {
+		contents[i] = entry.Content
+	}
+
+	return strings.Join(contents, ",")
+}
+
+func TestTriage(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return ts.Add(time.Duration(sec) * time.Second) }
+
+	runLogs := NewRunLogs("release", "main")
+
+	// Fails last, but is the only step naming an exit code
+	runLogs.AddStep(&StepLogs{
+		Workflow: "deploy.yml", RunID: 2, JobName: "deploy", StepName: "Apply", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{
+			{Timestamp: at(30), Content: "terraform apply", Level: LogLevelInfo},
+			{Timestamp: at(31), Content: "Error: quota exceeded", Level: LogLevelInfo},
+			{Timestamp: at(32), Content: "Process completed with exit code 1.", Level: LogLevelError},
+		},
+	})
+	// Fails first: the likely root cause
+	runLogs.AddStep(&StepLogs{
+		Workflow: "build.yml", RunID: 1, JobName: "build", StepName: "Test", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{
+			{Timestamp: at(1), Content: "line 1", Level: LogLevelInfo},
+			{Timestamp: at(2), Content: "line 2", Level: LogLevelInfo},
+			{Timestamp: at(3), Content: "line 3", Level: LogLevelInfo},
+			{Timestamp: at(4), Content: "FAIL TestLogin (0.12s)", Level: LogLevelError},
+			{Timestamp: at(5), Content: "after 1", Level: LogLevelInfo},
+			{Timestamp: at(6), Content: "FAIL TestLogout (0.40s)", Level: LogLevelError},
+		},
+	})
+	// Same first error as the step above apart from numbers
+	runLogs.AddStep(&StepLogs{
+		Workflow: "build.yml", RunID: 1, JobName: "build-arm", StepName: "Test", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{{Timestamp: at(8), Content: "FAIL TestLogin (0.31s)", Level: LogLevelError}},
+	})
+	// Succeeded steps are ignored even when they logged errors
+	runLogs.AddStep(&StepLogs{
+		Workflow: "build.yml", RunID: 1, JobName: "build", StepName: "Lint", Conclusion: github.ConclusionSuccess,
+		Entries: []LogEntry{{Timestamp: at(0), Content: "retrying", Level: LogLevelError}},
+	})
+
+	failures := Triage(runLogs, 2)
+
+	if len(failures) != 2 {
+		t.Fatalf("got %d failures, want 2: %+v", len(failures), failures)
+	}
+
+	first := failures[0]
+	if first.StepName != "Test" || first.Line.Content != "FAIL TestLogin (0.12s)" || first.Occurrences != 2 || first.ExitCode != -1 {
+		t.Errorf("unexpected first failure %+v", first)
+	}
+
+	if got := joinContents(first.Before); got != "line 2,line 3" {
+		t.Errorf("Before: got %v", got)
+	}
+
+	if got := joinContents(first.After); got != "after 1,FAIL TestLogout (0.40s)" {
+		t.Errorf("After: got %v", got)
+	}
+
+	if second := failures[1]; second.StepName != "Apply" || second.ExitCode != 1 || len(second.After) != 0 {
+		t.Errorf("unexpected second failure %+v", second)
+	}
+}
+
+func TestTriage_ExitCodeBreaksTies(t *testing.T) {
+	runLogs := NewRunLogs("", "")
+	runLogs.AddStep(&StepLogs{StepName: "a", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{{Content: "connection reset", Level: LogLevelError}}})
+	runLogs.AddStep(&StepLogs{StepName: "b", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{{Content: "make: *** [test] exit status 2", Level: LogLevelError}}})
+
+	failures := Triage(runLogs, DefaultTriageContext)
+
+	if len(failures) != 2 || failures[0].StepName != "b" || failures[0].ExitCode != 2 {
+		t.Errorf("expected the exit code line first, got %+v", failures)
+	}
+}
+
+func TestTriage_SameExitLineDifferentCauses(t *testing.T) {
+	runLogs := NewRunLogs("", "")
+	runLogs.AddStep(&StepLogs{Workflow: "test.yml", StepName: "Test", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{
+			{Content: "FAIL: TestFoo", Level: LogLevelInfo},
+			{Content: "##[error]Process completed with exit code 1.", Level: LogLevelError},
+		}})
+	runLogs.AddStep(&StepLogs{Workflow: "deploy.yml", StepName: "Deploy", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{
+			{Content: "panic: nil map write", Level: LogLevelInfo},
+			{Content: "##[error]Process completed with exit code 1.", Level: LogLevelError},
+		}})
+	// The specific error line is preferred over the exit code line after it
+	runLogs.AddStep(&StepLogs{Workflow: "lint.yml", StepName: "Lint", Conclusion: github.ConclusionFailure,
+		Entries: []LogEntry{
+			{Content: "main.go:3: undefined: foo", Level: LogLevelError},
+			{Content: "##[error]Process completed with exit code 2.", Level: LogLevelError},
+		}})
+
+	failures := Triage(runLogs, DefaultTriageContext)
+
+	if len(failures) != 3 {
+		t.Fatalf("got %d failures, want 3: %+v", len(failures), failures)
+	}
+
+	for _, f := range failures {
+		if f.Occurrences != 1 {
+			t.Errorf("%s merged with another failure: %+v", f.Workflow, f)
+		}
+	}
+
+	if lint := failures[2]; lint.Line.Content != "main.go:3: undefined: foo" || lint.ExitCode != 2 {
+		t.Errorf("unexpected lint failure %+v", lint)
+	}
+}
+
+func TestFormatTriage(t *testing.T) {
+	failures := []*Failure{
+		{
+			Workflow: "build.yml", RunID: 1, JobName: "build", StepName: "Test",
+			Line:        LogEntry{Content: "undefined: foo", Annotation: &Annotation{File: "main.go", Line: 3}},
+			Before:      []LogEntry{{Content: "go vet ./..."}},
+			ExitCode:    -1,
+			Occurrences: 2,
+		},
+		{Workflow: "deploy.yml", StepName: "Apply", Line: LogEntry{Content: "Process completed with exit code 1."}, ExitCode: 1, Occurrences: 1},
+	}
+
+	want := "Likely root cause first:\n" +
+		"1. build.yml / build / Test (run #1) [in 2 steps]\n" +
+		"     go vet ./...\n" +
+		"   > main.go:3: undefined: foo\n" +
+		"(1 more)"
+
+	if got := FormatTriage(failures, 1); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := FormatTriage(failures, 0); !strings.Contains(got, "2. deploy.yml / Apply [exit code 1]\n   > Process completed with exit code 1.") {
+		t.Errorf("unexpected summary:\n%s", got)
+	}
+
+	if FormatTriage(nil, 0) != "" {
+		t.Error("expected no summary without failures")
+	}
+}
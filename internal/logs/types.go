@@ -7,10 +7,16 @@ import (
 
 // LogEntry represents a single log line with metadata.
 type LogEntry struct {
-	Timestamp time.Time
-	Content   string
-	Level     LogLevel // error, warning, info, debug
-	StepName  string   // for grouping
+	Timestamp time.Time // zero when the log line carried none
+	Content   string    // message text, without timestamp, markers or ANSI codes
+	Level     LogLevel  // error, warning, info, debug
+	StepName  string    // for grouping
+	JobName   string    `json:",omitempty"`
+
+	Spans      []Span      `json:",omitempty"` // ANSI-styled segments of Content, nil when unstyled
+	Annotation *Annotation `json:",omitempty"` // file and line of an ::error/::warning command
+	Group      bool        `json:",omitempty"` // Content is the title of a collapsible group
+	Depth      int         `json:",omitempty"` // number of enclosing groups
 }
 
 // LogLevel indicates the severity of a log line.
@@ -38,11 +44,21 @@ type StepLogs struct {
 	Error      error
 }
 
+// RunInfo describes a workflow run whose logs are part of a RunLogs.
+type RunInfo struct {
+	RunID      int64
+	Workflow   string
+	URL        string
+	Conclusion string
+	Inputs     map[string]string
+}
+
 // RunLogs contains logs for all steps in a workflow run or chain.
 type RunLogs struct {
 	ChainName string
 	Branch    string
 	Steps     []*StepLogs
+	Runs      []RunInfo
 	mu        sync.RWMutex
 }
 
@@ -62,6 +78,27 @@ func (rl *RunLogs) AddStep(stepLogs *StepLogs) {
 	rl.Steps = append(rl.Steps, stepLogs)
 }
 
+// AddRun records the run that steps with its RunID belong to.
+func (rl *RunLogs) AddRun(run RunInfo) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.Runs = append(rl.Runs, run)
+}
+
+// Run returns the recorded run with the given ID.
+func (rl *RunLogs) Run(runID int64) (RunInfo, bool) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	for _, run := range rl.Runs {
+		if run.RunID == runID {
+			return run, true
+		}
+	}
+
+	return RunInfo{}, false
+}
+
 // GetStep returns step logs by index.
 func (rl *RunLogs) GetStep(idx int) *StepLogs {
 	rl.mu.RLock()
@@ -100,7 +137,14 @@ type FilterConfig struct {
 	SearchTerm    string
 	CaseSensitive bool
 	Regex         bool
-	StepIndex     int // -1 for all steps
+	StepIndex     int    // -1 for all steps
+	StepName      string // only steps with this name or workflow; empty for all
+}
+
+// FilterPreset is a named filter configuration the logs viewer cycles through.
+type FilterPreset struct {
+	Name   string
+	Config FilterConfig
 }
 
 // NewFilterConfig creates a default filter config.
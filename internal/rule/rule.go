@@ -2,9 +2,12 @@
 package rule
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -19,17 +22,71 @@ const (
 	RulePrefix
 	RuleSuffix
 	RuleLength
+	RuleSource
+	RuleOneOf
+	RuleSemver
+	RuleFloatRange
+	RuleURL
+	RuleJSON
+	RuleNotRegex
+	RuleRequires
+)
+
+// Option sources of a RuleSource rule, named by lazydispatch:source: comments.
+const (
+	SourceTags         = "tags"
+	SourceBranches     = "branches"
+	SourceReleases     = "releases"
+	SourcePRs          = "prs"
+	SourceEnvironments = "environments"
+	SourceCommand      = "command" // Pattern holds the shell command
 )
 
 // ValidationRule represents a single validation rule parsed from YAML comments.
+// A RuleSource rule does not validate the value; it names where the input's
+// options are listed from.
 type ValidationRule struct {
-	Type    RuleType
-	Pattern string
-	Min     int
-	Max     int
+	Type     RuleType
+	Pattern  string
+	Min      int
+	Max      int
+	MinFloat float64
+	MaxFloat float64
+	Values   []string
+	Source   string
+	When     *Condition // set for when: rules, which only apply while it holds
+}
+
+// Condition is the condition of a when: rule, e.g. environment=prod.
+type Condition struct {
+	Input  string
+	Value  string
+	Negate bool // environment!=prod
+}
+
+func (c Condition) String() string {
+	if c.Negate {
+		return c.Input + "!=" + c.Value
+	}
+
+	return c.Input + "=" + c.Value
 }
 
-const validationPrefix = "lazydispatch:validate:"
+func (c Condition) holds(inputs map[string]string) bool {
+	return (inputs[c.Input] == c.Value) != c.Negate
+}
+
+// Context is what rules that look beyond the value are evaluated against.
+type Context struct {
+	Inputs       map[string]string // values of all inputs, by name
+	LatestTag    string            // for semver constraints on latest; empty when no tag is a version
+	LatestTagErr error             // why the tags could not be listed, reported by those constraints
+}
+
+const (
+	validationPrefix = "lazydispatch:validate:"
+	sourcePrefix     = "lazydispatch:source:"
+)
 
 // ParseValidationComment parses a single comment line for validation rules.
 // Returns nil if the comment doesn't contain a validation rule.
@@ -38,12 +95,18 @@ func ParseValidationComment(comment string) (*ValidationRule, error) {
 	comment = strings.TrimPrefix(comment, "#")
 	comment = strings.TrimSpace(comment)
 
+	if spec, ok := strings.CutPrefix(comment, sourcePrefix); ok {
+		return parseSource(spec)
+	}
+
 	if !strings.HasPrefix(comment, validationPrefix) {
 		return nil, nil
 	}
 
-	ruleSpec := strings.TrimPrefix(comment, validationPrefix)
+	return parseRule(strings.TrimPrefix(comment, validationPrefix))
+}
 
+func parseRule(ruleSpec string) (*ValidationRule, error) {
 	parts := strings.SplitN(ruleSpec, ":", 2)
 	if len(parts) == 0 {
 		return nil, nil
@@ -69,12 +132,17 @@ func ParseValidationComment(comment string) (*ValidationRule, error) {
 		return &ValidationRule{Type: RuleRegex, Pattern: ruleValue}, nil
 
 	case "range":
-		minVal, maxVal, err := parseRange(ruleValue)
+		if minVal, maxVal, err := parseRange(ruleValue); err == nil {
+			return &ValidationRule{Type: RuleRange, Min: minVal, Max: maxVal}, nil
+		}
+
+		// Bounds that are not integers, such as 0.5 or 1e-3, make a float range
+		minVal, maxVal, err := parseFloatRange(ruleValue)
 		if err != nil {
 			return nil, fmt.Errorf("invalid range: %w", err)
 		}
 
-		return &ValidationRule{Type: RuleRange, Min: minVal, Max: maxVal}, nil
+		return &ValidationRule{Type: RuleFloatRange, MinFloat: minVal, MaxFloat: maxVal}, nil
 
 	case "required":
 		return &ValidationRule{Type: RuleRequired}, nil
@@ -101,11 +169,129 @@ func ParseValidationComment(comment string) (*ValidationRule, error) {
 
 		return &ValidationRule{Type: RuleLength, Min: minVal, Max: maxVal}, nil
 
+	case "oneof":
+		var values []string
+
+		for _, v := range strings.Split(ruleValue, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) == 0 {
+			return nil, errors.New("oneof rule requires values")
+		}
+
+		return &ValidationRule{Type: RuleOneOf, Values: values}, nil
+
+	case "semver":
+		if _, err := parseConstraints(ruleValue); err != nil {
+			return nil, fmt.Errorf("invalid semver constraint: %w", err)
+		}
+
+		return &ValidationRule{Type: RuleSemver, Pattern: strings.TrimSpace(ruleValue)}, nil
+
+	case "url":
+		return &ValidationRule{Type: RuleURL}, nil
+
+	case "json":
+		return &ValidationRule{Type: RuleJSON}, nil
+
+	case "not":
+		if ruleValue == "" {
+			return nil, errors.New("not rule requires a pattern")
+		}
+
+		if _, err := regexp.Compile(ruleValue); err != nil {
+			return nil, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+
+		return &ValidationRule{Type: RuleNotRegex, Pattern: ruleValue}, nil
+
+	case "requires":
+		if strings.TrimSpace(ruleValue) == "" {
+			return nil, errors.New("requires rule requires an input name")
+		}
+
+		return &ValidationRule{Type: RuleRequires, Pattern: strings.TrimSpace(ruleValue)}, nil
+
+	case "when":
+		return parseWhen(ruleValue)
+
 	default:
 		return nil, nil
 	}
 }
 
+// parseWhen parses the rest of a when: rule, a condition followed by the rule it
+// applies, e.g. environment=prod:required.
+func parseWhen(spec string) (*ValidationRule, error) {
+	condSpec, ruleSpec, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, errors.New("when rule requires a condition and a rule, e.g. when:environment=prod:required")
+	}
+
+	var cond Condition
+
+	if input, value, ok := strings.Cut(condSpec, "!="); ok {
+		cond = Condition{Input: input, Value: value, Negate: true}
+	} else if input, value, ok := strings.Cut(condSpec, "="); ok {
+		cond = Condition{Input: input, Value: value}
+	}
+
+	cond.Input = strings.TrimSpace(cond.Input)
+	cond.Value = strings.TrimSpace(cond.Value)
+
+	if cond.Input == "" {
+		return nil, fmt.Errorf("invalid when condition %q, expected input=value", condSpec)
+	}
+
+	r, err := parseRule(ruleSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if r == nil || r.When != nil {
+		return nil, fmt.Errorf("when rule requires a rule to apply, got %q", ruleSpec)
+	}
+
+	r.When = &cond
+
+	return r, nil
+}
+
+func parseSource(spec string) (*ValidationRule, error) {
+	source, command, _ := strings.Cut(spec, ":")
+
+	switch source {
+	case SourceTags, SourceBranches, SourceReleases, SourcePRs, SourceEnvironments:
+		return &ValidationRule{Type: RuleSource, Source: source}, nil
+
+	case SourceCommand:
+		command = strings.TrimSpace(command)
+		if command == "" {
+			return nil, errors.New("command source requires a shell command")
+		}
+
+		return &ValidationRule{Type: RuleSource, Source: source, Pattern: command}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown source %q (expected %s, %s, %s, %s, %s or %s)", source,
+			SourceTags, SourceBranches, SourceReleases, SourcePRs, SourceEnvironments, SourceCommand)
+	}
+}
+
+// FindSource returns the first RuleSource rule, if any.
+func FindSource(rules []ValidationRule) (ValidationRule, bool) {
+	for _, r := range rules {
+		if r.Type == RuleSource {
+			return r, true
+		}
+	}
+
+	return ValidationRule{}, false
+}
+
 // ParseValidationComments parses multiple comment lines and returns all valid rules.
 func ParseValidationComments(comments []string) ([]ValidationRule, error) {
 	var rules []ValidationRule
@@ -126,19 +312,38 @@ func ParseValidationComments(comments []string) ([]ValidationRule, error) {
 
 // ValidateValue validates a value against a set of rules.
 // Returns a slice of error messages for any failed validations.
+// Rules that depend on other inputs or the latest tag are skipped; see ValidateInput.
 func ValidateValue(value string, rules []ValidationRule) []string {
+	return ValidateInput(value, rules, nil)
+}
+
+// ValidateInput validates the value of an input against its rules, evaluating when:
+// and requires: rules against the other inputs in ctx.
+// Returns a slice of error messages for any failed validations.
+func ValidateInput(value string, rules []ValidationRule, ctx *Context) []string {
 	var validationErrs []string
 
 	for _, r := range rules {
-		if errMsg := validateRule(value, r); errMsg != "" {
-			validationErrs = append(validationErrs, errMsg)
+		if r.When != nil && (ctx == nil || !r.When.holds(ctx.Inputs)) {
+			continue
+		}
+
+		errMsg := validateRule(value, r, ctx)
+		if errMsg == "" {
+			continue
+		}
+
+		if r.When != nil {
+			errMsg += " when " + r.When.String()
 		}
+
+		validationErrs = append(validationErrs, errMsg)
 	}
 
 	return validationErrs
 }
 
-func validateRule(value string, r ValidationRule) string {
+func validateRule(value string, r ValidationRule, ctx *Context) string {
 	switch r.Type {
 	case RuleRequired:
 		if strings.TrimSpace(value) == "" {
@@ -184,15 +389,117 @@ func validateRule(value string, r ValidationRule) string {
 		if length < r.Min || length > r.Max {
 			return fmt.Sprintf("length must be between %d and %d", r.Min, r.Max)
 		}
+
+	case RuleFloatRange:
+		if value == "" {
+			return ""
+		}
+
+		num, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "must be a number"
+		}
+
+		if num < r.MinFloat || num > r.MaxFloat {
+			return fmt.Sprintf("must be between %g and %g", r.MinFloat, r.MaxFloat)
+		}
+
+	case RuleOneOf:
+		if value != "" && !slices.Contains(r.Values, value) {
+			return "must be one of: " + strings.Join(r.Values, ", ")
+		}
+
+	case RuleSemver:
+		if value != "" {
+			return validateSemver(value, r, ctx)
+		}
+
+	case RuleURL:
+		if value == "" {
+			return ""
+		}
+
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a URL, e.g. https://example.com"
+		}
+
+	case RuleJSON:
+		if value != "" && !json.Valid([]byte(value)) {
+			return "must be valid JSON"
+		}
+
+	case RuleNotRegex:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return "invalid regex pattern: " + r.Pattern
+		}
+
+		if re.MatchString(value) {
+			return "must not match pattern: " + r.Pattern
+		}
+
+	case RuleRequires:
+		if ctx != nil && isSet(value) && !isSet(ctx.Inputs[r.Pattern]) {
+			return "requires " + r.Pattern + " to be set"
+		}
 	}
 
 	return ""
 }
 
+// isSet reports whether an input has a value for requires: rules; a boolean set to
+// false counts as unset.
+func isSet(value string) bool {
+	value = strings.TrimSpace(value)
+	return value != "" && value != "false"
+}
+
+// splitRange splits min-max at the dash between the bounds, so either may be negative,
+// as in -0.5-1.5 or -10--1, or have an exponent, as in 1e-3-1.
+func splitRange(s string) ([]string, error) {
+	for i := 1; i < len(s); i++ {
+		if s[i] != '-' {
+			continue
+		}
+
+		prev := strings.TrimRight(s[:i], " ")
+		if prev == "" || !strings.ContainsAny(prev[len(prev)-1:], "0123456789.") {
+			continue
+		}
+
+		return []string{s[:i], s[i+1:]}, nil
+	}
+
+	return nil, errors.New("expected format: min-max")
+}
+
+func parseFloatRange(s string) (float64, float64, error) {
+	parts, err := splitRange(s)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	minVal, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid min value: %w", err)
+	}
+
+	maxVal, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid max value: %w", err)
+	}
+
+	if minVal > maxVal {
+		return 0, 0, errors.New("min must be less than or equal to max")
+	}
+
+	return minVal, maxVal, nil
+}
+
 func parseRange(s string) (int, int, error) {
-	parts := strings.Split(s, "-")
-	if len(parts) != 2 {
-		return 0, 0, errors.New("expected format: min-max")
+	parts, err := splitRange(s)
+	if err != nil {
+		return 0, 0, err
 	}
 
 	minVal, err := strconv.Atoi(strings.TrimSpace(parts[0]))
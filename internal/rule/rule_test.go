@@ -1,6 +1,9 @@
 package rule
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -86,6 +89,31 @@ func TestParseValidationComment(t *testing.T) {
 			comment:  "# lazydispatch:validate:unknown:value",
 			wantRule: nil,
 		},
+		{
+			name:     "tags source",
+			comment:  "# lazydispatch:source:tags",
+			wantRule: &ValidationRule{Type: RuleSource, Source: SourceTags},
+		},
+		{
+			name:     "prs source",
+			comment:  "# lazydispatch:source:prs",
+			wantRule: &ValidationRule{Type: RuleSource, Source: SourcePRs},
+		},
+		{
+			name:     "command source keeps colons in the command",
+			comment:  "# lazydispatch:source:command:ls deploy/ | sed 's/:.*//'",
+			wantRule: &ValidationRule{Type: RuleSource, Source: SourceCommand, Pattern: "ls deploy/ | sed 's/:.*//'"},
+		},
+		{
+			name:      "command source without a command",
+			comment:   "# lazydispatch:source:command:",
+			wantError: true,
+		},
+		{
+			name:      "unknown source",
+			comment:   "# lazydispatch:source:tag",
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -133,6 +161,73 @@ func TestParseValidationComment(t *testing.T) {
 			if rule.Max != tt.wantRule.Max {
 				t.Errorf("max = %d, want %d", rule.Max, tt.wantRule.Max)
 			}
+
+			if rule.Source != tt.wantRule.Source {
+				t.Errorf("source = %q, want %q", rule.Source, tt.wantRule.Source)
+			}
+		})
+	}
+}
+
+func TestParseValidationComment_Extended(t *testing.T) {
+	tests := []struct {
+		comment   string
+		wantRule  *ValidationRule
+		wantError bool
+	}{
+		{comment: "# lazydispatch:validate:oneof:a, b,c", wantRule: &ValidationRule{Type: RuleOneOf, Values: []string{"a", "b", "c"}}},
+		{comment: "# lazydispatch:validate:oneof:", wantError: true},
+		{comment: "# lazydispatch:validate:semver", wantRule: &ValidationRule{Type: RuleSemver}},
+		{comment: "# lazydispatch:validate:semver:>=1.2.0,<2.0.0", wantRule: &ValidationRule{Type: RuleSemver, Pattern: ">=1.2.0,<2.0.0"}},
+		{comment: "# lazydispatch:validate:semver:>latest", wantRule: &ValidationRule{Type: RuleSemver, Pattern: ">latest"}},
+		{comment: "# lazydispatch:validate:semver:>=1.2", wantError: true},
+		{comment: "# lazydispatch:validate:range:0.5-2.5", wantRule: &ValidationRule{Type: RuleFloatRange, MinFloat: 0.5, MaxFloat: 2.5}},
+		{comment: "# lazydispatch:validate:range:2.5-0.5", wantError: true},
+		{comment: "# lazydispatch:validate:range:-0.5-1.5", wantRule: &ValidationRule{Type: RuleFloatRange, MinFloat: -0.5, MaxFloat: 1.5}},
+		{comment: "# lazydispatch:validate:range:-2.5--0.5", wantRule: &ValidationRule{Type: RuleFloatRange, MinFloat: -2.5, MaxFloat: -0.5}},
+		{comment: "# lazydispatch:validate:range:1e-3-0.5", wantRule: &ValidationRule{Type: RuleFloatRange, MinFloat: 0.001, MaxFloat: 0.5}},
+		{comment: "# lazydispatch:validate:range:1e-3-1", wantRule: &ValidationRule{Type: RuleFloatRange, MinFloat: 0.001, MaxFloat: 1}},
+		{comment: "# lazydispatch:validate:range:-10--1", wantRule: &ValidationRule{Type: RuleRange, Min: -10, Max: -1}},
+		{comment: "# lazydispatch:validate:range:-0.5", wantError: true},
+		{comment: "# lazydispatch:validate:url", wantRule: &ValidationRule{Type: RuleURL}},
+		{comment: "# lazydispatch:validate:json", wantRule: &ValidationRule{Type: RuleJSON}},
+		{comment: "# lazydispatch:validate:not:^main$", wantRule: &ValidationRule{Type: RuleNotRegex, Pattern: "^main$"}},
+		{comment: "# lazydispatch:validate:not:[", wantError: true},
+		{comment: "# lazydispatch:validate:requires:region", wantRule: &ValidationRule{Type: RuleRequires, Pattern: "region"}},
+		{comment: "# lazydispatch:validate:requires:", wantError: true},
+		{
+			comment:  "# lazydispatch:validate:when:environment=prod:required",
+			wantRule: &ValidationRule{Type: RuleRequired, When: &Condition{Input: "environment", Value: "prod"}},
+		},
+		{
+			comment:  "# lazydispatch:validate:when:environment!=dev:regex:^v\\d",
+			wantRule: &ValidationRule{Type: RuleRegex, Pattern: "^v\\d", When: &Condition{Input: "environment", Value: "dev", Negate: true}},
+		},
+		{comment: "# lazydispatch:validate:when:environment=prod", wantError: true},
+		{comment: "# lazydispatch:validate:when:=prod:required", wantError: true},
+		{comment: "# lazydispatch:validate:when:environment=prod:unknown", wantError: true},
+		{comment: "# lazydispatch:validate:when:a=1:when:b=2:required", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			rule, err := ParseValidationComment(tt.comment)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", rule)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(rule, tt.wantRule) {
+				t.Errorf("got %+v, want %+v", rule, tt.wantRule)
+			}
 		})
 	}
 }
@@ -162,6 +257,22 @@ func TestParseValidationComments(t *testing.T) {
 	}
 }
 
+func TestFindSource(t *testing.T) {
+	rules := []ValidationRule{
+		{Type: RuleRequired},
+		{Type: RuleSource, Source: SourceReleases},
+		{Type: RuleSource, Source: SourceTags},
+	}
+
+	if source, ok := FindSource(rules); !ok || source.Source != SourceReleases {
+		t.Errorf("FindSource = %+v, %v; want the releases source", source, ok)
+	}
+
+	if _, ok := FindSource(rules[:1]); ok {
+		t.Error("expected no source")
+	}
+}
+
 func TestValidateValue(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -175,6 +286,12 @@ func TestValidateValue(t *testing.T) {
 			rules:      nil,
 			wantErrors: 0,
 		},
+		{
+			name:       "source rules do not validate",
+			value:      "",
+			rules:      []ValidationRule{{Type: RuleSource, Source: SourceTags}},
+			wantErrors: 0,
+		},
 		{
 			name:       "required with value",
 			value:      "something",
@@ -336,6 +453,12 @@ func TestParseRange(t *testing.T) {
 			wantMin: 1,
 			wantMax: 100,
 		},
+		{
+			name:    "negative bounds",
+			input:   "-10--1",
+			wantMin: -10,
+			wantMax: -1,
+		},
 		{
 			name:      "invalid format",
 			input:     "100",
@@ -385,3 +508,90 @@ func TestParseRange(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateInput(t *testing.T) {
+	whenProdRequired := ValidationRule{Type: RuleRequired, When: &Condition{Input: "environment", Value: "prod"}}
+
+	tests := []struct {
+		name   string
+		value  string
+		rules  []ValidationRule
+		inputs map[string]string
+		want   []string
+	}{
+		{name: "oneof match", value: "b", rules: []ValidationRule{{Type: RuleOneOf, Values: []string{"a", "b"}}}},
+		{name: "oneof miss", value: "c", rules: []ValidationRule{{Type: RuleOneOf, Values: []string{"a", "b"}}}, want: []string{"must be one of: a, b"}},
+		{name: "semver", value: "v1.2.3", rules: []ValidationRule{{Type: RuleSemver}}},
+		{name: "not semver", value: "1.2", rules: []ValidationRule{{Type: RuleSemver}}, want: []string{"must be a semantic version, e.g. 1.2.3"}},
+		{name: "semver constraint", value: "2.0.0", rules: []ValidationRule{{Type: RuleSemver, Pattern: ">=1.2.0,<2.0.0"}}, want: []string{"must be <2.0.0"}},
+		{name: "float range", value: "0.75", rules: []ValidationRule{{Type: RuleFloatRange, MinFloat: 0.5, MaxFloat: 1}}},
+		{name: "float range miss", value: "1.5", rules: []ValidationRule{{Type: RuleFloatRange, MinFloat: 0.5, MaxFloat: 1}}, want: []string{"must be between 0.5 and 1"}},
+		{name: "url", value: "https://example.com/hook", rules: []ValidationRule{{Type: RuleURL}}},
+		{name: "not a url", value: "example.com", rules: []ValidationRule{{Type: RuleURL}}, want: []string{"must be a URL, e.g. https://example.com"}},
+		{name: "json", value: `{"replicas": 3}`, rules: []ValidationRule{{Type: RuleJSON}}},
+		{name: "invalid json", value: `{replicas: 3}`, rules: []ValidationRule{{Type: RuleJSON}}, want: []string{"must be valid JSON"}},
+		{name: "not regex", value: "main", rules: []ValidationRule{{Type: RuleNotRegex, Pattern: "^main$"}}, want: []string{"must not match pattern: ^main$"}},
+		{
+			name:   "requires other input",
+			value:  "true",
+			rules:  []ValidationRule{{Type: RuleRequires, Pattern: "region"}},
+			inputs: map[string]string{"region": ""},
+			want:   []string{"requires region to be set"},
+		},
+		{
+			name:   "requires is satisfied while unset",
+			value:  "false",
+			rules:  []ValidationRule{{Type: RuleRequires, Pattern: "region"}},
+			inputs: map[string]string{"region": ""},
+		},
+		{
+			name:   "when condition holds",
+			rules:  []ValidationRule{whenProdRequired},
+			inputs: map[string]string{"environment": "prod"},
+			want:   []string{"value is required when environment=prod"},
+		},
+		{
+			name:   "when condition does not hold",
+			rules:  []ValidationRule{whenProdRequired},
+			inputs: map[string]string{"environment": "staging"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateInput(tt.value, tt.rules, &Context{Inputs: tt.inputs})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Without a context, rules that look at other inputs are skipped
+	if errs := ValidateValue("", []ValidationRule{whenProdRequired, {Type: RuleRequires, Pattern: "region"}}); len(errs) != 0 {
+		t.Errorf("ValidateValue without context: %v", errs)
+	}
+}
+
+func TestValidateInput_SemverLatest(t *testing.T) {
+	rules := []ValidationRule{{Type: RuleSemver, Pattern: ">latest"}}
+
+	if errs := ValidateInput("v1.4.0", rules, &Context{LatestTag: "v1.4.0"}); len(errs) != 1 || !strings.Contains(errs[0], "the latest tag v1.4.0") {
+		t.Errorf("expected the latest tag to be named, got %v", errs)
+	}
+
+	if errs := ValidateInput("v1.4.1", rules, &Context{LatestTag: "v1.4.0"}); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	if errs := ValidateInput("v0.1.0", rules, &Context{LatestTagErr: errors.New("failed to list tags")}); len(errs) != 1 || !strings.Contains(errs[0], "failed to list tags") {
+		t.Errorf("expected the unresolved latest tag to be reported, got %v", errs)
+	}
+
+	if errs := ValidateInput("v0.1.0", rules, &Context{}); len(errs) != 0 {
+		t.Errorf("expected the constraint to pass without a version tag, got %v", errs)
+	}
+
+	if errs := ValidateValue("v0.1.0", rules); len(errs) != 0 {
+		t.Errorf("expected the constraint to be skipped without a context, got %v", errs)
+	}
+}
@@ -0,0 +1,239 @@
+package rule
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LatestTarget is the constraint target of a semver rule that refers to the repository's
+// latest tag, as in semver:>latest.
+const LatestTarget = "latest"
+
+// semverPattern matches MAJOR.MINOR.PATCH with optional pre-release and build metadata,
+// allowing a leading v as used by most tags.
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`)
+
+// version is a parsed semantic version. Build metadata is ignored.
+type version struct {
+	major, minor, patch int
+	pre                 []string
+}
+
+func parseVersion(s string) (version, bool) {
+	match := semverPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return version{}, false
+	}
+
+	var v version
+
+	for i, field := range []*int{&v.major, &v.minor, &v.patch} {
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return version{}, false
+		}
+
+		*field = n
+	}
+
+	if match[4] != "" {
+		v.pre = strings.Split(match[4], ".")
+	}
+
+	return v, true
+}
+
+// compareVersions orders versions by semver precedence: a pre-release sorts before its
+// release, and pre-release identifiers compare numerically when both are numbers.
+func compareVersions(a, b version) int {
+	if c := cmp.Or(cmp.Compare(a.major, b.major), cmp.Compare(a.minor, b.minor), cmp.Compare(a.patch, b.patch)); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+
+	for i := range min(len(a.pre), len(b.pre)) {
+		an, aErr := strconv.Atoi(a.pre[i])
+		bn, bErr := strconv.Atoi(b.pre[i])
+
+		var c int
+
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a.pre[i], b.pre[i])
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(a.pre), len(b.pre))
+}
+
+// constraint is one comparison of a semver rule, such as >=1.2.0 or >latest.
+type constraint struct {
+	op     string
+	target string // a version, or LatestTarget
+}
+
+func (c constraint) String() string {
+	return c.op + c.target
+}
+
+var constraintOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// parseConstraints parses comma-separated constraints, e.g. ">=1.2.0,<2.0.0".
+// A version without an operator must match exactly.
+func parseConstraints(s string) ([]constraint, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var constraints []constraint
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		c := constraint{op: "=", target: part}
+
+		for _, op := range constraintOps {
+			if target, ok := strings.CutPrefix(part, op); ok {
+				c = constraint{op: op, target: strings.TrimSpace(target)}
+				break
+			}
+		}
+
+		if c.target == "" {
+			return nil, errors.New("empty semver constraint")
+		}
+
+		if _, ok := parseVersion(c.target); !ok && c.target != LatestTarget {
+			return nil, fmt.Errorf("%q is not a semantic version", c.target)
+		}
+
+		constraints = append(constraints, c)
+	}
+
+	return constraints, nil
+}
+
+func (c constraint) allows(v, target version) bool {
+	n := compareVersions(v, target)
+
+	switch c.op {
+	case ">=":
+		return n >= 0
+	case "<=":
+		return n <= 0
+	case "!=":
+		return n != 0
+	case ">":
+		return n > 0
+	case "<":
+		return n < 0
+	default:
+		return n == 0
+	}
+}
+
+// LatestVersion returns the highest of the tags that are semantic versions, or "" if
+// none is.
+func LatestVersion(tags []string) string {
+	var (
+		latest    string
+		latestVer version
+	)
+
+	for _, tag := range tags {
+		v, ok := parseVersion(tag)
+		if ok && (latest == "" || compareVersions(v, latestVer) > 0) {
+			latest, latestVer = tag, v
+		}
+	}
+
+	return latest
+}
+
+// UsesLatestTag reports whether any of the rules compares against the latest tag, which
+// then has to be looked up for Context.LatestTag.
+func UsesLatestTag(rules []ValidationRule) bool {
+	for _, r := range rules {
+		if r.Type != RuleSemver {
+			continue
+		}
+
+		constraints, _ := parseConstraints(r.Pattern)
+		for _, c := range constraints {
+			if c.target == LatestTarget {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func validateSemver(value string, r ValidationRule, ctx *Context) string {
+	v, ok := parseVersion(value)
+	if !ok {
+		return "must be a semantic version, e.g. 1.2.3"
+	}
+
+	constraints, err := parseConstraints(r.Pattern)
+	if err != nil {
+		return "invalid semver constraint: " + r.Pattern
+	}
+
+	for _, c := range constraints {
+		target := c.target
+
+		if target == LatestTarget {
+			// Without a context the value is checked on its own; see ValidateValue
+			if ctx == nil {
+				continue
+			}
+
+			if ctx.LatestTagErr != nil {
+				return "cannot compare with the latest tag: " + ctx.LatestTagErr.Error()
+			}
+
+			// Any version is newer than the latest tag of a repository without one
+			if ctx.LatestTag == "" {
+				continue
+			}
+
+			target = ctx.LatestTag
+		}
+
+		targetVer, _ := parseVersion(target)
+		if c.allows(v, targetVer) {
+			continue
+		}
+
+		if c.target == LatestTarget {
+			return fmt.Sprintf("must be %s the latest tag %s", c.op, target)
+		}
+
+		return "must be " + c.String()
+	}
+
+	return ""
+}
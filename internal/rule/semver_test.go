@@ -0,0 +1,62 @@
+package rule
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "v1.2.3", 0},
+		{"1.2.3+build.5", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.beta", "1.0.0-alpha.1", 1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+	}
+
+	for _, tt := range tests {
+		a, okA := parseVersion(tt.a)
+		b, okB := parseVersion(tt.b)
+
+		if !okA || !okB {
+			t.Fatalf("failed to parse %q or %q", tt.a, tt.b)
+		}
+
+		if got := compareVersions(a, b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersion_Rejects(t *testing.T) {
+	for _, s := range []string{"", "1.2", "1.2.3.4", "01.2.3", "v", "latest", "1.2.3-"} {
+		if _, ok := parseVersion(s); ok {
+			t.Errorf("parseVersion(%q) accepted an invalid version", s)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	tags := []string{"nightly", "v1.9.0", "v1.10.0-rc.1", "v1.10.0", "v1.2.0"}
+
+	if got := LatestVersion(tags); got != "v1.10.0" {
+		t.Errorf("LatestVersion() = %q, want v1.10.0", got)
+	}
+
+	if got := LatestVersion([]string{"nightly"}); got != "" {
+		t.Errorf("LatestVersion() = %q, want empty", got)
+	}
+}
+
+func TestUsesLatestTag(t *testing.T) {
+	if !UsesLatestTag([]ValidationRule{{Type: RuleRequired}, {Type: RuleSemver, Pattern: ">=1.0.0, >latest"}}) {
+		t.Error("expected a latest constraint to be found")
+	}
+
+	if UsesLatestTag([]ValidationRule{{Type: RuleSemver, Pattern: ">=1.0.0"}, {Type: RuleRegex, Pattern: "latest"}}) {
+		t.Error("expected no latest constraint")
+	}
+}
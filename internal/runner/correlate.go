@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	apperrors "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+// Dispatch records what is known about a workflow dispatch so the resulting run can be found.
+type Dispatch struct {
+	Workflow      string
+	Branch        string
+	Actor         string
+	DispatchedAt  time.Time
+	CorrelationID string
+}
+
+// CorrelationOptions bounds the polling performed while looking for a dispatched run.
+type CorrelationOptions struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// ClockSkew widens the created>= window to tolerate drift between the local and GitHub clocks.
+	ClockSkew time.Duration
+}
+
+// DefaultCorrelationOptions polls for roughly 30 seconds before giving up.
+var DefaultCorrelationOptions = CorrelationOptions{
+	MaxAttempts:    8,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     8 * time.Second,
+	ClockSkew:      5 * time.Second,
+}
+
+// NewCorrelationID returns a short random identifier that can be passed as a workflow input.
+func NewCorrelationID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
+
+// CorrelateRun polls the API until the run created by d appears.
+// It returns *errors.RunNotFoundError when no run shows up within the attempt budget and
+// *errors.AmbiguousRunError when several runs match and none can be preferred.
+func CorrelateRun(client GitHubClient, d Dispatch, opts CorrelationOptions) (int64, error) {
+	filter := github.RunFilter{
+		Workflow:     d.Workflow,
+		Event:        github.EventWorkflowDispatch,
+		Branch:       d.Branch,
+		Actor:        d.Actor,
+		CreatedAfter: d.DispatchedAt.Add(-opts.ClockSkew),
+		PerPage:      20,
+	}
+
+	attempts := max(opts.MaxAttempts, 1)
+	backoff := opts.InitialBackoff
+
+	var lastErr error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		runs, err := client.ListWorkflowRuns(filter)
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = nil
+
+			candidates := matchRuns(runs, d, opts.ClockSkew)
+			switch {
+			case len(candidates) == 1:
+				return candidates[0].ID, nil
+			case len(candidates) > 1:
+				ids := make([]int64, len(candidates))
+				for i, run := range candidates {
+					ids[i] = run.ID
+				}
+
+				return 0, &apperrors.AmbiguousRunError{Workflow: d.Workflow, Candidates: ids}
+			}
+		}
+
+		if attempt < attempts && backoff > 0 {
+			time.Sleep(backoff)
+
+			backoff *= 2
+			if opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+				backoff = opts.MaxBackoff
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return 0, lastErr
+	}
+
+	return 0, &apperrors.RunNotFoundError{Workflow: d.Workflow, Branch: d.Branch, Attempts: attempts}
+}
+
+// matchRuns applies the filters client-side, since the API treats some of them loosely.
+// When several runs remain, those created strictly after the dispatch are preferred over
+// ones that only fall inside the clock skew window.
+func matchRuns(runs []github.WorkflowRun, d Dispatch, skew time.Duration) []github.WorkflowRun {
+	var (
+		inWindow []github.WorkflowRun
+		strict   []github.WorkflowRun
+	)
+
+	for _, run := range runs {
+		if run.Event != "" && run.Event != github.EventWorkflowDispatch {
+			continue
+		}
+
+		if d.Branch != "" && run.HeadBranch != "" && run.HeadBranch != d.Branch {
+			continue
+		}
+
+		if d.Actor != "" && run.Actor.Login != "" && run.Actor.Login != d.Actor {
+			continue
+		}
+
+		if d.CorrelationID != "" && !strings.Contains(run.Title, d.CorrelationID) {
+			continue
+		}
+
+		if !run.CreatedAt.IsZero() && run.CreatedAt.Before(d.DispatchedAt.Add(-skew)) {
+			continue
+		}
+
+		inWindow = append(inWindow, run)
+
+		if run.CreatedAt.IsZero() || !run.CreatedAt.Before(d.DispatchedAt) {
+			strict = append(strict, run)
+		}
+	}
+
+	if len(inWindow) > 1 && len(strict) > 0 {
+		return strict
+	}
+
+	return inWindow
+}
@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+)
+
+func runsJSON(t *testing.T, runs ...github.WorkflowRun) string {
+	t.Helper()
+
+	data, err := json.Marshal(github.RunsResponse{TotalCount: len(runs), WorkflowRuns: runs})
+	if err != nil {
+		t.Fatalf("marshal runs: %v", err)
+	}
+
+	return string(data)
+}
+
+func TestCorrelateRun(t *testing.T) {
+	dispatchedAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	dispatchRun := func(id int64, created time.Time, branch, actor, title string) github.WorkflowRun {
+		return github.WorkflowRun{
+			ID:         id,
+			Event:      github.EventWorkflowDispatch,
+			HeadBranch: branch,
+			CreatedAt:  created,
+			Actor:      github.Actor{Login: actor},
+			Title:      title,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		dispatch      Dispatch
+		runs          []github.WorkflowRun
+		apiErr        error
+		wantRunID     int64
+		wantNotFound  bool
+		wantAmbiguous bool
+		wantErr       bool
+	}{
+		{
+			name:      "single matching run",
+			dispatch:  Dispatch{Workflow: "deploy.yml", Branch: "main", Actor: "octocat", DispatchedAt: dispatchedAt},
+			runs:      []github.WorkflowRun{dispatchRun(101, dispatchedAt.Add(time.Second), "main", "octocat", "Deploy")},
+			wantRunID: 101,
+		},
+		{
+			name:     "ignores runs by other actors",
+			dispatch: Dispatch{Workflow: "deploy.yml", Branch: "main", Actor: "octocat", DispatchedAt: dispatchedAt},
+			runs: []github.WorkflowRun{
+				dispatchRun(201, dispatchedAt.Add(2*time.Second), "main", "teammate", "Deploy"),
+				dispatchRun(202, dispatchedAt.Add(time.Second), "main", "octocat", "Deploy"),
+			},
+			wantRunID: 202,
+		},
+		{
+			name:     "ignores other branches and events",
+			dispatch: Dispatch{Workflow: "deploy.yml", Branch: "main", DispatchedAt: dispatchedAt},
+			runs: []github.WorkflowRun{
+				dispatchRun(301, dispatchedAt.Add(time.Second), "feature", "octocat", "Deploy"),
+				{ID: 302, Event: "schedule", HeadBranch: "main", CreatedAt: dispatchedAt.Add(time.Second)},
+				dispatchRun(303, dispatchedAt.Add(time.Second), "main", "octocat", "Deploy"),
+			},
+			wantRunID: 303,
+		},
+		{
+			name:     "ignores runs created before dispatch",
+			dispatch: Dispatch{Workflow: "deploy.yml", Branch: "main", DispatchedAt: dispatchedAt},
+			runs: []github.WorkflowRun{
+				dispatchRun(401, dispatchedAt.Add(-time.Minute), "main", "octocat", "Deploy"),
+			},
+			wantNotFound: true,
+		},
+		{
+			name:     "prefers runs after dispatch over runs in skew window",
+			dispatch: Dispatch{Workflow: "deploy.yml", Branch: "main", DispatchedAt: dispatchedAt},
+			runs: []github.WorkflowRun{
+				dispatchRun(501, dispatchedAt.Add(time.Second), "main", "octocat", "Deploy"),
+				dispatchRun(502, dispatchedAt.Add(-2*time.Second), "main", "octocat", "Deploy"),
+			},
+			wantRunID: 501,
+		},
+		{
+			name:     "ambiguous when several runs match",
+			dispatch: Dispatch{Workflow: "deploy.yml", Branch: "main", DispatchedAt: dispatchedAt},
+			runs: []github.WorkflowRun{
+				dispatchRun(601, dispatchedAt.Add(2*time.Second), "main", "octocat", "Deploy"),
+				dispatchRun(602, dispatchedAt.Add(time.Second), "main", "octocat", "Deploy"),
+			},
+			wantAmbiguous: true,
+		},
+		{
+			name: "correlation ID disambiguates",
+			dispatch: Dispatch{
+				Workflow: "deploy.yml", Branch: "main", DispatchedAt: dispatchedAt, CorrelationID: "abc123",
+			},
+			runs: []github.WorkflowRun{
+				dispatchRun(701, dispatchedAt.Add(2*time.Second), "main", "octocat", "Deploy [xyz789]"),
+				dispatchRun(702, dispatchedAt.Add(time.Second), "main", "octocat", "Deploy [abc123]"),
+			},
+			wantRunID: 702,
+		},
+		{
+			name:         "not found after retries",
+			dispatch:     Dispatch{Workflow: "deploy.yml", Branch: "main", DispatchedAt: dispatchedAt},
+			wantNotFound: true,
+		},
+		{
+			name:     "API error is returned",
+			dispatch: Dispatch{Workflow: "deploy.yml", DispatchedAt: dispatchedAt},
+			apiErr:   errors.New("exit status 1"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor()
+			mockExec.AddCommand("gh", []string{"api", "*"}, runsJSON(t, tt.runs...), "", tt.apiErr)
+
+			client, err := github.NewClientWithExecutor("owner/repo", mockExec)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			opts := CorrelationOptions{MaxAttempts: 3, ClockSkew: 5 * time.Second}
+			runID, err := CorrelateRun(client, tt.dispatch, opts)
+
+			var notFound *apperrors.RunNotFoundError
+			if errors.As(err, &notFound) != tt.wantNotFound {
+				t.Errorf("RunNotFoundError: got %v, want %v", err, tt.wantNotFound)
+			}
+
+			var ambiguous *apperrors.AmbiguousRunError
+			if errors.As(err, &ambiguous) != tt.wantAmbiguous {
+				t.Errorf("AmbiguousRunError: got %v, want %v", err, tt.wantAmbiguous)
+			}
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+
+			if runID != tt.wantRunID {
+				t.Errorf("runID = %d, want %d", runID, tt.wantRunID)
+			}
+
+			if tt.wantNotFound && len(mockExec.ExecutedCommands) != opts.MaxAttempts {
+				t.Errorf("polled %d times, want %d", len(mockExec.ExecutedCommands), opts.MaxAttempts)
+			}
+		})
+	}
+}
+
+func TestCorrelateRun_Filters(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "*"}, runsJSON(t), "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+	dispatchedAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	_, _ = CorrelateRun(client, Dispatch{
+		Workflow:     "deploy.yml",
+		Branch:       "main",
+		Actor:        "octocat",
+		DispatchedAt: dispatchedAt,
+	}, CorrelationOptions{MaxAttempts: 1})
+
+	if len(mockExec.ExecutedCommands) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(mockExec.ExecutedCommands))
+	}
+
+	path := mockExec.ExecutedCommands[0].Args[1]
+	for _, want := range []string{
+		"repos/owner/repo/actions/workflows/deploy.yml/runs?",
+		"event=workflow_dispatch",
+		"branch=main",
+		"actor=octocat",
+		"created=%3E%3D2026-01-02T15%3A04%3A05Z",
+	} {
+		if !strings.Contains(path, want) {
+			t.Errorf("path %q missing %q", path, want)
+		}
+	}
+}
+
+func TestExecuteAndGetRunIDWithExecutor_MockExecutor(t *testing.T) {
+	mockExec := exec.NewMockExecutor()
+	mockExec.AddCommand("gh", []string{"api", "user"}, `{"login":"octocat"}`, "", nil)
+	mockExec.AddGHWorkflowRun("deploy.yml", "main", map[string]string{"env": "prod"})
+	mockExec.AddCommand("gh", []string{"api", "*"}, runsJSON(t, github.WorkflowRun{
+		ID:         42,
+		Event:      github.EventWorkflowDispatch,
+		HeadBranch: "main",
+		CreatedAt:  time.Now(),
+		Actor:      github.Actor{Login: "octocat"},
+	}), "", nil)
+
+	client, _ := github.NewClientWithExecutor("owner/repo", mockExec)
+	cfg := RunConfig{
+		Workflow:    "deploy.yml",
+		Branch:      "main",
+		Inputs:      map[string]string{"env": "prod"},
+		Correlation: CorrelationOptions{MaxAttempts: 1, ClockSkew: time.Minute},
+	}
+
+	runID, err := ExecuteAndGetRunIDWithExecutor(cfg, client, defaultCommandExecutor{executor: mockExec})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if runID != 42 {
+		t.Errorf("runID = %d, want 42", runID)
+	}
+}
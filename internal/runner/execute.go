@@ -2,22 +2,44 @@
 package runner
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	execpkg "github.com/kyleking/gh-lazydispatch/internal/exec"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
 )
 
 // RunConfig holds the configuration for running a workflow.
 type RunConfig struct {
+	// Repo is the repository to dispatch in (owner/name); empty uses the current directory's repository.
+	Repo     string
 	Workflow string
 	Branch   string
 	Inputs   map[string]string
 	Watch    bool
+	// CorrelationInput names a workflow input that receives a generated correlation ID.
+	// The workflow should echo it in its run-name so the dispatched run can be identified exactly.
+	CorrelationInput string
+	// Correlation bounds the search for the dispatched run; the zero value uses DefaultCorrelationOptions.
+	Correlation CorrelationOptions
+	// InputTypes maps input names to their workflow_dispatch type (string, boolean, number, ...).
+	// Used to send typed JSON values when dispatching through the REST API.
+	InputTypes map[string]string
 }
 
+// ErrRunNotIdentified is returned by ExecuteAndGetRunID when the workflow was dispatched
+// but the run it started could not be found, so the dispatch must not be retried.
+var ErrRunNotIdentified = errors.New("dispatched, run not identified")
+
 // defaultCommandExecutor wraps exec.CommandExecutor for interactive use.
 type defaultCommandExecutor struct {
 	executor execpkg.CommandExecutor
@@ -27,7 +49,7 @@ func (e defaultCommandExecutor) Execute(name string, args ...string) error {
 	// For interactive execution, we want stdout/stderr to go directly to the terminal
 	if e.executor == nil {
 		cmd := exec.Command(name, args...)
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = output
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
 
@@ -42,6 +64,19 @@ func (e defaultCommandExecutor) Execute(name string, args ...string) error {
 
 var executor = defaultCommandExecutor{executor: nil}
 
+// output receives progress messages and the stdout of interactive gh commands.
+var output io.Writer = os.Stdout
+
+// SetOutput redirects progress messages, e.g. to keep stdout clean for machine-readable output.
+// Pass nil to reset to os.Stdout.
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	output = w
+}
+
 // SetExecutor sets the command executor for testing purposes.
 // Pass nil to reset to default behavior.
 func SetExecutor(exec execpkg.CommandExecutor) {
@@ -52,17 +87,67 @@ func SetExecutor(exec execpkg.CommandExecutor) {
 func BuildArgs(cfg RunConfig) []string {
 	args := []string{"workflow", "run", cfg.Workflow}
 
+	if cfg.Repo != "" {
+		args = append(args, "--repo", cfg.Repo)
+	}
+
 	if cfg.Branch != "" {
 		args = append(args, "--ref", cfg.Branch)
 	}
 
+	// Sorted so the same dispatch always builds the same command
+	for _, k := range slices.Sorted(maps.Keys(cfg.Inputs)) {
+		if v := cfg.Inputs[k]; v != "" {
+			args = append(args, "-f", k+"="+formatValue(typedValue(cfg.InputTypes[k], v)))
+		}
+	}
+
+	return args
+}
+
+// TypedInputs converts the string inputs to the JSON types declared in InputTypes.
+// Values that do not parse as their declared type are sent as strings and left for
+// the API to reject.
+func TypedInputs(cfg RunConfig) map[string]any {
+	typed := make(map[string]any, len(cfg.Inputs))
+
 	for k, v := range cfg.Inputs {
 		if v != "" {
-			args = append(args, "-f", k+"="+v)
+			typed[k] = typedValue(cfg.InputTypes[k], v)
 		}
 	}
 
-	return args
+	return typed
+}
+
+// typedValue converts an input value to its declared type: a bool for boolean inputs
+// and a float64 for number inputs. Other values, and ones that do not parse, stay strings.
+func typedValue(inputType, v string) any {
+	switch inputType {
+	case "boolean":
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+			return n
+		}
+	}
+
+	return v
+}
+
+// formatValue formats a typed input value the way GitHub expects it as a string
+// field, e.g. "true" for a boolean given as "True" and "10" for a number given as "1e1".
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
 // FormatCommand returns a human-readable command string.
@@ -94,10 +179,10 @@ func Execute(cfg RunConfig) error {
 func ExecuteWithExecutor(cfg RunConfig, exec CommandExecutor) error {
 	args := BuildArgs(cfg)
 
-	fmt.Println()
-	fmt.Println("Running command:")
-	fmt.Println("  " + FormatCommand(args))
-	fmt.Println()
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Running command:")
+	fmt.Fprintln(output, "  "+FormatCommand(args))
+	fmt.Fprintln(output)
 
 	if err := exec.Execute("gh", args...); err != nil {
 		return fmt.Errorf("gh workflow run failed: %w", err)
@@ -115,9 +200,9 @@ func watchLatestRun(workflow string) error {
 }
 
 func watchLatestRunWithExecutor(_ string, exec CommandExecutor) error {
-	fmt.Println()
-	fmt.Println("Watching run...")
-	fmt.Println()
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Watching run...")
+	fmt.Fprintln(output)
 
 	return exec.Execute("gh", "run", "watch")
 }
@@ -129,31 +214,74 @@ func DryRun(cfg RunConfig) string {
 }
 
 // ExecuteAndGetRunID runs the workflow and returns the run ID for watching.
-// This polls the API shortly after dispatch to find the triggered run.
+// The run is correlated by dispatch time, actor, and ref rather than taking the latest run,
+// so runs started concurrently by others are not picked up by mistake.
 func ExecuteAndGetRunID(cfg RunConfig, client GitHubClient) (int64, error) {
 	return ExecuteAndGetRunIDWithExecutor(cfg, client, executor)
 }
 
 func ExecuteAndGetRunIDWithExecutor(cfg RunConfig, client GitHubClient, exec CommandExecutor) (int64, error) {
-	args := BuildArgs(cfg)
+	dispatch := Dispatch{
+		Workflow: cfg.Workflow,
+		Branch:   cfg.Branch,
+	}
 
-	fmt.Println()
-	fmt.Println("Running command:")
-	fmt.Println("  " + FormatCommand(args))
-	fmt.Println()
+	if actor, err := client.CurrentUser(); err == nil {
+		dispatch.Actor = actor
+	}
 
-	if err := exec.Execute("gh", args...); err != nil {
-		return 0, fmt.Errorf("gh workflow run failed: %w", err)
+	if cfg.CorrelationInput != "" {
+		dispatch.CorrelationID = NewCorrelationID()
+
+		inputs := make(map[string]string, len(cfg.Inputs)+1)
+		for k, v := range cfg.Inputs {
+			inputs[k] = v
+		}
+
+		inputs[cfg.CorrelationInput] = dispatch.CorrelationID
+		cfg.Inputs = inputs
+	}
+
+	dispatch.DispatchedAt = time.Now()
+
+	if err := dispatchWorkflow(cfg, client, exec); err != nil {
+		return 0, err
+	}
+
+	opts := cfg.Correlation
+	if opts == (CorrelationOptions{}) {
+		opts = DefaultCorrelationOptions
 	}
 
-	run, err := client.GetLatestRun(cfg.Workflow)
+	runID, err := CorrelateRun(client, dispatch, opts)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get run ID: %w", err)
+		return 0, fmt.Errorf("%w: %w", ErrRunNotIdentified, err)
+	}
+
+	return runID, nil
+}
+
+// dispatchWorkflow uses the client's REST dispatcher when available and falls back to
+// gh workflow run otherwise. The REST endpoint requires an explicit ref, so dispatches
+// targeting the default branch go through gh, which resolves it.
+func dispatchWorkflow(cfg RunConfig, client GitHubClient, exec CommandExecutor) error {
+	if d, ok := client.(Dispatcher); ok && cfg.Branch != "" {
+		err := d.DispatchWorkflow(cfg.Workflow, cfg.Branch, TypedInputs(cfg))
+		if !errors.Is(err, github.ErrRESTUnavailable) {
+			return err
+		}
 	}
 
-	if run == nil {
-		return 0, fmt.Errorf("no run found for workflow: %s", cfg.Workflow)
+	args := BuildArgs(cfg)
+
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Running command:")
+	fmt.Fprintln(output, "  "+FormatCommand(args))
+	fmt.Fprintln(output)
+
+	if err := exec.Execute("gh", args...); err != nil {
+		return fmt.Errorf("gh workflow run failed: %w", err)
 	}
 
-	return run.ID, nil
+	return nil
 }
@@ -32,6 +32,16 @@ func TestBuildArgs(t *testing.T) {
 			},
 			wantContains: []string{"workflow", "run", "deploy.yml", "--ref", "main"},
 		},
+		{
+			name: "with repo",
+			cfg: RunConfig{
+				Repo:     "acme/api",
+				Workflow: "deploy.yml",
+				Branch:   "main",
+			},
+			wantContains: []string{"workflow", "run", "deploy.yml", "--repo", "acme/api", "--ref", "main"},
+			wantLen:      7,
+		},
 		{
 			name: "with inputs",
 			cfg: RunConfig{
@@ -67,6 +77,27 @@ func TestBuildArgs(t *testing.T) {
 			},
 			wantContains: []string{"workflow", "run", "ci.yml", "--ref", "feature/test", "-f", "env=staging", "-f", "verbose=true"},
 		},
+		{
+			name: "typed inputs",
+			cfg: RunConfig{
+				Workflow: "deploy.yml",
+				Inputs: map[string]string{
+					"dry_run":  "True",
+					"replicas": "03",
+					"ratio":    "2.50",
+					"bad_num":  "three",
+					"version":  "01",
+				},
+				InputTypes: map[string]string{
+					"dry_run":  "boolean",
+					"replicas": "number",
+					"ratio":    "number",
+					"bad_num":  "number",
+					"version":  "string",
+				},
+			},
+			wantContains: []string{"-f bad_num=three -f dry_run=true -f ratio=2.5 -f replicas=3 -f version=01"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -232,12 +263,20 @@ type mockGitHubClient struct {
 	err error
 }
 
-func (m *mockGitHubClient) GetLatestRun(_ string) (*github.WorkflowRun, error) {
+func (m *mockGitHubClient) ListWorkflowRuns(_ github.RunFilter) ([]github.WorkflowRun, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 
-	return m.run, nil
+	if m.run == nil {
+		return nil, nil
+	}
+
+	return []github.WorkflowRun{*m.run}, nil
+}
+
+func (m *mockGitHubClient) CurrentUser() (string, error) {
+	return "octocat", nil
 }
 
 // mockRepositoryDetector is a test double for RepositoryDetector.
@@ -392,7 +431,7 @@ func TestExecuteAndGetRunIDWithExecutor(t *testing.T) {
 			expectRunID:    0,
 		},
 		{
-			name: "GetLatestRun API error",
+			name: "ListWorkflowRuns API error",
 			cfg: RunConfig{
 				Workflow: "ci.yml",
 				Branch:   "feature",
@@ -444,7 +483,10 @@ func TestExecuteAndGetRunIDWithExecutor(t *testing.T) {
 				err: tt.mockRunErr,
 			}
 
-			runID, err := ExecuteAndGetRunIDWithExecutor(tt.cfg, mockClient, mockExec)
+			cfg := tt.cfg
+			cfg.Correlation = CorrelationOptions{MaxAttempts: 2}
+
+			runID, err := ExecuteAndGetRunIDWithExecutor(cfg, mockClient, mockExec)
 
 			if (err != nil) != tt.expectError {
 				t.Errorf("ExecuteAndGetRunIDWithExecutor() error = %v, expectError %v", err, tt.expectError)
@@ -453,6 +495,11 @@ func TestExecuteAndGetRunIDWithExecutor(t *testing.T) {
 			if runID != tt.expectRunID {
 				t.Errorf("ExecuteAndGetRunIDWithExecutor() runID = %d, want %d", runID, tt.expectRunID)
 			}
+
+			// Only a dispatch that went through leaves the run unidentified
+			if notIdentified := tt.expectError && tt.errorOnCommand < 0; errors.Is(err, ErrRunNotIdentified) != notIdentified {
+				t.Errorf("errors.Is(%v, ErrRunNotIdentified) = %v, want %v", err, !notIdentified, notIdentified)
+			}
 		})
 	}
 }
@@ -578,3 +625,93 @@ func TestDetectRepoWithDetector(t *testing.T) {
 		})
 	}
 }
+
+func TestTypedInputs(t *testing.T) {
+	cfg := RunConfig{
+		Inputs: map[string]string{
+			"dry_run":  "true",
+			"replicas": "3",
+			"env":      "prod",
+			"bad_num":  "three",
+			"empty":    "",
+		},
+		InputTypes: map[string]string{
+			"dry_run":  "boolean",
+			"replicas": "number",
+			"env":      "choice",
+			"bad_num":  "number",
+		},
+	}
+
+	typed := TypedInputs(cfg)
+
+	if typed["dry_run"] != true {
+		t.Errorf("dry_run = %#v, want true", typed["dry_run"])
+	}
+
+	if typed["replicas"] != 3.0 {
+		t.Errorf("replicas = %#v, want 3.0", typed["replicas"])
+	}
+
+	if typed["env"] != "prod" || typed["bad_num"] != "three" {
+		t.Errorf("strings not preserved: %#v", typed)
+	}
+
+	if _, ok := typed["empty"]; ok {
+		t.Error("empty input should be omitted")
+	}
+}
+
+// mockDispatchClient is a GitHubClient that also dispatches natively.
+type mockDispatchClient struct {
+	mockGitHubClient
+	dispatched  int
+	dispatchErr error
+}
+
+func (m *mockDispatchClient) DispatchWorkflow(_, _ string, _ map[string]any) error {
+	m.dispatched++
+	return m.dispatchErr
+}
+
+func TestExecuteAndGetRunIDWithExecutor_Dispatcher(t *testing.T) {
+	tests := []struct {
+		name         string
+		branch       string
+		dispatchErr  error
+		wantDispatch int
+		wantExec     int
+		expectError  bool
+	}{
+		{name: "REST dispatch", branch: "main", wantDispatch: 1},
+		{name: "REST unavailable falls back to gh", branch: "main", dispatchErr: github.ErrRESTUnavailable, wantDispatch: 1, wantExec: 1},
+		{name: "REST error is returned", branch: "main", dispatchErr: errors.New("HTTP 422"), wantDispatch: 1, expectError: true},
+		{name: "default branch uses gh", wantExec: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockDispatchClient{
+				mockGitHubClient: mockGitHubClient{run: &github.WorkflowRun{ID: 7}},
+				dispatchErr:      tt.dispatchErr,
+			}
+			mockExec := &mockCommandExecutor{errorOnCommand: -1}
+
+			cfg := RunConfig{Workflow: "ci.yml", Branch: tt.branch, Correlation: CorrelationOptions{MaxAttempts: 1}}
+
+			_, err := ExecuteAndGetRunIDWithExecutor(cfg, client, mockExec)
+
+			if (err != nil) != tt.expectError {
+				t.Errorf("error = %v, expectError %v", err, tt.expectError)
+			}
+
+			if client.dispatched != tt.wantDispatch {
+				t.Errorf("REST dispatches = %d, want %d", client.dispatched, tt.wantDispatch)
+			}
+
+			if len(mockExec.executedCommands) != tt.wantExec {
+				t.Errorf("gh commands = %d, want %d", len(mockExec.executedCommands), tt.wantExec)
+			}
+		})
+	}
+}
@@ -4,5 +4,12 @@ import "github.com/kyleking/gh-lazydispatch/internal/github"
 
 // GitHubClient defines the interface for GitHub API operations needed by the runner.
 type GitHubClient interface {
-	GetLatestRun(workflowName string) (*github.WorkflowRun, error)
+	ListWorkflowRuns(filter github.RunFilter) ([]github.WorkflowRun, error)
+	CurrentUser() (string, error)
+}
+
+// Dispatcher triggers workflows through the REST API.
+// Clients that implement it are preferred over shelling out to gh workflow run.
+type Dispatcher interface {
+	DispatchWorkflow(workflow, ref string, inputs map[string]any) error
 }
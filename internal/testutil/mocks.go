@@ -1,6 +1,9 @@
 package testutil
 
 import (
+	"sync"
+	"time"
+
 	"github.com/kyleking/gh-lazydispatch/internal/github"
 	"github.com/kyleking/gh-lazydispatch/internal/watcher"
 )
@@ -86,13 +89,36 @@ func (m *MockGitHubClient) GetLatestRun(workflow string) (*github.WorkflowRun, e
 	return &github.WorkflowRun{ID: m.LatestID, Status: github.StatusQueued}, nil
 }
 
+func (m *MockGitHubClient) ListWorkflowRuns(filter github.RunFilter) ([]github.WorkflowRun, error) {
+	latest, err := m.GetLatestRun(filter.Workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	latest.Event = github.EventWorkflowDispatch
+	latest.HeadBranch = filter.Branch
+	latest.CreatedAt = time.Now()
+
+	return []github.WorkflowRun{*latest}, nil
+}
+
+func (m *MockGitHubClient) CurrentUser() (string, error) {
+	if m.Err != nil {
+		return "", m.Err
+	}
+
+	return "octocat", nil
+}
+
 func (m *MockGitHubClient) Owner() string { return m.owner }
 func (m *MockGitHubClient) Repo() string  { return m.repo }
 
 // MockRunWatcher implements chain.RunWatcher interface.
+// Watch and Unwatch are safe for concurrent use, as chain steps may run in parallel.
 type MockRunWatcher struct {
 	Watched map[int64]string
 	updates chan watcher.RunUpdate
+	mu      sync.Mutex
 }
 
 // NewMockRunWatcher creates a new MockRunWatcher.
@@ -104,10 +130,16 @@ func NewMockRunWatcher() *MockRunWatcher {
 }
 
 func (m *MockRunWatcher) Watch(runID int64, workflowName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.Watched[runID] = workflowName
 }
 
 func (m *MockRunWatcher) Unwatch(runID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	delete(m.Watched, runID)
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -32,6 +33,7 @@ type resolvedStep struct {
 	Workflow string
 	Inputs   map[string]string
 	Command  string
+	Err      error
 }
 
 // ChainConfirmModal shows the chain configuration and confirms execution.
@@ -42,6 +44,7 @@ type ChainConfirmModal struct {
 	branch        string
 	watchMode     bool
 	resolvedSteps []resolvedStep
+	hasErrors     bool // a step has unresolvable template references; confirming is blocked
 	done          bool
 	result        ChainConfirmResultMsg
 	keys          chainConfirmKeyMap
@@ -67,35 +70,26 @@ func NewChainConfirmModal(chainName string, chainDef *config.Chain, variables ma
 }
 
 func (m *ChainConfirmModal) resolveSteps() {
+	previews := chain.PreviewInputs(m.chain, m.variables)
 	m.resolvedSteps = make([]resolvedStep, len(m.chain.Steps))
 
-	ctx := &chain.InterpolationContext{
-		Var:   m.variables,
-		Steps: make(map[int]*chain.StepResult),
-	}
-
 	for i, step := range m.chain.Steps {
-		inputs, _ := chain.InterpolateInputs(step.Inputs, ctx)
-
 		cfg := runner.RunConfig{
 			Workflow: step.Workflow,
 			Branch:   m.branch,
-			Inputs:   inputs,
+			Inputs:   previews[i].Inputs,
 		}
 		args := runner.BuildArgs(cfg)
 
 		m.resolvedSteps[i] = resolvedStep{
 			Workflow: step.Workflow,
-			Inputs:   inputs,
+			Inputs:   previews[i].Inputs,
 			Command:  runner.FormatCommand(args),
+			Err:      previews[i].Err,
 		}
 
-		ctx.Steps[i] = &chain.StepResult{
-			Workflow: step.Workflow,
-			Inputs:   inputs,
-		}
-		if i > 0 {
-			ctx.Previous = ctx.Steps[i-1]
+		if previews[i].Err != nil {
+			m.hasErrors = true
 		}
 	}
 }
@@ -109,6 +103,10 @@ func (m *ChainConfirmModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 			m.watchMode = !m.watchMode
 			return m, nil
 		case key.Matches(msg, m.keys.Confirm):
+			if m.hasErrors {
+				return m, nil
+			}
+
 			m.done = true
 			m.result = ChainConfirmResultMsg{
 				Confirmed: true,
@@ -202,8 +200,24 @@ func (m *ChainConfirmModal) View() string {
 		s.WriteString(ui.NormalStyle.Render(fmt.Sprintf("  %d. %s ", i+1, step.Workflow)))
 		s.WriteString(ui.TableDimmedStyle.Render(waitLabel))
 		s.WriteString("\n")
+
+		if stepDef.If != "" {
+			s.WriteString(ui.TableDimmedStyle.Render("     if: " + stepDef.If))
+			s.WriteString("\n")
+		}
+
+		if policy := stepPolicy(stepDef); policy != "" {
+			s.WriteString(ui.TableDimmedStyle.Render("     " + policy))
+			s.WriteString("\n")
+		}
+
 		s.WriteString(ui.CLIPreviewStyle.Render("     " + step.Command))
 		s.WriteString("\n")
+
+		if step.Err != nil {
+			s.WriteString(ui.ErrorStyle.Render("     " + step.Err.Error()))
+			s.WriteString("\n")
+		}
 	}
 
 	s.WriteString("\n")
@@ -216,11 +230,32 @@ func (m *ChainConfirmModal) View() string {
 	s.WriteString(ui.NormalStyle.Render("Watch runs: " + watchIndicator))
 	s.WriteString("\n\n")
 
-	s.WriteString(ui.HelpStyle.Render("[enter/y] confirm  [esc/n] cancel  [w] toggle watch"))
+	if m.hasErrors {
+		s.WriteString(ui.ErrorStyle.Render("Fix the template errors above in the chain config to run it."))
+		s.WriteString("\n")
+		s.WriteString(ui.HelpStyle.Render("[esc/n] cancel"))
+	} else {
+		s.WriteString(ui.HelpStyle.Render("[enter/y] confirm  [esc/n] cancel  [w] toggle watch"))
+	}
 
 	return s.String()
 }
 
+// stepPolicy summarizes a step's timeout and retry settings.
+func stepPolicy(step config.ChainStep) string {
+	var parts []string
+
+	if step.Timeout > 0 {
+		parts = append(parts, "timeout: "+time.Duration(step.Timeout).String())
+	}
+
+	if r := step.Retry; r != nil && r.MaxAttempts > 1 {
+		parts = append(parts, fmt.Sprintf("retry: up to %d attempts on %s (%s)", r.MaxAttempts, strings.Join(r.On, ", "), r.Mode))
+	}
+
+	return strings.Join(parts, "  ")
+}
+
 // IsDone returns true if the modal is finished.
 func (m *ChainConfirmModal) IsDone() bool {
 	return m.done
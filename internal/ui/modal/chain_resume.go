@@ -0,0 +1,117 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/ui"
+)
+
+// ChainResumeResultMsg is sent when the user decides what to do with an interrupted chain.
+// Neither Resume nor Discard is set when the decision is postponed.
+type ChainResumeResultMsg struct {
+	Entry   chain.JournalEntry
+	Resume  bool
+	Discard bool
+}
+
+type chainResumeKeyMap struct {
+	Resume  key.Binding
+	Discard key.Binding
+	Later   key.Binding
+}
+
+// ChainResumeModal offers to reattach to a chain execution that was interrupted when lazydispatch exited.
+type ChainResumeModal struct {
+	entry chain.JournalEntry
+	done  bool
+	keys  chainResumeKeyMap
+}
+
+// NewChainResumeModal creates a modal for the persisted chain execution.
+func NewChainResumeModal(entry chain.JournalEntry) *ChainResumeModal {
+	return &ChainResumeModal{
+		entry: entry,
+		keys: chainResumeKeyMap{
+			Resume:  key.NewBinding(key.WithKeys("enter", "r")),
+			Discard: key.NewBinding(key.WithKeys("d")),
+			Later:   key.NewBinding(key.WithKeys("esc", "q")),
+		},
+	}
+}
+
+// Update handles input for the chain resume modal.
+func (m *ChainResumeModal) Update(msg tea.Msg) (Context, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	result := ChainResumeResultMsg{Entry: m.entry}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Resume):
+		result.Resume = true
+	case key.Matches(keyMsg, m.keys.Discard):
+		result.Discard = true
+	case key.Matches(keyMsg, m.keys.Later):
+	default:
+		return m, nil
+	}
+
+	m.done = true
+
+	return m, func() tea.Msg {
+		return result
+	}
+}
+
+// View renders the chain resume modal.
+func (m *ChainResumeModal) View() string {
+	var s strings.Builder
+
+	s.WriteString(ui.TitleStyle.Render("Resume Chain: " + m.entry.ChainName))
+	s.WriteString("\n\n")
+
+	subtitle := fmt.Sprintf("Interrupted %s", m.entry.UpdatedAt.Local().Format(time.DateTime))
+	if m.entry.Branch != "" {
+		subtitle += " on " + m.entry.Branch
+	}
+
+	s.WriteString(ui.SubtitleStyle.Render(subtitle))
+	s.WriteString("\n\n")
+
+	for i, step := range m.entry.Steps {
+		line := fmt.Sprintf("  %s %d. %s", stepStatusIcon(step.Status), i+1, step.Workflow)
+		if step.RunID != 0 {
+			line += fmt.Sprintf(" (run %d)", step.RunID)
+		}
+
+		if i == m.entry.CurrentStep {
+			s.WriteString(ui.SelectedStyle.Render(line))
+		} else {
+			s.WriteString(ui.NormalStyle.Render(line))
+		}
+
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(ui.HelpStyle.Render("[enter/r] resume  [d] discard  [esc] later"))
+
+	return s.String()
+}
+
+// IsDone returns true if the modal is finished.
+func (m *ChainResumeModal) IsDone() bool {
+	return m.done
+}
+
+// Result returns the journaled entry.
+func (m *ChainResumeModal) Result() any {
+	return m.entry
+}
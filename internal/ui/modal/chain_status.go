@@ -10,6 +10,7 @@ import (
 	"github.com/kyleking/gh-lazydispatch/internal/browser"
 	"github.com/kyleking/gh-lazydispatch/internal/chain"
 	chainerr "github.com/kyleking/gh-lazydispatch/internal/errors"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
 	"github.com/kyleking/gh-lazydispatch/internal/ui"
 )
 
@@ -23,11 +24,15 @@ type ChainStatusViewLogsMsg struct {
 	ErrorsOnly bool
 }
 
+// triageViewLimit is the number of triaged failures shown in the modal.
+const triageViewLimit = 3
+
 // ChainStatusModal displays the current status of a chain execution.
 type ChainStatusModal struct {
 	state    chain.ChainState
 	commands []string
 	branch   string
+	triage   []*logs.Failure
 	done     bool
 	stopped  bool
 	copied   bool
@@ -40,6 +45,9 @@ type chainStatusKeyMap struct {
 	Copy        key.Binding
 	ViewLogs    key.Binding
 	OpenBrowser key.Binding
+	CancelRun   key.Binding
+	Rerun       key.Binding
+	RerunFailed key.Binding
 }
 
 func defaultChainStatusKeyMap() chainStatusKeyMap {
@@ -49,6 +57,9 @@ func defaultChainStatusKeyMap() chainStatusKeyMap {
 		Copy:        key.NewBinding(key.WithKeys("c")),
 		ViewLogs:    key.NewBinding(key.WithKeys("l")),
 		OpenBrowser: key.NewBinding(key.WithKeys("o")),
+		CancelRun:   key.NewBinding(key.WithKeys("x")),
+		Rerun:       key.NewBinding(key.WithKeys("R")),
+		RerunFailed: key.NewBinding(key.WithKeys("F")),
 	}
 }
 
@@ -75,6 +86,16 @@ func (m *ChainStatusModal) UpdateState(state chain.ChainState) {
 	m.state = state
 }
 
+// ChainName returns the name of the chain shown in the modal.
+func (m *ChainStatusModal) ChainName() string {
+	return m.state.ChainName
+}
+
+// SetTriage sets the failures found in the chain's logs, most likely root cause first.
+func (m *ChainStatusModal) SetTriage(failures []*logs.Failure) {
+	m.triage = failures
+}
+
 // SetCommands sets the command strings for each step.
 func (m *ChainStatusModal) SetCommands(commands []string, branch string) {
 	m.commands = commands
@@ -119,12 +140,65 @@ func (m *ChainStatusModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 			if url := m.GetFailedStepRunURL(); url != "" {
 				browser.Open(url)
 			}
+		case key.Matches(msg, m.keys.CancelRun):
+			if result := m.activeStepRun(); result != nil {
+				return m.requestRunAction(RunActionCancel, result)
+			}
+		case key.Matches(msg, m.keys.Rerun):
+			if result := m.failedStepRun(); result != nil {
+				return m.requestRunAction(RunActionRerun, result)
+			}
+		case key.Matches(msg, m.keys.RerunFailed):
+			if result := m.failedStepRun(); result != nil {
+				return m.requestRunAction(RunActionRerunFailed, result)
+			}
 		}
 	}
 
 	return m, nil
 }
 
+// requestRunAction closes the modal so the app can ask for confirmation; the chain keeps running.
+func (m *ChainStatusModal) requestRunAction(action RunAction, result *chain.StepResult) (Context, tea.Cmd) {
+	m.done = true
+
+	req := RunActionRequestMsg{Action: action, RunID: result.RunID, Workflow: result.Workflow}
+
+	return m, func() tea.Msg {
+		return req
+	}
+}
+
+// activeStepRun returns the first running step that has a dispatched run.
+func (m *ChainStatusModal) activeStepRun() *chain.StepResult {
+	for i := range m.state.StepStatuses {
+		if !m.isActiveStep(i) {
+			continue
+		}
+
+		if result := m.state.StepResults[i]; result != nil && result.RunID != 0 {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// failedStepRun returns the first failed step that has a run to re-run.
+func (m *ChainStatusModal) failedStepRun() *chain.StepResult {
+	for i, status := range m.state.StepStatuses {
+		if status != chain.StepFailed {
+			continue
+		}
+
+		if result := m.state.StepResults[i]; result != nil && result.RunID != 0 {
+			return result
+		}
+	}
+
+	return nil
+}
+
 func (m *ChainStatusModal) buildBashScript() string {
 	var sb strings.Builder
 
@@ -163,35 +237,10 @@ func (m *ChainStatusModal) View() string {
 	s.WriteString(ui.SubtitleStyle.Render("Steps:"))
 	s.WriteString("\n")
 
-	for i, status := range m.state.StepStatuses {
-		icon := stepStatusIcon(status)
-
-		prefix := "  "
-		if i == m.state.CurrentStep && m.state.Status == chain.ChainRunning {
-			prefix = "> "
-		}
-
-		var stepName string
-		if result, ok := m.state.StepResults[i]; ok {
-			stepName = result.Workflow
-		} else {
-			stepName = fmt.Sprintf("Step %d", i+1)
-		}
-
-		line := fmt.Sprintf("%s%s %s (%s)", prefix, icon, stepName, status)
-
-		if i == m.state.CurrentStep && m.state.Status == chain.ChainRunning {
-			s.WriteString(ui.SelectedStyle.Render(line))
-		} else {
-			s.WriteString(line)
-		}
-
-		s.WriteString("\n")
-
-		if i < len(m.commands) && m.commands[i] != "" {
-			s.WriteString(ui.CLIPreviewStyle.Render("     " + m.commands[i]))
-			s.WriteString("\n")
-		}
+	if m.state.IsSequential() {
+		m.renderStepList(&s)
+	} else {
+		m.renderStepGraph(&s)
 	}
 
 	if m.state.Error != nil {
@@ -214,6 +263,17 @@ func (m *ChainStatusModal) View() string {
 		}
 	}
 
+	if len(m.triage) > 0 {
+		s.WriteString("\n")
+		s.WriteString(ui.SubtitleStyle.Render("Triage:"))
+		s.WriteString("\n")
+
+		for _, line := range strings.Split(logs.FormatTriage(m.triage, triageViewLimit), "\n") {
+			s.WriteString(ui.TableDimmedStyle.Render("  " + line))
+			s.WriteString("\n")
+		}
+	}
+
 	s.WriteString("\n")
 
 	if m.copied {
@@ -223,8 +283,12 @@ func (m *ChainStatusModal) View() string {
 
 	hasFailedURL := m.GetFailedStepRunURL() != ""
 
-	if m.state.Status == chain.ChainRunning {
+	if m.state.Status == chain.ChainRunning && m.activeStepRun() != nil {
+		s.WriteString(ui.HelpStyle.Render("[esc/q] close (continues)  [C-c] stop  [x] cancel step run  [c] copy script"))
+	} else if m.state.Status == chain.ChainRunning {
 		s.WriteString(ui.HelpStyle.Render("[esc/q] close (continues)  [C-c] stop  [c] copy script"))
+	} else if m.state.Status == chain.ChainFailed && m.failedStepRun() != nil {
+		s.WriteString(ui.HelpStyle.Render("[esc/q] close  [o] open in browser  [l] view logs  [R/F] re-run all/failed jobs  [c] copy script"))
 	} else if m.state.Status == chain.ChainFailed && hasFailedURL {
 		s.WriteString(ui.HelpStyle.Render("[esc/q] close  [o] open in browser  [l] view logs  [c] copy script"))
 	} else if m.state.Status == chain.ChainCompleted || m.state.Status == chain.ChainFailed {
@@ -236,6 +300,161 @@ func (m *ChainStatusModal) View() string {
 	return s.String()
 }
 
+func (m *ChainStatusModal) renderStepList(s *strings.Builder) {
+	for i, status := range m.state.StepStatuses {
+		prefix := "  "
+		if m.isActiveStep(i) {
+			prefix = "> "
+		}
+
+		line := fmt.Sprintf("%s%s %s (%s)", prefix, stepStatusIcon(status), m.stepName(i), status)
+		line += m.skipReason(i) + m.attemptInfo(i)
+		m.writeStepLine(s, i, line, "     ")
+	}
+}
+
+// renderStepGraph groups steps into stages by dependency depth, so steps that can run
+// in parallel are listed together.
+func (m *ChainStatusModal) renderStepGraph(s *strings.Builder) {
+	stages := stepStages(m.state.Steps)
+
+	for n, stage := range stages {
+		s.WriteString(ui.TableDimmedStyle.Render(fmt.Sprintf("  Stage %d", n+1)))
+		s.WriteString("\n")
+
+		for j, i := range stage {
+			branch, indent := "├─", "  │    "
+			if j == len(stage)-1 {
+				branch, indent = "└─", "       "
+			}
+
+			status := m.state.StepStatuses[i]
+			line := fmt.Sprintf("  %s %s %s (%s)", branch, stepStatusIcon(status), m.stepName(i), status)
+
+			if needs := m.state.Steps[i].Needs; len(needs) > 0 {
+				names := make([]string, len(needs))
+				for k, need := range needs {
+					names[k] = m.stepName(need)
+				}
+
+				line += ui.TableDimmedStyle.Render("  needs: " + strings.Join(names, ", "))
+			}
+
+			line += m.skipReason(i) + m.attemptInfo(i)
+
+			m.writeStepLine(s, i, line, indent)
+		}
+	}
+}
+
+func (m *ChainStatusModal) writeStepLine(s *strings.Builder, i int, line, commandIndent string) {
+	if m.isActiveStep(i) {
+		s.WriteString(ui.SelectedStyle.Render(line))
+	} else {
+		s.WriteString(line)
+	}
+
+	s.WriteString("\n")
+
+	if i < len(m.commands) && m.commands[i] != "" {
+		s.WriteString(ui.CLIPreviewStyle.Render(commandIndent + m.commands[i]))
+		s.WriteString("\n")
+	}
+}
+
+func (m *ChainStatusModal) skipReason(i int) string {
+	if result, ok := m.state.StepResults[i]; ok && result != nil && result.SkipReason != "" {
+		return ui.TableDimmedStyle.Render("  " + result.SkipReason)
+	}
+
+	return ""
+}
+
+// attemptInfo describes the retries of a step, listing the conclusion of each finished attempt.
+func (m *ChainStatusModal) attemptInfo(i int) string {
+	result, ok := m.state.StepResults[i]
+	if !ok || result == nil || len(result.Attempts) == 0 {
+		return ""
+	}
+
+	conclusions := make([]string, len(result.Attempts))
+	for k, attempt := range result.Attempts {
+		conclusions[k] = attempt.Conclusion
+	}
+
+	switch status := m.state.StepStatuses[i]; {
+	case status == chain.StepRunning || status == chain.StepWaiting:
+		return ui.TableDimmedStyle.Render(fmt.Sprintf("  attempt %d (previous: %s)",
+			len(result.Attempts)+1, strings.Join(conclusions, ", ")))
+	case len(result.Attempts) > 1:
+		return ui.TableDimmedStyle.Render(fmt.Sprintf("  %d attempts: %s",
+			len(result.Attempts), strings.Join(conclusions, ", ")))
+	}
+
+	return ""
+}
+
+func (m *ChainStatusModal) isActiveStep(i int) bool {
+	if m.state.Status != chain.ChainRunning {
+		return false
+	}
+
+	status := m.state.StepStatuses[i]
+
+	return status == chain.StepRunning || status == chain.StepWaiting
+}
+
+func (m *ChainStatusModal) stepName(i int) string {
+	if i < len(m.state.Steps) {
+		return m.state.Steps[i].Name
+	}
+
+	if result, ok := m.state.StepResults[i]; ok {
+		return result.Workflow
+	}
+
+	return fmt.Sprintf("Step %d", i+1)
+}
+
+// stepStages groups step indices by the length of their longest dependency path.
+func stepStages(steps []chain.StepNode) [][]int {
+	depth := make([]int, len(steps))
+	for i := range depth {
+		depth[i] = -1
+	}
+
+	var depthOf func(i int) int
+	depthOf = func(i int) int {
+		if depth[i] >= 0 {
+			return depth[i]
+		}
+
+		depth[i] = 0 // config validation rules out cycles
+
+		d := 0
+		for _, need := range steps[i].Needs {
+			d = max(d, depthOf(need)+1)
+		}
+
+		depth[i] = d
+
+		return d
+	}
+
+	var stages [][]int
+
+	for i := range steps {
+		d := depthOf(i)
+		for len(stages) <= d {
+			stages = append(stages, nil)
+		}
+
+		stages[d] = append(stages[d], i)
+	}
+
+	return stages
+}
+
 // IsDone returns true if the modal is finished.
 func (m *ChainStatusModal) IsDone() bool {
 	return m.done
@@ -268,24 +487,35 @@ func (m *ChainStatusModal) GetFailedStepRunURL() string {
 	return ""
 }
 
-// GetDetailedError returns a detailed error message with context.
+// GetDetailedError returns a detailed error message with context, followed by
+// the triaged failures from the chain's logs.
 func (m *ChainStatusModal) GetDetailedError() string {
-	if m.state.Error == nil {
+	if m.state.Error == nil && len(m.triage) == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
 
-	sb.WriteString(m.state.Error.Error())
+	if m.state.Error != nil {
+		sb.WriteString(m.state.Error.Error())
+
+		if url := chainerr.GetRunURL(m.state.Error); url != "" {
+			sb.WriteString("\nRun URL: ")
+			sb.WriteString(url)
+		}
 
-	if url := chainerr.GetRunURL(m.state.Error); url != "" {
-		sb.WriteString("\nRun URL: ")
-		sb.WriteString(url)
+		if suggestion := chainerr.GetSuggestion(m.state.Error); suggestion != "" {
+			sb.WriteString("\nSuggestion: ")
+			sb.WriteString(suggestion)
+		}
 	}
 
-	if suggestion := chainerr.GetSuggestion(m.state.Error); suggestion != "" {
-		sb.WriteString("\nSuggestion: ")
-		sb.WriteString(suggestion)
+	if len(m.triage) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+
+		sb.WriteString(logs.FormatTriage(m.triage, 0))
 	}
 
 	return sb.String()
@@ -52,19 +52,26 @@ func (m *HelpModal) View() string {
   Esc                Deselect / Close modal
 
 ` + ui.SubtitleStyle.Render("Config Panel") + `
-  1-9, 0             Edit input by number (1-10)
+  1-9, 0             Edit input by number (1-10); toggles booleans
   b                  Select branch
   w                  Toggle watch mode
   /                  Start filtering inputs
   c                  Command - copy to clipboard
   r                  Reset all inputs to defaults
 
+` + ui.SubtitleStyle.Render("Live Runs") + `
+  d / D              Clear run / clear completed runs
+  x                  Cancel selected run
+  R / F              Re-run all jobs / failed jobs
+
 ` + ui.SubtitleStyle.Render("Input Editing") + `
   Ctrl+R             Restore default value
+  ↑↓ / PgUp PgDn     Step a number by 1 / 10
   Enter              Confirm (or apply anyway)
   Esc                Cancel / Keep editing
 
 ` + ui.SubtitleStyle.Render("Application") + `
+  p                  Switch repository (repos in user config)
   ?                  Show this help
   q, Ctrl+C          Quit
 
@@ -1,6 +1,8 @@
 package modal
 
 import (
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -27,20 +29,32 @@ type InputModal struct {
 }
 
 type inputKeyMap struct {
+	Decrement      key.Binding
+	DecrementTen   key.Binding
 	Enter          key.Binding
 	Escape         key.Binding
+	Increment      key.Binding
+	IncrementTen   key.Binding
 	RestoreDefault key.Binding
 }
 
 func defaultInputKeyMap() inputKeyMap {
 	return inputKeyMap{
+		Decrement:      key.NewBinding(key.WithKeys("down")),
+		DecrementTen:   key.NewBinding(key.WithKeys("pgdown")),
 		Enter:          key.NewBinding(key.WithKeys("enter")),
 		Escape:         key.NewBinding(key.WithKeys("esc")),
+		Increment:      key.NewBinding(key.WithKeys("up")),
+		IncrementTen:   key.NewBinding(key.WithKeys("pgup")),
 		RestoreDefault: key.NewBinding(key.WithKeys("ctrl+r", "alt+d")),
 	}
 }
 
-// NewInputModal creates a new text input modal.
+// numberChars are the characters that can be typed into a number input.
+const numberChars = "0123456789.-+eE"
+
+// NewInputModal creates a new text input modal. Number inputs only accept
+// numbers, which the arrow keys step up and down.
 func NewInputModal(title, description, defaultVal, inputType, current string, options []string, rules []rule.ValidationRule) *InputModal {
 	ti := textinput.New()
 	ti.SetValue(current)
@@ -67,6 +81,44 @@ func NewInputModal(title, description, defaultVal, inputType, current string, op
 	}
 }
 
+// numberError explains why the value of a number input is not a number.
+// A number input cannot be applied until it is one.
+func (m *InputModal) numberError() string {
+	value := strings.TrimSpace(m.input.Value())
+	if m.inputType != "number" || value == "" {
+		return ""
+	}
+
+	if _, ok := parseNumber(value); !ok {
+		return "\"" + value + "\" is not a number"
+	}
+
+	return ""
+}
+
+// step adds delta to the value of a number input. An empty or invalid value
+// steps from the default, or from 0 when the default is not a number either.
+func (m *InputModal) step(delta float64) {
+	n, ok := parseNumber(strings.TrimSpace(m.input.Value()))
+	if !ok {
+		n, _ = parseNumber(m.defaultVal)
+	}
+
+	m.input.SetValue(strconv.FormatFloat(n+delta, 'f', -1, 64))
+	m.input.CursorEnd()
+	m.validationErr = ""
+	m.hasError = false
+}
+
+func parseNumber(value string) (float64, bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, false
+	}
+
+	return n, true
+}
+
 func (m *InputModal) validate() string {
 	value := m.input.Value()
 
@@ -105,8 +157,29 @@ func (m *InputModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 			m.validationErr = ""
 			m.hasError = false
 
+			return m, nil
+		case m.inputType == "number" && key.Matches(msg, m.keys.Increment):
+			m.step(1)
+			return m, nil
+		case m.inputType == "number" && key.Matches(msg, m.keys.Decrement):
+			m.step(-1)
+			return m, nil
+		case m.inputType == "number" && key.Matches(msg, m.keys.IncrementTen):
+			m.step(10)
+			return m, nil
+		case m.inputType == "number" && key.Matches(msg, m.keys.DecrementTen):
+			m.step(-10)
+			return m, nil
+		case m.inputType == "number" && msg.Type == tea.KeyRunes && strings.Trim(string(msg.Runes), numberChars) != "":
 			return m, nil
 		case key.Matches(msg, m.keys.Enter):
+			if err := m.numberError(); err != "" {
+				m.validationErr = err
+				m.hasError = false
+
+				return m, nil
+			}
+
 			if err := m.validate(); err != "" && !m.hasError {
 				m.validationErr = err
 				m.hasError = true
@@ -121,7 +194,7 @@ func (m *InputModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 				return InputResultMsg{Value: m.result}
 			}
 		case key.Matches(msg, m.keys.Escape):
-			if m.hasError {
+			if m.validationErr != "" {
 				m.validationErr = ""
 				m.hasError = false
 
@@ -173,12 +246,21 @@ func (m *InputModal) View() string {
 	s.WriteString(ui.SubtitleStyle.Render("Default: " + defaultDisplay))
 	s.WriteString("\n")
 
-	if m.validationErr != "" {
+	switch {
+	case m.validationErr != "" && m.hasError:
 		s.WriteString("\n")
 		s.WriteString(ui.SelectedStyle.Render("! " + m.validationErr))
 		s.WriteString("\n\n")
 		s.WriteString(ui.HelpStyle.Render("[enter] apply anyway  [esc] keep editing  [ctrl+r] restore default"))
-	} else {
+	case m.validationErr != "":
+		s.WriteString("\n")
+		s.WriteString(ui.SelectedStyle.Render("! " + m.validationErr))
+		s.WriteString("\n\n")
+		s.WriteString(ui.HelpStyle.Render("[esc] keep editing  [ctrl+r] restore default"))
+	case m.inputType == "number":
+		s.WriteString("\n")
+		s.WriteString(ui.HelpStyle.Render("[↑↓] ±1  [pgup/pgdn] ±10  [enter] confirm  [esc] cancel  [ctrl+r] restore default"))
+	default:
 		s.WriteString("\n")
 		s.WriteString(ui.HelpStyle.Render("[enter] confirm  [esc] cancel  [ctrl+r] restore default"))
 	}
@@ -2,6 +2,7 @@ package modal
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -21,28 +22,53 @@ type MatchLocation struct {
 	LineNumber int // line number in viewport content (0-based)
 }
 
+// groupKey identifies a log group by its step and the index of its header entry.
+type groupKey struct {
+	StepIndex  int
+	EntryIndex int
+}
+
+// viewLine is one line of the rendered logs: a step header, an entry, or the blank line closing a step.
+type viewLine struct {
+	step  int // index in filtered.Steps
+	entry int // index in step.Entries; -1 for the step header and the closing blank line
+	blank bool
+}
+
 // LogsViewerModal displays workflow logs in a unified view with collapsible sections.
 type LogsViewerModal struct {
-	runLogs        *logs.RunLogs
-	filtered       *logs.FilteredResult
-	filter         *logs.Filter
-	filterCfg      *logs.FilterConfig
-	viewport       viewport.Model
-	searchInput    textinput.Model
-	collapsedSteps map[int]bool // track which steps are collapsed
-	searchMode     bool
-	done           bool
-	keys           logsViewerKeyMap
-	width          int
-	height         int
-	startTime      time.Time       // for calculating relative timestamps
-	matches        []MatchLocation // all match positions in rendered content
-	currentMatch   int             // index of current match (-1 if none)
-	isStreaming    bool
-	autoScroll     bool
-	streamRunID    int64
-	liveStatus     string
-	lastUpdateTime time.Time
+	runLogs         *logs.RunLogs
+	filtered        *logs.FilteredResult
+	filter          *logs.Filter
+	filterCfg       *logs.FilterConfig
+	presets         []logs.FilterPreset // cycled through with [f] after the built-in levels
+	preset          int                 // index of the last preset applied, -1 if none
+	viewport        viewport.Model
+	searchInput     textinput.Model
+	collapsedSteps  map[int]bool      // track which steps are collapsed
+	collapsedGroups map[groupKey]bool // track which log groups are collapsed
+	searchMode      bool
+	done            bool
+	keys            logsViewerKeyMap
+	width           int
+	height          int
+	startTime       time.Time       // for calculating relative timestamps
+	matches         []MatchLocation // all match positions in rendered content
+	currentMatch    int             // index of current match (-1 if none)
+	isStreaming     bool
+	autoScroll      bool
+	streamRunID     int64
+	liveStatus      string
+	lastUpdateTime  time.Time
+	exportMode      bool   // waiting for the export format key
+	exportDir       string // directory exports are written to
+	exportStatus    string // result of the last export
+}
+
+// logsExportedMsg reports the result of writing an export file.
+type logsExportedMsg struct {
+	path string
+	err  error
 }
 
 type logsViewerKeyMap struct {
@@ -55,11 +81,16 @@ type logsViewerKeyMap struct {
 	ToggleStep          key.Binding
 	ExpandAll           key.Binding
 	CollapseAll         key.Binding
+	CollapseGroups      key.Binding
 	QuickFilterAll      key.Binding
 	QuickFilterWarnings key.Binding
 	QuickFilterErrors   key.Binding
 	ToggleCaseSensitive key.Binding
 	ToggleAutoScroll    key.Binding
+	Export              key.Binding
+	ExportText          key.Binding
+	ExportNDJSON        key.Binding
+	ExportReport        key.Binding
 }
 
 func defaultLogsViewerKeyMap() logsViewerKeyMap {
@@ -73,11 +104,16 @@ func defaultLogsViewerKeyMap() logsViewerKeyMap {
 		ToggleStep:          key.NewBinding(key.WithKeys("enter", "space")),
 		ExpandAll:           key.NewBinding(key.WithKeys("E")),
 		CollapseAll:         key.NewBinding(key.WithKeys("C")),
+		CollapseGroups:      key.NewBinding(key.WithKeys("G")),
 		QuickFilterAll:      key.NewBinding(key.WithKeys("a")),
 		QuickFilterWarnings: key.NewBinding(key.WithKeys("w")),
 		QuickFilterErrors:   key.NewBinding(key.WithKeys("e")),
 		ToggleCaseSensitive: key.NewBinding(key.WithKeys("i")),
 		ToggleAutoScroll:    key.NewBinding(key.WithKeys("s")),
+		Export:              key.NewBinding(key.WithKeys("x")),
+		ExportText:          key.NewBinding(key.WithKeys("t")),
+		ExportNDJSON:        key.NewBinding(key.WithKeys("j")),
+		ExportReport:        key.NewBinding(key.WithKeys("m")),
 	}
 }
 
@@ -94,32 +130,35 @@ func NewLogsViewerModal(runLogs *logs.RunLogs, width, height int) *LogsViewerMod
 	searchInput.Placeholder = "Search logs..."
 	searchInput.CharLimit = 100
 
-	// Find earliest timestamp to use as start time
-	startTime := time.Now()
+	// Find earliest timestamp to use as start time; lines without one have a zero timestamp
+	var startTime time.Time
 
 	for _, step := range runLogs.AllSteps() {
 		for _, entry := range step.Entries {
-			if entry.Timestamp.Before(startTime) {
+			if !entry.Timestamp.IsZero() && (startTime.IsZero() || entry.Timestamp.Before(startTime)) {
 				startTime = entry.Timestamp
 			}
 		}
 	}
 
 	m := &LogsViewerModal{
-		runLogs:        runLogs,
-		filtered:       filtered,
-		filter:         filter,
-		filterCfg:      filterCfg,
-		viewport:       vp,
-		searchInput:    searchInput,
-		collapsedSteps: make(map[int]bool),
-		searchMode:     false,
-		keys:           defaultLogsViewerKeyMap(),
-		width:          width,
-		height:         height,
-		startTime:      startTime,
-		matches:        []MatchLocation{},
-		currentMatch:   -1,
+		runLogs:         runLogs,
+		filtered:        filtered,
+		filter:          filter,
+		filterCfg:       filterCfg,
+		viewport:        vp,
+		searchInput:     searchInput,
+		collapsedSteps:  make(map[int]bool),
+		collapsedGroups: make(map[groupKey]bool),
+		searchMode:      false,
+		keys:            defaultLogsViewerKeyMap(),
+		width:           width,
+		height:          height,
+		startTime:       startTime,
+		matches:         []MatchLocation{},
+		currentMatch:    -1,
+		preset:          -1,
+		exportDir:       logs.DefaultExportDir(),
 	}
 
 	m.updateViewportContent()
@@ -146,11 +185,24 @@ func (m *LogsViewerModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 		m.viewport.Height = msg.Height - 10
 		m.updateViewportContent()
 
+	case logsExportedMsg:
+		if msg.err != nil {
+			m.exportStatus = "Export failed: " + msg.err.Error()
+		} else {
+			m.exportStatus = "Exported to " + msg.path
+		}
+
+		return m, nil
+
 	case tea.KeyMsg:
 		if m.searchMode {
 			return m.handleSearchInput(msg)
 		}
 
+		if m.exportMode {
+			return m.handleExportInput(msg)
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Close):
 			m.done = true
@@ -201,7 +253,7 @@ func (m *LogsViewerModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, m.keys.ToggleStep):
-			m.toggleStepAtCursor()
+			m.toggleSectionAtCursor()
 			return m, nil
 
 		case key.Matches(msg, m.keys.ExpandAll):
@@ -212,9 +264,19 @@ func (m *LogsViewerModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 			m.collapseAll()
 			return m, nil
 
+		case key.Matches(msg, m.keys.CollapseGroups):
+			m.collapseGroups()
+			return m, nil
+
 		case key.Matches(msg, m.keys.ToggleAutoScroll):
 			m.toggleAutoScroll()
 			return m, nil
+
+		case key.Matches(msg, m.keys.Export):
+			m.exportMode = true
+			m.exportStatus = ""
+
+			return m, nil
 		}
 	}
 
@@ -224,6 +286,40 @@ func (m *LogsViewerModal) Update(msg tea.Msg) (Context, tea.Cmd) {
 	return m, cmd
 }
 
+// handleExportInput picks the export format after [x] was pressed.
+func (m *LogsViewerModal) handleExportInput(msg tea.KeyMsg) (Context, tea.Cmd) {
+	var format logs.ExportFormat
+
+	switch {
+	case key.Matches(msg, m.keys.ExportText):
+		format = logs.ExportText
+	case key.Matches(msg, m.keys.ExportNDJSON):
+		format = logs.ExportNDJSON
+	case key.Matches(msg, m.keys.ExportReport):
+		format = logs.ExportMarkdown
+	case key.Matches(msg, m.keys.ExitSearch):
+		m.exportMode = false
+		return m, nil
+	default:
+		return m, nil
+	}
+
+	m.exportMode = false
+
+	return m, m.export(format)
+}
+
+// export writes the current view in the given format; the failure report
+// covers all logs regardless of the filter.
+func (m *LogsViewerModal) export(format logs.ExportFormat) tea.Cmd {
+	runLogs, filtered, dir := m.runLogs, m.filtered, m.exportDir
+
+	return func() tea.Msg {
+		path, err := logs.ExportFile(dir, format, runLogs, filtered)
+		return logsExportedMsg{path: path, err: err}
+	}
+}
+
 // handleSearchInput processes input when in search mode.
 func (m *LogsViewerModal) handleSearchInput(msg tea.KeyMsg) (Context, tea.Cmd) {
 	switch {
@@ -248,20 +344,57 @@ func (m *LogsViewerModal) handleSearchInput(msg tea.KeyMsg) (Context, tea.Cmd) {
 	return m, cmd
 }
 
-// toggleStepAtCursor toggles the collapsed state of the step under the cursor.
-func (m *LogsViewerModal) toggleStepAtCursor() {
-	// This is a simplified implementation
-	// In a real implementation, track cursor position and toggle the appropriate step
-	if len(m.filtered.Steps) > 0 {
-		stepIdx := 0 // Would determine from cursor position
-		m.collapsedSteps[stepIdx] = !m.collapsedSteps[stepIdx]
-		m.updateViewportContent()
+// toggleSectionAtCursor toggles the innermost step or group enclosing the top line of the viewport.
+func (m *LogsViewerModal) toggleSectionAtCursor() {
+	lines := m.layout()
+	if len(lines) == 0 {
+		return
+	}
+
+	top := min(m.viewport.YOffset, len(lines)-1)
+	depthLimit := math.MaxInt
+
+	for i := top; i >= 0; i-- {
+		line := lines[i]
+		if line.entry < 0 {
+			m.collapsedSteps[line.step] = !m.collapsedSteps[line.step]
+			break
+		}
+
+		entry := m.filtered.Steps[line.step].Entries[line.entry]
+		if entry.Original.Group && entry.Original.Depth < depthLimit {
+			key := groupKey{m.filtered.Steps[line.step].StepIndex, entry.OriginalIndex}
+			m.collapsedGroups[key] = !m.collapsedGroups[key]
+
+			break
+		}
+
+		depthLimit = min(depthLimit, entry.Original.Depth)
 	}
+
+	m.buildMatchIndex()
+	m.updateViewportContent()
 }
 
-// expandAll expands all step sections.
+// expandAll expands all step sections and log groups.
 func (m *LogsViewerModal) expandAll() {
 	m.collapsedSteps = make(map[int]bool)
+	m.collapsedGroups = make(map[groupKey]bool)
+	m.buildMatchIndex()
+	m.updateViewportContent()
+}
+
+// collapseGroups collapses every log group, leaving step sections open.
+func (m *LogsViewerModal) collapseGroups() {
+	for _, step := range m.filtered.Steps {
+		for _, entry := range step.Entries {
+			if entry.Original.Group {
+				m.collapsedGroups[groupKey{step.StepIndex, entry.OriginalIndex}] = true
+			}
+		}
+	}
+
+	m.buildMatchIndex()
 	m.updateViewportContent()
 }
 
@@ -274,17 +407,57 @@ func (m *LogsViewerModal) collapseAll() {
 	m.updateViewportContent()
 }
 
-// cycleFilterLevel cycles through filter levels: all -> errors -> warnings -> all.
+// SetExportDir sets the directory exports are written to.
+func (m *LogsViewerModal) SetExportDir(dir string) {
+	m.exportDir = dir
+}
+
+// SetFilterPresets sets the named filters [f] cycles through after the built-in levels.
+func (m *LogsViewerModal) SetFilterPresets(presets []logs.FilterPreset) {
+	m.presets = presets
+}
+
+// activePreset returns the index of the applied preset, or -1 if none is
+// applied or the filter has been changed since.
+func (m *LogsViewerModal) activePreset() int {
+	if m.preset >= 0 && m.preset < len(m.presets) && *m.filterCfg == m.presets[m.preset].Config {
+		return m.preset
+	}
+
+	return -1
+}
+
+// cycleFilterLevel cycles through filter levels: all -> errors -> warnings,
+// then through the presets, and back to all.
 func (m *LogsViewerModal) cycleFilterLevel() {
-	switch m.filterCfg.Level {
-	case logs.FilterAll:
+	next := -1
+
+	switch active := m.activePreset(); {
+	case active >= 0:
+		next = active + 1
+	case m.filterCfg.Level == logs.FilterAll:
 		m.filterCfg.Level = logs.FilterErrors
-	case logs.FilterErrors:
+	case m.filterCfg.Level == logs.FilterErrors:
 		m.filterCfg.Level = logs.FilterWarnings
-	case logs.FilterWarnings:
+	case len(m.presets) > 0:
+		next = 0
+	default:
 		m.filterCfg.Level = logs.FilterAll
 	}
 
+	switch {
+	case next >= 0 && next < len(m.presets):
+		cfg := m.presets[next].Config
+		m.filterCfg = &cfg
+		m.preset = next
+		m.searchInput.SetValue(cfg.SearchTerm)
+	case next >= 0:
+		// Past the last preset: start over without its search
+		m.filterCfg = logs.NewFilterConfig()
+		m.preset = -1
+		m.searchInput.SetValue("")
+	}
+
 	m.applyFilter()
 }
 
@@ -310,29 +483,51 @@ func (m *LogsViewerModal) buildMatchIndex() {
 		return
 	}
 
-	lineNumber := 0
-	for stepIdx, step := range m.filtered.Steps {
-		// Step header line
-		lineNumber++
-
-		// Only count entry lines if step is not collapsed
-		if !m.collapsedSteps[stepIdx] {
-			for entryIdx, entry := range step.Entries {
-				if len(entry.Matches) > 0 {
-					m.matches = append(m.matches, MatchLocation{
-						StepIndex:  stepIdx,
-						EntryIndex: entryIdx,
-						LineNumber: lineNumber,
-					})
+	for lineNumber, line := range m.layout() {
+		if line.entry < 0 || len(m.filtered.Steps[line.step].Entries[line.entry].Matches) == 0 {
+			continue
+		}
+
+		m.matches = append(m.matches, MatchLocation{
+			StepIndex:  line.step,
+			EntryIndex: line.entry,
+			LineNumber: lineNumber,
+		})
+	}
+}
+
+// layout lists the lines of the rendered logs, leaving out collapsed steps and groups.
+func (m *LogsViewerModal) layout() []viewLine {
+	var lines []viewLine
+
+	for i, step := range m.filtered.Steps {
+		lines = append(lines, viewLine{step: i, entry: -1})
+
+		if !m.collapsedSteps[i] {
+			hideDeeper := -1 // entries deeper than this are inside a collapsed group
+
+			for j, entry := range step.Entries {
+				depth := entry.Original.Depth
+				if hideDeeper >= 0 {
+					if depth > hideDeeper {
+						continue
+					}
+
+					hideDeeper = -1
 				}
 
-				lineNumber++
+				lines = append(lines, viewLine{step: i, entry: j})
+
+				if entry.Original.Group && m.collapsedGroups[groupKey{step.StepIndex, entry.OriginalIndex}] {
+					hideDeeper = depth
+				}
 			}
 		}
 
-		// Empty line after step
-		lineNumber++
+		lines = append(lines, viewLine{step: i, entry: -1, blank: true})
 	}
+
+	return lines
 }
 
 // jumpToNextMatch scrolls to the next search match.
@@ -405,18 +600,15 @@ func (m *LogsViewerModal) updateViewportContent() {
 func (m *LogsViewerModal) renderUnifiedLogs() string {
 	var sb strings.Builder
 
-	for i, step := range m.filtered.Steps {
-		// Render step header
-		sb.WriteString(m.renderStepHeader(i, step))
-		sb.WriteString("\n")
+	for _, line := range m.layout() {
+		step := m.filtered.Steps[line.step]
 
-		// Render step logs if not collapsed
-		if !m.collapsedSteps[i] {
-			for j, entry := range step.Entries {
-				line := m.renderLogEntry(&entry, i, j)
-				sb.WriteString(line)
-				sb.WriteString("\n")
-			}
+		switch {
+		case line.blank:
+		case line.entry < 0:
+			sb.WriteString(m.renderStepHeader(line.step, step))
+		default:
+			sb.WriteString(m.renderLogEntry(&step.Entries[line.entry], line.step, line.entry))
 		}
 
 		sb.WriteString("\n")
@@ -449,13 +641,12 @@ func (m *LogsViewerModal) renderStepHeader(idx int, step *logs.FilteredStepLogs)
 
 // renderLogEntry renders a single log entry with highlighting.
 func (m *LogsViewerModal) renderLogEntry(entry *logs.FilteredLogEntry, stepIdx, entryIdx int) string {
-	// Calculate time since start
-	timeSinceStart := entry.Original.Timestamp.Sub(m.startTime)
-
 	// Format: [+00:05:23] [12:34:56] log content
-	timePrefix := fmt.Sprintf("[+%s] [%s] ",
-		formatDuration(timeSinceStart),
-		entry.Original.Timestamp.Format("15:04:05"))
+	timePrefix := strings.Repeat(" ", len("[+00:00:00] [00:00:00] "))
+
+	if ts := entry.Original.Timestamp; !ts.IsZero() {
+		timePrefix = fmt.Sprintf("[+%s] [%s] ", formatDuration(ts.Sub(m.startTime)), ts.Format("15:04:05"))
+	}
 
 	// Style the time prefix
 	timeStyle := lipgloss.NewStyle().
@@ -478,12 +669,74 @@ func (m *LogsViewerModal) renderLogEntry(entry *logs.FilteredLogEntry, stepIdx,
 	contentStyle := m.getLogLevelStyle(entry.Original.Level)
 	content := entry.Original.Content
 
-	// Highlight matches if present
-	if len(entry.Matches) > 0 {
-		content = m.highlightMatches(content, entry.Matches, isCurrentMatch)
+	var rendered string
+
+	switch {
+	case len(entry.Matches) > 0:
+		rendered = contentStyle.Render(m.highlightMatches(content, entry.Matches, isCurrentMatch))
+	case len(entry.Original.Spans) > 0:
+		rendered = renderSpans(entry.Original.Spans, contentStyle)
+	default:
+		rendered = contentStyle.Render(content)
 	}
 
-	return styledTimePrefix + contentStyle.Render(content)
+	indent := strings.Repeat("  ", entry.Original.Depth)
+
+	if entry.Original.Group {
+		icon := "▼ "
+		if m.collapsedGroups[groupKey{m.filtered.Steps[stepIdx].StepIndex, entry.OriginalIndex}] {
+			icon = "▶ "
+		}
+
+		return styledTimePrefix + indent + ui.SubtitleStyle.Render(icon) + rendered
+	}
+
+	if loc := entry.Original.Annotation.Location(); loc != "" {
+		rendered = ui.TableDimmedStyle.Render(loc+": ") + rendered
+	}
+
+	return styledTimePrefix + indent + rendered
+}
+
+// renderSpans renders ANSI-styled spans, using base for attributes a span leaves unset.
+func renderSpans(spans []logs.Span, base lipgloss.Style) string {
+	var sb strings.Builder
+
+	for _, span := range spans {
+		style := base
+
+		if span.Style.Foreground != "" {
+			style = style.Foreground(lipgloss.Color(span.Style.Foreground))
+		}
+
+		if span.Style.Background != "" {
+			style = style.Background(lipgloss.Color(span.Style.Background))
+		}
+
+		if span.Style.Bold {
+			style = style.Bold(true)
+		}
+
+		if span.Style.Faint {
+			style = style.Faint(true)
+		}
+
+		if span.Style.Italic {
+			style = style.Italic(true)
+		}
+
+		if span.Style.Underline {
+			style = style.Underline(true)
+		}
+
+		if span.Style.Reverse {
+			style = style.Reverse(true)
+		}
+
+		sb.WriteString(style.Render(span.Text))
+	}
+
+	return sb.String()
 }
 
 // formatDuration formats a duration as HH:MM:SS.
@@ -572,6 +825,11 @@ func (m *LogsViewerModal) View() string {
 	s.WriteString(m.renderFilterStatus())
 	s.WriteString("\n")
 
+	if m.exportStatus != "" {
+		s.WriteString(ui.TableDimmedStyle.Render(m.exportStatus))
+		s.WriteString("\n")
+	}
+
 	// Search input (if active)
 	if m.searchMode {
 		s.WriteString(ui.SubtitleStyle.Render("Search: "))
@@ -613,6 +871,10 @@ func (m *LogsViewerModal) renderFilterStatus() string {
 		filterLabel = fmt.Sprintf("Filter: %s", m.filterCfg.Level)
 	}
 
+	if active := m.activePreset(); active >= 0 {
+		filterLabel = "Filter: " + m.presets[active].Name
+	}
+
 	parts = append(parts, ui.SubtitleStyle.Render(filterLabel))
 
 	// Search term with case sensitivity indicator
@@ -672,6 +934,10 @@ func (m *LogsViewerModal) renderHelp() string {
 		return ui.HelpStyle.Render("[enter] apply  [esc] cancel")
 	}
 
+	if m.exportMode {
+		return ui.HelpStyle.Render("Export: [t] text  [j] NDJSON  [m] failure report  [esc] cancel")
+	}
+
 	helpParts := []string{
 		"[a] all",
 		"[w] warnings",
@@ -688,6 +954,8 @@ func (m *LogsViewerModal) renderHelp() string {
 		"[enter/space] toggle section",
 		"[E] expand all",
 		"[C] collapse all",
+		"[G] collapse groups",
+		"[x] export",
 		"[↑↓] scroll",
 	)
 
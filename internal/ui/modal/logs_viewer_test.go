@@ -256,3 +256,202 @@ func TestLogsViewerModal_Streaming(t *testing.T) {
 		t.Error("expected autoScroll to be enabled")
 	}
 }
+
+func TestLogsViewerModal_Groups(t *testing.T) {
+	runLogs := &logs.RunLogs{
+		Steps: []*logs.StepLogs{
+			{
+				StepName: "Checkout",
+				Entries: []logs.LogEntry{
+					{Content: "Run actions/checkout@v4", Level: logs.LogLevelInfo, Group: true},
+					{Content: "Syncing repository", Level: logs.LogLevelInfo, Depth: 1},
+					{Content: "Getting Git version info", Level: logs.LogLevelInfo, Group: true, Depth: 1},
+					{Content: "git version 2.43.0", Level: logs.LogLevelInfo, Depth: 2},
+					{Content: "Checkout done", Level: logs.LogLevelInfo},
+				},
+			},
+		},
+	}
+	modal := NewLogsViewerModal(runLogs, 120, 40)
+
+	if got := len(modal.layout()); got != 7 {
+		t.Fatalf("expected 7 lines expanded, got %d", got)
+	}
+
+	// Press G to collapse every group: only the outer header and the top-level line remain
+	_, _ = modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+
+	view := modal.renderUnifiedLogs()
+	if strings.Contains(view, "Syncing repository") || strings.Contains(view, "git version") {
+		t.Error("collapsed group contents should be hidden")
+	}
+
+	if !strings.Contains(view, "Checkout done") {
+		t.Error("lines after a collapsed group should stay visible")
+	}
+
+	// Press E to expand everything again
+	_, _ = modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+
+	if got := len(modal.layout()); got != 7 {
+		t.Errorf("expected 7 lines after E key, got %d", got)
+	}
+}
+
+func TestLogsViewerModal_ToggleInnermostGroup(t *testing.T) {
+	runLogs := &logs.RunLogs{
+		Steps: []*logs.StepLogs{
+			{
+				StepName: "Test",
+				Entries: []logs.LogEntry{
+					{Content: "Run go test", Level: logs.LogLevelInfo, Group: true},
+					{Content: "nested", Level: logs.LogLevelInfo, Group: true, Depth: 1},
+					{Content: "ok", Level: logs.LogLevelInfo, Depth: 2},
+				},
+			},
+		},
+	}
+	modal := NewLogsViewerModal(runLogs, 120, 40)
+
+	// The viewport top is the step header, so space toggles the step
+	modal.toggleSectionAtCursor()
+
+	if !modal.collapsedSteps[0] {
+		t.Fatal("expected step to collapse when the step header is at the top")
+	}
+
+	modal.toggleSectionAtCursor()
+
+	// Scroll to the nested group's only line: the nested group is the innermost section
+	modal.viewport.Height = 1
+	modal.viewport.SetYOffset(3)
+	modal.toggleSectionAtCursor()
+
+	if !modal.collapsedGroups[groupKey{StepIndex: 0, EntryIndex: 1}] {
+		t.Error("expected nested group to collapse")
+	}
+
+	if modal.collapsedGroups[groupKey{StepIndex: 0, EntryIndex: 0}] {
+		t.Error("outer group should stay expanded")
+	}
+}
+
+func TestLogsViewerModal_RenderAnnotationsAndUntimedLines(t *testing.T) {
+	runLogs := &logs.RunLogs{
+		Steps: []*logs.StepLogs{
+			{
+				StepName: "Lint",
+				Entries: []logs.LogEntry{
+					{
+						Content:    "unused variable",
+						Level:      logs.LogLevelError,
+						Annotation: &logs.Annotation{File: "app/main.go", Line: 12},
+					},
+					{
+						Content: "FAIL",
+						Level:   logs.LogLevelError,
+						Spans:   []logs.Span{{Text: "FAIL", Style: logs.SpanStyle{Foreground: "1", Bold: true}}},
+					},
+				},
+			},
+		},
+	}
+	modal := NewLogsViewerModal(runLogs, 120, 40)
+
+	view := modal.renderUnifiedLogs()
+
+	if !strings.Contains(view, "app/main.go:12: ") {
+		t.Error("expected annotation location in rendered line")
+	}
+
+	if !strings.Contains(view, "FAIL") {
+		t.Error("expected span text in rendered line")
+	}
+
+	if strings.Contains(view, "[+") {
+		t.Error("lines without timestamps should not render a time prefix")
+	}
+}
+
+func TestLogsViewerModal_Export(t *testing.T) {
+	modal := NewLogsViewerModal(createTestRunLogs(), 80, 24)
+	modal.exportDir = t.TempDir()
+
+	_, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if cmd != nil || !modal.exportMode {
+		t.Fatal("expected [x] to ask for the export format")
+	}
+
+	if !strings.Contains(modal.View(), "[j] NDJSON") {
+		t.Error("view should list the export formats")
+	}
+
+	_, cmd = modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if cmd == nil || modal.exportMode {
+		t.Fatal("expected an export command")
+	}
+
+	msg, ok := cmd().(logsExportedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("unexpected export result %+v", msg)
+	}
+
+	if !strings.HasSuffix(msg.path, ".ndjson") {
+		t.Errorf("unexpected export path %s", msg.path)
+	}
+
+	modal.Update(msg)
+
+	if !strings.Contains(modal.View(), "Exported to "+msg.path) {
+		t.Error("view should show the export path")
+	}
+
+	// Esc leaves export mode without closing the viewer
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	modal.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if modal.exportMode || modal.IsDone() {
+		t.Error("esc should only cancel the export")
+	}
+}
+
+func TestLogsViewerModal_FilterPresets(t *testing.T) {
+	modal := NewLogsViewerModal(createTestRunLogs(), 80, 24)
+	modal.SetFilterPresets([]logs.FilterPreset{
+		{Name: "build problems", Config: logs.FilterConfig{Level: logs.FilterWarnings, StepIndex: -1, StepName: "Build"}},
+		{Name: "setup", Config: logs.FilterConfig{Level: logs.FilterAll, SearchTerm: "setup", StepIndex: -1}},
+	})
+
+	press := func() {
+		modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	}
+
+	press() // errors
+	press() // warnings
+	press()
+
+	if !strings.Contains(modal.renderFilterStatus(), "Filter: build problems") || modal.filtered.TotalEntries() != 2 {
+		t.Errorf("expected the first preset, got %q with %d entries", modal.renderFilterStatus(), modal.filtered.TotalEntries())
+	}
+
+	press()
+
+	if !strings.Contains(modal.renderFilterStatus(), "Filter: setup") || modal.filtered.TotalEntries() != 2 {
+		t.Errorf("expected the second preset, got %q with %d entries", modal.renderFilterStatus(), modal.filtered.TotalEntries())
+	}
+
+	// Changing the filter leaves the preset
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+
+	if status := modal.renderFilterStatus(); !strings.Contains(status, "Filter: errors only") {
+		t.Errorf("preset still shown after changing the level: %q", status)
+	}
+
+	// Cycling past the last preset returns to all logs without its search
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	press()
+
+	if modal.filterCfg.Level != logs.FilterAll || modal.filterCfg.SearchTerm != "" || modal.filtered.TotalEntries() != 5 {
+		t.Errorf("expected all logs after the last preset, got %+v", modal.filterCfg)
+	}
+}
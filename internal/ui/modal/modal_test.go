@@ -1,9 +1,15 @@
 package modal
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/chain"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
 	"github.com/kyleking/gh-lazydispatch/internal/runner"
 )
 
@@ -97,6 +103,72 @@ func TestSelectModal_Select(t *testing.T) {
 	}
 }
 
+func typeText(m Context, text string) {
+	for _, r := range text {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestPickerModal_FiltersOnDescriptions(t *testing.T) {
+	picker := NewPickerModal("pr", []PickerOption{
+		{Value: "42", Description: "Fix login"},
+		{Value: "41", Description: "Add search"},
+	}, "", "")
+
+	typeText(picker, "search")
+
+	_, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a result")
+	}
+
+	if msg, ok := cmd().(SelectResultMsg); !ok || msg.Value != "41" {
+		t.Errorf("got %+v, want the value of the matching option", cmd())
+	}
+}
+
+func TestPickerModal_SelectsCurrentOrDefault(t *testing.T) {
+	options := []PickerOption{{Value: "v1.2.0"}, {Value: "v1.1.0"}, {Value: "v1.0.0"}}
+
+	if picker := NewPickerModal("tag", options, "v1.0.0", "v1.1.0"); picker.selected != 2 {
+		t.Errorf("expected the current value selected, got %d", picker.selected)
+	}
+
+	if picker := NewPickerModal("tag", options, "v0.9.0", "v1.1.0"); picker.selected != 1 {
+		t.Errorf("expected the default selected, got %d", picker.selected)
+	}
+}
+
+func TestPickerModal_EntersUnlistedValue(t *testing.T) {
+	picker := NewPickerModal("tag", []PickerOption{{Value: "v1.2.0"}}, "", "")
+
+	typeText(picker, "zzz")
+
+	if !strings.Contains(picker.View(), "No matches") {
+		t.Errorf("expected the no-match hint, got:\n%s", picker.View())
+	}
+
+	_, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || cmd().(SelectResultMsg).Value != "zzz" {
+		t.Error("expected Enter to use the typed text")
+	}
+
+	// Esc clears the filter before cancelling
+	picker = NewPickerModal("tag", []PickerOption{{Value: "v1.2.0"}}, "", "")
+	typeText(picker, "v")
+	picker.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if picker.IsDone() || picker.filterInput.Value() != "" {
+		t.Error("expected the first Esc to clear the filter")
+	}
+
+	picker.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if !picker.IsDone() || picker.Result() != "" {
+		t.Error("expected the second Esc to cancel")
+	}
+}
+
 func TestSelectModal_Escape(t *testing.T) {
 	modal := NewSelectModal("Test", []string{"a", "b"}, "a", "a")
 
@@ -142,6 +214,62 @@ func TestInputModal_Escape(t *testing.T) {
 	}
 }
 
+func TestInputModal_NumberSteps(t *testing.T) {
+	modal := NewInputModal("replicas", "", "3", "number", "", nil, nil)
+
+	modal.Update(tea.KeyMsg{Type: tea.KeyUp})
+
+	if got := modal.input.Value(); got != "4" {
+		t.Errorf("after up from empty: got %q, want 4 (default + 1)", got)
+	}
+
+	modal.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	modal.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	if got := modal.input.Value(); got != "-7" {
+		t.Errorf("after pgdown and down: got %q, want -7", got)
+	}
+
+	modal.input.SetValue("1.5")
+	modal.Update(tea.KeyMsg{Type: tea.KeyUp})
+
+	if got := modal.input.Value(); got != "2.5" {
+		t.Errorf("after up from 1.5: got %q, want 2.5", got)
+	}
+}
+
+func TestInputModal_NumberRejectsText(t *testing.T) {
+	modal := NewInputModal("replicas", "", "", "number", "1", nil, nil)
+
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+
+	if got := modal.input.Value(); got != "12" {
+		t.Errorf("value: got %q, want 12", got)
+	}
+
+	modal.input.SetValue("1.2.3")
+
+	for range 2 {
+		modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		if modal.IsDone() {
+			t.Fatal("expected an invalid number not to be applied")
+		}
+	}
+
+	if !strings.Contains(modal.View(), "is not a number") {
+		t.Errorf("expected the error in the view, got:\n%s", modal.View())
+	}
+
+	modal.input.SetValue("7")
+	modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if !modal.IsDone() || modal.Result() != "7" {
+		t.Errorf("expected 7 to be applied, got done=%v result=%v", modal.IsDone(), modal.Result())
+	}
+}
+
 func TestConfirmModal_Navigation(t *testing.T) {
 	modal := NewConfirmModal("Confirm?", "", true, true)
 
@@ -442,3 +570,296 @@ func TestValidationErrorModal_Cancel(t *testing.T) {
 		t.Error("expected override=false after escape")
 	}
 }
+
+func TestChainResumeModal_Keys(t *testing.T) {
+	entry := chain.JournalEntry{
+		ID:          "release-1",
+		ChainName:   "release",
+		Branch:      "main",
+		CurrentStep: 1,
+		Steps: []chain.JournalStep{
+			{Workflow: "build.yml", RunID: 101, Status: chain.StepCompleted},
+			{Workflow: "deploy.yml", RunID: 202, Status: chain.StepWaiting},
+		},
+	}
+
+	tests := []struct {
+		key         tea.KeyMsg
+		wantResume  bool
+		wantDiscard bool
+	}{
+		{key: tea.KeyMsg{Type: tea.KeyEnter}, wantResume: true},
+		{key: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}, wantDiscard: true},
+		{key: tea.KeyMsg{Type: tea.KeyEsc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key.String(), func(t *testing.T) {
+			m := NewChainResumeModal(entry)
+
+			if view := m.View(); !strings.Contains(view, "run 202") {
+				t.Errorf("view missing attached run: %s", view)
+			}
+
+			_, cmd := m.Update(tt.key)
+			if !m.IsDone() || cmd == nil {
+				t.Fatal("expected modal to finish with a command")
+			}
+
+			result, ok := cmd().(ChainResumeResultMsg)
+			if !ok {
+				t.Fatal("expected ChainResumeResultMsg")
+			}
+
+			if result.Resume != tt.wantResume || result.Discard != tt.wantDiscard || result.Entry.ID != "release-1" {
+				t.Errorf("result: got %+v", result)
+			}
+		})
+	}
+}
+
+func TestChainStatusModal_Graph(t *testing.T) {
+	state := chain.ChainState{
+		ChainName: "fan-out",
+		Status:    chain.ChainRunning,
+		StepStatuses: []chain.StepStatus{
+			chain.StepCompleted, chain.StepWaiting, chain.StepWaiting, chain.StepPending,
+		},
+		Steps: []chain.StepNode{
+			{Name: "build", Workflow: "build.yml"},
+			{Name: "us", Workflow: "deploy.yml", Needs: []int{0}},
+			{Name: "eu", Workflow: "deploy.yml", Needs: []int{0}},
+			{Name: "smoke", Workflow: "smoke.yml", Needs: []int{1, 2}},
+		},
+		StepResults: map[int]*chain.StepResult{},
+	}
+
+	view := NewChainStatusModal(state).View()
+
+	for _, want := range []string{"Stage 1", "Stage 2", "Stage 3", "needs: build", "needs: us, eu"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	stages := stepStages(state.Steps)
+	if len(stages) != 3 || len(stages[1]) != 2 {
+		t.Errorf("stepStages: got %v, want [[0] [1 2] [3]]", stages)
+	}
+
+	state.Steps = []chain.StepNode{
+		{Name: "build", Workflow: "build.yml"},
+		{Name: "deploy", Workflow: "deploy.yml", Needs: []int{0}},
+	}
+	state.StepStatuses = state.StepStatuses[:2]
+
+	if view := NewChainStatusModal(state).View(); strings.Contains(view, "Stage") {
+		t.Errorf("sequential chain rendered as graph:\n%s", view)
+	}
+}
+
+func TestChainConfirmModal_TemplateErrorBlocksConfirm(t *testing.T) {
+	chainDef := &config.Chain{
+		Steps: []config.ChainStep{
+			{Workflow: "deploy.yml", Inputs: map[string]string{"env": "{{ var.enviroment }}"}},
+		},
+	}
+
+	m := NewChainConfirmModal("release", chainDef, map[string]string{"environment": "prod"}, "main", false)
+
+	if view := m.View(); !strings.Contains(view, "var.enviroment") {
+		t.Errorf("view missing template error: %s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.IsDone() {
+		t.Error("confirm should be blocked while a step has template errors")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if !m.IsDone() {
+		t.Error("cancel should still close the modal")
+	}
+}
+
+func TestChainStatusModal_Attempts(t *testing.T) {
+	state := chain.ChainState{
+		ChainName:    "release",
+		Status:       chain.ChainRunning,
+		StepStatuses: []chain.StepStatus{chain.StepCompleted, chain.StepWaiting},
+		Steps: []chain.StepNode{
+			{Name: "build.yml", Workflow: "build.yml"},
+			{Name: "e2e.yml", Workflow: "e2e.yml", Needs: []int{0}},
+		},
+		StepResults: map[int]*chain.StepResult{
+			0: {Workflow: "build.yml", Attempts: []chain.StepAttempt{
+				{RunID: 1, Conclusion: "failure"},
+				{RunID: 2, Conclusion: "success"},
+			}},
+			1: {Workflow: "e2e.yml", Attempts: []chain.StepAttempt{{RunID: 3, Conclusion: "timed_out"}}},
+		},
+	}
+
+	view := NewChainStatusModal(state).View()
+
+	for _, want := range []string{"2 attempts: failure, success", "attempt 2 (previous: timed_out)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestRunActionModal(t *testing.T) {
+	tests := []struct {
+		name string
+		key  tea.KeyMsg
+		want bool
+	}{
+		{"confirm", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}, true},
+		{"cancel", tea.KeyMsg{Type: tea.KeyEscape}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRunActionModal(RunActionRerunFailed, 42, "ci.yml")
+
+			if view := m.View(); !strings.Contains(view, "Re-run failed jobs?") || !strings.Contains(view, "#42") {
+				t.Errorf("unexpected view:\n%s", view)
+			}
+
+			m.Update(tt.key)
+
+			if !m.IsDone() {
+				t.Fatal("expected modal to be done")
+			}
+
+			result, ok := m.Result().(RunActionResultMsg)
+			if !ok {
+				t.Fatal("expected RunActionResultMsg")
+			}
+
+			want := RunActionResultMsg{Action: RunActionRerunFailed, RunID: 42, Workflow: "ci.yml", Confirmed: tt.want}
+			if result != want {
+				t.Errorf("result: got %+v, want %+v", result, want)
+			}
+		})
+	}
+}
+
+func TestChainStatusModal_RunActions(t *testing.T) {
+	running := chain.ChainState{
+		ChainName:    "release",
+		Status:       chain.ChainRunning,
+		StepStatuses: []chain.StepStatus{chain.StepCompleted, chain.StepWaiting},
+		StepResults: map[int]*chain.StepResult{
+			0: {Workflow: "build.yml", RunID: 1},
+			1: {Workflow: "deploy.yml", RunID: 2},
+		},
+	}
+	failed := chain.ChainState{
+		ChainName:    "release",
+		Status:       chain.ChainFailed,
+		StepStatuses: []chain.StepStatus{chain.StepFailed, chain.StepPending},
+		StepResults:  map[int]*chain.StepResult{0: {Workflow: "build.yml", RunID: 1}},
+	}
+
+	tests := []struct {
+		name  string
+		state chain.ChainState
+		key   rune
+		want  *RunActionRequestMsg
+	}{
+		{"cancel active step", running, 'x', &RunActionRequestMsg{Action: RunActionCancel, RunID: 2, Workflow: "deploy.yml"}},
+		{"rerun needs a failed step", running, 'R', nil},
+		{"rerun failed step", failed, 'R', &RunActionRequestMsg{Action: RunActionRerun, RunID: 1, Workflow: "build.yml"}},
+		{"rerun failed jobs", failed, 'F', &RunActionRequestMsg{Action: RunActionRerunFailed, RunID: 1, Workflow: "build.yml"}},
+		{"cancel needs an active step", failed, 'x', nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewChainStatusModal(tt.state)
+
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{tt.key}})
+
+			if tt.want == nil {
+				if cmd != nil || m.IsDone() {
+					t.Error("expected key to be ignored")
+				}
+
+				return
+			}
+
+			if cmd == nil || !m.IsDone() {
+				t.Fatal("expected modal to close with a request")
+			}
+
+			if got := cmd(); got != *tt.want {
+				t.Errorf("msg: got %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestChainStatusModal_Triage(t *testing.T) {
+	state := chain.ChainState{
+		ChainName:    "release",
+		Status:       chain.ChainFailed,
+		StepStatuses: []chain.StepStatus{chain.StepFailed},
+		Steps:        []chain.StepNode{{Name: "build.yml", Workflow: "build.yml"}},
+		StepResults:  map[int]*chain.StepResult{},
+		Error:        errors.New("step 1 failed"),
+	}
+
+	m := NewChainStatusModal(state)
+
+	if got := m.GetDetailedError(); got != "step 1 failed" {
+		t.Errorf("GetDetailedError without triage: got %q", got)
+	}
+
+	failures := make([]*logs.Failure, 4)
+	for i := range failures {
+		failures[i] = &logs.Failure{
+			Workflow: "build.yml", StepName: fmt.Sprintf("step %d", i),
+			Line: logs.LogEntry{Content: fmt.Sprintf("error %d", i)}, ExitCode: -1, Occurrences: 1,
+		}
+	}
+
+	m.SetTriage(failures)
+
+	detailed := m.GetDetailedError()
+	if !strings.HasPrefix(detailed, "step 1 failed\n\nLikely root cause first:\n") || !strings.Contains(detailed, "> error 3") {
+		t.Errorf("GetDetailedError should list every failure:\n%s", detailed)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Triage:") || !strings.Contains(view, "> error 2") || strings.Contains(view, "> error 3") {
+		t.Errorf("view should show the first %d failures:\n%s", triageViewLimit, view)
+	}
+}
+
+func TestValidationErrorModal_ListsInputsInOrder(t *testing.T) {
+	m := NewValidationErrorModal(map[string][]string{
+		"version":     {"must be > the latest tag v1.4.0"},
+		"approver":    {"value is required when environment=prod"},
+		"environment": {"must be one of: staging, prod"},
+	})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	view := m.View()
+
+	approver := strings.Index(view, "approver:")
+	environment := strings.Index(view, "> environment:")
+	version := strings.Index(view, "version:")
+
+	if approver < 0 || environment < 0 || version < 0 || !(approver < environment && environment < version) {
+		t.Errorf("expected inputs sorted with the second selected, got:\n%s", view)
+	}
+
+	if !strings.Contains(view, "value is required when environment=prod") {
+		t.Errorf("expected the cross-input error, got:\n%s", view)
+	}
+}
@@ -0,0 +1,274 @@
+package modal
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/ui"
+	"github.com/sahilm/fuzzy"
+)
+
+// PickerOption is a value offered by a PickerModal, with an optional description
+// shown next to it, such as the title of a pull request.
+type PickerOption struct {
+	Value       string
+	Description string
+}
+
+// pickerOptions adapts options to fuzzy.Source, matching on value and description.
+type pickerOptions []PickerOption
+
+func (o pickerOptions) String(i int) string {
+	return strings.TrimSpace(o[i].Value + " " + o[i].Description)
+}
+
+func (o pickerOptions) Len() int {
+	return len(o)
+}
+
+// PickerModal is a fuzzy picker of input values listed from a source like tags or
+// pull requests. Typing filters the options; when none match, Enter uses the typed
+// text, so values that are not listed can still be entered.
+type PickerModal struct {
+	title        string
+	options      []PickerOption
+	filtered     []PickerOption
+	current      string
+	selected     int
+	done         bool
+	result       string
+	filterInput  textinput.Model
+	keys         pickerKeyMap
+	maxHeight    int
+	scrollOffset int
+}
+
+type pickerKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Enter  key.Binding
+	Escape key.Binding
+}
+
+func defaultPickerKeyMap() pickerKeyMap {
+	return pickerKeyMap{
+		Up:     key.NewBinding(key.WithKeys("up", "ctrl+p")),
+		Down:   key.NewBinding(key.WithKeys("down", "ctrl+n")),
+		Enter:  key.NewBinding(key.WithKeys("enter")),
+		Escape: key.NewBinding(key.WithKeys("esc")),
+	}
+}
+
+// NewPickerModal creates a picker with the current value selected, or the default
+// when the current value is not among the options.
+func NewPickerModal(title string, options []PickerOption, current, defaultVal string) *PickerModal {
+	ti := textinput.New()
+	ti.Placeholder = "Type to filter..."
+	ti.Prompt = "/ "
+	ti.PromptStyle = ti.PromptStyle.UnsetBackground()
+	ti.TextStyle = ti.TextStyle.UnsetBackground()
+	ti.PlaceholderStyle = ti.PlaceholderStyle.UnsetBackground()
+	ti.CompletionStyle = ti.CompletionStyle.UnsetBackground()
+	ti.Cursor.Style = ti.Cursor.Style.UnsetBackground()
+	ti.Focus()
+
+	selected := -1
+
+	for i, opt := range options {
+		if opt.Value == current {
+			selected = i
+			break
+		}
+
+		if opt.Value == defaultVal && selected < 0 {
+			selected = i
+		}
+	}
+
+	m := &PickerModal{
+		title:       title,
+		options:     options,
+		filtered:    options,
+		current:     current,
+		selected:    max(selected, 0),
+		filterInput: ti,
+		keys:        defaultPickerKeyMap(),
+		maxHeight:   14,
+	}
+	m.adjustScroll()
+
+	return m
+}
+
+// SetSize updates the modal dimensions.
+func (m *PickerModal) SetSize(_, height int) {
+	m.maxHeight = min(max(int(float64(height)*0.8), 10), 30) - 6 // Account for title, filter, help text
+	m.adjustScroll()
+}
+
+// Update handles input for the picker modal.
+func (m *PickerModal) Update(msg tea.Msg) (Context, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.selected > 0 {
+			m.selected--
+			m.adjustScroll()
+		}
+
+		return m, nil
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.selected < len(m.filtered)-1 {
+			m.selected++
+			m.adjustScroll()
+		}
+
+		return m, nil
+	case key.Matches(keyMsg, m.keys.Enter):
+		switch {
+		case m.selected < len(m.filtered):
+			m.result = m.filtered[m.selected].Value
+		case strings.TrimSpace(m.filterInput.Value()) != "":
+			m.result = strings.TrimSpace(m.filterInput.Value())
+		default:
+			return m, nil
+		}
+
+		m.done = true
+
+		return m, func() tea.Msg {
+			return SelectResultMsg{Value: m.result}
+		}
+	case key.Matches(keyMsg, m.keys.Escape):
+		if m.filterInput.Value() != "" {
+			m.filterInput.SetValue("")
+			m.applyFilter()
+
+			return m, nil
+		}
+
+		m.done = true
+
+		return m, nil
+	}
+
+	previous := m.filterInput.Value()
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(keyMsg)
+
+	if m.filterInput.Value() != previous {
+		m.applyFilter()
+	}
+
+	return m, cmd
+}
+
+func (m *PickerModal) applyFilter() {
+	m.selected = 0
+	m.scrollOffset = 0
+
+	query := strings.TrimSpace(m.filterInput.Value())
+	if query == "" {
+		m.filtered = m.options
+		return
+	}
+
+	matches := fuzzy.FindFrom(query, pickerOptions(m.options))
+
+	m.filtered = make([]PickerOption, len(matches))
+	for i, match := range matches {
+		m.filtered[i] = m.options[match.Index]
+	}
+}
+
+func (m *PickerModal) adjustScroll() {
+	if m.selected < m.scrollOffset {
+		m.scrollOffset = m.selected
+	}
+
+	if m.selected >= m.scrollOffset+m.maxHeight {
+		m.scrollOffset = m.selected - m.maxHeight + 1
+	}
+}
+
+// View renders the picker modal.
+func (m *PickerModal) View() string {
+	var s strings.Builder
+
+	s.WriteString(ui.TitleStyle.Render(m.title))
+	s.WriteString("\n\n")
+	s.WriteString(m.filterInput.View())
+	s.WriteString("\n\n")
+
+	endIdx := min(m.scrollOffset+m.maxHeight, len(m.filtered))
+
+	if len(m.filtered) == 0 {
+		if query := strings.TrimSpace(m.filterInput.Value()); query != "" {
+			s.WriteString(ui.SubtitleStyle.Render("No matches, [enter] uses " + query))
+		} else {
+			s.WriteString(ui.SubtitleStyle.Render("No options found"))
+		}
+	}
+
+	for i := m.scrollOffset; i < endIdx; i++ {
+		opt := m.filtered[i]
+		cursor := "  "
+		style := ui.NormalStyle
+
+		if i == m.selected {
+			cursor = "> "
+			style = ui.SelectedStyle
+		}
+
+		line := style.Render(cursor + opt.Value)
+		if opt.Value == m.current {
+			line += style.Render(" *")
+		}
+
+		if opt.Description != "" {
+			line += "  " + ui.SubtitleStyle.Render(opt.Description)
+		}
+
+		s.WriteString(line)
+
+		if i < endIdx-1 {
+			s.WriteString("\n")
+		}
+	}
+
+	if m.scrollOffset > 0 || endIdx < len(m.filtered) {
+		scrollInfo := "  "
+		if m.scrollOffset > 0 {
+			scrollInfo += "↑ "
+		}
+
+		if endIdx < len(m.filtered) {
+			scrollInfo += "↓"
+		}
+
+		s.WriteString("\n")
+		s.WriteString(ui.SubtitleStyle.Render(scrollInfo))
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(ui.HelpStyle.Render("[type] filter  [↑↓] navigate  [enter] select  [esc] clear/cancel"))
+
+	return s.String()
+}
+
+// IsDone returns true if the modal is finished.
+func (m *PickerModal) IsDone() bool {
+	return m.done
+}
+
+// Result returns the picked value.
+func (m *PickerModal) Result() any {
+	return m.result
+}
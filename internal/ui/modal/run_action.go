@@ -0,0 +1,144 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleking/gh-lazydispatch/internal/ui"
+)
+
+// RunAction is an operation on an existing workflow run.
+type RunAction string
+
+// Run actions offered from the Live tab and the chain status modal.
+const (
+	RunActionCancel      RunAction = "cancel"
+	RunActionRerun       RunAction = "rerun"
+	RunActionRerunFailed RunAction = "rerun_failed"
+)
+
+// Label returns a short human-readable name for the action.
+func (a RunAction) Label() string {
+	switch a {
+	case RunActionCancel:
+		return "Cancel run"
+	case RunActionRerun:
+		return "Re-run all jobs"
+	case RunActionRerunFailed:
+		return "Re-run failed jobs"
+	default:
+		return string(a)
+	}
+}
+
+// RunActionRequestMsg asks the app to confirm an action on a run, e.g. from the chain status modal.
+type RunActionRequestMsg struct {
+	Action   RunAction
+	RunID    int64
+	Workflow string
+}
+
+// RunActionResultMsg is sent when a run action is confirmed or cancelled.
+type RunActionResultMsg struct {
+	Action    RunAction
+	RunID     int64
+	Workflow  string
+	Confirmed bool
+}
+
+type runActionKeyMap struct {
+	Confirm key.Binding
+	Cancel  key.Binding
+}
+
+// RunActionModal confirms cancelling or re-running a workflow run.
+type RunActionModal struct {
+	request RunActionRequestMsg
+	done    bool
+	result  RunActionResultMsg
+	keys    runActionKeyMap
+}
+
+// NewRunActionModal creates a run action confirmation modal.
+func NewRunActionModal(action RunAction, runID int64, workflow string) *RunActionModal {
+	return &RunActionModal{
+		request: RunActionRequestMsg{Action: action, RunID: runID, Workflow: workflow},
+		keys: runActionKeyMap{
+			Confirm: key.NewBinding(key.WithKeys("enter", "y")),
+			Cancel:  key.NewBinding(key.WithKeys("esc", "n", "q")),
+		},
+	}
+}
+
+// Update handles input for the run action modal.
+func (m *RunActionModal) Update(msg tea.Msg) (Context, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Confirm):
+		return m, m.finish(true)
+	case key.Matches(keyMsg, m.keys.Cancel):
+		return m, m.finish(false)
+	}
+
+	return m, nil
+}
+
+func (m *RunActionModal) finish(confirmed bool) tea.Cmd {
+	m.done = true
+	m.result = RunActionResultMsg{
+		Action:    m.request.Action,
+		RunID:     m.request.RunID,
+		Workflow:  m.request.Workflow,
+		Confirmed: confirmed,
+	}
+
+	return func() tea.Msg {
+		return m.result
+	}
+}
+
+// View renders the run action modal.
+func (m *RunActionModal) View() string {
+	var s strings.Builder
+
+	s.WriteString(ui.TitleStyle.Render(m.request.Action.Label() + "?"))
+	s.WriteString("\n\n")
+
+	s.WriteString(ui.NormalStyle.Render("  Workflow: "))
+	s.WriteString(ui.TableDimmedStyle.Render(m.request.Workflow))
+	s.WriteString("\n")
+
+	s.WriteString(ui.NormalStyle.Render("  Run:      "))
+	s.WriteString(ui.TableDimmedStyle.Render(fmt.Sprintf("#%d", m.request.RunID)))
+	s.WriteString("\n\n")
+
+	switch m.request.Action {
+	case RunActionCancel:
+		s.WriteString(ui.NormalStyle.Render("Jobs still running will be stopped."))
+	case RunActionRerun:
+		s.WriteString(ui.NormalStyle.Render("Every job runs again as a new attempt of this run."))
+	case RunActionRerunFailed:
+		s.WriteString(ui.NormalStyle.Render("Failed jobs and their dependents run again as a new attempt of this run."))
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(ui.HelpStyle.Render("[enter/y] confirm  [esc/n] cancel"))
+
+	return s.String()
+}
+
+// IsDone returns true if the modal is finished.
+func (m *RunActionModal) IsDone() bool {
+	return m.done
+}
+
+// Result returns the run action result.
+func (m *RunActionModal) Result() any {
+	return m.result
+}
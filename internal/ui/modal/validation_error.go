@@ -2,6 +2,8 @@ package modal
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -12,6 +14,7 @@ import (
 // ValidationErrorModal displays validation errors and allows override or fixing.
 type ValidationErrorModal struct {
 	errors   map[string][]string
+	names    []string // inputs with errors, sorted so the selection stays put between renders
 	done     bool
 	override bool
 	selected int
@@ -45,6 +48,7 @@ type ValidationErrorResultMsg struct {
 func NewValidationErrorModal(errors map[string][]string) *ValidationErrorModal {
 	return &ValidationErrorModal{
 		errors: errors,
+		names:  slices.Sorted(maps.Keys(errors)),
 		keys:   defaultValidationErrorKeyMap(),
 	}
 }
@@ -91,9 +95,9 @@ func (m *ValidationErrorModal) View() string {
 	s.WriteString(ui.SubtitleStyle.Render("The following inputs have validation errors:"))
 	s.WriteString("\n\n")
 
-	idx := 0
+	for idx, inputName := range m.names {
+		errs := m.errors[inputName]
 
-	for inputName, errs := range m.errors {
 		prefix := "  "
 		if idx == m.selected {
 			prefix = "> "
@@ -118,8 +122,6 @@ func (m *ValidationErrorModal) View() string {
 
 			s.WriteString("\n")
 		}
-
-		idx++
 	}
 
 	s.WriteString("\n")
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+// ValidateInputs checks the inputs of a dispatch against the workflow's definitions:
+// unknown inputs, required ones, choice options, numbers, and validation rules.
+// ctx.LatestTag is looked up by the caller when UsesLatestTag reports the workflow
+// needs it; ctx.Inputs is set to inputs. Returns errors keyed by input name.
+func ValidateInputs(wf workflow.WorkflowFile, inputs map[string]string, ctx rule.Context) map[string][]string {
+	errs := make(map[string][]string)
+	defs := wf.GetInputs()
+
+	ctx.Inputs = inputs
+
+	for name := range inputs {
+		if _, ok := defs[name]; !ok {
+			errs[name] = append(errs[name], "unknown input")
+		}
+	}
+
+	for name, def := range defs {
+		value := inputs[name]
+
+		if def.Required && strings.TrimSpace(value) == "" {
+			errs[name] = append(errs[name], "value is required")
+		}
+
+		switch def.InputType() {
+		case "choice":
+			if value != "" && len(def.Options) > 0 && !slices.Contains(def.Options, value) {
+				errs[name] = append(errs[name], "must be one of: "+strings.Join(def.Options, ", "))
+			}
+		case "number":
+			if value != "" {
+				if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+					errs[name] = append(errs[name], fmt.Sprintf("%q is not a number", value))
+				}
+			}
+		}
+
+		if len(def.ValidationRules) > 0 {
+			errs[name] = append(errs[name], rule.ValidateInput(value, def.ValidationRules, &ctx)...)
+		}
+
+		if len(errs[name]) == 0 {
+			delete(errs, name)
+		}
+	}
+
+	return errs
+}
+
+// UsesLatestTag reports whether a rule of the workflow's inputs compares with the
+// latest tag, which then has to be looked up with LatestTag before validating.
+func UsesLatestTag(wf workflow.WorkflowFile) bool {
+	for _, def := range wf.GetInputs() {
+		if rule.UsesLatestTag(def.ValidationRules) {
+			return true
+		}
+	}
+
+	return false
+}
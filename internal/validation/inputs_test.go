@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
+	"github.com/kyleking/gh-lazydispatch/internal/workflow"
+)
+
+func TestValidateInputs(t *testing.T) {
+	wf := workflow.WorkflowFile{
+		Filename: "deploy.yml",
+		On: workflow.OnTrigger{
+			WorkflowDispatch: &workflow.WorkflowDispatch{
+				Inputs: map[string]workflow.WorkflowInput{
+					"environment": {Type: "choice", Required: true, Options: []string{"staging", "prod"}},
+					"replicas":    {Type: "number"},
+					"version": {ValidationRules: []rule.ValidationRule{
+						{Type: rule.RuleRequired, When: &rule.Condition{Input: "environment", Value: "prod"}},
+						{Type: rule.RuleSemver, Pattern: ">latest"},
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		inputs map[string]string
+		ctx    rule.Context
+		want   map[string]int // number of errors by input
+	}{
+		{
+			name:   "valid",
+			inputs: map[string]string{"environment": "staging", "replicas": "2.5", "version": "v1.3.0"},
+			ctx:    rule.Context{LatestTag: "v1.2.0"},
+			want:   map[string]int{},
+		},
+		{
+			name:   "required and number",
+			inputs: map[string]string{"replicas": "three"},
+			want:   map[string]int{"environment": 1, "replicas": 1},
+		},
+		{
+			name:   "choice, unknown and conditional",
+			inputs: map[string]string{"environment": "prod", "region": "eu"},
+			want:   map[string]int{"region": 1, "version": 1},
+		},
+		{
+			name:   "latest tag",
+			inputs: map[string]string{"environment": "staging", "version": "v1.1.0"},
+			ctx:    rule.Context{LatestTag: "v1.2.0"},
+			want:   map[string]int{"version": 1},
+		},
+		{
+			name:   "invalid choice",
+			inputs: map[string]string{"environment": "dev"},
+			want:   map[string]int{"environment": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateInputs(wf, tt.inputs, tt.ctx)
+
+			got := make(map[string]int, len(errs))
+			for name, msgs := range errs {
+				got[name] = len(msgs)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want errors %v", errs, tt.want)
+			}
+		})
+	}
+
+	if !UsesLatestTag(wf) {
+		t.Error("expected the semver:>latest rule to be found")
+	}
+}
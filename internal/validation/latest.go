@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyleking/gh-lazydispatch/internal/git"
+	"github.com/kyleking/gh-lazydispatch/internal/rule"
+)
+
+// TagLister lists the tags of a repository that is not checked out, like github.Client.
+type TagLister interface {
+	ListTags() ([]string, error)
+}
+
+// LatestTag returns the repository's highest semantic version tag for semver:>latest
+// rules, or "" when no tag is one. Tags are listed with git when remote is nil and
+// through remote otherwise.
+func LatestTag(ctx context.Context, remote TagLister) (string, error) {
+	var (
+		tags []string
+		err  error
+	)
+
+	if remote == nil {
+		tags, err = git.FetchTags(ctx)
+	} else {
+		tags, err = remote.ListTags()
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return rule.LatestVersion(tags), nil
+}
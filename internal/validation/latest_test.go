@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type tagLister struct {
+	tags []string
+	err  error
+}
+
+func (l tagLister) ListTags() ([]string, error) {
+	return l.tags, l.err
+}
+
+func TestLatestTag_Remote(t *testing.T) {
+	tests := []struct {
+		name    string
+		lister  tagLister
+		want    string
+		wantErr string
+	}{
+		{name: "highest version", lister: tagLister{tags: []string{"v1.9.0", "nightly", "v1.10.0", "v1.10.0-rc.1"}}, want: "v1.10.0"},
+		{name: "no versions", lister: tagLister{tags: []string{"nightly"}}},
+		{name: "no tags", lister: tagLister{}},
+		{name: "list error", lister: tagLister{err: errors.New("HTTP 404")}, wantErr: "HTTP 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LatestTag(t.Context(), tt.lister)
+			if got != tt.want || (tt.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("LatestTag() = %q, %v; want %q, %q", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -12,6 +12,15 @@ import (
 // PollInterval is the default interval between API polls.
 const PollInterval = 5 * time.Second
 
+// pollInterval is how often watchers poll their runs.
+var pollInterval = PollInterval
+
+// SetPollInterval overrides how often watchers started afterwards poll their runs.
+// Intended for tests; pass PollInterval to restore.
+func SetPollInterval(d time.Duration) {
+	pollInterval = d
+}
+
 // WatchedRun represents a run being watched.
 type WatchedRun struct {
 	RunID      int64
@@ -21,7 +30,10 @@ type WatchedRun struct {
 	Jobs       []JobStatus
 	HTMLURL    string
 	UpdatedAt  time.Time
+	Attempt    int
 	LastError  error
+
+	minAttempt int // polls reporting an older attempt are ignored, see WatchAttempt
 }
 
 // JobStatus represents the status of a job in a watched run.
@@ -99,6 +111,23 @@ func (w *RunWatcher) Watch(runID int64, workflowName string) {
 	w.pollRun(runID)
 }
 
+// WatchAttempt watches a run that was just re-run, ignoring its previous attempts.
+// Until GitHub reports attempt minAttempt the run stays queued, rather than showing
+// the stale conclusion of the attempt being replaced.
+func (w *RunWatcher) WatchAttempt(runID int64, workflowName string, minAttempt int) {
+	w.mu.Lock()
+	w.runs[runID] = &WatchedRun{
+		RunID:      runID,
+		Workflow:   workflowName,
+		Status:     github.StatusQueued,
+		minAttempt: minAttempt,
+	}
+	w.mu.Unlock()
+
+	w.ensurePolling()
+	w.pollRun(runID)
+}
+
 // Unwatch stops watching a workflow run.
 func (w *RunWatcher) Unwatch(runID int64) {
 	w.mu.Lock()
@@ -198,7 +227,7 @@ func (w *RunWatcher) ensurePolling() {
 
 	w.isPolling = true
 
-	w.ticker = time.NewTicker(PollInterval)
+	w.ticker = time.NewTicker(pollInterval)
 	w.wg.Add(1)
 
 	go w.pollLoop()
@@ -246,6 +275,18 @@ func (w *RunWatcher) pollRun(runID int64) {
 		return
 	}
 
+	w.mu.RLock()
+
+	minAttempt := 0
+	if watched, ok := w.runs[runID]; ok {
+		minAttempt = watched.minAttempt
+	}
+	w.mu.RUnlock()
+
+	if run.RunAttempt < minAttempt {
+		return
+	}
+
 	jobs, err := w.client.GetWorkflowRunJobs(runID)
 	if err != nil {
 		w.mu.Lock()
@@ -265,7 +306,9 @@ func (w *RunWatcher) pollRun(runID int64) {
 		Conclusion: run.Conclusion,
 		HTMLURL:    run.HTMLURL,
 		UpdatedAt:  run.UpdatedAt,
+		Attempt:    run.RunAttempt,
 		Jobs:       make([]JobStatus, len(jobs)),
+		minAttempt: minAttempt,
 	}
 
 	for i, job := range jobs {
@@ -2,9 +2,11 @@ package watcher_test
 
 import (
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/kyleking/gh-lazydispatch/internal/exec"
 	"github.com/kyleking/gh-lazydispatch/internal/github"
 	"github.com/kyleking/gh-lazydispatch/internal/watcher"
 )
@@ -155,6 +157,36 @@ func TestPollRun_SurfacesError(t *testing.T) {
 	}
 }
 
+func TestWatchAttempt_IgnoresPreviousAttempt(t *testing.T) {
+	client := &mockGitHubClient{
+		runs: map[int64]*github.WorkflowRun{
+			123: {ID: 123, Name: "test-workflow", Status: github.StatusCompleted, Conclusion: github.ConclusionFailure, RunAttempt: 1},
+		},
+	}
+
+	w := watcher.NewWatcher(client)
+	defer w.Stop()
+
+	w.WatchAttempt(123, "test-workflow", 2)
+
+	run, ok := w.GetRun(123)
+	if !ok {
+		t.Fatal("expected to find run 123")
+	}
+
+	if !run.IsActive() || run.Conclusion != "" {
+		t.Errorf("stale attempt applied: status %q, conclusion %q", run.Status, run.Conclusion)
+	}
+
+	client.runs[123] = &github.WorkflowRun{ID: 123, Name: "test-workflow", Status: github.StatusInProgress, RunAttempt: 2}
+	w.WatchAttempt(123, "test-workflow", 2)
+
+	run, _ = w.GetRun(123)
+	if run.Status != github.StatusInProgress || run.Attempt != 2 {
+		t.Errorf("new attempt: got status %q attempt %d, want in_progress attempt 2", run.Status, run.Attempt)
+	}
+}
+
 func TestClearCompleted(t *testing.T) {
 	client := &mockGitHubClient{
 		runs: map[int64]*github.WorkflowRun{
@@ -258,3 +290,58 @@ func TestWatcher_DoubleStop(t *testing.T) {
 	w.Stop()
 	w.Stop() // Should not panic
 }
+
+func TestWatcher_ReplaysCassette(t *testing.T) {
+	cassette := exec.UseCassette(t, "../../testdata/cassettes/watcher_failed_run.yaml")
+
+	client, err := github.NewClientWithExecutor("acme/app", cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher.SetPollInterval(5 * time.Millisecond)
+	defer watcher.SetPollInterval(watcher.PollInterval)
+
+	w := watcher.NewWatcher(client)
+	defer w.Stop()
+
+	w.Watch(5201, "CI")
+
+	var (
+		statuses []string
+		last     watcher.WatchedRun
+	)
+
+	timeout := time.After(5 * time.Second)
+
+	for last.Status != github.StatusCompleted {
+		select {
+		case update := <-w.Updates():
+			if update.Error != nil {
+				t.Fatalf("unexpected error: %v", update.Error)
+			}
+
+			last = update.Run
+			statuses = append(statuses, last.Status)
+		case <-timeout:
+			t.Fatalf("timeout waiting for the run to complete; saw %v", statuses)
+		}
+	}
+
+	want := []string{github.StatusQueued, github.StatusInProgress, github.StatusCompleted}
+	if !slices.Equal(statuses, want) {
+		t.Errorf("statuses: got %v, want %v", statuses, want)
+	}
+
+	if last.Conclusion != github.ConclusionFailure {
+		t.Errorf("Conclusion: got %q, want %q", last.Conclusion, github.ConclusionFailure)
+	}
+
+	if len(last.Jobs) != 1 || len(last.Jobs[0].Steps) != 4 {
+		t.Fatalf("Jobs: got %+v, want one job with 4 steps", last.Jobs)
+	}
+
+	if step := last.Jobs[0].Steps[2]; step.Name != "Run tests" || step.Conclusion != github.ConclusionFailure {
+		t.Errorf("failed step: got %q %q, want Run tests failure", step.Name, step.Conclusion)
+	}
+}
@@ -3,14 +3,27 @@ package workflow
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
+// WorkflowDir is the directory holding a repository's workflow files, relative to its root.
+const WorkflowDir = ".github/workflows"
+
+// FileSource reads files of a repository that is not checked out, e.g. through the GitHub contents API.
+type FileSource interface {
+	// ListFiles returns the names of the files in dir at ref.
+	ListFiles(dir, ref string) ([]string, error)
+	// ReadFile returns the content of the file at path at ref.
+	ReadFile(path, ref string) ([]byte, error)
+}
+
 // Discover finds all workflow files in the .github/workflows directory
 // and returns only those with workflow_dispatch triggers.
 func Discover(repoRoot string) ([]WorkflowFile, error) {
-	workflowDir := filepath.Join(repoRoot, ".github", "workflows")
+	workflowDir := filepath.Join(repoRoot, filepath.FromSlash(WorkflowDir))
 
 	patterns := []string{
 		filepath.Join(workflowDir, "*.yml"),
@@ -36,6 +49,49 @@ func Discover(repoRoot string) ([]WorkflowFile, error) {
 			continue
 		}
 
+		workflows = append(workflows, wf)
+	}
+
+	return dispatchable(workflows), nil
+}
+
+// DiscoverFrom finds the dispatchable workflows of a repository at ref, reading
+// them from src instead of the local checkout.
+func DiscoverFrom(src FileSource, ref string) ([]WorkflowFile, error) {
+	names, err := src.ListFiles(WorkflowDir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var workflows []WorkflowFile
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".yml") && !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+
+		data, err := src.ReadFile(path.Join(WorkflowDir, name), ref)
+		if err != nil {
+			return nil, err
+		}
+
+		wf, err := Parse(data)
+		if err != nil {
+			continue
+		}
+
+		wf.Filename = name
+		workflows = append(workflows, wf)
+	}
+
+	return dispatchable(workflows), nil
+}
+
+// dispatchable keeps the workflows with a workflow_dispatch trigger, sorted by filename.
+func dispatchable(all []WorkflowFile) []WorkflowFile {
+	var workflows []WorkflowFile
+
+	for _, wf := range all {
 		if wf.IsDispatchable() {
 			workflows = append(workflows, wf)
 		}
@@ -45,7 +101,7 @@ func Discover(repoRoot string) ([]WorkflowFile, error) {
 		return workflows[i].Filename < workflows[j].Filename
 	})
 
-	return workflows, nil
+	return workflows
 }
 
 func parseWorkflowFile(path string) (WorkflowFile, error) {
@@ -69,3 +69,79 @@ func TestDiscover_EmptyDir(t *testing.T) {
 		t.Errorf("expected 0 workflows for empty dir, got %d", len(workflows))
 	}
 }
+
+// dirSource serves a local checkout as a FileSource, recording the refs it was asked for.
+type dirSource struct {
+	root string
+	refs []string
+}
+
+func (s *dirSource) ListFiles(dir, ref string) ([]string, error) {
+	s.refs = append(s.refs, ref)
+
+	entries, err := os.ReadDir(filepath.Join(s.root, dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+func (s *dirSource) ReadFile(path, ref string) ([]byte, error) {
+	s.refs = append(s.refs, ref)
+	return os.ReadFile(filepath.Join(s.root, path))
+}
+
+func TestDiscoverFrom(t *testing.T) {
+	_, currentFile, _, _ := runtime.Caller(0)
+	repoRoot := filepath.Join(filepath.Dir(currentFile), "..", "..", "testdata")
+
+	local, err := Discover(repoRoot)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	src := &dirSource{root: repoRoot}
+
+	remote, err := DiscoverFrom(src, "v1.2.0")
+	if err != nil {
+		t.Fatalf("DiscoverFrom failed: %v", err)
+	}
+
+	if len(remote) != len(local) {
+		t.Fatalf("expected %d workflows, got %d", len(local), len(remote))
+	}
+
+	for i := range local {
+		if remote[i].Filename != local[i].Filename || remote[i].Name != local[i].Name {
+			t.Errorf("workflow %d = %s (%s), want %s (%s)",
+				i, remote[i].Filename, remote[i].Name, local[i].Filename, local[i].Name)
+		}
+
+		if len(remote[i].GetInputs()) != len(local[i].GetInputs()) {
+			t.Errorf("%s: got %d inputs, want %d", remote[i].Filename, len(remote[i].GetInputs()), len(local[i].GetInputs()))
+		}
+	}
+
+	for _, ref := range src.refs {
+		if ref != "v1.2.0" {
+			t.Errorf("read at ref %q, want v1.2.0", ref)
+		}
+	}
+}
+
+func TestDiscoverFrom_ListError(t *testing.T) {
+	src := &dirSource{root: t.TempDir()}
+
+	if _, err := DiscoverFrom(src, ""); err == nil {
+		t.Error("expected error for missing workflow directory")
+	}
+}
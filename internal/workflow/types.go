@@ -51,3 +51,15 @@ func (w WorkflowFile) GetInputs() map[string]WorkflowInput {
 
 	return w.On.WorkflowDispatch.Inputs
 }
+
+// InputTypes returns the normalized type of every input, keyed by input name.
+func (w WorkflowFile) InputTypes() map[string]string {
+	inputs := w.GetInputs()
+
+	types := make(map[string]string, len(inputs))
+	for name, input := range inputs {
+		types[name] = input.InputType()
+	}
+
+	return types
+}
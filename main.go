@@ -4,10 +4,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kyleking/gh-lazydispatch/internal/app"
+	"github.com/kyleking/gh-lazydispatch/internal/cli"
+	"github.com/kyleking/gh-lazydispatch/internal/config"
+	"github.com/kyleking/gh-lazydispatch/internal/demo"
 	"github.com/kyleking/gh-lazydispatch/internal/frecency"
+	"github.com/kyleking/gh-lazydispatch/internal/github"
+	"github.com/kyleking/gh-lazydispatch/internal/logs"
 	"github.com/kyleking/gh-lazydispatch/internal/runner"
 	"github.com/kyleking/gh-lazydispatch/internal/ui"
 	"github.com/kyleking/gh-lazydispatch/internal/ui/theme"
@@ -19,15 +25,27 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if code, ok := runSubcommand(os.Args[1], os.Args[2:]); ok {
+			os.Exit(code)
+		}
+	}
+
 	var (
 		showVersion bool
 		showHelp    bool
+		repoFlag    string
+		refFlag     string
+		demoMode    bool
 	)
 
 	flag.BoolVar(&showVersion, "version", false, "Show version")
 	flag.BoolVar(&showVersion, "v", false, "Show version (shorthand)")
 	flag.BoolVar(&showHelp, "help", false, "Show help")
 	flag.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
+	flag.StringVar(&repoFlag, "repo", "", "Repository (owner/name) to read workflows from instead of the current directory")
+	flag.StringVar(&refFlag, "ref", "", "Branch or tag to read workflows at and dispatch on")
+	flag.BoolVar(&demoMode, "demo", false, "Run against a simulated GitHub with a demo repository")
 	flag.Parse()
 
 	if showVersion {
@@ -40,6 +58,63 @@ func main() {
 		os.Exit(0)
 	}
 
+	ui.InitTheme(theme.Detect())
+
+	if demoMode {
+		if repoFlag != "" || refFlag != "" {
+			fmt.Fprintln(os.Stderr, "Error: --demo cannot be combined with --repo or --ref")
+			os.Exit(1)
+		}
+
+		os.Exit(runDemo())
+	}
+
+	history, err := frecency.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load history: %v\n", err)
+
+		history = frecency.NewStore()
+	}
+
+	var model app.Model
+
+	if repoFlag != "" || refFlag != "" {
+		model = newRemoteModel(repoFlag, refFlag, history)
+	} else {
+		model = newLocalModel(history)
+	}
+
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load user config: %v\n", err)
+	} else if userConfig != nil {
+		model.SetRepos(userConfig.Repos)
+	}
+
+	os.Exit(runProgram(model))
+}
+
+// runProgram runs the TUI and returns the exit code.
+func runProgram(model app.Model) int {
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		return 1
+	}
+
+	if m, ok := final.(app.Model); ok {
+		if err := m.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving log cache: %v\n", err)
+		}
+	}
+
+	return 0
+}
+
+// newLocalModel reads the workflows of the repository checked out in the current directory.
+func newLocalModel(history *frecency.Store) app.Model {
 	cwd, err := os.Getwd()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
@@ -55,6 +130,7 @@ func main() {
 	if len(workflows) == 0 {
 		fmt.Println("No dispatchable workflows found in .github/workflows/")
 		fmt.Println("\nWorkflows must have 'workflow_dispatch' trigger to be dispatchable.")
+		fmt.Println("Use --repo owner/name to dispatch in a repository that is not checked out.")
 		os.Exit(0)
 	}
 
@@ -65,23 +141,84 @@ func main() {
 		repo = "unknown/unknown"
 	}
 
-	history, err := frecency.Load()
+	return app.New(workflows, history, repo, app.DefaultDirs())
+}
+
+// newRemoteModel reads the workflows and lazydispatch.yml of a repository at ref
+// through the GitHub API. The repository defaults to the current directory's.
+func newRemoteModel(repo, ref string, history *frecency.Store) app.Model {
+	if repo == "" {
+		detected, err := runner.DetectRepo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --ref without --repo requires a GitHub repository in the current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		repo = detected
+	}
+
+	client, err := github.NewClient(repo)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not load history: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-		history = frecency.NewStore()
+	remote, err := app.LoadRemoteRepo(client, ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading workflows of %s: %v\n", repo, err)
+		os.Exit(1)
 	}
 
-	detectedTheme := theme.Detect()
-	ui.InitTheme(detectedTheme)
+	if len(remote.Workflows) == 0 {
+		fmt.Printf("No dispatchable workflows found in %s at %s\n", repo, remote.Ref)
+		fmt.Println("\nWorkflows must have 'workflow_dispatch' trigger to be dispatchable.")
+		os.Exit(0)
+	}
 
-	model := app.New(workflows, history, repo)
+	return app.NewRemote(remote, history, app.DefaultDirs())
+}
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
-		os.Exit(1)
+// runDemo shows the demo repository of an in-process fake GitHub, where
+// dispatched runs and chains play out without touching GitHub. History, chain
+// journals and caches are kept in a temporary directory, removed on exit, so
+// demo runs never mix with those of real repositories; the user config is not read.
+// Exported logs go to the usual export directory so they outlive the demo.
+func runDemo() int {
+	dir, err := os.MkdirTemp("", "lazydispatch-demo-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	defer os.RemoveAll(dir)
+
+	history, err := frecency.LoadFrom(filepath.Join(dir, "history.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	remote, err := app.LoadRemoteRepo(demo.NewServer().Client(), "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading the demo repository: %v\n", err)
+		return 1
 	}
+
+	dirs := app.Dirs{Journal: filepath.Join(dir, "chains"), Logs: filepath.Join(dir, "logs"), Exports: logs.DefaultExportDir()}
+
+	return runProgram(app.NewRemote(remote, history, dirs))
+}
+
+// runSubcommand runs a headless subcommand. ok is false when name is not a subcommand.
+func runSubcommand(name string, args []string) (code int, ok bool) {
+	switch name {
+	case "run":
+		return cli.RunCommand(args, os.Stdout, os.Stderr, cli.NewEnv), true
+	case "chain":
+		return cli.ChainCommand(args, os.Stdout, os.Stderr, cli.NewEnv), true
+	}
+
+	return 0, false
 }
 
 func printHelp() {
@@ -89,24 +226,42 @@ func printHelp() {
 
 Usage:
   lazydispatch [flags]
+  lazydispatch --repo owner/name [--ref REF]
+  lazydispatch --demo
+  lazydispatch run <workflow> [--ref REF] [--input k=v]... [--from-history N] [--watch] [--json]
+  lazydispatch chain <name> [--branch BRANCH] [--var k=v]... [--json]
 
 Description:
   A TUI for triggering GitHub Actions workflow_dispatch workflows with
   fuzzy selection, interactive input configuration, and frecency-based
   history tracking.
 
+Subcommands:
+  run            Dispatch a workflow without the TUI (see lazydispatch run --help)
+  chain          Run a chain to completion without the TUI (see lazydispatch chain --help)
+
 Flags:
-  -h, --help     Show this help message
-  -v, --version  Show version
+  --repo OWNER/NAME  Read workflows and lazydispatch.yml from a repository through
+                     the GitHub API, so no local checkout is needed
+  --ref REF          Branch or tag to read workflows at and dispatch on
+                     (default: the repository's default branch)
+  --demo             Run against a simulated GitHub with a demo repository, whose
+                     runs, logs and chains play out without touching GitHub
+  -h, --help         Show this help message
+  -v, --version      Show version
 
 Environment Variables:
   CATPPUCCIN_THEME   Override theme (latte/macchiato)
 
+Configuration:
+  ~/.config/lazydispatch/config.yml   Repositories for the repo switcher (repos: [owner/name, ...])
+
 Keyboard Shortcuts:
   Tab / Shift+Tab    Switch between panes
   ↑/k, ↓/j           Navigate within pane
   Enter              Select / Execute workflow
   b                  Select branch
+  p                  Switch repository
   w                  Toggle watch mode
   1-9                Edit input by number
   ?                  Show help